@@ -75,6 +75,14 @@ func TestSetCmd(t *testing.T) {
 				"tune_swappiness":            false,
 				"tune_transparent_hugepages": false,
 				"enable_memory_locking":      false,
+				"tune_ethtool":               false,
+				"tune_cgroup":                false,
+				"tune_nic_channels":          false,
+				"tune_block_queue":           false,
+				"tune_max_map_count":         false,
+				"tune_dirty_ratio":           false,
+				"tune_filesystem_check":      false,
+				"tune_files_limit":           false,
 				"tune_fstrim":                false,
 				"tune_coredump":              false,
 				"tune_ballast_file":          false,
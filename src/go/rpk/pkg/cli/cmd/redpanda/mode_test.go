@@ -30,19 +30,25 @@ func fillRpkConfig(path, mode string) *config.Config {
 	val := mode == config.ModeProd
 	conf.Redpanda.DeveloperMode = !val
 	conf.Rpk = config.RpkConfig{
-		TuneNetwork:        val,
-		TuneDiskScheduler:  val,
-		TuneDiskWriteCache: val,
-		TuneNomerges:       val,
-		TuneDiskIrq:        val,
-		TuneFstrim:         val,
-		TuneCpu:            val,
-		TuneAioEvents:      val,
-		TuneClocksource:    val,
-		TuneSwappiness:     val,
-		CoredumpDir:        path,
-		Overprovisioned:    !val,
-		TuneBallastFile:    val,
+		TuneNetwork:         val,
+		TuneDiskScheduler:   val,
+		TuneDiskWriteCache:  val,
+		TuneNomerges:        val,
+		TuneDiskIrq:         val,
+		TuneEthtool:         val,
+		TuneBlockQueue:      val,
+		TuneMaxMapCount:     val,
+		TuneDirtyRatio:      val,
+		TuneFilesystemCheck: val,
+		TuneFilesLimit:      val,
+		TuneFstrim:          val,
+		TuneCpu:             val,
+		TuneAioEvents:       val,
+		TuneClocksource:     val,
+		TuneSwappiness:      val,
+		CoredumpDir:         path,
+		Overprovisioned:     !val,
+		TuneBallastFile:     val,
 	}
 	return conf
 }
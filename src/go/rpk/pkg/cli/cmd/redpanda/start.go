@@ -701,7 +701,9 @@ func resolveWellKnownIo(
 func tuneAll(
 	fs afero.Fs, cpuSet string, conf *config.Config, timeout time.Duration,
 ) ([]api.TunerPayload, error) {
-	params := &factory.TunerParams{}
+	// redpanda is exec'd in place of rpk, keeping its PID, so moving rpk
+	// into redpanda's cgroup places redpanda in it.
+	params := &factory.TunerParams{CgroupPid: os.Getpid()}
 	tunerFactory := factory.NewDirectExecutorTunersFactory(fs, *conf, timeout)
 	hw := hwloc.NewHwLocCmd(vos.NewProc(), timeout)
 	if cpuSet == "" {
@@ -716,6 +718,7 @@ func tuneAll(
 			return []api.TunerPayload{}, err
 		}
 		params.CpuMask = cpuMask
+		params.CpuSet = cpuSet
 	}
 
 	err := factory.FillTunerParamsWithValuesFromConfig(params, conf)
@@ -728,18 +731,18 @@ func tuneAll(
 
 	for _, tunerName := range availableTuners {
 		enabled := factory.IsTunerEnabled(tunerName, conf.Rpk)
-		tuner := tunerFactory.CreateTuner(tunerName, params)
-		supported, reason := tuner.CheckIfSupported()
-		payload := api.TunerPayload{
-			Name:      tunerName,
-			Enabled:   enabled,
-			Supported: supported,
-		}
+		payload := api.TunerPayload{Name: tunerName}
 		if !enabled {
+			// Checking whether it's supported may probe the system,
+			// e.g. dial the seed servers.
 			log.Infof("Skipping disabled tuner %s", tunerName)
 			tunerPayloads = append(tunerPayloads, payload)
 			continue
 		}
+		tuner := tunerFactory.CreateTuner(tunerName, params)
+		supported, reason := tuner.CheckIfSupported()
+		payload.Enabled = true
+		payload.Supported = supported
 		if !supported {
 			log.Debugf("Tuner '%s' is not supported - %s", tunerName, reason)
 			tunerPayloads = append(tunerPayloads, payload)
@@ -7,19 +7,22 @@
 // the Business Source License, use of this software will be governed
 // by the Apache License, Version 2.0
 
-// +build linux
-
 package redpanda
 
 import (
 	"bufio"
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"runtime"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/fatih/color"
@@ -29,16 +32,30 @@ import (
 	tunecmd "github.com/vectorizedio/redpanda/src/go/rpk/pkg/cli/cmd/redpanda/tune"
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/cli/ui"
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/config"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors/commands"
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/factory"
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/hwloc"
 )
 
+const (
+	formatText = "text"
+	formatJSON = "json"
+)
+
+// The outcome of running a tuner. It's printed as JSON with --format=json.
 type result struct {
-	name      string
-	applied   bool
-	enabled   bool
-	supported bool
-	errMsg    string
+	Name      string `json:"name"`
+	Enabled   bool   `json:"enabled"`
+	Supported bool   `json:"supported"`
+	Applied   bool   `json:"applied"`
+	Changed   bool   `json:"changed"`
+	// Whether the tuner was cut off, as it didn't complete within the
+	// per-tuner timeout or before the deadline.
+	TimedOut bool `json:"timed_out,omitempty"`
+	// The error the tuner failed with, or why it's unsupported.
+	ErrMsg string `json:"error,omitempty"`
 }
 
 func NewTuneCommand(fs afero.Fs, mgr config.Manager) *cobra.Command {
@@ -46,9 +63,26 @@ func NewTuneCommand(fs afero.Fs, mgr config.Manager) *cobra.Command {
 	var (
 		configFile        string
 		outTuneScriptFile string
+		outUndoScriptFile string
+		outManifestFile   string
+		outputFormat      string
 		cpuSet            string
 		timeout           time.Duration
+		commandTimeout    time.Duration
+		tunerTimeout      time.Duration
+		deadline          time.Duration
 		interactive       bool
+		concurrency       int
+		verifyWrites      bool
+		writeRetries      int
+		dryRun            bool
+		ownerSpec         string
+		exclude           []string
+		profilePath       string
+		continueOnError   bool
+		lateBindDevice    bool
+		guardWrites       bool
+		showProgress      bool
 	)
 	baseMsg := "Sets the OS parameters to tune system performance." +
 		" Available tuners: all, " +
@@ -65,31 +99,64 @@ func NewTuneCommand(fs afero.Fs, mgr config.Manager) *cobra.Command {
 			if len(args) == 1 && args[0] == "all" {
 				return nil
 			}
-
-			for _, toTune := range strings.Split(args[0], ",") {
-				if !factory.IsTunerAvailable(toTune) {
-					return fmt.Errorf("invalid element to tune '%s' "+
-						"only %s are supported",
-						args[0], factory.AvailableTuners())
-				}
-			}
-			return nil
+			_, err := factory.DefaultRegistry.Enabled(
+				strings.Split(args[0], ",")...,
+			)
+			return err
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if !tunerParamsEmpty(&tunerParams) && configFile != "" {
 				return errors.New("Use either tuner params or redpanda config file")
 			}
+			if outTuneScriptFile != "" && outUndoScriptFile != "" {
+				return errors.New("Use either --output-script or --output-undo-script")
+			}
+			if dryRun && (outTuneScriptFile != "" || outUndoScriptFile != "") {
+				return errors.New("--dry-run can't be used along with --output-script or --output-undo-script")
+			}
+			if lateBindDevice && (outTuneScriptFile == "" || outputFormat != formatText) {
+				return errors.New("--late-bind-data-device can only be used along with --output-script, in the text format")
+			}
+			if guardWrites && (outTuneScriptFile == "" || outputFormat != formatText) {
+				return errors.New("--guard-writes can only be used along with --output-script, in the text format")
+			}
+			if outManifestFile != "" && (outTuneScriptFile == "" || outputFormat != formatText) {
+				return errors.New("--output-manifest can only be used along with --output-script, in the text format")
+			}
+			if outputFormat != formatText && outputFormat != formatJSON {
+				return fmt.Errorf(
+					"unsupported format '%s', only %s are supported",
+					outputFormat,
+					[]string{formatText, formatJSON},
+				)
+			}
+			if len(exclude) > 0 && args[0] != "all" {
+				return errors.New("--exclude can only be used along with 'all'")
+			}
 			var tuners []string
+			var err error
 			if args[0] == "all" {
-				tuners = factory.AvailableTuners()
+				tuners, err = factory.DefaultRegistry.Disabled(exclude...)
 			} else {
-				tuners = strings.Split(args[0], ",")
+				tuners, err = factory.DefaultRegistry.Enabled(
+					strings.Split(args[0], ",")...,
+				)
+			}
+			if err != nil {
+				return err
 			}
 			cpuMask, err := hwloc.TranslateToHwLocCpuSet(cpuSet)
 			if err != nil {
 				return err
 			}
 			tunerParams.CpuMask = cpuMask
+			tunerParams.CpuSet = cpuSet
+			if profilePath != "" {
+				tunerParams.Profile, err = factory.LoadTuningProfile(fs, profilePath)
+				if err != nil {
+					return err
+				}
+			}
 			conf, err := mgr.FindOrGenerate(configFile)
 			if err != nil {
 				if !interactive {
@@ -109,15 +176,138 @@ Would you like to continue with the default configuration?`,
 				}
 				conf = config.Default()
 			}
-			var tunerFactory factory.TunersFactory
-			if outTuneScriptFile != "" {
-				tunerFactory = factory.NewScriptRenderingTunersFactory(
-					fs, *conf, outTuneScriptFile, timeout)
+			var owner *executors.Owner
+			if ownerSpec != "" {
+				// Resolve the owner before anything is written.
+				dirs := tunerParams.Directories
+				if len(dirs) == 0 {
+					dirs = []string{conf.Redpanda.Directory}
+				}
+				o, err := executors.LookupOwner(ownerSpec, dirs)
+				if err != nil {
+					return fmt.Errorf("invalid --owner: %w", err)
+				}
+				owner = &o
+			}
+			var (
+				executor executors.Executor
+				recorder executors.RecordingExecutor
+				manifest executors.ManifestRenderingExecutor
+			)
+			if outTuneScriptFile != "" && outputFormat == formatJSON {
+				executor = executors.NewJSONRenderingExecutor(fs, outTuneScriptFile)
+			} else if outTuneScriptFile != "" {
+				var ctx *commands.RenderContext
+				if lateBindDevice {
+					dirs := tunerParams.Directories
+					if len(dirs) == 0 {
+						dirs = []string{conf.Redpanda.Directory}
+					}
+					ctx, err = factory.NewDataDeviceRenderContext(fs, dirs[0], timeout)
+					if err != nil {
+						return err
+					}
+				}
+				if guardWrites {
+					if ctx == nil {
+						ctx = commands.NewRenderContext()
+					}
+					ctx.GuardWrites()
+				}
+				if outManifestFile != "" {
+					manifest = executors.NewManifestRenderingExecutorWithContext(
+						fs,
+						outTuneScriptFile,
+						ctx,
+						outManifestFile,
+						manifestFormat(outManifestFile),
+					)
+					executor = manifest
+				} else {
+					executor = executors.NewScriptRenderingExecutorWithContext(
+						fs,
+						outTuneScriptFile,
+						ctx,
+					)
+				}
+			} else if dryRun {
+				executor = executors.NewDryRunExecutor()
 			} else {
-				tunerFactory = factory.NewDirectExecutorTunersFactory(
-					fs, *conf, timeout)
+				executor = executors.NewDirectExecutorWithParams(
+					executors.DirectExecutorParams{
+						CommandTimeout: commandTimeout,
+						VerifyWrites:   verifyWrites,
+						Retries:        writeRetries,
+					},
+				)
+			}
+			if owner != nil {
+				executor = executors.NewOwningExecutor(fs, executor, *owner)
+			}
+			if outUndoScriptFile != "" {
+				recorder = executors.NewRecordingExecutor(executor)
+				executor = recorder
+			}
+			if showProgress {
+				executor = executors.NewObservingExecutor(
+					executor,
+					newProgressPrinter(cmd.ErrOrStderr()),
+				)
 			}
-			return tune(fs, conf, tuners, tunerFactory, &tunerParams)
+			tunerFactory := &timedTunersFactory{
+				fs:              fs,
+				conf:            *conf,
+				executor:        executor,
+				timeout:         timeout,
+				timings:         map[string]executors.TimingExecutor{},
+				failures:        map[string]executors.ErrorCollector{},
+				continueOnError: continueOnError,
+			}
+			if outTuneScriptFile != "" {
+				// The rendered script must list the commands in the
+				// order the tuners ran in.
+				concurrency = 1
+			}
+			ctx, cancel := context.Background(), func() {}
+			if deadline > 0 {
+				ctx, cancel = context.WithTimeout(ctx, deadline)
+			}
+			err = tune(
+				ctx,
+				fs,
+				conf,
+				tuners,
+				tunerFactory,
+				&tunerParams,
+				concurrency,
+				tunerTimeout,
+				outputFormat,
+			)
+			cancel()
+			tunerFactory.logSummary()
+			if err == nil {
+				// Every failed command is reported, after the tuners'
+				// results, so that rpk exits with an error.
+				err = tunerFactory.Err()
+			}
+			if manifest != nil {
+				// Like the script, it lists whatever was rendered,
+				// even if tuning failed.
+				werr := manifest.WriteManifest()
+				if werr != nil {
+					return werr
+				}
+				log.Infof("Manifest written to '%s'", outManifestFile)
+			}
+			if recorder != nil {
+				// Write the undo script even if tuning failed, so that
+				// whatever was applied can be reverted.
+				werr := writeUndoScript(fs, recorder, outUndoScriptFile)
+				if werr != nil {
+					return werr
+				}
+			}
+			return err
 		},
 	}
 	command.Flags().StringVarP(&tunerParams.Mode,
@@ -138,6 +328,11 @@ Would you like to continue with the default configuration?`,
 		[]string{}, "List of *data* directories or places to store data,"+
 			" i.e.: '/var/vectorized/redpanda/',"+
 			" usually your XFS filesystem on an NVMe SSD device.")
+	command.Flags().BoolVar(&tunerParams.InstallPackages,
+		"install-packages", false, "If set, the packages providing the"+
+			" binaries the tuners run (e.g. ethtool) are installed with the"+
+			" distribution's package manager when they're missing, instead"+
+			" of the tuners being reported as unsupported")
 	command.Flags().BoolVar(&tunerParams.RebootAllowed,
 		"reboot-allowed", false, "If set will allow tuners to tune boot parameters"+
 			" and request system reboot.")
@@ -150,7 +345,24 @@ Would you like to continue with the default configuration?`,
 	)
 	command.Flags().StringVar(&outTuneScriptFile,
 		"output-script", "", "If set tuners will generate tuning file that "+
-			"can later be used to tune the system")
+			"can later be used to tune the system. The rollback of a batch"+
+			" of changes failing half-way is rendered with the values"+
+			" found on the host generating the script, so it should be run"+
+			" on the same host, or one set up the same way")
+	command.Flags().StringVar(&outUndoScriptFile,
+		"output-undo-script", "", "If set tuners will be applied, and a script"+
+			" reverting the changes they made will be generated")
+	command.Flags().StringVar(&outManifestFile,
+		"output-manifest", "", "If set along with --output-script, a manifest"+
+			" listing the type, target and value of each action of the script"+
+			" (e.g. the files and sysctls it changes) is written to it, for"+
+			" review. It's written as CSV if the file name ends in '.csv', and"+
+			" as JSON otherwise")
+	command.Flags().StringVar(&outputFormat,
+		"format", formatText, "Output format: one of [text, json]. If set to"+
+			" 'json', the result of each tuner is printed as a JSON array, and"+
+			" along with --output-script, the tuning file will contain a JSON"+
+			" array describing each tuning command instead of a shell script")
 	command.Flags().DurationVar(
 		&timeout,
 		"timeout",
@@ -160,6 +372,16 @@ Would you like to continue with the default configuration?`,
 			"fraction and a unit suffix, such as '300ms', '1.5s' or '2h45m'. "+
 			"Valid time units are 'ns', 'us' (or 'µs'), 'ms', 's', 'm', 'h'",
 	)
+	command.Flags().DurationVar(
+		&commandTimeout,
+		"command-timeout",
+		0,
+		"The maximum time each tuning command may take before its tuner"+
+			" fails. Commands launching a process (e.g. ethtool or sysctl)"+
+			" are killed, while the others, such as sysfs writes blocked in"+
+			" a wedged driver, are abandoned and may still complete later."+
+			" If 0, commands aren't timed out",
+	)
 	command.Flags().BoolVar(
 		&interactive,
 		"interactive",
@@ -167,10 +389,163 @@ Would you like to continue with the default configuration?`,
 		"Ask for confirmation on every step (e.g. tuner execution,"+
 			" configuration generation)",
 	)
+	command.Flags().IntVar(
+		&concurrency,
+		"concurrency",
+		runtime.NumCPU(),
+		"The maximum number of tuners to run at the same time. Tuners which"+
+			" change shared state (e.g. IRQ affinity) always run one after"+
+			" the other",
+	)
+	command.Flags().BoolVar(
+		&verifyWrites,
+		"verify-writes",
+		false,
+		"If set, the files the tuners write to are read back, and tuning"+
+			" fails if the kernel didn't accept the written values",
+	)
+	command.Flags().IntVar(
+		&writeRetries,
+		"write-retries",
+		3,
+		"The number of times to retry a tuning command failing with a"+
+			" transient error (EBUSY or EAGAIN), as some kernels reject"+
+			" writes to sysfs files while the device settles",
+	)
+	command.Flags().BoolVar(
+		&dryRun,
+		"dry-run",
+		false,
+		"If set, the tuners will run but, instead of changing anything, they'll"+
+			" log the changes they would make",
+	)
+	command.Flags().StringVar(
+		&ownerSpec,
+		"owner",
+		"",
+		"The user (and optionally group) to hand the files written to the"+
+			" data directories over to, as 'user[:group]', e.g. 'redpanda'."+
+			" Useful when tuning as root while redpanda runs as another user",
+	)
+	command.Flags().StringSliceVar(
+		&exclude,
+		"exclude",
+		[]string{},
+		"Tuners to skip when tuning 'all', e.g. 'disk_irq,clocksource'",
+	)
+	command.Flags().StringVar(
+		&profilePath,
+		"profile",
+		"",
+		"A YAML file overriding the values set by the tuners by default"+
+			" (swappiness, transparent_hugepages, ring_sizes) and which"+
+			" tuners are enabled (tuners), e.g. to tune a dev machine"+
+			" differently than a production one",
+	)
+	command.Flags().BoolVar(
+		&continueOnError,
+		"continue-on-error",
+		false,
+		"If set, a tuner goes on with its next commands when one fails,"+
+			" instead of stopping at it, and is reported as failed once"+
+			" it's done. Batches of commands are still aborted as a"+
+			" whole. Either way, every command which failed is reported"+
+			" at the end, and rpk exits with an error if any did",
+	)
+	command.Flags().BoolVar(
+		&guardWrites,
+		"guard-writes",
+		false,
+		"If set along with --output-script, the script only writes the"+
+			" files which don't already hold the value, so that it can be"+
+			" run again without rewriting them",
+	)
+	command.Flags().BoolVar(
+		&lateBindDevice,
+		"late-bind-data-device",
+		false,
+		"If set along with --output-script, the script finds the data"+
+			" directory's disk when it runs, from the DATA_DIR environment"+
+			" variable (defaulting to the data directory), or uses"+
+			" DATA_DEVICE if it's set, instead of the disk found when"+
+			" rendering it. Useful to render a script on a host and run it"+
+			" on another one",
+	)
+	command.Flags().BoolVar(
+		&showProgress,
+		"progress",
+		false,
+		"If set, each tuning command is printed to stderr as it starts"+
+			" and finishes, along with how many have finished so far",
+	)
+	command.Flags().DurationVar(
+		&tunerTimeout,
+		"tuner-timeout",
+		0,
+		"The maximum time each tuner may take. A tuner exceeding it is"+
+			" reported as timed out, its remaining commands aren't executed,"+
+			" and the others go on once its current command completes. Zero"+
+			" means tuners may run indefinitely",
+	)
+	command.Flags().DurationVar(
+		&deadline,
+		"deadline",
+		0,
+		"The maximum time tuning may take overall, e.g. to stay within"+
+			" the start timeout of the unit running it at boot. The tuners"+
+			" which didn't complete by then are reported as timed out, along"+
+			" with the results of the ones which did. Zero means no deadline",
+	)
 	command.AddCommand(tunecmd.NewHelpCommand())
+	command.AddCommand(tunecmd.NewListCommand(fs, mgr))
+	command.AddCommand(tunecmd.NewCheckCommand(fs, mgr))
 	return command
 }
 
+// Returns the format of the manifest written to filename, which is given by
+// its extension.
+func manifestFormat(filename string) executors.ManifestFormat {
+	if strings.EqualFold(filepath.Ext(filename), ".csv") {
+		return executors.ManifestCSV
+	}
+	return executors.ManifestJSON
+}
+
+func writeUndoScript(
+	fs afero.Fs, recorder executors.RecordingExecutor, filename string,
+) error {
+	file, err := fs.OpenFile(filename, os.O_CREATE|os.O_TRUNC|os.O_RDWR, 0755)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	w := bufio.NewWriter(file)
+	err = recorder.RenderUndoScript(w)
+	if err != nil {
+		return err
+	}
+	log.Infof("Undo script written to '%s'", filename)
+	return nil
+}
+
+// Returns an observer printing a line to w as each command starts and
+// finishes, e.g. "[3 done] ok: Write '2' to '/sys/block/sda/queue/nomerges'".
+func newProgressPrinter(w io.Writer) executors.ProgressObserver {
+	done := 0
+	return func(p executors.CommandProgress) {
+		switch p.Status {
+		case executors.CommandStarted:
+			fmt.Fprintf(w, "[%d done] running: %s\n", done, p.Command.Desc)
+		case executors.CommandSucceeded:
+			done++
+			fmt.Fprintf(w, "[%d done] ok: %s\n", done, p.Command.Desc)
+		case executors.CommandFailed:
+			done++
+			fmt.Fprintf(w, "[%d done] failed: %s: %v\n", done, p.Command.Desc, p.Err)
+		}
+	}
+}
+
 func promptConfirmation(msg string, in io.Reader) (bool, error) {
 	scanner := bufio.NewScanner(in)
 	for {
@@ -202,40 +577,29 @@ func promptConfirmation(msg string, in io.Reader) (bool, error) {
 }
 
 func tune(
+	ctx context.Context,
 	fs afero.Fs,
 	conf *config.Config,
 	tunerNames []string,
 	tunersFactory factory.TunersFactory,
 	params *factory.TunerParams,
+	concurrency int,
+	tunerTimeout time.Duration,
+	outputFormat string,
 ) error {
 	params, err := factory.MergeTunerParamsConfig(params, conf)
 	if err != nil {
 		return err
 	}
-	rebootRequired := false
+	results, rebootRequired := runTuners(
+		ctx, conf, tunerNames, tunersFactory, params, concurrency, tunerTimeout,
+	)
 
-	results := []result{}
 	includeErr := false
 	allDisabled := true
-	for _, tunerName := range tunerNames {
-		enabled := factory.IsTunerEnabled(tunerName, conf.Rpk)
-		allDisabled = allDisabled && !enabled
-		tuner := tunersFactory.CreateTuner(tunerName, params)
-		supported, reason := tuner.CheckIfSupported()
-		if !enabled || !supported {
-			includeErr = includeErr || !supported
-			results = append(results, result{tunerName, false, enabled, supported, reason})
-			continue
-		}
-		log.Debugf("Tuner parameters %+v", params)
-		res := tuner.Tune()
-		includeErr = includeErr || res.IsFailed()
-		rebootRequired = rebootRequired || res.IsRebootRequired()
-		errMsg := ""
-		if res.IsFailed() {
-			errMsg = res.Error().Error()
-		}
-		results = append(results, result{tunerName, !res.IsFailed(), enabled, supported, errMsg})
+	for _, res := range results {
+		allDisabled = allDisabled && !res.Enabled
+		includeErr = includeErr || !res.Supported || res.ErrMsg != ""
 	}
 
 	if allDisabled {
@@ -246,7 +610,14 @@ func tune(
 		)
 	}
 
-	printTuneResult(results, includeErr)
+	if outputFormat == formatJSON {
+		err = printTuneResultJSON(os.Stdout, results)
+		if err != nil {
+			return err
+		}
+	} else {
+		printTuneResult(results, includeErr)
+	}
 
 	if rebootRequired {
 		red := color.New(color.FgRed).SprintFunc()
@@ -259,16 +630,320 @@ func tune(
 	return nil
 }
 
+// Runs the tuners, returning their results in the same order as tunerNames
+// and whether any of them requires a reboot. The independent tuners run
+// concurrently, up to concurrency at a time, while the ones which aren't
+// (see factory.IsTunerIndependent) run one after the other, in order, as a
+// single job. With a concurrency of 1, they all run in order. Every job runs to completion, so that each tuner's result is
+// reported even if others failed.
+//
+// A tuner which takes longer than tunerTimeout (unless it's zero), or which
+// is still running when ctx is done, is reported as timed out, and the ones
+// which didn't start by then are skipped, so that the results of those which
+// completed are returned instead of hanging the whole run. If the factory is
+// a contextTunersFactory, a tuner which timed out stops executing commands,
+// and the next one in its job only starts once it returned, so that they
+// never change the system at the same time. A tuner which doesn't return
+// before ctx is done is abandoned, as no other tuner starts after that.
+func runTuners(
+	ctx context.Context,
+	conf *config.Config,
+	tunerNames []string,
+	tunersFactory factory.TunersFactory,
+	params *factory.TunerParams,
+	concurrency int,
+	tunerTimeout time.Duration,
+) ([]result, bool) {
+	results := make([]result, len(tunerNames))
+	reboots := make([]bool, len(tunerNames))
+	// Each tuner's context is cancelled when it times out.
+	ctxs := make([]context.Context, len(tunerNames))
+	cancels := make([]context.CancelFunc, len(tunerNames))
+	for i := range tunerNames {
+		ctxs[i], cancels[i] = context.WithCancel(ctx)
+		defer cancels[i]()
+	}
+	runOne := func(i int, tuner tuners.Tunable) {
+		name := tunerNames[i]
+		enabled := params.Profile.IsTunerEnabled(name, conf.Rpk)
+		if enabled && ctx.Err() != nil {
+			results[i] = timedOutResult(name, "it didn't start before the deadline")
+			return
+		}
+		tctx := ctxs[i]
+		if tunerTimeout > 0 {
+			timer := time.AfterFunc(tunerTimeout, cancels[i])
+			defer timer.Stop()
+		}
+		type outcome struct {
+			res    result
+			reboot bool
+		}
+		done := make(chan outcome, 1)
+		go func() {
+			res, reboot := tuneOne(name, enabled, tuner, params)
+			done <- outcome{res, reboot}
+		}()
+		select {
+		case o := <-done:
+			results[i], reboots[i] = o.res, o.reboot
+			return
+		case <-tctx.Done():
+		}
+		msg := "it didn't complete before the deadline"
+		if ctx.Err() == nil {
+			msg = fmt.Sprintf("it didn't complete within %s", tunerTimeout)
+		}
+		log.Warnf("Tuner '%s' timed out: %s", name, msg)
+		results[i] = timedOutResult(name, msg)
+		// The commands the tuner executes from now on fail, and the one
+		// it's executing is aborted, but the tuner may be busy elsewhere,
+		// so it's waited for.
+		select {
+		case <-done:
+		case <-ctx.Done():
+		}
+	}
+
+	var (
+		jobs    []func()
+		ordered []int
+	)
+	// The tuners are created up front, as the factory isn't meant to be
+	// used concurrently.
+	created := make([]tuners.Tunable, len(tunerNames))
+	for i, name := range tunerNames {
+		if f, ok := tunersFactory.(contextTunersFactory); ok {
+			created[i] = f.CreateTunerContext(ctxs[i], name, params)
+		} else {
+			created[i] = tunersFactory.CreateTuner(name, params)
+		}
+		// Without concurrency, every tuner runs in the requested order,
+		// e.g. so that the rendered script lists them in it.
+		if concurrency <= 1 || !factory.IsTunerIndependent(name) {
+			ordered = append(ordered, i)
+			continue
+		}
+		i := i
+		jobs = append(jobs, func() { runOne(i, created[i]) })
+	}
+	if len(ordered) > 0 {
+		jobs = append(jobs, func() {
+			for _, i := range ordered {
+				runOne(i, created[i])
+			}
+		})
+	}
+
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for _, job := range jobs {
+		job := job
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			job()
+		}()
+	}
+	wg.Wait()
+
+	rebootRequired := false
+	for _, r := range reboots {
+		rebootRequired = rebootRequired || r
+	}
+	return results, rebootRequired
+}
+
+// Runs the tuner if it's enabled and supported, returning its result and
+// whether it requires a reboot.
+func tuneOne(
+	name string, enabled bool, tuner tuners.Tunable, params *factory.TunerParams,
+) (result, bool) {
+	if !enabled {
+		// Checking whether it's supported may probe the system, e.g.
+		// dial the seed servers, which is left to 'rpk tune list'.
+		return result{Name: name}, false
+	}
+	supported, reason := tuner.CheckIfSupported()
+	if !supported {
+		return result{
+			Name:      name,
+			Enabled:   enabled,
+			Supported: supported,
+			ErrMsg:    reason,
+		}, false
+	}
+	log.Debugf("Tuner parameters %+v", params)
+	res := tuner.Tune()
+	errMsg := ""
+	if res.IsFailed() {
+		errMsg = res.Error().Error()
+	}
+	return result{
+		Name:      name,
+		Enabled:   true,
+		Supported: true,
+		Applied:   !res.IsFailed(),
+		Changed:   !res.IsFailed() && res.IsChanged(),
+		ErrMsg:    errMsg,
+	}, res.IsRebootRequired()
+}
+
+func timedOutResult(name, msg string) result {
+	return result{
+		Name:      name,
+		Enabled:   true,
+		Supported: true,
+		TimedOut:  true,
+		ErrMsg:    "timed out: " + msg,
+	}
+}
+
+// Implemented by the factories creating tuners which stop executing commands
+// once ctx is done.
+type contextTunersFactory interface {
+	CreateTunerContext(
+		ctx context.Context, name string, params *factory.TunerParams,
+	) tuners.Tunable
+}
+
+// Creates each tuner with a factory of its own, whose executor times the
+// commands the tuner executes and keeps those which failed, so that they can
+// be summarized per tuner, and collects their results, which tell whether
+// the tuner changed anything. With continueOnError, the tuners go on past
+// the commands which failed.
+type timedTunersFactory struct {
+	fs              afero.Fs
+	conf            config.Config
+	executor        executors.Executor
+	timeout         time.Duration
+	continueOnError bool
+	names           []string
+	timings         map[string]executors.TimingExecutor
+	failures        map[string]executors.ErrorCollector
+}
+
+func (f *timedTunersFactory) CreateTuner(
+	name string, params *factory.TunerParams,
+) tuners.Tunable {
+	return f.CreateTunerContext(context.Background(), name, params)
+}
+
+// Creates a tuner like CreateTuner, which stops executing commands once ctx is
+// done.
+func (f *timedTunersFactory) CreateTunerContext(
+	ctx context.Context, name string, params *factory.TunerParams,
+) tuners.Tunable {
+	// The timing executor is wrapped, so that it sees the commands which
+	// failed.
+	timing := executors.NewTimingExecutor(f.executor)
+	failures := executors.NewErrorCollectingExecutor(timing)
+	if f.continueOnError {
+		failures = executors.NewContinuingExecutor(timing)
+	}
+	f.names = append(f.names, name)
+	f.timings[name] = timing
+	f.failures[name] = failures
+	executor := executors.NewCollectingExecutor(failures)
+	tuner := factory.NewTunersFactory(
+		f.fs,
+		f.conf,
+		executors.WithContext(ctx, executor),
+		f.timeout,
+	).CreateTuner(name, params)
+	return &collectedTuner{Tunable: tuner, executor: executor, failures: failures}
+}
+
+// A tuner whose change is derived from the results of the commands it
+// executed, rather than assumed from it having executed them.
+type collectedTuner struct {
+	tuners.Tunable
+	executor executors.ResultCollector
+	failures executors.ErrorCollector
+}
+
+// Reports the tuner as failed if any of the commands it executed did, even if
+// it went on past them, and as unchanged if none of them changed anything,
+// e.g. as every file it wrote already held the value.
+func (t *collectedTuner) Tune() tuners.TuneResult {
+	res := t.Tunable.Tune()
+	if err := t.failures.Err(); !res.IsFailed() && err != nil {
+		return tuners.NewTuneError(err)
+	}
+	if res.IsFailed() || !res.IsChanged() || res.IsRebootRequired() ||
+		t.executor.IsLazy() {
+		return res
+	}
+	for _, r := range t.executor.Results() {
+		if r.Changed {
+			return res
+		}
+	}
+	return tuners.NewUnchangedTuneResult()
+}
+
+// Logs, at debug level, how many commands each tuner executed and how long
+// they took, e.g. 'disk_irq: 12 commands, 340ms'.
+func (f *timedTunersFactory) logSummary() {
+	if !log.IsLevelEnabled(log.DebugLevel) {
+		return
+	}
+	for _, name := range f.names {
+		log.Debugf(
+			"%s: %s",
+			name,
+			executors.SummarizeTimings(f.timings[name].Timings()),
+		)
+	}
+}
+
+// Returns a *executors.MultiError with the commands which failed, in the
+// order their tuners were created, or nil if none did.
+func (f *timedTunersFactory) Err() error {
+	var errs []executors.CommandError
+	for _, name := range f.names {
+		var multi *executors.MultiError
+		if errors.As(f.failures[name].Err(), &multi) {
+			errs = append(errs, multi.Errors...)
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return &executors.MultiError{Errors: errs}
+}
+
 func tunerParamsEmpty(params *factory.TunerParams) bool {
 	return len(params.Directories) == 0 &&
 		len(params.Disks) == 0 &&
 		len(params.Nics) == 0
 }
 
-func printTuneResult(results []result, includeErr bool) {
+func sortTuneResults(results []result) {
 	sort.Slice(results, func(i, j int) bool {
-		return results[i].name < results[j].name
+		return results[i].Name < results[j].Name
 	})
+}
+
+func printTuneResultJSON(w io.Writer, results []result) error {
+	sortTuneResults(results)
+	bs, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(bs))
+	return err
+}
+
+func printTuneResult(results []result, includeErr bool) {
+	sortTuneResults(results)
 	headers := []string{
 		"Tuner",
 		"Applied",
@@ -289,19 +964,19 @@ func printTuneResult(results []result, includeErr bool) {
 	for _, res := range results {
 		c := white
 		row := []string{
-			res.name,
-			strconv.FormatBool(res.applied),
-			strconv.FormatBool(res.enabled),
-			strconv.FormatBool(res.supported),
+			res.Name,
+			strconv.FormatBool(res.Applied),
+			strconv.FormatBool(res.Enabled),
+			strconv.FormatBool(res.Supported),
 		}
 		if includeErr {
-			row = append(row, res.errMsg)
+			row = append(row, res.ErrMsg)
 		}
-		if !res.supported {
+		if res.Enabled && !res.Supported {
 			c = yellow
-		} else if res.errMsg != "" {
+		} else if res.ErrMsg != "" {
 			c = red
-		} else if res.applied {
+		} else if res.Applied {
 			c = green
 		}
 		t.Append(colorRow(c, row))
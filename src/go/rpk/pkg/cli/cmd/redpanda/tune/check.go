@@ -0,0 +1,140 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tune
+
+import (
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/cli/ui"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/config"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/factory"
+)
+
+const (
+	statusOk          = "OK"
+	statusNeedsTuning = "NEEDS-TUNING"
+	statusError       = "ERROR"
+	statusUnsupported = "UNSUPPORTED"
+)
+
+func NewCheckCommand(fs afero.Fs, mgr config.Manager) *cobra.Command {
+	var configFile, profilePath string
+	var timeout time.Duration
+	command := &cobra.Command{
+		Use:   "check [<list of tuners>|all]",
+		Short: "Show which settings the tuners would change, without changing them.",
+		Long: `Show which settings the tuners would change, without changing them.
+
+For each setting, its current and required values are printed, along with
+whether it's already OK or it NEEDS-TUNING. If no tuners are given, all of
+them are checked.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(ccmd *cobra.Command, args []string) error {
+			var tunerNames []string
+			var err error
+			if len(args) == 0 || args[0] == "all" {
+				tunerNames = factory.DefaultRegistry.Names()
+			} else {
+				tunerNames, err = factory.DefaultRegistry.Enabled(
+					strings.Split(args[0], ",")...,
+				)
+				if err != nil {
+					return err
+				}
+			}
+			conf, err := mgr.FindOrGenerate(configFile)
+			if err != nil {
+				return err
+			}
+			params := &factory.TunerParams{CpuMask: "all"}
+			if profilePath != "" {
+				params.Profile, err = factory.LoadTuningProfile(fs, profilePath)
+				if err != nil {
+					return err
+				}
+			}
+			params, err = factory.MergeTunerParamsConfig(params, conf)
+			if err != nil {
+				return err
+			}
+			// Checking mustn't change anything, so the tuners' commands
+			// aren't run.
+			tunersFactory := factory.NewTunersFactory(
+				fs, *conf, executors.NewDryRunExecutor(), timeout)
+			created := make([]tuners.Tunable, len(tunerNames))
+			for i, name := range tunerNames {
+				created[i] = tunersFactory.CreateTuner(name, params)
+			}
+			printCheckResults(os.Stdout, tunerNames, created)
+			return nil
+		},
+	}
+	command.Flags().StringVar(
+		&configFile,
+		"config",
+		"",
+		"Redpanda config file, if not set the file will be searched for"+
+			" in the default locations.",
+	)
+	command.Flags().StringVar(
+		&profilePath,
+		"profile",
+		"",
+		"A YAML file overriding the values the tuners set by default,"+
+			" checked instead of them",
+	)
+	command.Flags().DurationVar(
+		&timeout,
+		"timeout",
+		10000*time.Millisecond,
+		"The maximum time to wait for the checks to complete. "+
+			"The value passed is a sequence of decimal numbers, each with optional "+
+			"fraction and a unit suffix, such as '300ms', '1.5s' or '2h45m'. "+
+			"Valid time units are 'ns', 'us' (or 'µs'), 'ms', 's', 'm', 'h'",
+	)
+	return command
+}
+
+// Prints a row for each setting of the given tuners, or a single one for
+// the tuners which can't be checked.
+func printCheckResults(
+	out io.Writer, tunerNames []string, tunables []tuners.Tunable,
+) {
+	t := ui.NewRpkTable(out)
+	t.SetHeader([]string{"Tuner", "Setting", "Current", "Required", "Status"})
+	for i, name := range tunerNames {
+		if supported, reason := tunables[i].CheckIfSupported(); !supported {
+			t.Append([]string{name, reason, "", "", statusUnsupported})
+			continue
+		}
+		results, err := tuners.CheckTunable(tunables[i])
+		if err != nil {
+			t.Append([]string{name, err.Error(), "", "", statusError})
+			continue
+		}
+		for _, res := range results {
+			status, current := statusOk, res.Current
+			if res.Err != nil {
+				status, current = statusError, res.Err.Error()
+			} else if !res.IsOk {
+				status = statusNeedsTuning
+			}
+			t.Append([]string{name, res.Desc, current, res.Required, status})
+		}
+	}
+	t.Render()
+}
@@ -0,0 +1,72 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tune
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners"
+)
+
+type fakeTunable struct {
+	supported bool
+	results   []tuners.CheckResult
+	err       error
+}
+
+func (t *fakeTunable) CheckIfSupported() (bool, string) {
+	return t.supported, "not on this machine"
+}
+
+func (*fakeTunable) Tune() tuners.TuneResult {
+	return tuners.NewTuneResult(false)
+}
+
+func (t *fakeTunable) Check() ([]tuners.CheckResult, error) {
+	return t.results, t.err
+}
+
+func TestPrintCheckResults(t *testing.T) {
+	names := []string{"swappiness", "cpu", "ethtool", "fstrim"}
+	tunables := []tuners.Tunable{
+		&fakeTunable{supported: true, results: []tuners.CheckResult{
+			{Desc: "Swappiness", IsOk: true, Current: "1", Required: "1"},
+		}},
+		&fakeTunable{supported: true, results: []tuners.CheckResult{
+			{Desc: "CPU governor", IsOk: false, Current: "powersave", Required: "performance"},
+			{Desc: "CPU boost", Err: errors.New("no boost")},
+		}},
+		&fakeTunable{supported: true, err: errors.New("no NICs")},
+		&fakeTunable{supported: false},
+	}
+	var out bytes.Buffer
+	printCheckResults(&out, names, tunables)
+	lines := nonEmptyLines(out.String())
+	require.Len(t, lines, 6)
+	require.Regexp(t, `^TUNER\s+SETTING\s+CURRENT\s+REQUIRED\s+STATUS$`, lines[0])
+	require.Regexp(t, `^swappiness\s+Swappiness\s+1\s+1\s+OK$`, lines[1])
+	require.Regexp(t, `^cpu\s+CPU governor\s+powersave\s+performance\s+NEEDS-TUNING$`, lines[2])
+	require.Regexp(t, `^cpu\s+CPU boost\s+no boost\s+ERROR$`, lines[3])
+	require.Regexp(t, `^ethtool\s+no NICs\s+ERROR$`, lines[4])
+	require.Regexp(t, `^fstrim\s+not on this machine\s+UNSUPPORTED$`, lines[5])
+}
+
+func nonEmptyLines(s string) []string {
+	var lines []string
+	for _, line := range bytes.Split([]byte(s), []byte("\n")) {
+		if trimmed := bytes.TrimSpace(line); len(trimmed) > 0 {
+			lines = append(lines, string(trimmed))
+		}
+	}
+	return lines
+}
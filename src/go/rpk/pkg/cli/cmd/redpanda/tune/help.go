@@ -30,6 +30,18 @@ func NewHelpCommand() *cobra.Command {
 		"transparent_hugepages": transparentHugepagesTunerHelp,
 		"clocksource":           clocksourceTunerHelp,
 		"nomerges":              nomergesTunerHelp,
+		"files_limit":           filesLimitTunerHelp,
+		"ethtool":               ethtoolTunerHelp,
+		"cgroup":                cgroupTunerHelp,
+		"cstate":                cstateTunerHelp,
+		"nic_channels":          nicChannelsTunerHelp,
+		"filesystem_check":      filesystemCheckTunerHelp,
+		"block_queue":           blockQueueTunerHelp,
+		"kernel_threads":        kernelThreadsTunerHelp,
+		"irq_balance":           irqBalanceTunerHelp,
+		"task_scheduler":        taskSchedulerTunerHelp,
+		"max_map_count":         maxMapCountTunerHelp,
+		"dirty_ratio":           dirtyRatioTunerHelp,
 	}
 
 	return &cobra.Command{
@@ -91,10 +103,12 @@ are allowed by the provided CPU masks.
 This tuner performs the following operations:
 
 	- Setup NIC IRQs affinity
-	- Setup NIC RPS and RFS
+	- Setup NIC RPS
+	- Size the RFS table and split its entries across each NIC's RX queues
 	- Setup NIC XPS
 	- Increase socket listen backlog
 	- Increase number of remembered connection requests
+	- Increase the max socket buffer sizes
 	- Ban the IRQ Balance service from moving distributed IRQs
 
 Modes description:
@@ -120,8 +134,9 @@ If there isn't any mode given script will use a default mode:
 const diskSchedulerTunerHelp = `
 This tuner sets the preferred I/O scheduler for given block devices and disables
 I/O operation merging. It can work using both the device name or a directory,
-then the device where directory is stored will be optimized.
-The tuner sets either ‘none’ or ‘noop’ scheduler if supported.
+then the devices where directory is stored will be optimized. Partitions, LVM
+volumes and md arrays are resolved to the physical devices backing them.
+The tuner sets ‘none’, ‘noop’ or ‘mq-deadline’, the first one supported.
 
 Schedulers:
 
@@ -130,7 +145,9 @@ Schedulers:
 	noop - used when ‘none’ is not available,
 		   it is preferred for non-NVME devices, this scheduler uses simple FIFO
 		   queue where all I/O operations are first stored
-		   and then handled by the driver`
+		   and then handled by the driver
+	mq-deadline - used when neither ‘none’ nor ‘noop’ are available,
+		   it's the lightest of the multi-queue schedulers`
 
 const diskIrqTunerHelp = `
 This tuner distributes block devices IRQs according to the specified mode.
@@ -144,6 +161,10 @@ NVMe devices IRQs are distributed across all available cores allowed by CPU mask
 This tuner performs the following operations:
 	- Setup disks IRQs affinity
 	- Ban the IRQ Balance service from moving distributed IRQs
+	- Set the default affinity of the IRQs registered later on (e.g. by
+	  hotplugged devices) to the cores non-NVMe devices IRQs are
+	  distributed across, if the kernel exposes
+	  /proc/irq/default_smp_affinity
 
 Modes description:
 
@@ -167,7 +188,8 @@ If there isn't any mode given script will use a default mode:
 
 const swappinessTunerHelp = `
 Tunes the kernel to keep process data in-memory for as long as possible, instead
-of swapping it out to disk.
+of swapping it out to disk. The swappiness is lowered to 1, but never raised if
+it's already lower.
 `
 
 const fstrimTunerHelp = `
@@ -192,10 +214,12 @@ simultaneous IO requests as possible, increasing throughput.
 `
 
 const transparentHugepagesTunerHelp = `
-Enables Transparent Hugepages. This allows the kernel to index larger pages
-(2MB, as opposed to the standard 4KB) in the CPU's TLB (if it supports it, which
-is the case for most current CPUs). This results in fewer cache misses, which
-means less time is spent searching and loading pages.
+Sets the Transparent Hugepages mode to 'madvise'. This allows the kernel to
+index larger pages (2MB, as opposed to the standard 4KB) in the CPU's TLB (if it
+supports it, which is the case for most current CPUs) for the memory regions
+redpanda's allocator requests them for. This results in fewer cache misses,
+which means less time is spent searching and loading pages, while avoiding the
+latency spikes 'always' can cause when the kernel compacts memory.
 `
 
 const clocksourceTunerHelp = `
@@ -203,10 +227,138 @@ Sets the clock source to TSC (Time Stamp Counter) to get the time more
 efficiently via the Virtual Dynamic Shared Object. Most VMs run on Xen, with
 'xen' as the default clock source, which doesn't support reading the time in
 userspace via the vDSO, requiring making an actual syscall with the overhead it
-entails.
+entails. If TSC isn't among the available clock sources, the current one is
+left as is.
 `
 
 const nomergesTunerHelp = `
 Disables merging adjacent IO requests, which would require checking outstanding
 IO requests to batch them where possible, incurring in some CPU overhead.
 `
+
+const filesLimitTunerHelp = `
+Raises the open file descriptors limit (RLIMIT_NOFILE) to 1048576, as redpanda
+keeps a file open for each log segment and each client connection. Limits which
+are already higher are left as is. The limit applies to rpk and the processes it
+starts, such as redpanda with 'rpk redpanda start', and is persisted in
+/etc/security/limits.d/99-redpanda-nofile.conf for new sessions. Unless the
+coredump tuner is enabled (tune_coredump), core dumps are disabled the same way,
+by setting the soft RLIMIT_CORE to 0. Raising the hard limit requires running as
+root.
+`
+
+const ethtoolTunerHelp = `
+Grows the RX and TX ring buffers of the NICs used by redpanda to the maximum
+size their drivers support, and enables adaptive interrupt coalescing, so that
+bursts of traffic at line rate aren't dropped. Bonds are tuned through their
+slaves, and virtual interfaces are skipped. Settings which a driver doesn't
+report, or doesn't allow changing, are skipped. Requires ethtool, which is
+installed with the distribution's package manager if it's missing and
+--install-packages is passed.
+`
+
+const cgroupTunerHelp = `
+Creates a 'redpanda' cgroup (under the cpu controller's hierarchy in cgroup v1)
+and raises its CPU weight to 1000 (cpu.shares to 10240 in cgroup v1), so that
+redpanda gets most of the CPU time when the node is overcommitted. In cgroup
+v2, its IO weight on the devices backing the data directory is raised to 1000
+and, if --cpu-set is given, its cpuset is restricted to it. The settings whose
+controller isn't enabled in the root's cgroup.subtree_control are skipped.
+Then the running redpanda, found through the PID file in its data directory,
+is moved into the cgroup. With 'rpk start --tune', redpanda is moved into it
+as it starts. If systemd manages redpanda's service, it may move redpanda
+back into the service's cgroup, so prefer setting the unit's Slice=,
+CPUWeight= and IOWeight= instead.
+`
+
+const cstateTunerHelp = `
+Disables the CPU idle states (C-states) whose exit latency is over 10us, on
+every online CPU, as waking up from deep C-states adds jitter to redpanda's
+latency. The states are picked by their latency rather than by their number,
+which depends on the idle driver. It's only enabled by a --profile with
+'low_latency: true', whose 'max_cstate_latency' overrides the threshold, as it
+raises the power consumption. Its changes can be reverted with the script
+written by --output-undo-script.
+`
+
+const nicChannelsTunerHelp = `
+Sets the number of combined channels (RX/TX queue pairs, each with its own
+IRQ) of the NICs used by redpanda to the number of cores, or to the maximum
+their drivers support if it's lower, with 'ethtool -L'. NICs whose driver
+doesn't support 'ethtool -l' are skipped. So are the ones carrying the RPC
+connections, as changing the channels briefly resets the link: the one the
+RPC server is bound to or, if it's bound to 0.0.0.0, the ones routing to the
+seed servers, or the default route's if there are none. If they can't be
+found, the tuner isn't supported. Its changes can be reverted with the script
+written by --output-undo-script. Requires ethtool.
+`
+
+const filesystemCheckTunerHelp = `
+Checks that the data directories are on a supported filesystem (xfs, which is
+recommended, or ext4), and that they aren't mounted with options risking the
+data on a power loss, like 'nobarrier'. The mounts are read from
+/proc/self/mountinfo. Bind mounts and overlays (e.g. a container's root) are
+followed to the mount holding the files, when it's visible. It changes
+nothing, and only warns about the failed checks. It's enabled by
+tune_filesystem_check, which production mode sets.
+`
+
+const blockQueueTunerHelp = `
+Sets the queue parameters of the block devices backing the data directory to
+the values recommended for redpanda: 'rq_affinity' to 2, so that each request
+completes on the CPU which issued it. Each parameter is checked and set on its
+own, and the ones a device's kernel doesn't expose are skipped. 'nomerges' is
+set by the disk_nomerges tuner.
+`
+
+const kernelThreadsTunerHelp = `
+Keeps the kernel threads off the CPUs given with --cpu-set, which redpanda is
+pinned to, so that they don't steal cycles from it. The unbound workqueues are
+restricted to the other online CPUs, by writing
+/sys/devices/virtual/workqueue/cpumask. The ksoftirqd threads can't be moved,
+as each is bound to its CPU, so the ones on redpanda's CPUs are only reported:
+steering the IRQs away from those CPUs (e.g. with the disk_irq and net tuners)
+keeps them idle. It's only enabled by a --profile listing it under 'tuners', as
+it takes CPUs away from the kernel. Its changes can be reverted with the
+script written by --output-undo-script.
+`
+
+const irqBalanceTunerHelp = `
+Sets IRQBALANCE_BANNED_CPUS in irqbalance's config (/etc/default/irqbalance,
+/etc/sysconfig/irqbalance or /etc/conf.d/irqbalance) to the CPUs given with
+--cpu-set, and restarts irqbalance, so that it doesn't move IRQs onto
+redpanda's CPUs, undoing the affinity set by the disk_irq and net tuners. An
+IRQBALANCE_BANNED_CPULIST setting, which would take precedence, is removed.
+Nothing changes if irqbalance isn't running. It's only enabled by a --profile
+listing it under 'tuners'. Its changes can be reverted with the script written
+by --output-undo-script, which restores the config and restarts irqbalance.
+`
+
+const taskSchedulerTunerHelp = `
+Raises the task scheduler's migration cost (kernel.sched_migration_cost_ns, set
+to 5ms) and latency (kernel.sched_latency_ns, set to 24ms), so that redpanda's
+threads aren't needlessly migrated between CPUs, favoring throughput over
+latency. Only the settings which differ are changed. On kernels which moved
+them out of /proc/sys/kernel, they're set in /sys/kernel/debug/sched instead,
+which requires debugfs to be mounted, and the ones writable in neither are
+reported and skipped. It's only enabled by a --profile listing it under
+'tuners'.
+`
+
+const maxMapCountTunerHelp = `
+Raises the max number of memory map areas a process may have
+(vm.max_map_count) to 262144, as redpanda maps a few per log segment, and
+fails to map more once the limit is hit. It's never lowered, so a higher value
+set by the operator is kept. The value is written to
+/etc/sysctl.d/99-redpanda.conf, updating the line setting it if there's one,
+and loaded with 'sysctl -p', so that it's kept after a reboot.
+`
+
+const dirtyRatioTunerHelp = `
+Lowers the share of memory which may hold dirty pages before the kernel starts
+writing them back (vm.dirty_background_ratio, to 5%) and before processes
+writing are throttled (vm.dirty_ratio, to 10%), which bounds the time an fsync
+may take. They're never raised, so lower ratios set by the operator are kept.
+A ratio whose limit is set in bytes instead (vm.dirty_background_bytes or
+vm.dirty_bytes) is left as is, since setting the ratio would clear it.
+`
@@ -0,0 +1,74 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tune
+
+import (
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/cli/ui"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/config"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/factory"
+)
+
+func NewListCommand(fs afero.Fs, mgr config.Manager) *cobra.Command {
+	var configFile string
+	command := &cobra.Command{
+		Use:   "list",
+		Short: "List the available tuners, and whether they're enabled and supported.",
+		Args:  cobra.NoArgs,
+		RunE: func(ccmd *cobra.Command, args []string) error {
+			conf, err := mgr.FindOrGenerate(configFile)
+			if err != nil {
+				return err
+			}
+			params, err := factory.MergeTunerParamsConfig(
+				&factory.TunerParams{CpuMask: "all"},
+				conf,
+			)
+			if err != nil {
+				log.Debugf("Couldn't read the tuner params from the config: %v", err)
+			}
+			tunersFactory := factory.NewDirectExecutorTunersFactory(
+				fs, *conf, 10000*time.Millisecond)
+			tunerNames := factory.AvailableTuners()
+			sort.Strings(tunerNames)
+
+			t := ui.NewRpkTable(os.Stdout)
+			t.SetHeader([]string{"Tuner", "Enabled", "Supported", "Unsupported Reason"})
+			for _, name := range tunerNames {
+				enabled := factory.IsTunerEnabled(name, conf.Rpk)
+				tuner := tunersFactory.CreateTuner(name, params)
+				supported, reason := tuner.CheckIfSupported()
+				t.Append([]string{
+					name,
+					strconv.FormatBool(enabled),
+					strconv.FormatBool(supported),
+					reason,
+				})
+			}
+			t.Render()
+			return nil
+		},
+	}
+	command.Flags().StringVar(
+		&configFile,
+		"config",
+		"",
+		"Redpanda config file, if not set the file will be searched for"+
+			" in the default locations.",
+	)
+	return command
+}
@@ -13,13 +13,22 @@ package redpanda
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"log"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/config"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors/commands"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/factory"
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/utils"
 )
 
@@ -94,3 +103,340 @@ func TestInteractive(t *testing.T) {
 		})
 	}
 }
+
+type fakeTuner struct {
+	name string
+	fail bool
+	// If set, Tune blocks until it's closed, or until ctx is done.
+	block chan struct{}
+	ctx   context.Context
+	mu    *sync.Mutex
+	ran   *[]string
+	// The tuners which returned, in order.
+	returned *[]string
+}
+
+func (t *fakeTuner) CheckIfSupported() (bool, string) {
+	return true, ""
+}
+
+func (t *fakeTuner) Tune() tuners.TuneResult {
+	t.mu.Lock()
+	*t.ran = append(*t.ran, t.name)
+	t.mu.Unlock()
+	defer func() {
+		t.mu.Lock()
+		*t.returned = append(*t.returned, t.name)
+		t.mu.Unlock()
+	}()
+	if t.block != nil {
+		select {
+		case <-t.block:
+		case <-t.ctx.Done():
+			return tuners.NewTuneError(t.ctx.Err())
+		}
+	}
+	if t.fail {
+		return tuners.NewTuneError(errors.New(t.name + " failed"))
+	}
+	return tuners.NewTuneResult(false)
+}
+
+type fakeTunersFactory struct {
+	failing  string
+	hung     string
+	block    chan struct{}
+	mu       sync.Mutex
+	ran      []string
+	returned []string
+}
+
+func (f *fakeTunersFactory) CreateTuner(
+	name string, params *factory.TunerParams,
+) tuners.Tunable {
+	return f.CreateTunerContext(context.Background(), name, params)
+}
+
+func (f *fakeTunersFactory) CreateTunerContext(
+	ctx context.Context, name string, _ *factory.TunerParams,
+) tuners.Tunable {
+	tuner := &fakeTuner{
+		name:     name,
+		fail:     name == f.failing,
+		ctx:      ctx,
+		mu:       &f.mu,
+		ran:      &f.ran,
+		returned: &f.returned,
+	}
+	if name == f.hung {
+		tuner.block = f.block
+	}
+	return tuner
+}
+
+func TestRunTuners(t *testing.T) {
+	conf := config.Default()
+	conf.Rpk.TuneNetwork = true
+	conf.Rpk.TuneDiskIrq = true
+	conf.Rpk.TuneSwappiness = true
+	conf.Rpk.TuneClocksource = true
+	names := []string{"swappiness", "disk_irq", "clocksource", "net", "fstrim"}
+	fact := &fakeTunersFactory{failing: "clocksource"}
+
+	results, rebootRequired := runTuners(
+		context.Background(), conf, names, fact, &factory.TunerParams{}, 4, 0,
+	)
+
+	require.False(t, rebootRequired)
+	expected := []result{
+		{Name: "swappiness", Enabled: true, Supported: true, Applied: true, Changed: true},
+		{Name: "disk_irq", Enabled: true, Supported: true, Applied: true, Changed: true},
+		{Name: "clocksource", Enabled: true, Supported: true, ErrMsg: "clocksource failed"},
+		{Name: "net", Enabled: true, Supported: true, Applied: true, Changed: true},
+		// The disabled tuner isn't checked for support.
+		{Name: "fstrim"},
+	}
+	require.Equal(t, expected, results)
+	// The disabled tuner isn't run, and the ones which aren't independent
+	// run in the requested order.
+	require.Len(t, fact.ran, 4)
+	var ordered []string
+	for _, name := range fact.ran {
+		if !factory.IsTunerIndependent(name) {
+			ordered = append(ordered, name)
+		}
+	}
+	require.Equal(t, []string{"disk_irq", "net"}, ordered)
+}
+
+func TestRunTunersSequential(t *testing.T) {
+	conf := config.Default()
+	conf.Rpk.TuneNetwork = true
+	conf.Rpk.TuneDiskIrq = true
+	conf.Rpk.TuneSwappiness = true
+	conf.Rpk.TuneClocksource = true
+	names := []string{"disk_irq", "swappiness", "net", "clocksource"}
+	fact := &fakeTunersFactory{}
+
+	runTuners(context.Background(), conf, names, fact, &factory.TunerParams{}, 1, 0)
+
+	// The independent tuners don't run before the others.
+	require.Equal(t, names, fact.ran)
+}
+
+func TestRunTunersTimeout(t *testing.T) {
+	conf := config.Default()
+	conf.Rpk.TuneNetwork = true
+	conf.Rpk.TuneDiskIrq = true
+	conf.Rpk.TuneSwappiness = true
+	names := []string{"swappiness", "disk_irq", "net"}
+	tests := []struct {
+		name         string
+		hung         string
+		tunerTimeout time.Duration
+		deadline     time.Duration
+		expected     []result
+	}{
+		{
+			name:         "it should cut off a tuner exceeding its timeout",
+			hung:         "swappiness",
+			tunerTimeout: 50 * time.Millisecond,
+			expected: []result{
+				{Name: "swappiness", Enabled: true, Supported: true, TimedOut: true, ErrMsg: "timed out: it didn't complete within 50ms"},
+				{Name: "disk_irq", Enabled: true, Supported: true, Applied: true, Changed: true},
+				{Name: "net", Enabled: true, Supported: true, Applied: true, Changed: true},
+			},
+		},
+		{
+			name:     "it should skip the tuners which didn't start before the deadline",
+			hung:     "disk_irq",
+			deadline: 50 * time.Millisecond,
+			expected: []result{
+				{Name: "swappiness", Enabled: true, Supported: true, Applied: true, Changed: true},
+				{Name: "disk_irq", Enabled: true, Supported: true, TimedOut: true, ErrMsg: "timed out: it didn't complete before the deadline"},
+				{Name: "net", Enabled: true, Supported: true, TimedOut: true, ErrMsg: "timed out: it didn't start before the deadline"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fact := &fakeTunersFactory{hung: tt.hung, block: make(chan struct{})}
+			defer close(fact.block)
+			ctx := context.Background()
+			if tt.deadline > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.deadline)
+				defer cancel()
+			}
+
+			results, _ := runTuners(
+				ctx, conf, names, fact, &factory.TunerParams{}, 4, tt.tunerTimeout,
+			)
+
+			require.Equal(t, tt.expected, results)
+		})
+	}
+}
+
+func TestRunTunersTimeoutOrdered(t *testing.T) {
+	conf := config.Default()
+	conf.Rpk.TuneNetwork = true
+	conf.Rpk.TuneDiskIrq = true
+	fact := &fakeTunersFactory{hung: "disk_irq", block: make(chan struct{})}
+	defer close(fact.block)
+
+	results, _ := runTuners(
+		context.Background(),
+		conf,
+		[]string{"disk_irq", "net"},
+		fact,
+		&factory.TunerParams{},
+		4,
+		50*time.Millisecond,
+	)
+
+	require.True(t, results[0].TimedOut)
+	require.Equal(t, result{Name: "net", Enabled: true, Supported: true, Applied: true, Changed: true}, results[1])
+	// The next tuner only started once the one which timed out returned.
+	require.Equal(t, []string{"disk_irq", "net"}, fact.ran)
+	require.Equal(t, []string{"disk_irq", "net"}, fact.returned)
+}
+
+// A tuner writing a file through an executor.
+type writingTuner struct {
+	fakeTuner
+	fs       afero.Fs
+	executor executors.Executor
+}
+
+func (t *writingTuner) Tune() tuners.TuneResult {
+	err := t.executor.Execute(commands.NewWriteFileCmd(t.fs, "/some/file", "1"))
+	if err != nil {
+		return tuners.NewTuneError(err)
+	}
+	return tuners.NewTuneResult(false)
+}
+
+func TestCollectedTunerChanged(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	newTuner := func() tuners.Tunable {
+		failures := executors.NewErrorCollectingExecutor(executors.NewDirectExecutor())
+		executor := executors.NewCollectingExecutor(failures)
+		return &collectedTuner{
+			Tunable:  &writingTuner{fs: fs, executor: executor},
+			executor: executor,
+			failures: failures,
+		}
+	}
+	require.True(t, newTuner().Tune().IsChanged())
+
+	// The file already holds the value, so the write is skipped.
+	res := newTuner().Tune()
+	require.False(t, res.IsFailed())
+	require.False(t, res.IsChanged())
+}
+
+// A tuner recording whether it was asked if it's supported.
+type probedTuner struct {
+	fakeTuner
+	probed bool
+}
+
+func (t *probedTuner) CheckIfSupported() (bool, string) {
+	t.probed = true
+	return true, ""
+}
+
+func TestTuneOneDisabled(t *testing.T) {
+	tuner := &probedTuner{}
+	res, reboot := tuneOne("nic_channels", false, tuner, &factory.TunerParams{})
+	require.Equal(t, result{Name: "nic_channels"}, res)
+	require.False(t, reboot)
+	require.False(t, tuner.probed)
+}
+
+// Fails every command.
+type failingExecutor struct{}
+
+func (failingExecutor) Execute(commands.Command) error {
+	return errors.New("boom")
+}
+
+func (failingExecutor) IsLazy() bool {
+	return false
+}
+
+func TestTimedTunersFactoryFailures(t *testing.T) {
+	for _, continueOnError := range []bool{false, true} {
+		fs := afero.NewMemMapFs()
+		_, err := utils.WriteBytes(fs, []byte("60"), "/proc/sys/vm/swappiness")
+		require.NoError(t, err)
+		f := &timedTunersFactory{
+			fs:              fs,
+			conf:            *config.Default(),
+			executor:        failingExecutor{},
+			continueOnError: continueOnError,
+			timings:         map[string]executors.TimingExecutor{},
+			failures:        map[string]executors.ErrorCollector{},
+		}
+		tuner := f.CreateTuner("swappiness", &factory.TunerParams{})
+		// Even if it went on past the failed command.
+		res := tuner.Tune()
+		require.True(t, res.IsFailed())
+		require.Contains(t, res.Error().Error(), "boom")
+
+		summary := executors.SummarizeTimings(f.timings["swappiness"].Timings())
+		require.Contains(t, summary, "(1 failed)")
+		var multi *executors.MultiError
+		require.True(t, errors.As(f.Err(), &multi))
+		require.Len(t, multi.Errors, 1)
+	}
+}
+
+func TestPrintTuneResultJson(t *testing.T) {
+	results := []result{
+		{Name: "swappiness", Enabled: true, Supported: true, Applied: true},
+		{Name: "clocksource", Supported: false, ErrMsg: "no tsc"},
+	}
+	var out bytes.Buffer
+	require.NoError(t, printTuneResultJSON(&out, results))
+	expected := `[
+  {
+    "name": "clocksource",
+    "enabled": false,
+    "supported": false,
+    "applied": false,
+    "changed": false,
+    "error": "no tsc"
+  },
+  {
+    "name": "swappiness",
+    "enabled": true,
+    "supported": true,
+    "applied": true,
+    "changed": false
+  }
+]
+`
+	require.Equal(t, expected, out.String())
+}
+
+func TestProgressPrinter(t *testing.T) {
+	var out bytes.Buffer
+	observe := newProgressPrinter(&out)
+	write := commands.Description{Desc: "Write '2' to '/a'"}
+	observe(executors.CommandProgress{Command: write, Status: executors.CommandStarted})
+	observe(executors.CommandProgress{Command: write, Status: executors.CommandSucceeded})
+	observe(executors.CommandProgress{Command: write, Status: executors.CommandStarted})
+	observe(executors.CommandProgress{
+		Command: write,
+		Status:  executors.CommandFailed,
+		Err:     errors.New("boom"),
+	})
+	expected := `[0 done] running: Write '2' to '/a'
+[1 done] ok: Write '2' to '/a'
+[1 done] running: Write '2' to '/a'
+[2 done] failed: Write '2' to '/a': boom
+`
+	require.Equal(t, expected, out.String())
+}
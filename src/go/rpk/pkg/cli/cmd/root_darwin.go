@@ -15,8 +15,10 @@ import (
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/config"
 )
 
-// On MacOS this is a no-op.
+// On MacOS tuning is unsupported, but the tune command is still added so
+// that the available tuners can be listed.
 func addPlatformDependentCmds(
 	fs afero.Fs, mgr config.Manager, cmd *cobra.Command,
 ) {
+	cmd.AddCommand(NewTuneCommand(fs, mgr))
 }
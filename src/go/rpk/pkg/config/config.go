@@ -191,6 +191,12 @@ func setProduction(conf *Config) *Config {
 	conf.Rpk.Overprovisioned = false
 	conf.Rpk.TuneDiskWriteCache = true
 	conf.Rpk.TuneBallastFile = true
+	conf.Rpk.TuneFilesLimit = true
+	conf.Rpk.TuneEthtool = true
+	conf.Rpk.TuneBlockQueue = true
+	conf.Rpk.TuneMaxMapCount = true
+	conf.Rpk.TuneDirtyRatio = true
+	conf.Rpk.TuneFilesystemCheck = true
 	return conf
 }
 
@@ -38,6 +38,14 @@ func getValidConfig() *Config {
 		TuneDiskWriteCache:       true,
 		TuneNomerges:             true,
 		TuneDiskIrq:              true,
+		TuneEthtool:              true,
+		TuneCgroup:               true,
+		TuneNicChannels:          true,
+		TuneBlockQueue:           true,
+		TuneMaxMapCount:          true,
+		TuneDirtyRatio:           true,
+		TuneFilesystemCheck:      true,
+		TuneFilesLimit:           true,
 		TuneFstrim:               true,
 		TuneCpu:                  true,
 		TuneAioEvents:            true,
@@ -160,6 +168,14 @@ func TestSet(t *testing.T) {
 					TuneSwappiness:           false,
 					TuneTransparentHugePages: false,
 					EnableMemoryLocking:      false,
+					TuneEthtool:              false,
+					TuneCgroup:               false,
+					TuneNicChannels:          false,
+					TuneBlockQueue:           false,
+					TuneMaxMapCount:          false,
+					TuneDirtyRatio:           false,
+					TuneFilesystemCheck:      false,
+					TuneFilesLimit:           false,
 					TuneFstrim:               false,
 					TuneCoredump:             false,
 					TuneDiskWriteCache:       false,
@@ -475,15 +491,23 @@ rpk:
   overprovisioned: false
   tune_aio_events: true
   tune_ballast_file: true
+  tune_block_queue: true
+  tune_cgroup: true
   tune_clocksource: true
   tune_coredump: true
   tune_cpu: true
+  tune_dirty_ratio: true
   tune_disk_irq: true
   tune_disk_nomerges: true
   tune_disk_scheduler: true
   tune_disk_write_cache: true
+  tune_ethtool: true
+  tune_files_limit: true
+  tune_filesystem_check: true
   tune_fstrim: true
+  tune_max_map_count: true
   tune_network: true
+  tune_nic_channels: true
   tune_swappiness: true
   tune_transparent_hugepages: true
   well_known_io: vendor:vm:storage
@@ -523,15 +547,23 @@ rpk:
   overprovisioned: false
   tune_aio_events: true
   tune_ballast_file: true
+  tune_block_queue: true
+  tune_cgroup: true
   tune_clocksource: true
   tune_coredump: true
   tune_cpu: true
+  tune_dirty_ratio: true
   tune_disk_irq: true
   tune_disk_nomerges: true
   tune_disk_scheduler: true
   tune_disk_write_cache: true
+  tune_ethtool: true
+  tune_files_limit: true
+  tune_filesystem_check: true
   tune_fstrim: true
+  tune_max_map_count: true
   tune_network: true
+  tune_nic_channels: true
   tune_swappiness: true
   tune_transparent_hugepages: true
   well_known_io: vendor:vm:storage
@@ -581,15 +613,23 @@ rpk:
   overprovisioned: false
   tune_aio_events: true
   tune_ballast_file: true
+  tune_block_queue: true
+  tune_cgroup: true
   tune_clocksource: true
   tune_coredump: true
   tune_cpu: true
+  tune_dirty_ratio: true
   tune_disk_irq: true
   tune_disk_nomerges: true
   tune_disk_scheduler: true
   tune_disk_write_cache: true
+  tune_ethtool: true
+  tune_files_limit: true
+  tune_filesystem_check: true
   tune_fstrim: true
+  tune_max_map_count: true
   tune_network: true
+  tune_nic_channels: true
   tune_swappiness: true
   tune_transparent_hugepages: true
   well_known_io: vendor:vm:storage
@@ -641,15 +681,23 @@ rpk:
   overprovisioned: false
   tune_aio_events: true
   tune_ballast_file: true
+  tune_block_queue: true
+  tune_cgroup: true
   tune_clocksource: true
   tune_coredump: true
   tune_cpu: true
+  tune_dirty_ratio: true
   tune_disk_irq: true
   tune_disk_nomerges: true
   tune_disk_scheduler: true
   tune_disk_write_cache: true
+  tune_ethtool: true
+  tune_files_limit: true
+  tune_filesystem_check: true
   tune_fstrim: true
+  tune_max_map_count: true
   tune_network: true
+  tune_nic_channels: true
   tune_swappiness: true
   tune_transparent_hugepages: true
   well_known_io: vendor:vm:storage
@@ -693,15 +741,23 @@ rpk:
   overprovisioned: false
   tune_aio_events: false
   tune_ballast_file: false
+  tune_block_queue: false
+  tune_cgroup: false
   tune_clocksource: false
   tune_coredump: false
   tune_cpu: false
+  tune_dirty_ratio: false
   tune_disk_irq: false
   tune_disk_nomerges: false
   tune_disk_scheduler: false
   tune_disk_write_cache: false
+  tune_ethtool: false
+  tune_files_limit: false
+  tune_filesystem_check: false
   tune_fstrim: false
+  tune_max_map_count: false
   tune_network: false
+  tune_nic_channels: false
   tune_swappiness: false
   tune_transparent_hugepages: false
 schema_registry: {}
@@ -749,15 +805,23 @@ rpk:
   overprovisioned: false
   tune_aio_events: true
   tune_ballast_file: true
+  tune_block_queue: true
+  tune_cgroup: true
   tune_clocksource: true
   tune_coredump: true
   tune_cpu: true
+  tune_dirty_ratio: true
   tune_disk_irq: true
   tune_disk_nomerges: true
   tune_disk_scheduler: true
   tune_disk_write_cache: true
+  tune_ethtool: true
+  tune_files_limit: true
+  tune_filesystem_check: true
   tune_fstrim: true
+  tune_max_map_count: true
   tune_network: true
+  tune_nic_channels: true
   tune_swappiness: true
   tune_transparent_hugepages: true
   well_known_io: vendor:vm:storage
@@ -829,15 +893,23 @@ rpk:
   overprovisioned: false
   tune_aio_events: true
   tune_ballast_file: true
+  tune_block_queue: true
+  tune_cgroup: true
   tune_clocksource: true
   tune_coredump: true
   tune_cpu: true
+  tune_dirty_ratio: true
   tune_disk_irq: true
   tune_disk_nomerges: true
   tune_disk_scheduler: true
   tune_disk_write_cache: true
+  tune_ethtool: true
+  tune_files_limit: true
+  tune_filesystem_check: true
   tune_fstrim: true
+  tune_max_map_count: true
   tune_network: true
+  tune_nic_channels: true
   tune_swappiness: true
   tune_transparent_hugepages: true
   well_known_io: vendor:vm:storage
@@ -901,15 +973,23 @@ rpk:
   overprovisioned: false
   tune_aio_events: true
   tune_ballast_file: true
+  tune_block_queue: true
+  tune_cgroup: true
   tune_clocksource: true
   tune_coredump: true
   tune_cpu: true
+  tune_dirty_ratio: true
   tune_disk_irq: true
   tune_disk_nomerges: true
   tune_disk_scheduler: true
   tune_disk_write_cache: true
+  tune_ethtool: true
+  tune_files_limit: true
+  tune_filesystem_check: true
   tune_fstrim: true
+  tune_max_map_count: true
   tune_network: true
+  tune_nic_channels: true
   tune_swappiness: true
   tune_transparent_hugepages: true
   well_known_io: vendor:vm:storage
@@ -969,15 +1049,23 @@ rpk:
   overprovisioned: false
   tune_aio_events: true
   tune_ballast_file: true
+  tune_block_queue: true
+  tune_cgroup: true
   tune_clocksource: true
   tune_coredump: true
   tune_cpu: true
+  tune_dirty_ratio: true
   tune_disk_irq: true
   tune_disk_nomerges: true
   tune_disk_scheduler: true
   tune_disk_write_cache: true
+  tune_ethtool: true
+  tune_files_limit: true
+  tune_filesystem_check: true
   tune_fstrim: true
+  tune_max_map_count: true
   tune_network: true
+  tune_nic_channels: true
   tune_swappiness: true
   tune_transparent_hugepages: true
   well_known_io: vendor:vm:storage
@@ -1033,15 +1121,23 @@ rpk:
   overprovisioned: false
   tune_aio_events: true
   tune_ballast_file: true
+  tune_block_queue: true
+  tune_cgroup: true
   tune_clocksource: true
   tune_coredump: true
   tune_cpu: true
+  tune_dirty_ratio: true
   tune_disk_irq: true
   tune_disk_nomerges: true
   tune_disk_scheduler: true
   tune_disk_write_cache: true
+  tune_ethtool: true
+  tune_files_limit: true
+  tune_filesystem_check: true
   tune_fstrim: true
+  tune_max_map_count: true
   tune_network: true
+  tune_nic_channels: true
   tune_swappiness: true
   tune_transparent_hugepages: true
   well_known_io: vendor:vm:storage
@@ -1087,15 +1183,23 @@ rpk:
   overprovisioned: false
   tune_aio_events: true
   tune_ballast_file: true
+  tune_block_queue: true
+  tune_cgroup: true
   tune_clocksource: true
   tune_coredump: true
   tune_cpu: true
+  tune_dirty_ratio: true
   tune_disk_irq: true
   tune_disk_nomerges: true
   tune_disk_scheduler: true
   tune_disk_write_cache: true
+  tune_ethtool: true
+  tune_files_limit: true
+  tune_filesystem_check: true
   tune_fstrim: true
+  tune_max_map_count: true
   tune_network: true
+  tune_nic_channels: true
   tune_swappiness: true
   tune_transparent_hugepages: true
   well_known_io: vendor:vm:storage
@@ -1179,15 +1283,23 @@ rpk:
   overprovisioned: false
   tune_aio_events: true
   tune_ballast_file: true
+  tune_block_queue: true
+  tune_cgroup: true
   tune_clocksource: true
   tune_coredump: true
   tune_cpu: true
+  tune_dirty_ratio: true
   tune_disk_irq: true
   tune_disk_nomerges: true
   tune_disk_scheduler: true
   tune_disk_write_cache: true
+  tune_ethtool: true
+  tune_files_limit: true
+  tune_filesystem_check: true
   tune_fstrim: true
+  tune_max_map_count: true
   tune_network: true
+  tune_nic_channels: true
   tune_swappiness: true
   tune_transparent_hugepages: true
   well_known_io: vendor:vm:storage
@@ -1245,15 +1357,23 @@ rpk:
   overprovisioned: false
   tune_aio_events: true
   tune_ballast_file: true
+  tune_block_queue: true
+  tune_cgroup: true
   tune_clocksource: true
   tune_coredump: true
   tune_cpu: true
+  tune_dirty_ratio: true
   tune_disk_irq: true
   tune_disk_nomerges: true
   tune_disk_scheduler: true
   tune_disk_write_cache: true
+  tune_ethtool: true
+  tune_files_limit: true
+  tune_filesystem_check: true
   tune_fstrim: true
+  tune_max_map_count: true
   tune_network: true
+  tune_nic_channels: true
   tune_swappiness: true
   tune_transparent_hugepages: true
   well_known_io: vendor:vm:storage
@@ -1321,15 +1441,23 @@ rpk:
   overprovisioned: false
   tune_aio_events: true
   tune_ballast_file: true
+  tune_block_queue: true
+  tune_cgroup: true
   tune_clocksource: true
   tune_coredump: true
   tune_cpu: true
+  tune_dirty_ratio: true
   tune_disk_irq: true
   tune_disk_nomerges: true
   tune_disk_scheduler: true
   tune_disk_write_cache: true
+  tune_ethtool: true
+  tune_files_limit: true
+  tune_filesystem_check: true
   tune_fstrim: true
+  tune_max_map_count: true
   tune_network: true
+  tune_nic_channels: true
   tune_swappiness: true
   tune_transparent_hugepages: true
   well_known_io: vendor:vm:storage
@@ -1375,15 +1503,23 @@ rpk:
   overprovisioned: false
   tune_aio_events: true
   tune_ballast_file: true
+  tune_block_queue: true
+  tune_cgroup: true
   tune_clocksource: true
   tune_coredump: true
   tune_cpu: true
+  tune_dirty_ratio: true
   tune_disk_irq: true
   tune_disk_nomerges: true
   tune_disk_scheduler: true
   tune_disk_write_cache: true
+  tune_ethtool: true
+  tune_files_limit: true
+  tune_filesystem_check: true
   tune_fstrim: true
+  tune_max_map_count: true
   tune_network: true
+  tune_nic_channels: true
   tune_swappiness: true
   tune_transparent_hugepages: true
   well_known_io: vendor:vm:storage
@@ -1429,15 +1565,23 @@ rpk:
   overprovisioned: false
   tune_aio_events: true
   tune_ballast_file: true
+  tune_block_queue: true
+  tune_cgroup: true
   tune_clocksource: true
   tune_coredump: true
   tune_cpu: true
+  tune_dirty_ratio: true
   tune_disk_irq: true
   tune_disk_nomerges: true
   tune_disk_scheduler: true
   tune_disk_write_cache: true
+  tune_ethtool: true
+  tune_files_limit: true
+  tune_filesystem_check: true
   tune_fstrim: true
+  tune_max_map_count: true
   tune_network: true
+  tune_nic_channels: true
   tune_swappiness: true
   tune_transparent_hugepages: true
   well_known_io: vendor:vm:storage
@@ -1492,15 +1636,23 @@ rpk:
   overprovisioned: false
   tune_aio_events: true
   tune_ballast_file: true
+  tune_block_queue: true
+  tune_cgroup: true
   tune_clocksource: true
   tune_coredump: true
   tune_cpu: true
+  tune_dirty_ratio: true
   tune_disk_irq: true
   tune_disk_nomerges: true
   tune_disk_scheduler: true
   tune_disk_write_cache: true
+  tune_ethtool: true
+  tune_files_limit: true
+  tune_filesystem_check: true
   tune_fstrim: true
+  tune_max_map_count: true
   tune_network: true
+  tune_nic_channels: true
   tune_swappiness: true
   tune_transparent_hugepages: true
   well_known_io: vendor:vm:storage
@@ -1576,15 +1728,23 @@ rpk:
   overprovisioned: false
   tune_aio_events: true
   tune_ballast_file: true
+  tune_block_queue: true
+  tune_cgroup: true
   tune_clocksource: true
   tune_coredump: true
   tune_cpu: true
+  tune_dirty_ratio: true
   tune_disk_irq: true
   tune_disk_nomerges: true
   tune_disk_scheduler: true
   tune_disk_write_cache: true
+  tune_ethtool: true
+  tune_files_limit: true
+  tune_filesystem_check: true
   tune_fstrim: true
+  tune_max_map_count: true
   tune_network: true
+  tune_nic_channels: true
   tune_swappiness: true
   tune_transparent_hugepages: true
   well_known_io: vendor:vm:storage
@@ -1634,15 +1794,23 @@ rpk:
   overprovisioned: false
   tune_aio_events: true
   tune_ballast_file: true
+  tune_block_queue: true
+  tune_cgroup: true
   tune_clocksource: true
   tune_coredump: true
   tune_cpu: true
+  tune_dirty_ratio: true
   tune_disk_irq: true
   tune_disk_nomerges: true
   tune_disk_scheduler: true
   tune_disk_write_cache: true
+  tune_ethtool: true
+  tune_files_limit: true
+  tune_filesystem_check: true
   tune_fstrim: true
+  tune_max_map_count: true
   tune_network: true
+  tune_nic_channels: true
   tune_swappiness: true
   tune_transparent_hugepages: true
   well_known_io: vendor:vm:storage
@@ -1695,15 +1863,23 @@ rpk:
   overprovisioned: false
   tune_aio_events: true
   tune_ballast_file: true
+  tune_block_queue: true
+  tune_cgroup: true
   tune_clocksource: true
   tune_coredump: true
   tune_cpu: true
+  tune_dirty_ratio: true
   tune_disk_irq: true
   tune_disk_nomerges: true
   tune_disk_scheduler: true
   tune_disk_write_cache: true
+  tune_ethtool: true
+  tune_files_limit: true
+  tune_filesystem_check: true
   tune_fstrim: true
+  tune_max_map_count: true
   tune_network: true
+  tune_nic_channels: true
   tune_swappiness: true
   tune_transparent_hugepages: true
   well_known_io: vendor:vm:storage
@@ -1766,15 +1942,23 @@ rpk:
   overprovisioned: false
   tune_aio_events: false
   tune_ballast_file: false
+  tune_block_queue: false
+  tune_cgroup: false
   tune_clocksource: false
   tune_coredump: false
   tune_cpu: false
+  tune_dirty_ratio: false
   tune_disk_irq: false
   tune_disk_nomerges: false
   tune_disk_scheduler: false
   tune_disk_write_cache: false
+  tune_ethtool: false
+  tune_files_limit: false
+  tune_filesystem_check: false
   tune_fstrim: false
+  tune_max_map_count: false
   tune_network: false
+  tune_nic_channels: false
   tune_swappiness: false
   tune_transparent_hugepages: false
 schema_registry: {}
@@ -1909,19 +2093,25 @@ func TestSetMode(t *testing.T) {
 			val := mode == ModeProd
 			conf.Redpanda.DeveloperMode = !val
 			conf.Rpk = RpkConfig{
-				TuneNetwork:        val,
-				TuneDiskScheduler:  val,
-				TuneNomerges:       val,
-				TuneDiskWriteCache: val,
-				TuneDiskIrq:        val,
-				TuneFstrim:         val,
-				TuneCpu:            val,
-				TuneAioEvents:      val,
-				TuneClocksource:    val,
-				TuneSwappiness:     val,
-				CoredumpDir:        conf.Rpk.CoredumpDir,
-				Overprovisioned:    !val,
-				TuneBallastFile:    val,
+				TuneNetwork:         val,
+				TuneDiskScheduler:   val,
+				TuneNomerges:        val,
+				TuneDiskWriteCache:  val,
+				TuneDiskIrq:         val,
+				TuneEthtool:         val,
+				TuneBlockQueue:      val,
+				TuneMaxMapCount:     val,
+				TuneDirtyRatio:      val,
+				TuneFilesystemCheck: val,
+				TuneFilesLimit:      val,
+				TuneFstrim:          val,
+				TuneCpu:             val,
+				TuneAioEvents:       val,
+				TuneClocksource:     val,
+				TuneSwappiness:      val,
+				CoredumpDir:         conf.Rpk.CoredumpDir,
+				Overprovisioned:     !val,
+				TuneBallastFile:     val,
 			}
 			return conf
 		}
@@ -2162,7 +2352,7 @@ func TestReadAsJSON(t *testing.T) {
 				return mgr.Write(conf)
 			},
 			path:     Default().ConfigFile,
-			expected: `{"config_file":"/etc/redpanda/redpanda.yaml","pandaproxy":{},"redpanda":{"admin":[{"address":"0.0.0.0","port":9644}],"data_directory":"/var/lib/redpanda/data","developer_mode":true,"kafka_api":[{"address":"0.0.0.0","name":"internal","port":9092}],"node_id":0,"rpc_server":{"address":"0.0.0.0","port":33145},"seed_servers":[]},"rpk":{"coredump_dir":"/var/lib/redpanda/coredump","enable_memory_locking":false,"enable_usage_stats":false,"overprovisioned":false,"tune_aio_events":false,"tune_ballast_file":false,"tune_clocksource":false,"tune_coredump":false,"tune_cpu":false,"tune_disk_irq":false,"tune_disk_nomerges":false,"tune_disk_scheduler":false,"tune_disk_write_cache":false,"tune_fstrim":false,"tune_network":false,"tune_swappiness":false,"tune_transparent_hugepages":false},"schema_registry":{}}`,
+			expected: `{"config_file":"/etc/redpanda/redpanda.yaml","pandaproxy":{},"redpanda":{"admin":[{"address":"0.0.0.0","port":9644}],"data_directory":"/var/lib/redpanda/data","developer_mode":true,"kafka_api":[{"address":"0.0.0.0","name":"internal","port":9092}],"node_id":0,"rpc_server":{"address":"0.0.0.0","port":33145},"seed_servers":[]},"rpk":{"coredump_dir":"/var/lib/redpanda/coredump","enable_memory_locking":false,"enable_usage_stats":false,"overprovisioned":false,"tune_aio_events":false,"tune_ballast_file":false,"tune_block_queue":false,"tune_cgroup":false,"tune_clocksource":false,"tune_coredump":false,"tune_cpu":false,"tune_dirty_ratio":false,"tune_disk_irq":false,"tune_disk_nomerges":false,"tune_disk_scheduler":false,"tune_disk_write_cache":false,"tune_ethtool":false,"tune_files_limit":false,"tune_filesystem_check":false,"tune_fstrim":false,"tune_max_map_count":false,"tune_network":false,"tune_nic_channels":false,"tune_swappiness":false,"tune_transparent_hugepages":false},"schema_registry":{}}`,
 		},
 		{
 			name:           "it should fail if the the config isn't found",
@@ -2235,6 +2425,14 @@ func TestReadFlat(t *testing.T) {
 		"rpk.tune_disk_nomerges":                       "false",
 		"rpk.tune_disk_scheduler":                      "false",
 		"rpk.tune_disk_write_cache":                    "false",
+		"rpk.tune_ethtool":                             "false",
+		"rpk.tune_cgroup":                              "false",
+		"rpk.tune_nic_channels":                        "false",
+		"rpk.tune_block_queue":                         "false",
+		"rpk.tune_max_map_count":                       "false",
+		"rpk.tune_dirty_ratio":                         "false",
+		"rpk.tune_filesystem_check":                    "false",
+		"rpk.tune_files_limit":                         "false",
 		"rpk.tune_fstrim":                              "false",
 		"rpk.tune_network":                             "false",
 		"rpk.tune_swappiness":                          "false",
@@ -159,6 +159,14 @@ type RpkConfig struct {
 	TuneDiskWriteCache       bool        `yaml:"tune_disk_write_cache" mapstructure:"tune_disk_write_cache" json:"tuneDiskWriteCache"`
 	TuneDiskIrq              bool        `yaml:"tune_disk_irq" mapstructure:"tune_disk_irq" json:"tuneDiskIrq"`
 	TuneFstrim               bool        `yaml:"tune_fstrim" mapstructure:"tune_fstrim" json:"tuneFstrim"`
+	TuneFilesLimit           bool        `yaml:"tune_files_limit" mapstructure:"tune_files_limit" json:"tuneFilesLimit"`
+	TuneEthtool              bool        `yaml:"tune_ethtool" mapstructure:"tune_ethtool" json:"tuneEthtool"`
+	TuneCgroup               bool        `yaml:"tune_cgroup" mapstructure:"tune_cgroup" json:"tuneCgroup"`
+	TuneNicChannels          bool        `yaml:"tune_nic_channels" mapstructure:"tune_nic_channels" json:"tuneNicChannels"`
+	TuneBlockQueue           bool        `yaml:"tune_block_queue" mapstructure:"tune_block_queue" json:"tuneBlockQueue"`
+	TuneMaxMapCount          bool        `yaml:"tune_max_map_count" mapstructure:"tune_max_map_count" json:"tuneMaxMapCount"`
+	TuneDirtyRatio           bool        `yaml:"tune_dirty_ratio" mapstructure:"tune_dirty_ratio" json:"tuneDirtyRatio"`
+	TuneFilesystemCheck      bool        `yaml:"tune_filesystem_check" mapstructure:"tune_filesystem_check" json:"tuneFilesystemCheck"`
 	TuneCpu                  bool        `yaml:"tune_cpu" mapstructure:"tune_cpu" json:"tuneCpu"`
 	TuneAioEvents            bool        `yaml:"tune_aio_events" mapstructure:"tune_aio_events" json:"tuneAioEvents"`
 	TuneClocksource          bool        `yaml:"tune_clocksource" mapstructure:"tune_clocksource" json:"tuneClocksource"`
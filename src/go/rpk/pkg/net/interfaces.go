@@ -10,12 +10,19 @@
 package net
 
 import (
+	"fmt"
 	"net"
+	"strconv"
+	"strings"
+	"time"
 
 	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/utils"
 )
 
+const routeFile = "/proc/net/route"
+
 func GetInterfacesByIps(addresses ...string) ([]string, error) {
 
 	log.Debugf("Looking for interface with '%v' addresses", addresses)
@@ -45,6 +52,90 @@ func GetInterfacesByIps(addresses ...string) ([]string, error) {
 	return utils.GetKeys(nics), nil
 }
 
+// Returns the interfaces the given IP is assigned to. Nothing is returned for
+// the addresses which don't name a single interface, i.e. unspecified
+// addresses like '0.0.0.0', and those which aren't IPs, e.g. hostnames.
+func GetInterfacesWithIp(address string) ([]string, error) {
+	ip := net.ParseIP(address)
+	if ip == nil || ip.IsUnspecified() {
+		return nil, nil
+	}
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return nil, err
+	}
+	var nics []string
+	for _, iface := range ifaces {
+		addrs, err := iface.Addrs()
+		if err != nil {
+			return nil, err
+		}
+		for _, addr := range addrs {
+			if ipNet, ok := addr.(*net.IPNet); ok && ipNet.IP.Equal(ip) {
+				nics = append(nics, iface.Name)
+				break
+			}
+		}
+	}
+	return nics, nil
+}
+
+// Returns the interfaces the kernel routes the traffic to the given hosts
+// through, e.g. 'host:33145'. The local address it picks is read from a UDP
+// socket connected to each host, which doesn't send anything.
+func GetInterfacesRoutingTo(
+	timeout time.Duration, hosts ...string,
+) ([]string, error) {
+	nics := map[string]bool{}
+	for _, host := range hosts {
+		conn, err := net.DialTimeout("udp", host, timeout)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't find the route to '%s': %w", host, err)
+		}
+		local := conn.LocalAddr().(*net.UDPAddr).IP.String()
+		conn.Close()
+		ifaces, err := GetInterfacesWithIp(local)
+		if err != nil {
+			return nil, err
+		}
+		for _, iface := range ifaces {
+			nics[iface] = true
+		}
+	}
+	return utils.GetKeys(nics), nil
+}
+
+// Returns the interfaces of the IPv4 default routes, read from
+// /proc/net/route.
+func GetDefaultRouteInterfaces(fs afero.Fs) ([]string, error) {
+	content, err := afero.ReadFile(fs, routeFile)
+	if err != nil {
+		return nil, err
+	}
+	nics := map[string]bool{}
+	// The first line holds the column names, e.g.
+	// 'Iface Destination Gateway Flags RefCnt Use Metric Mask ...'.
+	lines := strings.Split(string(content), "\n")
+	for _, line := range lines[1:] {
+		fields := strings.Fields(line)
+		if len(fields) < 8 {
+			continue
+		}
+		dest, err := strconv.ParseUint(fields[1], 16, 32)
+		if err != nil {
+			return nil, fmt.Errorf("corrupt route in '%s': %s", routeFile, line)
+		}
+		mask, err := strconv.ParseUint(fields[7], 16, 32)
+		if err != nil {
+			return nil, fmt.Errorf("corrupt route in '%s': %s", routeFile, line)
+		}
+		if dest == 0 && mask == 0 {
+			nics[fields[0]] = true
+		}
+	}
+	return utils.GetKeys(nics), nil
+}
+
 func GetFreePort() (uint, error) {
 	addr, err := net.ResolveTCPAddr("tcp", "localhost:0")
 	if err != nil {
@@ -0,0 +1,33 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package net
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetDefaultRouteInterfaces(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	routes := `Iface	Destination	Gateway 	Flags	RefCnt	Use	Metric	Mask		MTU	Window	IRTT
+eth0	00000000	010200C0	0003	0	0	0	00000000	0	0	0
+eth0	000200C0	00000000	0001	0	0	0	00FFFFFF	0	0	0
+eth1	00000000	010300C0	0003	0	0	100	00000000	0	0	0
+eth2	000300C0	00000000	0001	0	0	0	00FFFFFF	0	0	0
+`
+	require.NoError(t, afero.WriteFile(fs, "/proc/net/route", []byte(routes), 0444))
+	nics, err := GetDefaultRouteInterfaces(fs)
+	require.NoError(t, err)
+	sort.Strings(nics)
+	require.Equal(t, []string{"eth0", "eth1"}, nics)
+}
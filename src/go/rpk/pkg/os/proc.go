@@ -29,6 +29,16 @@ type Proc interface {
 	IsRunning(timeout time.Duration, processName string) bool
 }
 
+// ContextProc is implemented by the Procs which can kill the processes they
+// run once a context is done.
+type ContextProc interface {
+	Proc
+	// Runs command like RunWithSystemLdPath, killing it once ctx is done.
+	RunWithSystemLdPathContext(
+		ctx context.Context, timeout time.Duration, command string, args ...string,
+	) ([]string, error)
+}
+
 func NewProc() Proc {
 	return &proc{}
 }
@@ -39,7 +49,13 @@ func (proc *proc) RunWithSystemLdPath(
 	timeout time.Duration, command string, args ...string,
 ) ([]string, error) {
 
-	return run(timeout, command, SystemLdPathEnv(), args...)
+	return proc.RunWithSystemLdPathContext(context.Background(), timeout, command, args...)
+}
+
+func (proc *proc) RunWithSystemLdPathContext(
+	ctx context.Context, timeout time.Duration, command string, args ...string,
+) ([]string, error) {
+	return run(ctx, timeout, command, SystemLdPathEnv(), args...)
 }
 
 func (proc *proc) IsRunning(timeout time.Duration, processName string) bool {
@@ -92,10 +108,14 @@ func SystemLdPathEnv() []string {
 }
 
 func run(
-	timeout time.Duration, command string, env []string, args ...string,
+	ctx context.Context,
+	timeout time.Duration,
+	command string,
+	env []string,
+	args ...string,
 ) ([]string, error) {
 	log.Debugf("Running command '%s' with arguments '%s'", command, args)
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 	cmd := exec.CommandContext(ctx, command, args...)
 	var out bytes.Buffer
@@ -0,0 +1,166 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package filesystem
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+const MountInfoFile = "/proc/self/mountinfo"
+
+// A mount of the current mount namespace, as described in 'man 5 proc'.
+type Mount struct {
+	// The device number of the mounted filesystem, e.g. '8:1'.
+	Device string
+	// The path, within the filesystem, of the directory mounted at
+	// MountPoint. It's '/' unless it's a bind mount.
+	Root       string
+	MountPoint string
+	// The per-mount options, e.g. 'rw' or 'noatime'.
+	Options []string
+	FsType  string
+	Source  string
+	// The per-superblock (i.e. filesystem specific) options, e.g.
+	// 'nobarrier'.
+	SuperOptions []string
+}
+
+// Returns the value of the super option name, e.g. the directory of
+// 'upperdir=<dir>', and whether it's set.
+func (m *Mount) SuperOption(name string) (string, bool) {
+	for _, opt := range m.SuperOptions {
+		if opt == name {
+			return "", true
+		}
+		if strings.HasPrefix(opt, name+"=") {
+			return strings.TrimPrefix(opt, name+"="), true
+		}
+	}
+	return "", false
+}
+
+// Reads the mounts from MountInfoFile, in the order they were mounted.
+func ReadMounts(fs afero.Fs) ([]Mount, error) {
+	content, err := afero.ReadFile(fs, MountInfoFile)
+	if err != nil {
+		return nil, err
+	}
+	var mounts []Mount
+	for _, line := range strings.Split(string(content), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		mount, err := parseMount(line)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't parse '%s': %w", MountInfoFile, err)
+		}
+		mounts = append(mounts, mount)
+	}
+	return mounts, nil
+}
+
+// Parses a mountinfo line, e.g.
+// '36 35 98:0 /mnt1 /mnt2 rw,noatime master:1 - ext3 /dev/root rw,errors=continue'.
+// The optional fields, between the options and the '-' separator, are
+// skipped.
+func parseMount(line string) (Mount, error) {
+	fields := strings.Fields(line)
+	sep := -1
+	for i := 6; i < len(fields); i++ {
+		if fields[i] == "-" {
+			sep = i
+			break
+		}
+	}
+	if sep == -1 || len(fields) < sep+4 {
+		return Mount{}, fmt.Errorf("invalid mount '%s'", line)
+	}
+	return Mount{
+		Device:       fields[2],
+		Root:         unescapeMountPath(fields[3]),
+		MountPoint:   unescapeMountPath(fields[4]),
+		Options:      strings.Split(fields[5], ","),
+		FsType:       fields[sep+1],
+		Source:       unescapeMountPath(fields[sep+2]),
+		SuperOptions: strings.Split(fields[sep+3], ","),
+	}, nil
+}
+
+// The kernel escapes spaces, tabs, newlines and backslashes in the paths as
+// octal sequences, e.g. '\040' for a space.
+func unescapeMountPath(path string) string {
+	if !strings.Contains(path, `\`) {
+		return path
+	}
+	var b strings.Builder
+	for i := 0; i < len(path); i++ {
+		if path[i] == '\\' && i+4 <= len(path) {
+			if c, err := strconv.ParseUint(path[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(c))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(path[i])
+	}
+	return b.String()
+}
+
+// Returns the mount path is on, i.e. the last one mounted on path or on the
+// closest of its parents, or nil if there's none. path must be absolute, and
+// have its symlinks resolved.
+func FindMount(mounts []Mount, path string) *Mount {
+	path = filepath.Clean(path)
+	var found *Mount
+	for i := range mounts {
+		m := &mounts[i]
+		if !isWithin(path, m.MountPoint) {
+			continue
+		}
+		// Later mounts on the same directory hide the earlier ones.
+		if found == nil || len(m.MountPoint) >= len(found.MountPoint) {
+			found = m
+		}
+	}
+	return found
+}
+
+// Returns the mount bind was bind-mounted from, i.e. another mount of the
+// same filesystem, of a directory holding bind's Root, or nil if there's
+// none (e.g. in a container, whose source mount is in the host's
+// namespace).
+func FindBindSource(mounts []Mount, bind *Mount) *Mount {
+	var found *Mount
+	for i := range mounts {
+		m := &mounts[i]
+		if m == bind || m.Device != bind.Device || !isWithin(bind.Root, m.Root) {
+			continue
+		}
+		// The highest directory is preferred, i.e. the filesystem's root
+		// if it's mounted.
+		if found == nil || len(m.Root) < len(found.Root) {
+			found = m
+		}
+	}
+	return found
+}
+
+// Returns whether path is dir or is under it.
+func isWithin(path, dir string) bool {
+	if dir == "/" || path == dir {
+		return true
+	}
+	return strings.HasPrefix(path, dir+"/")
+}
@@ -0,0 +1,68 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package filesystem
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+const mountInfo = `22 1 8:1 / / rw,relatime shared:1 - ext4 /dev/sda1 rw
+23 22 8:2 / /mnt/data rw,noatime shared:2 - xfs /dev/sdb1 rw,nobarrier,logbsize=256k
+24 22 8:2 /redpanda /var/lib/redpanda\040data rw,noatime shared:2 - xfs /dev/sdb1 rw,nobarrier
+25 22 0:40 / /merged rw,relatime - overlay overlay rw,lowerdir=/l,upperdir=/mnt/data/upper,workdir=/mnt/data/work
+`
+
+func TestReadMounts(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, MountInfoFile, []byte(mountInfo), 0444))
+
+	mounts, err := ReadMounts(fs)
+	require.NoError(t, err)
+	require.Len(t, mounts, 4)
+	require.Equal(t, Mount{
+		Device:       "8:2",
+		Root:         "/redpanda",
+		MountPoint:   "/var/lib/redpanda data",
+		Options:      []string{"rw", "noatime"},
+		FsType:       "xfs",
+		Source:       "/dev/sdb1",
+		SuperOptions: []string{"rw", "nobarrier"},
+	}, mounts[2])
+
+	upper, ok := mounts[3].SuperOption("upperdir")
+	require.True(t, ok)
+	require.Equal(t, "/mnt/data/upper", upper)
+	_, ok = mounts[3].SuperOption("nobarrier")
+	require.False(t, ok)
+
+	require.Equal(t, "/", FindMount(mounts, "/var/lib").MountPoint)
+	require.Equal(t, "/mnt/data", FindMount(mounts, "/mnt/data/upper").MountPoint)
+	require.Equal(t, "/mnt/data", FindMount(mounts, "/mnt/data").MountPoint)
+	require.Equal(t, "/", FindMount(mounts, "/mnt/database").MountPoint)
+
+	source := FindBindSource(mounts, &mounts[2])
+	require.NotNil(t, source)
+	require.Equal(t, "/mnt/data", source.MountPoint)
+}
+
+func TestReadMountsInvalid(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, MountInfoFile, []byte("22 1 8:1 / / rw\n"), 0444))
+
+	_, err := ReadMounts(fs)
+	require.EqualError(
+		t,
+		err,
+		"couldn't parse '/proc/self/mountinfo': invalid mount '22 1 8:1 / / rw'",
+	)
+}
@@ -29,6 +29,7 @@ func (t *aggregatedTunable) CheckIfSupported() (supported bool, reason string) {
 
 func (t *aggregatedTunable) Tune() TuneResult {
 	var needReboot = false
+	var changed = false
 	for _, tunable := range t.tunables {
 		result := tunable.Tune()
 		if result.IsFailed() {
@@ -37,6 +38,24 @@ func (t *aggregatedTunable) Tune() TuneResult {
 		if result.IsRebootRequired() {
 			needReboot = true
 		}
+		if result.IsChanged() {
+			changed = true
+		}
+	}
+	if !changed {
+		return NewUnchangedTuneResult()
 	}
 	return NewTuneResult(needReboot)
 }
+
+func (t *aggregatedTunable) Check() ([]CheckResult, error) {
+	var results []CheckResult
+	for _, tunable := range t.tunables {
+		res, err := CheckTunable(tunable)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, res...)
+	}
+	return results, nil
+}
@@ -179,3 +179,38 @@ func Test_aggregatedTunable_CheckIfSupported(t *testing.T) {
 		})
 	}
 }
+
+func Test_aggregatedTunable_Check(t *testing.T) {
+	newTunable := func(desc string, current int) Tunable {
+		checker := NewEqualityChecker(
+			SwapChecker,
+			desc,
+			Warning,
+			1,
+			func() (interface{}, error) {
+				return current, nil
+			},
+		)
+		return NewCheckedTunable(checker, nil, nil, false)
+	}
+	tunable := NewAggregatedTunable([]Tunable{
+		newTunable("first", 1),
+		newTunable("second", 2),
+	})
+	results, err := CheckTunable(tunable)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	require.Equal(t, "first", results[0].Desc)
+	require.True(t, results[0].IsOk)
+	require.Equal(t, "second", results[1].Desc)
+	require.False(t, results[1].IsOk)
+	require.Equal(t, "2", results[1].Current)
+
+	// A single tunable which can't be checked fails the whole check.
+	tunable = NewAggregatedTunable([]Tunable{
+		newTunable("first", 1),
+		&mockedTunable{},
+	})
+	_, err = CheckTunable(tunable)
+	require.Error(t, err)
+}
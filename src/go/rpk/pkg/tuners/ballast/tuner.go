@@ -7,12 +7,11 @@
 // the Business Source License, use of this software will be governed
 // by the Apache License, Version 2.0
 
-// +build linux
-
 package ballast
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
 
 	"github.com/docker/go-units"
@@ -34,17 +33,56 @@ func NewBallastFileTuner(
 }
 
 func (t *ballastTuner) Tune() tuners.TuneResult {
+	abspath, sizeBytes, err := t.file()
+	if err != nil {
+		return tuners.NewTuneError(err)
+	}
+	cmd := commands.NewWriteSizedFileCmd(abspath, sizeBytes, true)
+	err = t.executor.Execute(cmd)
+	if err != nil {
+		return tuners.NewTuneError(err)
+	}
+	return tuners.NewTuneResult(false)
+}
+
+func (t *ballastTuner) Check() ([]tuners.CheckResult, error) {
+	abspath, sizeBytes, err := t.file()
+	if err != nil {
+		return nil, err
+	}
+	res := tuners.CheckResult{
+		CheckerId: tuners.BallastFileSizeChecker,
+		Desc:      fmt.Sprintf("Ballast file '%s'", abspath),
+		Severity:  tuners.Warning,
+		Required:  fmt.Sprintf(">= %s", units.HumanSize(float64(sizeBytes))),
+	}
+	info, err := os.Stat(abspath)
+	if os.IsNotExist(err) {
+		res.Current = "missing"
+		return []tuners.CheckResult{res}, nil
+	}
+	if err != nil {
+		res.Err = err
+		return []tuners.CheckResult{res}, nil
+	}
+	res.Current = units.HumanSize(float64(info.Size()))
+	res.IsOk = info.Size() >= sizeBytes
+	return []tuners.CheckResult{res}, nil
+}
+
+// Returns the ballast file's absolute path and its size, in bytes.
+func (t *ballastTuner) file() (string, int64, error) {
 	path := config.DefaultBallastFilePath
 	if t.conf.Rpk.BallastFilePath != "" {
 		path = t.conf.Rpk.BallastFilePath
 	}
 	abspath, err := filepath.Abs(path)
 	if err != nil {
-		return tuners.NewTuneError(fmt.Errorf(
+		return "", 0, fmt.Errorf(
 			"couldn't resolve the absolute file path for %s: %w",
 			path,
 			err,
-		))
+		)
 	}
 
 	size := config.DefaultBallastFileSize
@@ -53,18 +91,12 @@ func (t *ballastTuner) Tune() tuners.TuneResult {
 	}
 	sizeBytes, err := units.FromHumanSize(size)
 	if err != nil {
-		return tuners.NewTuneError(fmt.Errorf(
+		return "", 0, fmt.Errorf(
 			"'%s' is not a valid size unit.",
 			size,
-		))
+		)
 	}
-
-	cmd := commands.NewWriteSizedFileCmd(abspath, sizeBytes)
-	err = t.executor.Execute(cmd)
-	if err != nil {
-		return tuners.NewTuneError(err)
-	}
-	return tuners.NewTuneResult(false)
+	return abspath, sizeBytes, nil
 }
 
 func (*ballastTuner) CheckIfSupported() (supported bool, reason string) {
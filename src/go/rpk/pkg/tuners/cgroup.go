@@ -0,0 +1,496 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/os"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/disk"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors/commands"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/utils"
+)
+
+const (
+	CgroupRoot = "/sys/fs/cgroup"
+	// The name of the cgroup created for redpanda, under the root of the
+	// hierarchy (cgroup v2) or of the cpu controller's (cgroup v1).
+	RedpandaCgroup = "redpanda"
+
+	// 10 times the default weight (100), so that redpanda gets most of the
+	// CPU and IO bandwidth when the node is overcommitted with other
+	// workloads.
+	RecommendedCgroupCpuWeight = 1000
+	RecommendedCgroupIoWeight  = 1000
+	// cgroup v1's equivalent of RecommendedCgroupCpuWeight, as cpu.shares
+	// defaults to 1024.
+	RecommendedCgroupCpuShares = 10240
+)
+
+type CgroupVersion int
+
+const (
+	CgroupV1 CgroupVersion = 1
+	CgroupV2 CgroupVersion = 2
+)
+
+// Returns the version of the cgroup hierarchy mounted at CgroupRoot. The
+// unified (v2) hierarchy exposes cgroup.controllers at its root, while the
+// v1 one mounts each controller on its own directory.
+func DetectCgroupVersion(fs afero.Fs) (CgroupVersion, error) {
+	v2, err := afero.Exists(fs, filepath.Join(CgroupRoot, "cgroup.controllers"))
+	if err != nil {
+		return 0, err
+	}
+	if v2 {
+		return CgroupV2, nil
+	}
+	v1, err := afero.Exists(fs, filepath.Join(CgroupRoot, "cpu"))
+	if err != nil {
+		return 0, err
+	}
+	if v1 {
+		return CgroupV1, nil
+	}
+	return 0, fmt.Errorf("no cgroup hierarchy is mounted at '%s'", CgroupRoot)
+}
+
+// Returns the controllers enabled for the root's children with the given
+// version's hierarchy. In cgroup v1 only the cpu controller is used, whose
+// hierarchy is mounted if it was detected.
+func enabledCgroupControllers(
+	fs afero.Fs, version CgroupVersion,
+) (map[string]bool, error) {
+	if version == CgroupV1 {
+		return map[string]bool{"cpu": true}, nil
+	}
+	enabled := map[string]bool{}
+	content, err := afero.ReadFile(fs, filepath.Join(CgroupRoot, "cgroup.subtree_control"))
+	if err != nil {
+		return nil, err
+	}
+	for _, controller := range strings.Fields(string(content)) {
+		enabled[controller] = true
+	}
+	return enabled, nil
+}
+
+// A file in redpanda's cgroup, set by the tuner.
+type cgroupSetting struct {
+	controller string
+	file       string
+	value      string
+	desc       string
+	// Returns whether the file's current content holds value. If nil, it
+	// must be equal to it.
+	isSet func(content string) bool
+}
+
+type cgroupTuner struct {
+	fs           afero.Fs
+	cpuSet       string
+	directories  []string
+	devices      []string
+	blockDevices disk.BlockDevices
+	pid          int
+	pidFile      string
+	executor     executors.Executor
+}
+
+// Creates a tuner placing redpanda's resources under a dedicated cgroup
+// (see RedpandaCgroup), created if it doesn't exist, and raising its CPU
+// weight (cpu.shares in cgroup v1) and its IO weight on the devices backing
+// the given directories and devices. If cpuSet isn't 'all', the cgroup's
+// cpuset is restricted to it. The settings whose controller isn't enabled
+// for the root's children are skipped. Then the process with the given PID
+// is moved into the cgroup or, if pid is 0, the running redpanda's, read
+// from pidFile. If there's none, no process is moved. The settings and the
+// move depend on the cgroup's creation (see commands.Dependent), so the
+// executor must resolve dependencies, e.g. the one the factory's tuners use
+// (see executors.NewDependencyResolvingExecutor).
+func NewCgroupTuner(
+	fs afero.Fs,
+	cpuSet string,
+	directories []string,
+	devices []string,
+	blockDevices disk.BlockDevices,
+	pid int,
+	pidFile string,
+	executor executors.Executor,
+) Tunable {
+	return &cgroupTuner{
+		fs:           fs,
+		cpuSet:       cpuSet,
+		directories:  directories,
+		devices:      devices,
+		blockDevices: blockDevices,
+		pid:          pid,
+		pidFile:      pidFile,
+		executor:     executor,
+	}
+}
+
+func (t *cgroupTuner) CheckIfSupported() (supported bool, reason string) {
+	tunables, err := t.createTunables()
+	if err != nil {
+		return false, err.Error()
+	}
+	return NewAggregatedTunable(tunables).CheckIfSupported()
+}
+
+func (t *cgroupTuner) Tune() TuneResult {
+	tunables, err := t.createTunables()
+	if err != nil {
+		return NewTuneError(err)
+	}
+	return NewAggregatedTunable(tunables).Tune()
+}
+
+func (t *cgroupTuner) Check() ([]CheckResult, error) {
+	tunables, err := t.createTunables()
+	if err != nil {
+		return nil, err
+	}
+	return CheckTunable(NewAggregatedTunable(tunables))
+}
+
+func (t *cgroupTuner) createTunables() ([]Tunable, error) {
+	version, err := DetectCgroupVersion(t.fs)
+	if err != nil {
+		return nil, err
+	}
+	settings, err := t.settings(version)
+	if err != nil {
+		return nil, err
+	}
+	enabled, err := enabledCgroupControllers(t.fs, version)
+	if err != nil {
+		return nil, err
+	}
+	dir := redpandaCgroupDir(version)
+	tunables := []Tunable{t.newDirTunable(dir)}
+	for _, setting := range settings {
+		if !enabled[setting.controller] {
+			tunables = append(tunables, newSkippedCgroupSetting(setting))
+			continue
+		}
+		tunables = append(tunables, t.newSettingTunable(dir, setting))
+	}
+	pid, err := t.redpandaPid()
+	if err != nil {
+		return nil, err
+	}
+	if pid == 0 {
+		tunables = append(tunables, &skippedCgroupTunable{
+			desc:   "Redpanda process in cgroup",
+			state:  "redpanda not running",
+			reason: "Skipping moving redpanda into its cgroup, as it isn't running",
+		})
+		return tunables, nil
+	}
+	return append(tunables, t.newAttachTunable(dir, pid)), nil
+}
+
+// Returns the PID of the process to move into redpanda's cgroup, or 0 if
+// there's none.
+func (t *cgroupTuner) redpandaPid() (int, error) {
+	if t.pid != 0 {
+		return t.pid, nil
+	}
+	if t.pidFile == "" {
+		return 0, nil
+	}
+	pidStr, err := afero.ReadFile(t.fs, t.pidFile)
+	if errors.Is(err, afero.ErrFileNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(pidStr)))
+	if err != nil {
+		return 0, fmt.Errorf("couldn't parse the PID in '%s': %w", t.pidFile, err)
+	}
+	// The PID file is left behind if redpanda crashes.
+	running, err := os.IsRunningPID(t.fs, pid)
+	if err != nil || !running {
+		return 0, err
+	}
+	return pid, nil
+}
+
+// Returns the settings applied to redpanda's cgroup with the given
+// version's hierarchy.
+func (t *cgroupTuner) settings(version CgroupVersion) ([]cgroupSetting, error) {
+	if version == CgroupV1 {
+		return []cgroupSetting{{
+			controller: "cpu",
+			file:       "cpu.shares",
+			value:      fmt.Sprint(RecommendedCgroupCpuShares),
+			desc:       "CPU shares",
+		}}, nil
+	}
+	settings := []cgroupSetting{{
+		controller: "cpu",
+		file:       "cpu.weight",
+		value:      fmt.Sprint(RecommendedCgroupCpuWeight),
+		desc:       "CPU weight",
+	}}
+	if t.cpuSet != "" && t.cpuSet != "all" {
+		settings = append(settings, cgroupSetting{
+			controller: "cpuset",
+			file:       "cpuset.cpus",
+			value:      t.cpuSet,
+			desc:       "CPU set",
+		})
+	}
+	devices, err := t.getDevices()
+	if err != nil {
+		return nil, err
+	}
+	for _, device := range devices {
+		devNum, err := deviceNumber(t.fs, device)
+		if err != nil {
+			return nil, err
+		}
+		value := fmt.Sprintf("%s %d", devNum, RecommendedCgroupIoWeight)
+		settings = append(settings, cgroupSetting{
+			controller: "io",
+			file:       "io.weight",
+			value:      value,
+			desc:       fmt.Sprintf("IO weight on '%s'", device),
+			isSet: func(content string) bool {
+				return hasLine(content, value)
+			},
+		})
+	}
+	return settings, nil
+}
+
+// Returns the devices backing the tuner's directories, along with its
+// devices.
+func (t *cgroupTuner) getDevices() ([]string, error) {
+	directoryDevices, err := t.blockDevices.GetDirectoriesDevices(t.directories)
+	if err != nil {
+		return nil, err
+	}
+	devicesSet := map[string]bool{}
+	for _, devices := range directoryDevices {
+		for _, device := range devices {
+			devicesSet[device] = true
+		}
+	}
+	for _, device := range t.devices {
+		devicesSet[device] = true
+	}
+	devices := utils.GetKeys(devicesSet)
+	sort.Strings(devices)
+	return devices, nil
+}
+
+// Returns the ID of the command creating the cgroup, which its settings and
+// the process moved into it depend on.
+func (t *cgroupTuner) mkdirID(dir string) commands.CommandID {
+	return commands.IDOf(commands.NewMkdirAllCmd(t.fs, dir, 0755))
+}
+
+func (t *cgroupTuner) newDirTunable(dir string) Tunable {
+	return NewCheckedTunable(
+		NewFileExistanceChecker(
+			t.fs,
+			CgroupChecker,
+			"Redpanda cgroup",
+			Warning,
+			dir,
+		),
+		func() TuneResult {
+			log.Infof("Creating the '%s' cgroup", dir)
+			err := t.executor.Execute(
+				commands.NewMkdirAllCmd(t.fs, dir, 0755),
+			)
+			if err != nil {
+				return NewTuneError(err)
+			}
+			return NewTuneResult(false)
+		},
+		func() (bool, string) {
+			return true, ""
+		},
+		t.executor.IsLazy(),
+	)
+}
+
+func (t *cgroupTuner) newSettingTunable(dir string, setting cgroupSetting) Tunable {
+	path := filepath.Join(dir, setting.file)
+	return NewCheckedTunable(
+		newCgroupSettingChecker(t.fs, path, setting),
+		func() TuneResult {
+			log.Infof("Setting the redpanda cgroup's %s to '%s'", setting.desc, setting.value)
+			err := t.executor.Execute(commands.NewDependentCmd(
+				commands.NewWriteFileCmd(t.fs, path, setting.value),
+				t.mkdirID(dir),
+			))
+			if err != nil {
+				return NewTuneError(err)
+			}
+			return NewTuneResult(false)
+		},
+		func() (bool, string) {
+			return true, ""
+		},
+		t.executor.IsLazy(),
+	)
+}
+
+func (t *cgroupTuner) newAttachTunable(dir string, pid int) Tunable {
+	procsPath := filepath.Join(dir, "cgroup.procs")
+	return NewCheckedTunable(
+		NewEqualityChecker(
+			CgroupChecker,
+			"Redpanda process in cgroup",
+			Warning,
+			true,
+			func() (interface{}, error) {
+				content, err := afero.ReadFile(t.fs, procsPath)
+				// The file is missing until the cgroup is created.
+				if errors.Is(err, afero.ErrFileNotFound) {
+					return false, nil
+				}
+				if err != nil {
+					return nil, err
+				}
+				return commands.IsCgroupMember(string(content), pid), nil
+			},
+		),
+		func() TuneResult {
+			log.Infof("Moving redpanda (PID %d) into the '%s' cgroup", pid, dir)
+			err := t.executor.Execute(commands.NewDependentCmd(
+				commands.NewAttachCgroupCmd(t.fs, dir, pid),
+				t.mkdirID(dir),
+			))
+			if err != nil {
+				return NewTuneError(err)
+			}
+			return NewTuneResult(false)
+		},
+		func() (bool, string) {
+			return true, ""
+		},
+		t.executor.IsLazy(),
+	)
+}
+
+func newCgroupSettingChecker(
+	fs afero.Fs, path string, setting cgroupSetting,
+) Checker {
+	isSet := setting.isSet
+	if isSet == nil {
+		isSet = func(content string) bool {
+			return content == setting.value
+		}
+	}
+	return NewEqualityChecker(
+		CgroupChecker,
+		fmt.Sprintf("Redpanda cgroup %s", setting.desc),
+		Warning,
+		setting.value,
+		func() (interface{}, error) {
+			content, err := afero.ReadFile(fs, path)
+			// The file is missing until the cgroup is created.
+			if errors.Is(err, afero.ErrFileNotFound) {
+				return "", nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			current := strings.TrimSpace(string(content))
+			if isSet(current) {
+				return setting.value, nil
+			}
+			return current, nil
+		},
+	)
+}
+
+// Reports a tunable which can't be applied, e.g. a setting whose
+// controller isn't enabled.
+type skippedCgroupTunable struct {
+	desc string
+	// The state reported by Check, as both the current and required one.
+	state string
+	// The message logged by Tune.
+	reason string
+}
+
+func newSkippedCgroupSetting(setting cgroupSetting) *skippedCgroupTunable {
+	return &skippedCgroupTunable{
+		desc:  fmt.Sprintf("Redpanda cgroup %s", setting.desc),
+		state: fmt.Sprintf("'%s' controller not enabled", setting.controller),
+		reason: fmt.Sprintf(
+			"Skipping the redpanda cgroup's %s, as the '%s' controller isn't enabled in '%s'",
+			setting.desc,
+			setting.controller,
+			CgroupRoot,
+		),
+	}
+}
+
+func (s *skippedCgroupTunable) CheckIfSupported() (supported bool, reason string) {
+	return true, ""
+}
+
+func (s *skippedCgroupTunable) Tune() TuneResult {
+	log.Info(s.reason)
+	return NewUnchangedTuneResult()
+}
+
+func (s *skippedCgroupTunable) Check() ([]CheckResult, error) {
+	return []CheckResult{{
+		CheckerId: CgroupChecker,
+		IsOk:      true,
+		Desc:      s.desc,
+		Severity:  Warning,
+		Current:   s.state,
+		Required:  s.state,
+	}}, nil
+}
+
+func redpandaCgroupDir(version CgroupVersion) string {
+	if version == CgroupV1 {
+		return filepath.Join(CgroupRoot, "cpu", RedpandaCgroup)
+	}
+	return filepath.Join(CgroupRoot, RedpandaCgroup)
+}
+
+// Returns the 'major:minor' number of device, e.g. 'nvme0n1'.
+func deviceNumber(fs afero.Fs, device string) (string, error) {
+	content, err := afero.ReadFile(fs, filepath.Join("/sys/block", device, "dev"))
+	if err != nil {
+		return "", fmt.Errorf("couldn't read the device number of '%s': %w", device, err)
+	}
+	return strings.TrimSpace(string(content)), nil
+}
+
+func hasLine(content, line string) bool {
+	for _, l := range strings.Split(content, "\n") {
+		if strings.TrimSpace(l) == line {
+			return true
+		}
+	}
+	return false
+}
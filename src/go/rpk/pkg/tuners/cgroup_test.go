@@ -0,0 +1,199 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors"
+)
+
+func dataDirBlockDevices(devices ...string) *blockDevicesMock {
+	return &blockDevicesMock{
+		getDirectoriesDevices: func(dirs []string) (map[string][]string, error) {
+			return map[string][]string{"/var/lib/redpanda": devices}, nil
+		},
+	}
+}
+
+func TestDetectCgroupVersion(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	_, err := DetectCgroupVersion(fs)
+	require.Error(t, err)
+
+	require.NoError(t, fs.MkdirAll("/sys/fs/cgroup/cpu", 0755))
+	version, err := DetectCgroupVersion(fs)
+	require.NoError(t, err)
+	require.Equal(t, CgroupV1, version)
+
+	require.NoError(t, afero.WriteFile(fs, "/sys/fs/cgroup/cgroup.controllers", []byte("cpu io"), 0644))
+	version, err = DetectCgroupVersion(fs)
+	require.NoError(t, err)
+	require.Equal(t, CgroupV2, version)
+}
+
+func TestCgroupTunerV2(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	files := map[string]string{
+		"/sys/fs/cgroup/cgroup.controllers":     "cpuset cpu io memory pids",
+		"/sys/fs/cgroup/cgroup.subtree_control": "cpu io memory",
+		"/sys/block/nvme0n1/dev":                "259:0\n",
+		"/sys/fs/cgroup/redpanda/cpu.weight":    "100\n",
+		"/sys/fs/cgroup/redpanda/io.weight":     "default 100\n",
+		"/sys/fs/cgroup/redpanda/cgroup.procs":  "",
+	}
+	for path, content := range files {
+		require.NoError(t, afero.WriteFile(fs, path, []byte(content), 0644))
+	}
+	executor := executors.NewDependencyResolvingExecutor(
+		executors.NewDirectExecutor(),
+	)
+	tuner := NewCgroupTuner(
+		fs,
+		"0-3",
+		[]string{"/var/lib/redpanda"},
+		nil,
+		dataDirBlockDevices("nvme0n1"),
+		1234,
+		"",
+		executor,
+	)
+	supported, reason := tuner.CheckIfSupported()
+	require.True(t, supported, reason)
+	res := tuner.Tune()
+	require.NoError(t, res.Error())
+	require.True(t, res.IsChanged())
+	// The cgroup already exists, so the commands depending on its
+	// creation wait for the executor to be flushed.
+	cpuWeight, err := afero.ReadFile(fs, "/sys/fs/cgroup/redpanda/cpu.weight")
+	require.NoError(t, err)
+	require.Equal(t, "100\n", string(cpuWeight))
+	require.NoError(t, executor.Flush())
+
+	cpuWeight, err = afero.ReadFile(fs, "/sys/fs/cgroup/redpanda/cpu.weight")
+	require.NoError(t, err)
+	require.Equal(t, "1000", string(cpuWeight))
+	ioWeight, err := afero.ReadFile(fs, "/sys/fs/cgroup/redpanda/io.weight")
+	require.NoError(t, err)
+	require.Equal(t, "259:0 1000", string(ioWeight))
+	// The cpuset controller isn't enabled for the root's children.
+	exists, err := afero.Exists(fs, "/sys/fs/cgroup/redpanda/cpuset.cpus")
+	require.NoError(t, err)
+	require.False(t, exists)
+	procs, err := afero.ReadFile(fs, "/sys/fs/cgroup/redpanda/cgroup.procs")
+	require.NoError(t, err)
+	require.Equal(t, "1234", string(procs))
+}
+
+func TestCgroupTunerPidFile(t *testing.T) {
+	const pidFile = "/var/lib/redpanda/data/pid.lock"
+	tests := []struct {
+		name     string
+		stat     string
+		expected string
+	}{
+		{
+			name:     "it should move the running redpanda into the cgroup",
+			stat:     "4321 (redpanda) S 1",
+			expected: "4321",
+		},
+		{
+			name: "it shouldn't move a dead redpanda into the cgroup",
+			stat: "4321 (redpanda) Z 1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			fs := afero.NewMemMapFs()
+			files := map[string]string{
+				"/sys/fs/cgroup/cpu/redpanda/cgroup.procs": "",
+				pidFile:           "4321\n",
+				"/proc/4321/stat": tt.stat,
+			}
+			for path, content := range files {
+				require.NoError(st, afero.WriteFile(fs, path, []byte(content), 0644))
+			}
+			executor := executors.NewDependencyResolvingExecutor(
+				executors.NewDirectExecutor(),
+			)
+			tuner := NewCgroupTuner(
+				fs,
+				"all",
+				nil,
+				nil,
+				dataDirBlockDevices(),
+				0,
+				pidFile,
+				executor,
+			)
+			res := tuner.Tune()
+			require.NoError(st, res.Error())
+			require.NoError(st, executor.Flush())
+			procs, err := afero.ReadFile(fs, "/sys/fs/cgroup/cpu/redpanda/cgroup.procs")
+			require.NoError(st, err)
+			require.Equal(st, tt.expected, string(procs))
+		})
+	}
+}
+
+func TestCgroupTunerV1(t *testing.T) {
+	const scriptPath = "/tune.sh"
+	fs := afero.NewMemMapFs()
+	require.NoError(t, fs.MkdirAll("/sys/fs/cgroup/cpu", 0755))
+	tuner := NewCgroupTuner(
+		fs,
+		"all",
+		[]string{"/var/lib/redpanda"},
+		nil,
+		dataDirBlockDevices("sda"),
+		0,
+		"/var/lib/redpanda/data/pid.lock",
+		executors.NewDependencyResolvingExecutor(
+			executors.NewScriptRenderingExecutor(fs, scriptPath),
+		),
+	)
+	res := tuner.Tune()
+	require.NoError(t, res.Error())
+	require.True(t, res.IsChanged())
+
+	// The cgroup is created first, so the commands depending on it are
+	// rendered right after.
+	script, err := afero.ReadFile(fs, scriptPath)
+	require.NoError(t, err)
+	require.Contains(t, string(script), "mkdir -p -m 0755 /sys/fs/cgroup/cpu/redpanda\n"+
+		"echo '10240' > /sys/fs/cgroup/cpu/redpanda/cpu.shares\n")
+}
+
+func TestCgroupSettingChecker(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	path := "/sys/fs/cgroup/redpanda/io.weight"
+	setting := cgroupSetting{
+		controller: "io",
+		file:       "io.weight",
+		value:      "8:0 1000",
+		desc:       "IO weight on 'sda'",
+		isSet: func(content string) bool {
+			return hasLine(content, "8:0 1000")
+		},
+	}
+	checker := newCgroupSettingChecker(fs, path, setting)
+
+	res := checker.Check()
+	require.NoError(t, res.Err)
+	require.False(t, res.IsOk)
+
+	require.NoError(t, afero.WriteFile(fs, path, []byte("default 100\n8:0 1000\n"), 0644))
+	res = checker.Check()
+	require.NoError(t, res.Err)
+	require.True(t, res.IsOk)
+	require.Equal(t, "8:0 1000", res.Current)
+}
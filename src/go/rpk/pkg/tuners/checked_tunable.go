@@ -41,6 +41,10 @@ func (t *checkedTunable) CheckIfSupported() (supported bool, reason string) {
 	return t.supportedAction()
 }
 
+func (t *checkedTunable) Check() ([]CheckResult, error) {
+	return []CheckResult{*t.checker.Check()}, nil
+}
+
 func (t *checkedTunable) Tune() TuneResult {
 	log.Debugf("Checking '%s'", t.checker.GetDesc())
 	result := t.checker.Check()
@@ -50,7 +54,7 @@ func (t *checkedTunable) Tune() TuneResult {
 
 	if result.IsOk {
 		log.Debugf("Check '%s' passed, skipping tuning", t.checker.GetDesc())
-		return NewTuneResult(false)
+		return NewUnchangedTuneResult()
 	}
 
 	tuneResult := t.tuneAction()
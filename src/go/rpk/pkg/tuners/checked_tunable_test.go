@@ -75,7 +75,7 @@ func TestTune(t *testing.T) {
 				return NewTuneResult(false)
 			},
 			severity:         Fatal,
-			want:             NewTuneResult(false),
+			want:             NewUnchangedTuneResult(),
 			expectTuneCalled: false,
 		},
 		{
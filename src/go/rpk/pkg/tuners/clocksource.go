@@ -10,39 +10,101 @@
 package tuners
 
 import (
-	"fmt"
 	"strings"
 
+	log "github.com/sirupsen/logrus"
 	"github.com/spf13/afero"
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors"
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors/commands"
 )
 
-const prefferedClkSource = "tsc"
+const (
+	prefferedClkSource       = "tsc"
+	currentClockSourceFile   = "/sys/devices/system/clocksource/clocksource0/current_clocksource"
+	availableClockSourceFile = "/sys/devices/system/clocksource/clocksource0/available_clocksource"
+)
+
+type clockSourceChecker struct {
+	fs afero.Fs
+}
 
+// Creates a checker comparing the current clock source with the preferred
+// one. The available clock sources are logged, as the preferred one may not
+// be among them.
 func NewClockSourceChecker(fs afero.Fs) Checker {
-	return NewEqualityChecker(
-		ClockSource,
-		"Clock Source",
-		Warning,
-		prefferedClkSource,
-		func() (interface{}, error) {
-			content, err := afero.ReadFile(fs,
-				"/sys/devices/system/clocksource/clocksource0/current_clocksource")
-			if err != nil {
-				return "", err
-			}
-			return strings.TrimSpace(string(content)), nil
-		},
+	return &clockSourceChecker{fs: fs}
+}
+
+func (c *clockSourceChecker) Id() CheckerID {
+	return ClockSource
+}
+
+func (c *clockSourceChecker) GetDesc() string {
+	return "Clock Source"
+}
+
+func (c *clockSourceChecker) GetSeverity() Severity {
+	return Warning
+}
+
+func (c *clockSourceChecker) GetRequiredAsString() string {
+	return prefferedClkSource
+}
+
+func (c *clockSourceChecker) Check() *CheckResult {
+	res := &CheckResult{
+		CheckerId: c.Id(),
+		Desc:      c.GetDesc(),
+		Severity:  c.GetSeverity(),
+		Required:  c.GetRequiredAsString(),
+	}
+	current, err := readCurrentClockSource(c.fs)
+	if err != nil {
+		res.Err = err
+		return res
+	}
+	available, err := readAvailableClockSources(c.fs)
+	if err != nil {
+		res.Err = err
+		return res
+	}
+	log.Debugf(
+		"Current clock source: '%s' (available: %s)",
+		current,
+		strings.Join(available, ", "),
 	)
+	res.Current = current
+	res.IsOk = current == prefferedClkSource
+	return res
 }
 
+// Creates a tuner setting the clock source to the preferred one. If it isn't
+// available, the current clock source is left as is, since writing one the
+// kernel doesn't offer would fail.
 func NewClockSourceTuner(fs afero.Fs, executor executors.Executor) Tunable {
 	return NewCheckedTunable(
 		NewClockSourceChecker(fs),
 		func() TuneResult {
-			err := executor.Execute(commands.NewWriteFileCmd(fs,
-				"/sys/devices/system/clocksource/clocksource0/current_clocksource",
+			available, err := readAvailableClockSources(fs)
+			if err != nil {
+				return NewTuneError(err)
+			}
+			if !isClockSourceAvailable(available, prefferedClkSource) {
+				current, err := readCurrentClockSource(fs)
+				if err != nil {
+					return NewTuneError(err)
+				}
+				log.Infof(
+					"Preferred clock source '%s' isn't available (available: %s),"+
+						" leaving '%s'",
+					prefferedClkSource,
+					strings.Join(available, ", "),
+					current,
+				)
+				return NewUnchangedTuneResult()
+			}
+			err = executor.Execute(commands.NewWriteFileCmd(fs,
+				currentClockSourceFile,
 				prefferedClkSource))
 			if err != nil {
 				return NewTuneError(err)
@@ -50,21 +112,37 @@ func NewClockSourceTuner(fs afero.Fs, executor executors.Executor) Tunable {
 			return NewTuneResult(false)
 		},
 		func() (bool, string) {
-			content, err := afero.ReadFile(fs,
-				"/sys/devices/system/clocksource/clocksource0/available_clocksource")
+			_, err := readAvailableClockSources(fs)
 			if err != nil {
 				return false, err.Error()
 			}
-			availableSrcs := strings.Fields(string(content))
-
-			for _, src := range availableSrcs {
-				if src == prefferedClkSource {
-					return true, ""
-				}
-			}
-			return false, fmt.Sprintf(
-				"Preffered clocksource '%s' not avaialable", prefferedClkSource)
+			return true, ""
 		},
 		executor.IsLazy(),
 	)
 }
+
+func readCurrentClockSource(fs afero.Fs) (string, error) {
+	content, err := afero.ReadFile(fs, currentClockSourceFile)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(content)), nil
+}
+
+func readAvailableClockSources(fs afero.Fs) ([]string, error) {
+	content, err := afero.ReadFile(fs, availableClockSourceFile)
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(string(content)), nil
+}
+
+func isClockSourceAvailable(available []string, src string) bool {
+	for _, a := range available {
+		if a == src {
+			return true
+		}
+	}
+	return false
+}
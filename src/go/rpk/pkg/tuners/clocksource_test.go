@@ -0,0 +1,108 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners_test
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors"
+)
+
+const (
+	currentClockSourceFile   = "/sys/devices/system/clocksource/clocksource0/current_clocksource"
+	availableClockSourceFile = "/sys/devices/system/clocksource/clocksource0/available_clocksource"
+)
+
+func writeClockSources(t *testing.T, fs afero.Fs, current, available string) {
+	require.NoError(t, afero.WriteFile(fs, currentClockSourceFile, []byte(current+"\n"), 0644))
+	require.NoError(t, afero.WriteFile(fs, availableClockSourceFile, []byte(available+"\n"), 0644))
+}
+
+func TestClockSourceChecker(t *testing.T) {
+	tests := []struct {
+		name            string
+		current         string
+		available       string
+		expectOk        bool
+		expectedCurrent string
+	}{
+		{
+			name:            "it should pass if the current clock source is tsc",
+			current:         "tsc",
+			available:       "tsc hpet acpi_pm",
+			expectOk:        true,
+			expectedCurrent: "tsc",
+		},
+		{
+			name:            "it should fail if the current clock source isn't tsc",
+			current:         "kvm-clock",
+			available:       "kvm-clock tsc acpi_pm",
+			expectedCurrent: "kvm-clock",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			writeClockSources(t, fs, tt.current, tt.available)
+			res := tuners.NewClockSourceChecker(fs).Check()
+			require.NoError(t, res.Err)
+			require.Equal(t, tt.expectOk, res.IsOk)
+			require.Equal(t, "tsc", res.Required)
+			require.Equal(t, tt.expectedCurrent, res.Current)
+		})
+	}
+}
+
+func TestClockSourceTuner(t *testing.T) {
+	tests := []struct {
+		name      string
+		current   string
+		available string
+		expected  string
+	}{
+		{
+			name:      "it should set tsc if it's available",
+			current:   "kvm-clock",
+			available: "kvm-clock tsc acpi_pm",
+			expected:  "tsc",
+		},
+		{
+			name:      "it should leave the current clock source if tsc isn't available",
+			current:   "xen",
+			available: "xen",
+			expected:  "xen\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			writeClockSources(t, fs, tt.current, tt.available)
+			tuner := tuners.NewClockSourceTuner(fs, executors.NewDirectExecutor())
+			supported, reason := tuner.CheckIfSupported()
+			require.True(t, supported, reason)
+			res := tuner.Tune()
+			require.NoError(t, res.Error())
+			require.False(t, res.IsRebootRequired())
+			content, err := afero.ReadFile(fs, currentClockSourceFile)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, string(content))
+		})
+	}
+}
+
+func TestClockSourceTunerUnsupported(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	tuner := tuners.NewClockSourceTuner(fs, executors.NewDirectExecutor())
+	supported, _ := tuner.CheckIfSupported()
+	require.False(t, supported)
+}
@@ -11,6 +11,9 @@ package coredump
 
 import (
 	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
 	"text/template"
 
 	"github.com/spf13/afero"
@@ -56,6 +59,10 @@ func (t *tuner) Tune() tuners.TuneResult {
 	if err != nil {
 		return tuners.NewTuneError(err)
 	}
+	err = t.executor.Execute(commands.NewMkdirAllCmd(t.fs, filepath.Dir(scriptFilePath), 0755))
+	if err != nil {
+		return tuners.NewTuneError(err)
+	}
 	err = t.executor.Execute(commands.NewWriteFileModeCmd(t.fs, scriptFilePath, script, 0777))
 	if err != nil {
 		return tuners.NewTuneError(err)
@@ -67,6 +74,58 @@ func (t *tuner) Tune() tuners.TuneResult {
 	return tuners.NewTuneResult(false)
 }
 
+func (t *tuner) Check() ([]tuners.CheckResult, error) {
+	script, err := renderTemplate(coredumpScriptTmpl, t.conf.Rpk)
+	if err != nil {
+		return nil, err
+	}
+	scriptChecker := tuners.NewEqualityChecker(
+		tuners.CoredumpChecker,
+		"Coredump script",
+		tuners.Warning,
+		"installed",
+		func() (interface{}, error) {
+			current, err := t.read(scriptFilePath)
+			if err != nil {
+				return nil, err
+			}
+			switch current {
+			case "":
+				return "missing", nil
+			case strings.TrimSpace(script):
+				return "installed", nil
+			}
+			return "outdated", nil
+		},
+	)
+	patternChecker := tuners.NewEqualityChecker(
+		tuners.CoredumpChecker,
+		"Kernel core pattern",
+		tuners.Warning,
+		coredumpPattern,
+		func() (interface{}, error) {
+			return t.read(corePatternFilePath)
+		},
+	)
+	return []tuners.CheckResult{
+		*scriptChecker.Check(),
+		*patternChecker.Check(),
+	}, nil
+}
+
+// Returns the trimmed content of the file at path, which is empty if it
+// doesn't exist.
+func (t *tuner) read(path string) (string, error) {
+	content, err := afero.ReadFile(t.fs, path)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(content)), nil
+}
+
 func (*tuner) CheckIfSupported() (supported bool, reason string) {
 	return true, ""
 }
@@ -12,6 +12,7 @@ package cpu
 import (
 	"fmt"
 	"strconv"
+	"strings"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/afero"
@@ -52,17 +53,7 @@ func NewCpuTuner(
 func (tuner *tuner) Tune() tuners.TuneResult {
 	grubUpdated := false
 	log.Debug("Running CPU tuner...")
-	allCpusMask, err := tuner.cpuMasks.GetAllCpusMask()
-	if err != nil {
-		return tuners.NewTuneError(err)
-	}
-	tuner.cores, err = tuner.cpuMasks.GetNumberOfCores(allCpusMask)
-	if err != nil {
-		return tuners.NewTuneError(err)
-	}
-	tuner.pus, err = tuner.cpuMasks.GetNumberOfPUs(allCpusMask)
-	log.Debugf("Running on system with '%d' cores and '%d' PUs",
-		tuner.cores, tuner.pus)
+	err := tuner.readTopology()
 	if err != nil {
 		return tuners.NewTuneError(err)
 	}
@@ -107,6 +98,95 @@ func (tuner *tuner) Tune() tuners.TuneResult {
 	return tuners.NewTuneResult(false)
 }
 
+func (tuner *tuner) Check() ([]tuners.CheckResult, error) {
+	err := tuner.readTopology()
+	if err != nil {
+		return nil, err
+	}
+	var checkers []tuners.Checker
+	if tuner.rebootAllowed {
+		checkers = append(
+			checkers,
+			tuners.NewEqualityChecker(
+				tuners.CStatesChecker,
+				"Max CPU C-State",
+				tuners.Warning,
+				uint(0),
+				func() (interface{}, error) {
+					return tuner.getMaxCState()
+				},
+			),
+			tuners.NewEqualityChecker(
+				tuners.PStatesChecker,
+				"Intel P-States enabled",
+				tuners.Warning,
+				false,
+				func() (interface{}, error) {
+					return tuner.checkIfPStateIsEnabled()
+				},
+			),
+		)
+	}
+	if exists, _ := afero.Exists(tuner.fs, boostFile); exists {
+		checkers = append(checkers, tuner.newFileChecker(
+			tuners.CpuFrequencyBoostChecker,
+			"CPU frequency boost",
+			boostFile,
+			"0",
+		))
+	}
+	for i, policyPath := range tuner.governorPolicies() {
+		checkers = append(checkers, tuner.newFileChecker(
+			tuners.CpuGovernorChecker,
+			fmt.Sprintf("CPU governor (policy %d)", i),
+			policyPath,
+			"performance",
+		))
+	}
+	var results []tuners.CheckResult
+	for _, checker := range checkers {
+		results = append(results, *checker.Check())
+	}
+	return results, nil
+}
+
+func (tuner *tuner) newFileChecker(
+	id tuners.CheckerID, desc, path, required string,
+) tuners.Checker {
+	return tuners.NewEqualityChecker(
+		id,
+		desc,
+		tuners.Warning,
+		required,
+		func() (interface{}, error) {
+			lines, err := utils.ReadFileLines(tuner.fs, path)
+			if err != nil {
+				return nil, err
+			}
+			if len(lines) == 0 {
+				return "", nil
+			}
+			return strings.TrimSpace(lines[0]), nil
+		},
+	)
+}
+
+// Reads the number of cores and PUs in the system.
+func (tuner *tuner) readTopology() error {
+	allCpusMask, err := tuner.cpuMasks.GetAllCpusMask()
+	if err != nil {
+		return err
+	}
+	tuner.cores, err = tuner.cpuMasks.GetNumberOfCores(allCpusMask)
+	if err != nil {
+		return err
+	}
+	tuner.pus, err = tuner.cpuMasks.GetNumberOfPUs(allCpusMask)
+	log.Debugf("Running on system with '%d' cores and '%d' PUs",
+		tuner.cores, tuner.pus)
+	return err
+}
+
 func (tuner *tuner) CheckIfSupported() (supported bool, reason string) {
 	hwLocSupported := tuner.cpuMasks.IsSupported()
 	if !hwLocSupported {
@@ -166,30 +246,40 @@ func (tuner *tuner) disablePStates() error {
 	return tuner.grub.AddCommandLineOptions([]string{"intel_pstate=disable"})
 }
 
+const boostFile = "/sys/devices/system/cpu/cpufreq/boost"
+
 func (tuner *tuner) setupCPUGovernors() error {
 	log.Debugf("Setting up ACPI based CPU governors")
-	if exists, _ := afero.Exists(tuner.fs, "/sys/devices/system/cpu/cpufreq/boost"); exists {
+	if exists, _ := afero.Exists(tuner.fs, boostFile); exists {
 		err := tuner.executor.Execute(
-			commands.NewWriteFileCmd(tuner.fs,
-				"/sys/devices/system/cpu/cpufreq/boost", "0"))
+			commands.NewWriteFileCmd(tuner.fs, boostFile, "0"))
 		if err != nil {
 			return err
 		}
 	} else {
 		log.Debugf("CPU frequency boost is not available in this system")
 	}
+	for _, policyPath := range tuner.governorPolicies() {
+		err := tuner.executor.Execute(
+			commands.NewWriteFileCmd(tuner.fs, policyPath, "performance"))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Returns the paths of the existing cores' governor policy files.
+func (tuner *tuner) governorPolicies() []string {
+	var paths []string
 	for i := uint(0); i < tuner.cores; i = i + 1 {
 		policyPath := fmt.Sprintf(
 			"/sys/devices/system/cpu/cpufreq/policy%d/scaling_governor", i)
 		if exists, _ := afero.Exists(tuner.fs, policyPath); exists {
-			err := tuner.executor.Execute(
-				commands.NewWriteFileCmd(tuner.fs, policyPath, "performance"))
-			if err != nil {
-				return err
-			}
+			paths = append(paths, policyPath)
 		} else {
-			log.Debugf("Unable to set CPU governor policy for CPU %d", i)
+			log.Debugf("There is no CPU governor policy for CPU %d", i)
 		}
 	}
-	return nil
+	return paths
 }
@@ -0,0 +1,329 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors/commands"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/topology"
+)
+
+const (
+	cpusDir = "/sys/devices/system/cpu"
+	// The exit latency, in microseconds, above which the CPU idle states
+	// are disabled, unless another one is given. It keeps the polling state
+	// and C1, whose latencies are of a couple of microseconds at most.
+	RecommendedMaxCStateLatency = 10
+)
+
+// A CPU idle state (see the kernel's Documentation/admin-guide/pm/cpuidle.rst)
+// of a group of CPUs. The states are numbered by the driver, so the same
+// number may not be the same state on every machine, or even on every CPU.
+type idleState struct {
+	// The state's directory name, e.g. 'state3'.
+	dir  string
+	name string
+	// The exit latency, in microseconds.
+	latency int
+	// The CPUs having the state, and the ones among them on which it's
+	// disabled.
+	cpus     []int
+	disabled []int
+}
+
+func (s *idleState) desc() string {
+	return fmt.Sprintf("CPU idle state %s (%s, %dus exit latency)", s.name, s.dir, s.latency)
+}
+
+// Returns the CPUs on which the state isn't disabled.
+func (s *idleState) enabled() []int {
+	disabled := map[int]bool{}
+	for _, cpu := range s.disabled {
+		disabled[cpu] = true
+	}
+	var enabled []int
+	for _, cpu := range s.cpus {
+		if !disabled[cpu] {
+			enabled = append(enabled, cpu)
+		}
+	}
+	return enabled
+}
+
+// Returns a summary of the CPUs the state is disabled on, e.g.
+// 'disabled on 0-3, enabled on 4-7'.
+func (s *idleState) status() string {
+	enabled := s.enabled()
+	switch {
+	case len(s.disabled) == 0:
+		return "enabled on all CPUs"
+	case len(enabled) == 0:
+		return "disabled on all CPUs"
+	}
+	return fmt.Sprintf(
+		"disabled on %s, enabled on %s",
+		formatCpuList(s.disabled),
+		formatCpuList(enabled),
+	)
+}
+
+func idleStateFile(cpu int, state, file string) string {
+	return filepath.Join(cpusDir, fmt.Sprintf("cpu%d", cpu), "cpuidle", state, file)
+}
+
+type cstateTuner struct {
+	fs         afero.Fs
+	maxLatency int
+	executor   executors.Executor
+}
+
+// Creates a tuner disabling, on every online CPU, the idle states whose exit
+// latency is over maxLatency microseconds (e.g.
+// RecommendedMaxCStateLatency), as waking up from deep C-states adds jitter
+// to the latency. The states are told apart by their latency, read from
+// sysfs, rather than by their number. The changes are reversible (see
+// commands.Reversible), as they raise the power consumption.
+func NewCStateTuner(
+	fs afero.Fs, maxLatency int, executor executors.Executor,
+) Tunable {
+	return &cstateTuner{fs: fs, maxLatency: maxLatency, executor: executor}
+}
+
+func (t *cstateTuner) CheckIfSupported() (supported bool, reason string) {
+	states, err := t.readStates()
+	if err != nil {
+		return false, err.Error()
+	}
+	if len(states) == 0 {
+		return false, "The kernel doesn't expose the CPU idle states (cpuidle) in sysfs"
+	}
+	return true, ""
+}
+
+func (t *cstateTuner) Tune() TuneResult {
+	tunables, err := t.createTunables()
+	if err != nil {
+		return NewTuneError(err)
+	}
+	return NewAggregatedTunable(tunables).Tune()
+}
+
+func (t *cstateTuner) Check() ([]CheckResult, error) {
+	tunables, err := t.createTunables()
+	if err != nil {
+		return nil, err
+	}
+	return CheckTunable(NewAggregatedTunable(tunables))
+}
+
+func (t *cstateTuner) createTunables() ([]Tunable, error) {
+	states, err := t.readStates()
+	if err != nil {
+		return nil, err
+	}
+	tunables := make([]Tunable, 0, len(states))
+	for _, state := range states {
+		if state.latency <= t.maxLatency {
+			tunables = append(tunables, &keptIdleState{state})
+			continue
+		}
+		tunables = append(tunables, t.newStateTunable(state))
+	}
+	return tunables, nil
+}
+
+func (t *cstateTuner) newStateTunable(state *idleState) Tunable {
+	return NewCheckedTunable(
+		newIdleStateChecker(t.fs, state),
+		func() TuneResult {
+			enabled := state.enabled()
+			log.Infof("Disabling the %s on CPUs %s", state.desc(), formatCpuList(enabled))
+			for _, cmd := range t.disableCmds(state, enabled) {
+				err := t.executor.Execute(cmd)
+				if err != nil {
+					return NewTuneError(err)
+				}
+			}
+			return NewTuneResult(false)
+		},
+		func() (bool, string) {
+			return true, ""
+		},
+		t.executor.IsLazy(),
+	)
+}
+
+// Returns the commands disabling state on the given CPUs. If they're all the
+// CPUs with the state, a single command writes to all of them.
+func (t *cstateTuner) disableCmds(state *idleState, cpus []int) []commands.Command {
+	glob := filepath.Join(cpusDir, "cpu*", "cpuidle", state.dir, "disable")
+	matches, err := afero.Glob(t.fs, glob)
+	if err == nil && len(matches) == len(cpus) && len(cpus) == len(state.cpus) {
+		return []commands.Command{commands.NewWriteToGlobCmd(t.fs, glob, "1")}
+	}
+	cmds := make([]commands.Command, 0, len(cpus))
+	for _, cpu := range cpus {
+		cmds = append(
+			cmds,
+			commands.NewWriteFileCmd(t.fs, idleStateFile(cpu, state.dir, "disable"), "1"),
+		)
+	}
+	return cmds
+}
+
+// Reads the idle states of the online CPUs, grouping the CPUs whose states
+// have the same number, name and latency.
+func (t *cstateTuner) readStates() ([]*idleState, error) {
+	topo, err := topology.Read(t.fs)
+	if err != nil {
+		return nil, err
+	}
+	cpus := topo.SpreadOrder(nil)
+	sort.Ints(cpus)
+	groups := map[string]*idleState{}
+	var states []*idleState
+	for _, cpu := range cpus {
+		dirs, err := afero.Glob(t.fs, idleStateFile(cpu, "state*", ""))
+		if err != nil {
+			return nil, err
+		}
+		for _, dir := range dirs {
+			state, disabled, err := readIdleState(t.fs, dir)
+			if err != nil {
+				return nil, err
+			}
+			key := fmt.Sprintf("%s/%s/%d", state.dir, state.name, state.latency)
+			group, ok := groups[key]
+			if !ok {
+				group = state
+				groups[key] = group
+				states = append(states, group)
+			}
+			group.cpus = append(group.cpus, cpu)
+			if disabled {
+				group.disabled = append(group.disabled, cpu)
+			}
+		}
+	}
+	sort.SliceStable(states, func(i, j int) bool {
+		return states[i].latency < states[j].latency
+	})
+	return states, nil
+}
+
+// Reads the state in dir, returning whether it's disabled.
+func readIdleState(fs afero.Fs, dir string) (*idleState, bool, error) {
+	read := func(file string) (string, error) {
+		content, err := afero.ReadFile(fs, filepath.Join(dir, file))
+		return strings.TrimSpace(string(content)), err
+	}
+	name, err := read("name")
+	if err != nil {
+		return nil, false, err
+	}
+	latencyStr, err := read("latency")
+	if err != nil {
+		return nil, false, err
+	}
+	latency, err := strconv.Atoi(latencyStr)
+	if err != nil {
+		return nil, false, fmt.Errorf(
+			"couldn't parse '%s': %w",
+			filepath.Join(dir, "latency"),
+			err,
+		)
+	}
+	disable, err := read("disable")
+	if err != nil {
+		return nil, false, err
+	}
+	state := &idleState{dir: filepath.Base(dir), name: name, latency: latency}
+	return state, disable == "1", nil
+}
+
+func newIdleStateChecker(fs afero.Fs, state *idleState) Checker {
+	return NewEqualityChecker(
+		CpuIdleStatesChecker,
+		state.desc(),
+		Warning,
+		"disabled on all CPUs",
+		func() (interface{}, error) {
+			// Read again, as the state may have been disabled since
+			// the tunable was created.
+			current := &idleState{
+				dir:     state.dir,
+				name:    state.name,
+				latency: state.latency,
+			}
+			for _, cpu := range state.cpus {
+				content, err := afero.ReadFile(fs, idleStateFile(cpu, state.dir, "disable"))
+				if err != nil {
+					return nil, err
+				}
+				current.cpus = append(current.cpus, cpu)
+				if strings.TrimSpace(string(content)) == "1" {
+					current.disabled = append(current.disabled, cpu)
+				}
+			}
+			return current.status(), nil
+		},
+	)
+}
+
+// Reports a state shallow enough to be kept as is.
+type keptIdleState struct {
+	state *idleState
+}
+
+func (s *keptIdleState) CheckIfSupported() (supported bool, reason string) {
+	return true, ""
+}
+
+func (s *keptIdleState) Tune() TuneResult {
+	return NewUnchangedTuneResult()
+}
+
+func (s *keptIdleState) Check() ([]CheckResult, error) {
+	return []CheckResult{{
+		CheckerId: CpuIdleStatesChecker,
+		IsOk:      true,
+		Desc:      s.state.desc(),
+		Severity:  Warning,
+		Current:   s.state.status(),
+		Required:  "kept as is",
+	}}, nil
+}
+
+// Formats cpus, in ascending order, in cpuset(7)'s list format, e.g.
+// '0-3,8'.
+func formatCpuList(cpus []int) string {
+	var parts []string
+	for i := 0; i < len(cpus); {
+		j := i
+		for j+1 < len(cpus) && cpus[j+1] == cpus[j]+1 {
+			j++
+		}
+		if i == j {
+			parts = append(parts, fmt.Sprint(cpus[i]))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d-%d", cpus[i], cpus[j]))
+		}
+		i = j + 1
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,180 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners_test
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors"
+)
+
+type idleStateSpec struct {
+	name     string
+	latency  int
+	disabled bool
+}
+
+// Writes the topology of a CPU without siblings and its idle states, in the
+// given order.
+func writeCpuIdleStates(t *testing.T, fs afero.Fs, cpu int, states ...idleStateSpec) {
+	dir := fmt.Sprintf("/sys/devices/system/cpu/cpu%d", cpu)
+	files := map[string]string{
+		"topology/physical_package_id":  "0",
+		"topology/core_id":              fmt.Sprint(cpu),
+		"topology/thread_siblings_list": fmt.Sprint(cpu),
+	}
+	for i, state := range states {
+		disable := "0"
+		if state.disabled {
+			disable = "1"
+		}
+		stateDir := fmt.Sprintf("cpuidle/state%d/", i)
+		files[stateDir+"name"] = state.name
+		files[stateDir+"latency"] = fmt.Sprint(state.latency)
+		files[stateDir+"disable"] = disable
+	}
+	for name, content := range files {
+		path := dir + "/" + name
+		require.NoError(t, afero.WriteFile(fs, path, []byte(content+"\n"), 0644))
+	}
+}
+
+var intelIdleStates = []idleStateSpec{
+	{name: "POLL", latency: 0},
+	{name: "C1", latency: 2},
+	{name: "C1E", latency: 10},
+	{name: "C6", latency: 133},
+}
+
+func readDisable(t *testing.T, fs afero.Fs, cpu, state int) string {
+	path := fmt.Sprintf("/sys/devices/system/cpu/cpu%d/cpuidle/state%d/disable", cpu, state)
+	content, err := afero.ReadFile(fs, path)
+	require.NoError(t, err)
+	return string(bytes.TrimSpace(content))
+}
+
+func TestCStateTuner(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	writeCpuIdleStates(t, fs, 0, intelIdleStates...)
+	writeCpuIdleStates(t, fs, 1, intelIdleStates...)
+	tuner := tuners.NewCStateTuner(
+		fs,
+		tuners.RecommendedMaxCStateLatency,
+		executors.NewDirectExecutor(),
+	)
+	supported, _ := tuner.CheckIfSupported()
+	require.True(t, supported)
+
+	results, err := tuners.CheckTunable(tuner)
+	require.NoError(t, err)
+	require.Len(t, results, 4)
+	require.True(t, results[2].IsOk)
+	require.Equal(t, "CPU idle state C6 (state3, 133us exit latency)", results[3].Desc)
+	require.False(t, results[3].IsOk)
+	require.Equal(t, "enabled on all CPUs", results[3].Current)
+
+	res := tuner.Tune()
+	require.NoError(t, res.Error())
+	for cpu := 0; cpu < 2; cpu++ {
+		require.Equal(t, "0", readDisable(t, fs, cpu, 2))
+		require.Equal(t, "1", readDisable(t, fs, cpu, 3))
+	}
+	results, err = tuners.CheckTunable(tuner)
+	require.NoError(t, err)
+	require.True(t, results[3].IsOk)
+	require.Equal(t, "disabled on all CPUs", results[3].Current)
+}
+
+func TestCStateTunerLatencies(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	// The deepest state is numbered differently on CPU 0, and it's already
+	// disabled on CPUs 0 and 2.
+	writeCpuIdleStates(
+		t,
+		fs,
+		0,
+		idleStateSpec{name: "C1", latency: 1},
+		idleStateSpec{name: "C6", latency: 85, disabled: true},
+	)
+	writeCpuIdleStates(
+		t,
+		fs,
+		1,
+		idleStateSpec{name: "C1", latency: 1},
+		idleStateSpec{name: "C3", latency: 40},
+		idleStateSpec{name: "C6", latency: 85},
+	)
+	writeCpuIdleStates(
+		t,
+		fs,
+		2,
+		idleStateSpec{name: "C1", latency: 1},
+		idleStateSpec{name: "C3", latency: 40},
+		idleStateSpec{name: "C6", latency: 85, disabled: true},
+	)
+	const scriptPath = "/tune.sh"
+	tuner := tuners.NewCStateTuner(fs, 50, executors.NewScriptRenderingExecutor(fs, scriptPath))
+	results, err := tuners.CheckTunable(tuner)
+	require.NoError(t, err)
+	require.Len(t, results, 4)
+	require.Equal(t, "CPU idle state C3 (state1, 40us exit latency)", results[1].Desc)
+	require.True(t, results[1].IsOk)
+	require.Equal(t, "CPU idle state C6 (state1, 85us exit latency)", results[2].Desc)
+	require.True(t, results[2].IsOk)
+	require.Equal(t, "CPU idle state C6 (state2, 85us exit latency)", results[3].Desc)
+	require.False(t, results[3].IsOk)
+	require.Equal(t, "disabled on 2, enabled on 1", results[3].Current)
+
+	// Only CPU 1's state is written, as it's disabled on CPU 2.
+	res := tuner.Tune()
+	require.NoError(t, res.Error())
+	script, err := afero.ReadFile(fs, scriptPath)
+	require.NoError(t, err)
+	require.Contains(
+		t,
+		string(script),
+		"echo '1' > /sys/devices/system/cpu/cpu1/cpuidle/state2/disable\n",
+	)
+	require.NotContains(t, string(script), "cpu2")
+	require.NotContains(t, string(script), "state1")
+}
+
+func TestCStateTunerUndo(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	writeCpuIdleStates(t, fs, 0, intelIdleStates...)
+	writeCpuIdleStates(t, fs, 1, intelIdleStates...)
+	recorder := executors.NewRecordingExecutor(executors.NewDirectExecutor())
+	res := tuners.NewCStateTuner(fs, tuners.RecommendedMaxCStateLatency, recorder).Tune()
+	require.NoError(t, res.Error())
+
+	var buf bytes.Buffer
+	require.NoError(t, recorder.RenderUndoScript(bufio.NewWriter(&buf)))
+	for cpu := 0; cpu < 2; cpu++ {
+		require.Contains(
+			t,
+			buf.String(),
+			fmt.Sprintf("echo '0' > /sys/devices/system/cpu/cpu%d/cpuidle/state3/disable\n", cpu),
+		)
+	}
+}
+
+func TestCStateTunerUnsupported(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	writeCpuIdleStates(t, fs, 0)
+	supported, reason := tuners.NewCStateTuner(fs, 10, executors.NewDirectExecutor()).CheckIfSupported()
+	require.False(t, supported)
+	require.Equal(t, "The kernel doesn't expose the CPU idle states (cpuidle) in sysfs", reason)
+}
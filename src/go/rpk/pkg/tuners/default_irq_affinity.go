@@ -0,0 +1,116 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners
+
+import (
+	"fmt"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/irq"
+)
+
+// The mask the IRQs registered from now on (e.g. by a hotplugged device, or
+// a driver loaded late) are bound to.
+const DefaultSmpAffinityFile = "/proc/irq/default_smp_affinity"
+
+const defaultIRQAffinityDesc = "Default IRQ affinity"
+
+// Creates a tuner writing mask (in the format hwloc-calc prints masks) to
+// /proc/irq/default_smp_affinity, so that the IRQs registered after tuning
+// are kept off the CPUs the existing ones were moved away from. The current
+// default is read and validated before being replaced. If the kernel doesn't
+// expose the file, nothing is changed.
+func NewDefaultIRQAffinityTuner(
+	fs afero.Fs, mask string, cpuMasks irq.CpuMasks, executor executors.Executor,
+) Tunable {
+	if _, err := fs.Stat(DefaultSmpAffinityFile); os.IsNotExist(err) {
+		return &missingDefaultIRQAffinity{mask: mask}
+	}
+	return NewCheckedTunable(
+		NewEqualityChecker(
+			DefaultIRQAffinityChecker,
+			defaultIRQAffinityDesc,
+			Warning,
+			maskCpuList(mask),
+			func() (interface{}, error) {
+				current, err := cpuMasks.ReadMask(DefaultSmpAffinityFile)
+				if err != nil {
+					return "", err
+				}
+				err = irq.ValidateMask(current, irq.GetNrCpus(fs))
+				if err != nil {
+					return "", fmt.Errorf(
+						"invalid default IRQ affinity in '%s': %w",
+						DefaultSmpAffinityFile,
+						err,
+					)
+				}
+				cpus, err := irq.MaskCpus(current)
+				if err != nil {
+					return "", err
+				}
+				return formatCpuList(cpus), nil
+			},
+		),
+		func() TuneResult {
+			err := cpuMasks.SetMask(DefaultSmpAffinityFile, mask)
+			if err != nil {
+				return NewTuneError(err)
+			}
+			return NewTuneResult(false)
+		},
+		func() (bool, string) {
+			return true, ""
+		},
+		executor.IsLazy(),
+	)
+}
+
+// Returns the CPUs in mask in cpuset(7)'s list format, or mask itself if
+// it's invalid, so that it's reported as is.
+func maskCpuList(mask string) string {
+	cpus, err := irq.MaskCpus(mask)
+	if err != nil {
+		return mask
+	}
+	return formatCpuList(cpus)
+}
+
+// Reports that the kernel doesn't expose the default IRQ affinity, so
+// there's nothing to set.
+type missingDefaultIRQAffinity struct {
+	mask string
+}
+
+func (m *missingDefaultIRQAffinity) CheckIfSupported() (supported bool, reason string) {
+	return true, ""
+}
+
+func (m *missingDefaultIRQAffinity) Tune() TuneResult {
+	log.Infof(
+		"Skipping the default IRQ affinity, as the kernel doesn't expose '%s'",
+		DefaultSmpAffinityFile,
+	)
+	return NewUnchangedTuneResult()
+}
+
+func (m *missingDefaultIRQAffinity) Check() ([]CheckResult, error) {
+	return []CheckResult{{
+		CheckerId: DefaultIRQAffinityChecker,
+		IsOk:      true,
+		Desc:      defaultIRQAffinityDesc,
+		Severity:  Warning,
+		Current:   "not exposed by the kernel",
+		Required:  maskCpuList(m.mask),
+	}}, nil
+}
@@ -0,0 +1,117 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners_test
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/irq"
+)
+
+func TestDefaultIRQAffinityTuner(t *testing.T) {
+	tests := []struct {
+		name            string
+		current         string
+		expectedOk      bool
+		expectedCurrent string
+		expectedErr     string
+		expectedContent string
+	}{
+		{
+			name:            "it should write the mask",
+			current:         "ffffffff,ffffffff\n",
+			expectedCurrent: "0-63",
+			expectedContent: "0,00000001",
+		},
+		{
+			name:            "it shouldn't change anything if the mask is set already",
+			current:         "00000000,00000001\n",
+			expectedOk:      true,
+			expectedCurrent: "0",
+			expectedContent: "00000000,00000001\n",
+		},
+		{
+			name:            "it should fail if the current mask is invalid",
+			current:         "zz\n",
+			expectedErr:     "invalid default IRQ affinity in '/proc/irq/default_smp_affinity': invalid CPU mask '0xzz'",
+			expectedContent: "zz\n",
+		},
+		{
+			name:            "it should fail if the current mask has CPUs which don't exist",
+			current:         "1,00000000,00000000\n",
+			expectedErr:     "invalid default IRQ affinity in '/proc/irq/default_smp_affinity': CPU mask '0x1,0x00000000,0x00000000' includes CPU 64, but there are only 64 CPUs",
+			expectedContent: "1,00000000,00000000\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			require.NoError(t, afero.WriteFile(fs, "/sys/devices/system/cpu/possible", []byte("0-63\n"), 0644))
+			require.NoError(t, afero.WriteFile(fs, tuners.DefaultSmpAffinityFile, []byte(tt.current), 0644))
+			executor := executors.NewDirectExecutor()
+			tuner := tuners.NewDefaultIRQAffinityTuner(
+				fs,
+				"0x00000001",
+				irq.NewCpuMasks(fs, nil, executor),
+				executor,
+			)
+
+			results, err := tuners.CheckTunable(tuner)
+			require.NoError(t, err)
+			require.Len(t, results, 1)
+			require.Equal(t, "0", results[0].Required)
+			res := tuner.Tune()
+			if tt.expectedErr != "" {
+				require.Error(t, results[0].Err)
+				require.Contains(t, res.Error().Error(), tt.expectedErr)
+			} else {
+				require.NoError(t, results[0].Err)
+				require.NoError(t, res.Error())
+				require.Equal(t, tt.expectedOk, results[0].IsOk)
+				require.Equal(t, tt.expectedCurrent, results[0].Current)
+				require.Equal(t, !tt.expectedOk, res.IsChanged())
+			}
+			content, err := afero.ReadFile(fs, tuners.DefaultSmpAffinityFile)
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedContent, string(content))
+		})
+	}
+}
+
+func TestDefaultIRQAffinityTunerMissingFile(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	executor := executors.NewDirectExecutor()
+	tuner := tuners.NewDefaultIRQAffinityTuner(
+		fs,
+		"0x00000003",
+		irq.NewCpuMasks(fs, nil, executor),
+		executor,
+	)
+	supported, _ := tuner.CheckIfSupported()
+	require.True(t, supported)
+
+	results, err := tuners.CheckTunable(tuner)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.True(t, results[0].IsOk)
+	require.Equal(t, "not exposed by the kernel", results[0].Current)
+	require.Equal(t, "0-1", results[0].Required)
+
+	res := tuner.Tune()
+	require.NoError(t, res.Error())
+	require.False(t, res.IsChanged())
+	exists, err := afero.Exists(fs, tuners.DefaultSmpAffinityFile)
+	require.NoError(t, err)
+	require.False(t, exists)
+}
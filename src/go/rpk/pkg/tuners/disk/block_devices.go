@@ -36,6 +36,9 @@ const (
 type DevicesIRQs struct {
 	Devices []string
 	Irqs    []int
+	// The NUMA node of the device each IRQ belongs to, for the devices
+	// whose node is known.
+	NumaNodes map[int]int
 }
 type BlockDevices interface {
 	GetDirectoriesDevices(directories []string) (map[string][]string, error)
@@ -114,7 +117,7 @@ func (b *blockDevices) GetDirectoryDevices(path string) ([]string, error) {
 			}
 			devices = append(devices, directoryDevices...)
 		} else {
-			log.Error("Failed to create device"+
+			log.Errorf("Failed to create device"+
 				" while 'df -P %s' returns a '%s'",
 				path, devicePath)
 		}
@@ -123,11 +126,21 @@ func (b *blockDevices) GetDirectoryDevices(path string) ([]string, error) {
 		log.Errorf("Can't get a block device for '%s' - skipping", path)
 	}
 
-	return []string{}, nil
+	return devices, nil
 }
 
+// Resolves device down to the physical disks backing it. Partitions are
+// resolved to their disk, and virtual devices (LVM volumes, md arrays, etc)
+// to the devices they're built upon, which are resolved recursively.
 func (b *blockDevices) getPhysDevices(device BlockDevice) ([]string, error) {
 	log.Debugf("Getting physical device from '%s'", device.Syspath())
+	// Partitions don't have a queue of their own, the disk's is used.
+	isPartition, _ := afero.Exists(b.fs, path.Join(device.Syspath(), "partition"))
+	if isPartition && device.Parent() != nil {
+		log.Debugf("'%s' is a partition of '%s'",
+			device.Devnode(), device.Parent().Devnode())
+		return b.getPhysDevices(device.Parent())
+	}
 	if strings.Contains(device.Syspath(), "virtual") {
 		joinedPath := path.Join(device.Syspath(), "slaves")
 		files, err := afero.ReadDir(b.fs, joinedPath)
@@ -236,11 +249,21 @@ func (b *blockDevices) GetDiskInfoByType(
 	nvmeIRQs := map[int]bool{}
 	nonNvmeDisks := map[string]bool{}
 	nonNvmeIRQs := map[int]bool{}
+	numaNodes := map[int]int{}
 	deviceIRQs, err := b.getDevicesIRQs(devices)
 	if err != nil {
 		return nil, err
 	}
 	for device, irqs := range deviceIRQs {
+		node, err := irq.GetNumaNode(b.fs, path.Join("/sys/class/block", device))
+		if err != nil {
+			return nil, err
+		}
+		if node >= 0 {
+			for _, IRQ := range irqs {
+				numaNodes[IRQ] = node
+			}
+		}
 		if strings.HasPrefix(device, "nvme") {
 			nvmeDisks[device] = true
 			for _, IRQ := range irqs {
@@ -266,9 +289,9 @@ func (b *blockDevices) GetDiskInfoByType(
 		}
 	}
 	diskInfoByType[Nvme] = DevicesIRQs{utils.GetKeys(nvmeDisks),
-		utils.GetIntKeys(nvmeIRQs)}
+		utils.GetIntKeys(nvmeIRQs), numaNodes}
 	diskInfoByType[NonNvme] = DevicesIRQs{utils.GetKeys(nonNvmeDisks),
-		utils.GetIntKeys(nonNvmeIRQs)}
+		utils.GetIntKeys(nonNvmeIRQs), numaNodes}
 	return diskInfoByType, nil
 }
 
@@ -122,3 +122,53 @@ func Test_blockDevices_isIRQNvmeFastPathIRQ(t *testing.T) {
 		})
 	}
 }
+
+func Test_blockDevices_getPhysDevices(t *testing.T) {
+	tests := []struct {
+		name     string
+		device   BlockDevice
+		before   func(afero.Fs) error
+		expected []string
+	}{
+		{
+			name: "it should return the device if it's a disk",
+			device: &blockDevice{
+				syspath: "/sys/devices/pci0000:00/0000:00:1d.0/nvme/nvme0/nvme0n1",
+				devnode: "/dev/nvme0n1",
+			},
+			expected: []string{"nvme0n1"},
+		},
+		{
+			name: "it should resolve partitions to their disk",
+			device: &blockDevice{
+				syspath: "/sys/devices/pci0000:00/0000:00:1d.0/nvme/nvme0/nvme0n1/nvme0n1p2",
+				devnode: "/dev/nvme0n1p2",
+				parent: &blockDevice{
+					syspath: "/sys/devices/pci0000:00/0000:00:1d.0/nvme/nvme0/nvme0n1",
+					devnode: "/dev/nvme0n1",
+				},
+			},
+			before: func(fs afero.Fs) error {
+				return afero.WriteFile(
+					fs,
+					"/sys/devices/pci0000:00/0000:00:1d.0/nvme/nvme0/nvme0n1/nvme0n1p2/partition",
+					[]byte("2\n"),
+					0644,
+				)
+			},
+			expected: []string{"nvme0n1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			fs := afero.NewMemMapFs()
+			if tt.before != nil {
+				require.NoError(st, tt.before(fs))
+			}
+			blockDevices := &blockDevices{fs: fs}
+			devices, err := blockDevices.getPhysDevices(tt.device)
+			require.NoError(st, err)
+			require.Equal(st, tt.expected, devices)
+		})
+	}
+}
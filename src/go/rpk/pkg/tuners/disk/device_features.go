@@ -33,6 +33,9 @@ type DeviceFeatures interface {
 	GetSchedulerFeatureFile(device string) (string, error)
 	GetWriteCache(device string) (string, error)
 	GetWriteCacheFeatureFile(device string) (string, error)
+	// Returns the file of the given queue parameter (e.g. 'rq_affinity')
+	// of device, or of its parent if it has none, or "" if neither have it.
+	GetQueueFeatureFile(device string, feature string) (string, error)
 }
 
 func NewDeviceFeatures(fs afero.Fs, blockDevices BlockDevices) DeviceFeatures {
@@ -108,6 +111,12 @@ func (d *deviceFeatures) GetWriteCacheFeatureFile(
 	return d.getQueueFeatureFile(deviceNode(device), "write_cache")
 }
 
+func (d *deviceFeatures) GetQueueFeatureFile(
+	device string, feature string,
+) (string, error) {
+	return d.getQueueFeatureFile(deviceNode(device), feature)
+}
+
 func (d *deviceFeatures) getSchedulerOptions(
 	device string,
 ) (*system.RuntimeOptions, error) {
@@ -11,6 +11,7 @@ package tuners
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/spf13/afero"
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/disk"
@@ -87,18 +88,87 @@ func checkDeviceNomerges(
 	return nomerges == 2, nil
 }
 
+type deviceSchedulerChecker struct {
+	device         string
+	deviceFeatures disk.DeviceFeatures
+}
+
+// Creates a checker comparing the device's current scheduler with the one
+// recommended for it among those it supports.
 func NewDeviceSchedulerChecker(
 	fs afero.Fs, device string, deviceFeatures disk.DeviceFeatures,
 ) Checker {
-	return NewEqualityChecker(
-		SchedulerChecker,
-		fmt.Sprintf("Disk '%s' scheduler tuned", device),
-		Warning,
-		true,
-		func() (interface{}, error) {
-			return checkScheduler(deviceFeatures, device)
-		},
-	)
+	return &deviceSchedulerChecker{
+		device:         device,
+		deviceFeatures: deviceFeatures,
+	}
+}
+
+func (c *deviceSchedulerChecker) Id() CheckerID {
+	return SchedulerChecker
+}
+
+func (c *deviceSchedulerChecker) GetDesc() string {
+	return fmt.Sprintf("Disk '%s' scheduler", c.device)
+}
+
+func (c *deviceSchedulerChecker) GetSeverity() Severity {
+	return Warning
+}
+
+func (c *deviceSchedulerChecker) GetRequiredAsString() string {
+	preferred, err := getPreferredScheduler(c.device, c.deviceFeatures)
+	if err != nil {
+		return strings.Join(preferredSchedulers, " | ")
+	}
+	return preferred
+}
+
+func (c *deviceSchedulerChecker) Check() *CheckResult {
+	res := &CheckResult{
+		CheckerId: c.Id(),
+		Desc:      c.GetDesc(),
+		Severity:  c.GetSeverity(),
+	}
+	preferred, err := getPreferredScheduler(c.device, c.deviceFeatures)
+	if err != nil {
+		res.Required = c.GetRequiredAsString()
+		res.Err = err
+		return res
+	}
+	res.Required = preferred
+	current, err := c.deviceFeatures.GetScheduler(c.device)
+	if err != nil {
+		res.Err = err
+		return res
+	}
+	res.Current = current
+	res.IsOk = current == preferred
+	return res
+}
+
+// Creates a scheduler checker for each of the physical devices backing dir.
+// If they can't be listed, a single checker reporting the error is returned.
+func NewDirectorySchedulerCheckers(
+	fs afero.Fs,
+	dir string,
+	deviceFeatures disk.DeviceFeatures,
+	blockDevices disk.BlockDevices,
+) []Checker {
+	devices, err := blockDevices.GetDirectoryDevices(dir)
+	if err != nil || len(devices) == 0 {
+		return []Checker{
+			NewDirectorySchedulerChecker(fs, dir, deviceFeatures, blockDevices),
+		}
+	}
+	var checkers []Checker
+	for _, device := range devices {
+		checkers = append(
+			checkers,
+			NewDeviceSchedulerChecker(fs, device, deviceFeatures),
+		)
+	}
+	return checkers
 }
 
 func NewDirectorySchedulerChecker(
@@ -133,11 +203,15 @@ func NewDirectorySchedulerChecker(
 func checkScheduler(
 	deviceFeatures disk.DeviceFeatures, device string,
 ) (bool, error) {
+	preferred, err := getPreferredScheduler(device, deviceFeatures)
+	if err != nil {
+		return false, err
+	}
 	scheduler, err := deviceFeatures.GetScheduler(device)
 	if err != nil {
 		return false, err
 	}
-	return scheduler == "none" || scheduler == "noop", nil
+	return scheduler == preferred, nil
 }
 
 func NewDeviceWriteCacheChecker(
@@ -0,0 +1,162 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners
+
+import (
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/disk"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors/commands"
+)
+
+// A block device queue parameter (see the kernel's
+// Documentation/block/queue-sysfs.rst), and the value it's set to.
+type QueueParameter struct {
+	Name  string
+	Value string
+}
+
+// The queue parameters set on the devices backing redpanda's data, in the
+// order they're set. nomerges is left to the disk_nomerges tuner (see
+// NewNomergesTuner).
+var RecommendedQueueParameters = []QueueParameter{
+	// Complete each request on the CPU which issued it, rather than on any
+	// CPU sharing its cache, so that the completion runs where the
+	// reactor waiting for it does.
+	{Name: "rq_affinity", Value: "2"},
+}
+
+// Creates a tuner setting the given queue parameters (e.g.
+// RecommendedQueueParameters) on the devices backing directories, along with
+// the given devices. Each parameter is checked and set on its own, so that a
+// device whose kernel doesn't expose one of them still gets the others.
+func NewBlockQueueTuner(
+	fs afero.Fs,
+	directories []string,
+	devices []string,
+	blockDevices disk.BlockDevices,
+	parameters []QueueParameter,
+	executor executors.Executor,
+) Tunable {
+	deviceFeatures := disk.NewDeviceFeatures(fs, blockDevices)
+	return NewDiskTuner(
+		fs,
+		directories,
+		devices,
+		blockDevices,
+		func(device string) Tunable {
+			return NewDeviceQueueTuner(fs, device, deviceFeatures, parameters, executor)
+		},
+	)
+}
+
+// Creates a tuner setting the given queue parameters of device.
+func NewDeviceQueueTuner(
+	fs afero.Fs,
+	device string,
+	deviceFeatures disk.DeviceFeatures,
+	parameters []QueueParameter,
+	executor executors.Executor,
+) Tunable {
+	tunables := make([]Tunable, 0, len(parameters))
+	for _, param := range parameters {
+		file, err := deviceFeatures.GetQueueFeatureFile(device, param.Name)
+		if err == nil && file == "" {
+			tunables = append(tunables, &missingQueueParameter{device, param})
+			continue
+		}
+		tunables = append(
+			tunables,
+			newQueueParameterTunable(fs, device, param, file, err, executor),
+		)
+	}
+	return NewAggregatedTunable(tunables)
+}
+
+// Returns a tunable writing param to file. If the file couldn't be found,
+// fileErr is returned when it's checked or tuned.
+func newQueueParameterTunable(
+	fs afero.Fs,
+	device string,
+	param QueueParameter,
+	file string,
+	fileErr error,
+	executor executors.Executor,
+) Tunable {
+	return NewCheckedTunable(
+		NewEqualityChecker(
+			BlockQueueChecker,
+			queueParameterDesc(device, param),
+			Warning,
+			param.Value,
+			func() (interface{}, error) {
+				if fileErr != nil {
+					return "", fileErr
+				}
+				content, err := afero.ReadFile(fs, file)
+				if err != nil {
+					return "", err
+				}
+				return strings.TrimSpace(string(content)), nil
+			},
+		),
+		func() TuneResult {
+			log.Infof("Setting '%s' queue %s to %s", device, param.Name, param.Value)
+			err := executor.Execute(commands.NewWriteFileCmd(fs, file, param.Value))
+			if err != nil {
+				return NewTuneError(err)
+			}
+			return NewTuneResult(false)
+		},
+		func() (bool, string) {
+			return true, ""
+		},
+		executor.IsLazy(),
+	)
+}
+
+func queueParameterDesc(device string, param QueueParameter) string {
+	return fmt.Sprintf("Disk '%s' queue %s", device, param.Name)
+}
+
+// Reports a queue parameter the device's kernel doesn't expose, which is
+// skipped rather than failing the device's other parameters.
+type missingQueueParameter struct {
+	device string
+	param  QueueParameter
+}
+
+func (p *missingQueueParameter) CheckIfSupported() (supported bool, reason string) {
+	return true, ""
+}
+
+func (p *missingQueueParameter) Tune() TuneResult {
+	log.Infof(
+		"Skipping '%s' queue %s, as the kernel doesn't expose it",
+		p.device,
+		p.param.Name,
+	)
+	return NewUnchangedTuneResult()
+}
+
+func (p *missingQueueParameter) Check() ([]CheckResult, error) {
+	return []CheckResult{{
+		CheckerId: BlockQueueChecker,
+		IsOk:      true,
+		Desc:      queueParameterDesc(p.device, p.param),
+		Severity:  Warning,
+		Current:   "not exposed by the kernel",
+		Required:  p.param.Value,
+	}}, nil
+}
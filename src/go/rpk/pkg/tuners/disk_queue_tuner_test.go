@@ -0,0 +1,130 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors"
+)
+
+const fakeQueueDir = "/sys/devices/pci0000:00/0000:00:1d.0/0000:71:00.0/nvme/fake/queue"
+
+// Returns a mock exposing the given queue files, relative to fakeQueueDir.
+func queueFeaturesMock(files ...string) *deviceFeaturesMock {
+	return &deviceFeaturesMock{
+		getQueueFeatureFile: func(_ string, feature string) (string, error) {
+			for _, file := range files {
+				if file == feature {
+					return filepath.Join(fakeQueueDir, file), nil
+				}
+			}
+			return "", nil
+		},
+	}
+}
+
+// Queue parameters other than the recommended ones, so that setting several of
+// them can be tested.
+var testQueueParameters = []QueueParameter{
+	{Name: "add_random", Value: "0"},
+	{Name: "rq_affinity", Value: "2"},
+}
+
+func TestDeviceQueueTuner(t *testing.T) {
+	tests := []struct {
+		name        string
+		files       map[string]string
+		expected    map[string]string
+		expectedOk  []bool
+		expectedCur []interface{}
+	}{
+		{
+			name:        "it should set the parameters which aren't set yet",
+			files:       map[string]string{"add_random": "1\n", "rq_affinity": "1\n"},
+			expected:    map[string]string{"add_random": "0", "rq_affinity": "2"},
+			expectedOk:  []bool{false, false},
+			expectedCur: []interface{}{"1", "1"},
+		},
+		{
+			name:        "it should leave the parameters which are already set",
+			files:       map[string]string{"add_random": "0\n", "rq_affinity": "1\n"},
+			expected:    map[string]string{"add_random": "0\n", "rq_affinity": "2"},
+			expectedOk:  []bool{true, false},
+			expectedCur: []interface{}{"0", "1"},
+		},
+		{
+			name:        "it should skip the parameters the kernel doesn't expose",
+			files:       map[string]string{"rq_affinity": "1\n"},
+			expected:    map[string]string{"rq_affinity": "2"},
+			expectedOk:  []bool{true, false},
+			expectedCur: []interface{}{"not exposed by the kernel", "1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			var names []string
+			for name, content := range tt.files {
+				names = append(names, name)
+				err := afero.WriteFile(fs, filepath.Join(fakeQueueDir, name), []byte(content), 0644)
+				require.NoError(t, err)
+			}
+			tuner := NewDeviceQueueTuner(
+				fs,
+				"fake",
+				queueFeaturesMock(names...),
+				testQueueParameters,
+				executors.NewDirectExecutor(),
+			)
+
+			results, err := CheckTunable(tuner)
+			require.NoError(t, err)
+			require.Len(t, results, len(testQueueParameters))
+			for i, res := range results {
+				require.EqualValues(t, BlockQueueChecker, res.CheckerId)
+				require.Equal(t, tt.expectedOk[i], res.IsOk, res.Desc)
+				require.Equal(t, tt.expectedCur[i], res.Current, res.Desc)
+			}
+
+			res := tuner.Tune()
+			require.NoError(t, res.Error())
+			for name, expected := range tt.expected {
+				content, err := afero.ReadFile(fs, filepath.Join(fakeQueueDir, name))
+				require.NoError(t, err)
+				require.Equal(t, expected, string(content))
+			}
+			_, err = fs.Stat(filepath.Join(fakeQueueDir, "add_random"))
+			_, exists := tt.files["add_random"]
+			require.Equal(t, exists, err == nil, "add_random shouldn't be created")
+		})
+	}
+}
+
+func TestDeviceQueueTunerLookupError(t *testing.T) {
+	deviceFeatures := &deviceFeaturesMock{
+		getQueueFeatureFile: func(string, string) (string, error) {
+			return "", errors.New("no such device")
+		},
+	}
+	tuner := NewDeviceQueueTuner(
+		afero.NewMemMapFs(),
+		"fake",
+		deviceFeatures,
+		RecommendedQueueParameters,
+		executors.NewDirectExecutor(),
+	)
+	res := tuner.Tune()
+	require.EqualError(t, res.Error(), "no such device")
+}
@@ -11,6 +11,7 @@ package tuners
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/spf13/afero"
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/disk"
@@ -63,6 +64,13 @@ func tuneScheduler(
 	return NewTuneResult(false)
 }
 
+// The schedulers recommended for the disks backing redpanda's data, in order
+// of preference. NVMe devices are fast enough that any reordering done by the
+// kernel only adds latency, so 'none' (or its legacy single-queue equivalent,
+// 'noop') is preferred, falling back to the lightweight 'mq-deadline' if
+// neither is offered.
+var preferredSchedulers = []string{"none", "noop", "mq-deadline"}
+
 func getPreferredScheduler(
 	device string, deviceFeatures disk.DeviceFeatures,
 ) (string, error) {
@@ -70,20 +78,19 @@ func getPreferredScheduler(
 	if err != nil {
 		return "", err
 	}
-	preferred := []string{"none", "noop"}
 	supportedMap := make(map[string]bool)
 
 	for _, sched := range supported {
 		supportedMap[sched] = true
 	}
 
-	for _, sched := range preferred {
+	for _, sched := range preferredSchedulers {
 		if _, exists := supportedMap[sched]; exists {
 			return sched, nil
 		}
 	}
-	return "", fmt.Errorf("None and Noop schedulers are not supported for %s",
-		device)
+	return "", fmt.Errorf("None of the %s schedulers are supported for %s",
+		strings.Join(preferredSchedulers, ", "), device)
 }
 
 func NewSchedulerTuner(
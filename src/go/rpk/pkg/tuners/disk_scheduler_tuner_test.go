@@ -27,6 +27,7 @@ type deviceFeaturesMock struct {
 	getScheduler             func(string) (string, error)
 	getWriteCacheFeatureFile func(string) (string, error)
 	getWriteCache            func(string) (string, error)
+	getQueueFeatureFile      func(string, string) (string, error)
 }
 
 func (m *deviceFeaturesMock) GetScheduler(device string) (string, error) {
@@ -65,6 +66,12 @@ func (m *deviceFeaturesMock) GetWriteCache(device string) (string, error) {
 	return m.getWriteCache(device)
 }
 
+func (m *deviceFeaturesMock) GetQueueFeatureFile(
+	device string, feature string,
+) (string, error) {
+	return m.getQueueFeatureFile(device, feature)
+}
+
 func TestDeviceSchedulerTuner_Tune(t *testing.T) {
 	// given
 	deviceFeatures := &deviceFeaturesMock{
@@ -154,3 +161,77 @@ func TestDeviceSchedulerTuner_Tune_should_prefer_none_over_noop(t *testing.T) {
 	setValue, _ := afero.ReadFile(fs, "/sys/devices/pci0000:00/0000:00:1d.0/0000:71:00.0/nvme/fake/queue/scheduler")
 	require.Equal(t, "none", string(setValue))
 }
+
+func TestDeviceSchedulerTuner_Tune_should_fall_back_to_mq_deadline(t *testing.T) {
+	// given
+	deviceFeatures := &deviceFeaturesMock{
+		getSchedulerFeatureFile: func(string) (string, error) {
+			return "/sys/devices/pci0000:00/0000:00:1d.0/0000:71:00.0/nvme/fake/queue/scheduler", nil
+		},
+		getScheduler: func(string) (string, error) {
+			return "bfq", nil
+		},
+		getSupportedSchedulers: func(string) ([]string, error) {
+			return []string{"mq-deadline", "kyber", "bfq"}, nil
+		},
+	}
+	fs := afero.NewMemMapFs()
+	fs.MkdirAll("/sys/devices/pci0000:00/0000:00:1d.0/0000:71:00.0/nvme/fake/queue", 0644)
+	tuner := NewDeviceSchedulerTuner(fs, "fake", deviceFeatures, executors.NewDirectExecutor())
+	// when
+	tuner.Tune()
+	// then
+	setValue, _ := afero.ReadFile(fs, "/sys/devices/pci0000:00/0000:00:1d.0/0000:71:00.0/nvme/fake/queue/scheduler")
+	require.Equal(t, "mq-deadline", string(setValue))
+}
+
+func TestDeviceSchedulerChecker(t *testing.T) {
+	tests := []struct {
+		name             string
+		current          string
+		supported        []string
+		expectedOk       bool
+		expectedRequired string
+	}{
+		{
+			name:             "it should pass if the preferred scheduler is set",
+			current:          "none",
+			supported:        []string{"mq-deadline", "none"},
+			expectedOk:       true,
+			expectedRequired: "none",
+		},
+		{
+			name:             "it should fail if a worse scheduler is set",
+			current:          "mq-deadline",
+			supported:        []string{"mq-deadline", "none"},
+			expectedOk:       false,
+			expectedRequired: "none",
+		},
+		{
+			name:             "it should pass if the best available scheduler is set",
+			current:          "mq-deadline",
+			supported:        []string{"mq-deadline", "kyber"},
+			expectedOk:       true,
+			expectedRequired: "mq-deadline",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			deviceFeatures := &deviceFeaturesMock{
+				getScheduler: func(string) (string, error) {
+					return tt.current, nil
+				},
+				getSupportedSchedulers: func(string) ([]string, error) {
+					return tt.supported, nil
+				},
+			}
+			checker := NewDeviceSchedulerChecker(afero.NewMemMapFs(), "nvme0n1", deviceFeatures)
+			res := checker.Check()
+			require.NoError(st, res.Err)
+			require.Equal(st, "Disk 'nvme0n1' scheduler", res.Desc)
+			require.Equal(st, tt.expectedOk, res.IsOk)
+			require.Equal(st, tt.current, res.Current)
+			require.Equal(st, tt.expectedRequired, res.Required)
+		})
+	}
+}
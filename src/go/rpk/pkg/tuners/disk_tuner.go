@@ -48,6 +48,14 @@ func (tuner *diskTuner) Tune() TuneResult {
 	return NewAggregatedTunable(tunables).Tune()
 }
 
+func (tuner *diskTuner) Check() ([]CheckResult, error) {
+	tunables, err := tuner.createDeviceTuners()
+	if err != nil {
+		return nil, err
+	}
+	return CheckTunable(NewAggregatedTunable(tunables))
+}
+
 func (tuner *diskTuner) CheckIfSupported() (supported bool, reason string) {
 	if len(tuner.directories) == 0 && len(tuner.devices) == 0 {
 		return false,
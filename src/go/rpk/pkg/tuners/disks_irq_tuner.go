@@ -80,10 +80,29 @@ func (tuner *disksIRQsTuner) CheckIfSupported() (
 }
 
 func (tuner *disksIRQsTuner) Tune() TuneResult {
+	tunables, err := tuner.createTunables()
+	if err != nil {
+		return NewTuneError(err)
+	}
+	return NewAggregatedTunable(tunables).Tune()
+}
+
+func (tuner *disksIRQsTuner) Check() ([]CheckResult, error) {
+	tunables, err := tuner.createTunables()
+	if err != nil {
+		return nil, err
+	}
+	return CheckTunable(NewAggregatedTunable(tunables))
+}
+
+// Returns the tunables banning the devices' IRQs from irqbalance, then
+// distributing them, and setting the default affinity of the IRQs registered
+// from now on.
+func (tuner *disksIRQsTuner) createTunables() ([]Tunable, error) {
 	directoryDevices, err := tuner.blockDevices.GetDirectoriesDevices(
 		tuner.directories)
 	if err != nil {
-		return NewTuneError(err)
+		return nil, err
 	}
 
 	var allDevices []string
@@ -97,10 +116,6 @@ func (tuner *disksIRQsTuner) Tune() TuneResult {
 		tuner.blockDevices,
 		tuner.irqBalanceService,
 		tuner.executor)
-
-	if result := balanceServiceTuner.Tune(); result.IsFailed() {
-		return result
-	}
 	affinityTuner := NewDiskIRQsAffinityTuner(
 		tuner.fs,
 		allDevices,
@@ -110,7 +125,23 @@ func (tuner *disksIRQsTuner) Tune() TuneResult {
 		tuner.cpuMasks,
 		tuner.executor,
 	)
-	return affinityTuner.Tune()
+	defaultMask, err := GetExpectedIRQsCpuMask(
+		allDevices,
+		tuner.blockDevices,
+		tuner.mode,
+		tuner.baseCPUMask,
+		tuner.cpuMasks,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defaultAffinityTuner := NewDefaultIRQAffinityTuner(
+		tuner.fs,
+		defaultMask,
+		tuner.cpuMasks,
+		tuner.executor,
+	)
+	return []Tunable{balanceServiceTuner, affinityTuner, defaultAffinityTuner}, nil
 }
 
 func NewDiskIRQsBalanceServiceTuner(
@@ -189,6 +220,71 @@ func GetExpectedIRQsDistribution(
 	log.Debugf("Getting %v IRQs distribution with mode %s and CPU mask %s",
 		devices,
 		mode, cpuMask)
+	masks, err := getDisksIRQsCpuMasks(devices, blockDevices, mode, cpuMask, cpuMasks)
+	if err != nil {
+		return nil, err
+	}
+	finalCpuMask, irqCPUMask := masks.base, masks.irqs
+	nonNvmeDisksInfo := masks.diskInfoByType[disk.NonNvme]
+	nvmeDisksInfo := masks.diskInfoByType[disk.Nvme]
+	devicesIRQsDistribution := make(map[int]string)
+	if len(nonNvmeDisksInfo.Devices) > 0 {
+		IRQsDist, err := distributeDevicesIRQs(
+			nonNvmeDisksInfo, irqCPUMask, cpuMasks)
+		if err != nil {
+			return nil, err
+		}
+		for IRQ, mask := range IRQsDist {
+			devicesIRQsDistribution[IRQ] = mask
+		}
+	}
+
+	if len(nvmeDisksInfo.Devices) > 0 {
+		IRQsDist, err := distributeDevicesIRQs(
+			nvmeDisksInfo, finalCpuMask, cpuMasks)
+		if err != nil {
+			return nil, err
+		}
+		for IRQ, mask := range IRQsDist {
+			devicesIRQsDistribution[IRQ] = mask
+		}
+	}
+	log.Debugf("Calculated IRQs distribution %v", devicesIRQsDistribution)
+	return devicesIRQsDistribution, nil
+}
+
+// Returns the mask the IRQs of the devices which aren't NVMe are distributed
+// among, for mode (or the default one for the devices, see GetDefaultMode)
+// and cpuMask. It's the one the IRQs registered after tuning are bound to.
+func GetExpectedIRQsCpuMask(
+	devices []string,
+	blockDevices disk.BlockDevices,
+	mode irq.Mode,
+	cpuMask string,
+	cpuMasks irq.CpuMasks,
+) (string, error) {
+	masks, err := getDisksIRQsCpuMasks(devices, blockDevices, mode, cpuMask, cpuMasks)
+	if err != nil {
+		return "", err
+	}
+	return masks.irqs, nil
+}
+
+type disksIRQsCpuMasks struct {
+	// The CPUs in the cpuMask the tuner was given.
+	base string
+	// The CPUs the IRQs of the devices which aren't NVMe are bound to.
+	irqs           string
+	diskInfoByType map[disk.DiskType]disk.DevicesIRQs
+}
+
+func getDisksIRQsCpuMasks(
+	devices []string,
+	blockDevices disk.BlockDevices,
+	mode irq.Mode,
+	cpuMask string,
+	cpuMasks irq.CpuMasks,
+) (*disksIRQsCpuMasks, error) {
 	finalCpuMask, err := cpuMasks.BaseCpuMask(cpuMask)
 	if err != nil {
 		return nil, err
@@ -208,36 +304,55 @@ func GetExpectedIRQsDistribution(
 		}
 	}
 
-	nonNvmeDisksInfo := diskInfoByType[disk.NonNvme]
-	nvmeDisksInfo := diskInfoByType[disk.Nvme]
 	irqCPUMask, err := cpuMasks.CpuMaskForIRQs(effectiveMode, finalCpuMask)
 	if err != nil {
 		return nil, err
 	}
-	devicesIRQsDistribution := make(map[int]string)
-	if len(nonNvmeDisksInfo.Devices) > 0 {
-		IRQsDist, err := cpuMasks.GetIRQsDistributionMasks(
-			nonNvmeDisksInfo.Irqs, irqCPUMask)
-		if err != nil {
-			return nil, err
+	return &disksIRQsCpuMasks{
+		base:           finalCpuMask,
+		irqs:           irqCPUMask,
+		diskInfoByType: diskInfoByType,
+	}, nil
+}
+
+// Distributes the devices' IRQs among the CPUs in cpuMask. The IRQs of the
+// devices attached to a known NUMA node are distributed among the CPUs in
+// that node only.
+func distributeDevicesIRQs(
+	devicesIRQs disk.DevicesIRQs, cpuMask string, cpuMasks irq.CpuMasks,
+) (map[int]string, error) {
+	var nodes []int
+	IRQsByNode := make(map[int][]int)
+	for _, IRQ := range devicesIRQs.Irqs {
+		node, known := devicesIRQs.NumaNodes[IRQ]
+		if !known {
+			node = -1
 		}
-		for IRQ, mask := range IRQsDist {
-			devicesIRQsDistribution[IRQ] = mask
+		if _, seen := IRQsByNode[node]; !seen {
+			nodes = append(nodes, node)
 		}
+		IRQsByNode[node] = append(IRQsByNode[node], IRQ)
 	}
-
-	if len(nvmeDisksInfo.Devices) > 0 {
+	distribution := make(map[int]string)
+	for _, node := range nodes {
+		nodeCPUMask := cpuMask
+		if node >= 0 {
+			var err error
+			nodeCPUMask, err = cpuMasks.RestrictToNumaNode(cpuMask, node)
+			if err != nil {
+				return nil, err
+			}
+		}
 		IRQsDist, err := cpuMasks.GetIRQsDistributionMasks(
-			nvmeDisksInfo.Irqs, finalCpuMask)
+			IRQsByNode[node], nodeCPUMask)
 		if err != nil {
 			return nil, err
 		}
 		for IRQ, mask := range IRQsDist {
-			devicesIRQsDistribution[IRQ] = mask
+			distribution[IRQ] = mask
 		}
 	}
-	log.Debugf("Calculated IRQs distribution %v", devicesIRQsDistribution)
-	return devicesIRQsDistribution, nil
+	return distribution, nil
 }
 
 func GetDefaultMode(
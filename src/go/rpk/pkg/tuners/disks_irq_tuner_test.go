@@ -22,6 +22,7 @@ type cpuMasksMock struct {
 	baseCpuMask              func(string) (string, error)
 	cpuMaskForIRQs           func(irq.Mode, string) (string, error)
 	getIRQsDistributionMasks func([]int, string) (map[int]string, error)
+	restrictToNumaNode       func(string, int) (string, error)
 }
 
 type blockDevicesMock struct {
@@ -48,6 +49,12 @@ func (m *cpuMasksMock) GetIRQsDistributionMasks(
 	return m.getIRQsDistributionMasks(IRQs, cpuMask)
 }
 
+func (m *cpuMasksMock) RestrictToNumaNode(
+	cpuMask string, node int,
+) (string, error) {
+	return m.restrictToNumaNode(cpuMask, node)
+}
+
 func (m *blockDevicesMock) GetDirectoriesDevices(
 	directories []string,
 ) (map[string][]string, error) {
@@ -138,6 +145,53 @@ func TestGetExpectedIRQsDistribution(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "shall distribute IRQs among their devices' NUMA node CPUs",
+			args: args{
+				devices: []string{"nvme0", "nvme1", "nvme2"},
+				mode:    irq.Mq,
+				cpuMask: "all",
+				blockDevices: &blockDevicesMock{
+					getDiskInfoByType: func([]string) (map[disk.DiskType]disk.DevicesIRQs, error) {
+						return map[disk.DiskType]disk.DevicesIRQs{
+							disk.NonNvme: {},
+							disk.Nvme: {
+								Devices: []string{"nvme0", "nvme1", "nvme2"},
+								Irqs:    []int{12, 15, 18, 24, 30},
+								// IRQ 30's device node is unknown.
+								NumaNodes: map[int]int{12: 0, 15: 0, 18: 1, 24: 1},
+							},
+						}, nil
+					},
+				},
+				cpuMasks: &cpuMasksMock{
+					baseCpuMask: func(string) (string, error) {
+						return "0x000000ff", nil
+					},
+					cpuMaskForIRQs: func(mode irq.Mode, cpuMask string) (string, error) {
+						return cpuMask, nil
+					},
+					restrictToNumaNode: func(cpuMask string, node int) (string, error) {
+						return []string{"0x0000000f", "0x000000f0"}[node], nil
+					},
+					getIRQsDistributionMasks: func(IRQs []int, cpuMask string) (map[int]string, error) {
+						dist := map[int]string{}
+						for _, IRQ := range IRQs {
+							dist[IRQ] = cpuMask
+						}
+						return dist, nil
+					},
+				},
+			},
+			want: map[int]string{
+				12: "0x0000000f",
+				15: "0x0000000f",
+				18: "0x000000f0",
+				24: "0x000000f0",
+				30: "0x000000ff",
+			},
+			wantErr: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
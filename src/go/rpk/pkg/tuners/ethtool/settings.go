@@ -0,0 +1,105 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package ethtool
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// The values 'ethtool -c' reports, keyed by the names 'ethtool -C' sets them
+// with, e.g. 'adaptive-rx' or 'rx-usecs'.
+type Settings map[string]string
+
+var (
+	// e.g. 'Adaptive RX: off  TX: off'
+	rxTxLinePattern = regexp.MustCompile(`^(.*?)\s*RX:\s*(\S+)\s+TX:\s*(\S+)$`)
+	// e.g. 'rx-usecs: 3'
+	keyValueLinePattern = regexp.MustCompile(`^([a-z0-9-]+):\s*(\S+)$`)
+)
+
+// Parses the output of 'ethtool -g <interface>', returning the maximum and
+// current ring sizes, keyed by the names 'ethtool -G' sets them with (e.g.
+// 'rx', 'rx-jumbo'). The fields are driver-dependent: those which aren't
+// numeric, e.g. 'n/a', are skipped.
+func ParseRingParameters(lines []string) (maximums, current map[string]int) {
+	return parseMaximumsAndCurrent(lines)
+}
+
+// Parses the output of 'ethtool -l <interface>', returning the maximum and
+// current channel counts, keyed by the names 'ethtool -L' sets them with
+// (e.g. 'rx', 'combined'). The counts the driver reports as 'n/a' are
+// skipped.
+func ParseChannelParameters(lines []string) (maximums, current map[string]int) {
+	return parseMaximumsAndCurrent(lines)
+}
+
+// Parses the 'Pre-set maximums' and 'Current hardware settings' sections
+// shared by the outputs of 'ethtool -g' and 'ethtool -l'.
+func parseMaximumsAndCurrent(lines []string) (maximums, current map[string]int) {
+	maximums = map[string]int{}
+	current = map[string]int{}
+	var section map[string]int
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "Pre-set maximums"):
+			section = maximums
+			continue
+		case strings.HasPrefix(line, "Current hardware settings"):
+			section = current
+			continue
+		}
+		if section == nil {
+			continue
+		}
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		value, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+		if err != nil {
+			continue
+		}
+		section[settingName(parts[0])] = value
+	}
+	return maximums, current
+}
+
+// Parses the output of 'ethtool -c <interface>'. The fields are
+// driver-dependent: those reported as 'n/a' are skipped.
+func ParseCoalesce(lines []string) Settings {
+	settings := Settings{}
+	set := func(name, value string) {
+		if value != "n/a" {
+			settings[name] = value
+		}
+	}
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if m := rxTxLinePattern.FindStringSubmatch(line); m != nil {
+			prefix := settingName(m[1])
+			set(prefix+"-rx", m[2])
+			set(prefix+"-tx", m[3])
+			continue
+		}
+		if m := keyValueLinePattern.FindStringSubmatch(line); m != nil {
+			set(m[1], m[2])
+		}
+	}
+	return settings
+}
+
+// Converts a field name as printed by ethtool (e.g. 'RX Jumbo') into the
+// one it's set with (e.g. 'rx-jumbo').
+func settingName(field string) string {
+	return strings.Join(strings.Fields(strings.ToLower(field)), "-")
+}
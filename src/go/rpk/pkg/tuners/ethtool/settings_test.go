@@ -0,0 +1,115 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package ethtool_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/ethtool"
+)
+
+func TestParseRingParameters(t *testing.T) {
+	tests := []struct {
+		name             string
+		output           string
+		expectedMaximums map[string]int
+		expectedCurrent  map[string]int
+	}{
+		{
+			name: "it should parse the ring sizes",
+			output: `Ring parameters for eth0:
+Pre-set maximums:
+RX:		4096
+RX Mini:	n/a
+RX Jumbo:	0
+TX:		4096
+Current hardware settings:
+RX:		512
+RX Mini:	n/a
+RX Jumbo:	0
+TX:		1024
+`,
+			expectedMaximums: map[string]int{"rx": 4096, "rx-jumbo": 0, "tx": 4096},
+			expectedCurrent:  map[string]int{"rx": 512, "rx-jumbo": 0, "tx": 1024},
+		},
+		{
+			name: "it should skip the fields which aren't sizes",
+			output: `Ring parameters for ens5:
+Pre-set maximums:
+RX:		16384
+TX:		1024
+Current hardware settings:
+RX:		1024
+TX:		1024
+RX Buf Len:		n/a
+TX Push:	off
+`,
+			expectedMaximums: map[string]int{"rx": 16384, "tx": 1024},
+			expectedCurrent:  map[string]int{"rx": 1024, "tx": 1024},
+		},
+		{
+			name:             "it should return nothing if the output is empty",
+			expectedMaximums: map[string]int{},
+			expectedCurrent:  map[string]int{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			maximums, current := ethtool.ParseRingParameters(
+				strings.Split(tt.output, "\n"),
+			)
+			require.Equal(st, tt.expectedMaximums, maximums)
+			require.Equal(st, tt.expectedCurrent, current)
+		})
+	}
+}
+
+func TestParseCoalesce(t *testing.T) {
+	output := `Coalesce parameters for eth0:
+Adaptive RX: off  TX: n/a
+CQE mode RX: n/a  TX: n/a
+stats-block-usecs: 0
+sample-interval: 0
+pkt-rate-low: n/a
+
+rx-usecs: 3
+rx-frames: 0
+tx-usecs: 0
+`
+	expected := ethtool.Settings{
+		"adaptive-rx":       "off",
+		"stats-block-usecs": "0",
+		"sample-interval":   "0",
+		"rx-usecs":          "3",
+		"rx-frames":         "0",
+		"tx-usecs":          "0",
+	}
+	require.Equal(t, expected, ethtool.ParseCoalesce(strings.Split(output, "\n")))
+}
+
+func TestParseChannelParameters(t *testing.T) {
+	output := `Channel parameters for eth0:
+Pre-set maximums:
+RX:		n/a
+TX:		n/a
+Other:		1
+Combined:	63
+Current hardware settings:
+RX:		n/a
+TX:		n/a
+Other:		1
+Combined:	8
+`
+	maximums, current := ethtool.ParseChannelParameters(strings.Split(output, "\n"))
+	require.Equal(t, map[string]int{"other": 1, "combined": 63}, maximums)
+	require.Equal(t, map[string]int{"other": 1, "combined": 8}, current)
+}
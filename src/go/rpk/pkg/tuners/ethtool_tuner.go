@@ -0,0 +1,357 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners
+
+import (
+	"fmt"
+	osexec "os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/os"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/ethtool"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors/commands"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/irq"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/network"
+)
+
+// The ring buffers which are grown to the maximum size the NIC supports.
+var RecommendedRingSizesToMax = []string{"rx", "tx"}
+
+// The interrupt coalescing settings applied, if the NIC reports them.
+// Adaptive coalescing lets the driver batch interrupts under load, while
+// keeping the latency low when the traffic is light.
+var RecommendedCoalesce = ethtool.Settings{
+	"adaptive-rx": "on",
+	"adaptive-tx": "on",
+}
+
+// A group of NIC settings that ethtool reads with an option and changes with
+// another one.
+type ethtoolSettings struct {
+	checkerID CheckerID
+	name      string
+	getOption string
+	setOption string
+	// Returns the current and recommended values of the settings reported
+	// in the output of 'ethtool <getOption>'.
+	recommend func(lines []string) (current, required ethtool.Settings)
+}
+
+// Returns the settings growing the ring buffers to the given sizes, or to
+// their maximum one for those which aren't given. Sizes are capped to the
+// maximum.
+func newRingSettings(sizes map[string]int) ethtoolSettings {
+	return ethtoolSettings{
+		checkerID: NicRingsChecker,
+		name:      "ring sizes",
+		getOption: "-g",
+		setOption: "-G",
+		recommend: func(lines []string) (current, required ethtool.Settings) {
+			maximums, currentSizes := ethtool.ParseRingParameters(lines)
+			current, required = ethtool.Settings{}, ethtool.Settings{}
+			for _, ring := range RecommendedRingSizesToMax {
+				max, maxOk := maximums[ring]
+				size, sizeOk := currentSizes[ring]
+				if !maxOk || !sizeOk || max == 0 {
+					continue
+				}
+				target := max
+				if wanted, ok := sizes[ring]; ok && wanted < max {
+					target = wanted
+				}
+				current[ring] = fmt.Sprint(size)
+				required[ring] = fmt.Sprint(target)
+			}
+			return current, required
+		},
+	}
+}
+
+var (
+	coalesceSettings = ethtoolSettings{
+		checkerID: NicCoalesceChecker,
+		name:      "interrupt coalescing",
+		getOption: "-c",
+		setOption: "-C",
+		recommend: func(lines []string) (current, required ethtool.Settings) {
+			reported := ethtool.ParseCoalesce(lines)
+			current, required = ethtool.Settings{}, ethtool.Settings{}
+			for name, value := range RecommendedCoalesce {
+				if currentValue, ok := reported[name]; ok {
+					current[name] = currentValue
+					required[name] = value
+				}
+			}
+			return current, required
+		},
+	}
+)
+
+// Returns the HW interfaces among the given ones, replacing bonds with their
+// slaves. Virtual interfaces (e.g. loopback or bridges) are skipped.
+func PhysicalNics(
+	fs afero.Fs,
+	irqProcFile irq.ProcFile,
+	irqDeviceInfo irq.DeviceInfo,
+	ethtoolWrapper ethtool.EthtoolWrapper,
+	interfaces []string,
+) []network.Nic {
+	var nics []network.Nic
+	for _, iface := range interfaces {
+		nic := network.NewNic(fs, irqProcFile, irqDeviceInfo, ethtoolWrapper, iface)
+		nics = append(nics, physicalNics(nic)...)
+	}
+	return nics
+}
+
+func physicalNics(nic network.Nic) []network.Nic {
+	if nic.IsHwInterface() {
+		return []network.Nic{nic}
+	}
+	if !nic.IsBondIface() {
+		log.Debugf("Skipping '%s' virtual interface", nic.Name())
+		return nil
+	}
+	slaves, err := nic.Slaves()
+	if err != nil {
+		log.Errorf("Couldn't get the slaves of '%s': %v", nic.Name(), err)
+		return nil
+	}
+	var nics []network.Nic
+	for _, slave := range slaves {
+		nics = append(nics, physicalNics(slave)...)
+	}
+	return nics
+}
+
+type ethtoolChecker struct {
+	proc     os.Proc
+	timeout  time.Duration
+	nic      string
+	settings ethtoolSettings
+}
+
+func (c *ethtoolChecker) Id() CheckerID {
+	return c.settings.checkerID
+}
+
+func (c *ethtoolChecker) GetDesc() string {
+	return fmt.Sprintf("NIC %s %s", c.nic, c.settings.name)
+}
+
+func (c *ethtoolChecker) GetSeverity() Severity {
+	return Warning
+}
+
+func (c *ethtoolChecker) GetRequiredAsString() string {
+	_, required, err := c.read()
+	if err != nil || len(required) == 0 {
+		return "recommended values"
+	}
+	return formatEthtoolSettings(required)
+}
+
+// Interfaces whose driver doesn't report the settings pass the check, as
+// there's nothing to tune.
+func (c *ethtoolChecker) Check() *CheckResult {
+	res := &CheckResult{
+		CheckerId: c.Id(),
+		Desc:      c.GetDesc(),
+		Severity:  c.GetSeverity(),
+	}
+	current, required, err := c.read()
+	if isUnsupportedByDriver(err) {
+		res.IsOk = true
+		res.Current = "unsupported by the driver"
+		res.Required = res.Current
+		return res
+	}
+	if err != nil {
+		res.Err = err
+		return res
+	}
+	if len(required) == 0 {
+		res.IsOk = true
+		res.Current = "not reported by the driver"
+		res.Required = res.Current
+		return res
+	}
+	res.Current = formatEthtoolSettings(current)
+	res.Required = formatEthtoolSettings(required)
+	res.IsOk = len(changedEthtoolSettings(current, required)) == 0
+	return res
+}
+
+// Returns the current and recommended values of the settings.
+func (c *ethtoolChecker) read() (current, required ethtool.Settings, err error) {
+	lines, err := c.proc.RunWithSystemLdPath(
+		c.timeout,
+		"ethtool",
+		c.settings.getOption,
+		c.nic,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	current, required = c.settings.recommend(lines)
+	return current, required, nil
+}
+
+func newEthtoolTunable(
+	proc os.Proc,
+	timeout time.Duration,
+	nic string,
+	settings ethtoolSettings,
+	executor executors.Executor,
+) Tunable {
+	checker := &ethtoolChecker{
+		proc:     proc,
+		timeout:  timeout,
+		nic:      nic,
+		settings: settings,
+	}
+	return newCheckedEthtoolTunable(checker, executor)
+}
+
+func newCheckedEthtoolTunable(
+	checker *ethtoolChecker, executor executors.Executor,
+) Tunable {
+	return NewCheckedTunable(
+		checker,
+		func() TuneResult {
+			current, required, err := checker.read()
+			if err != nil {
+				return NewTuneError(err)
+			}
+			changes := changedEthtoolSettings(current, required)
+			log.Infof(
+				"Changing '%s' %s from '%s' to '%s'",
+				checker.nic,
+				checker.settings.name,
+				formatEthtoolSettings(current),
+				formatEthtoolSettings(required),
+			)
+			err = executor.Execute(commands.NewEthtoolSetCmd(
+				checker.proc,
+				checker.timeout,
+				checker.settings.setOption,
+				checker.nic,
+				changes,
+			))
+			if isUnsupportedByDriver(err) {
+				log.Infof(
+					"Skipping '%s' %s, as its driver doesn't support changing them: %v",
+					checker.nic,
+					checker.settings.name,
+					err,
+				)
+				return NewUnchangedTuneResult()
+			}
+			if err != nil {
+				return NewTuneError(err)
+			}
+			return NewTuneResult(false)
+		},
+		func() (bool, string) {
+			if _, err := osexec.LookPath("ethtool"); err != nil {
+				return false, "ethtool isn't installed"
+			}
+			return true, ""
+		},
+		executor.IsLazy(),
+	)
+}
+
+// Creates a tuner growing the ring buffers of the given NICs (see
+// PhysicalNics) to the given sizes, or to their maximum one for the rings
+// which aren't given (see RecommendedRingSizesToMax), and applying the
+// recommended interrupt coalescing settings (see RecommendedCoalesce). The
+// settings a NIC's driver doesn't report or support are skipped.
+func NewEthtoolTuner(
+	nics []network.Nic,
+	ringSizes map[string]int,
+	proc os.Proc,
+	timeout time.Duration,
+	executor executors.Executor,
+) Tunable {
+	ringSettings := newRingSettings(ringSizes)
+	var tunables []Tunable
+	for _, nic := range nics {
+		for _, settings := range []ethtoolSettings{ringSettings, coalesceSettings} {
+			tunables = append(
+				tunables,
+				newEthtoolTunable(proc, timeout, nic.Name(), settings, executor),
+			)
+		}
+	}
+	return NewAggregatedTunable(tunables)
+}
+
+func NewNicRingsCheckers(
+	nics []network.Nic, proc os.Proc, timeout time.Duration,
+) []Checker {
+	return newEthtoolCheckers(nics, proc, timeout, newRingSettings(nil))
+}
+
+func NewNicCoalesceCheckers(
+	nics []network.Nic, proc os.Proc, timeout time.Duration,
+) []Checker {
+	return newEthtoolCheckers(nics, proc, timeout, coalesceSettings)
+}
+
+func newEthtoolCheckers(
+	nics []network.Nic,
+	proc os.Proc,
+	timeout time.Duration,
+	settings ethtoolSettings,
+) []Checker {
+	var checkers []Checker
+	for _, nic := range nics {
+		checkers = append(checkers, &ethtoolChecker{
+			proc:     proc,
+			timeout:  timeout,
+			nic:      nic.Name(),
+			settings: settings,
+		})
+	}
+	return checkers
+}
+
+// Returns the settings whose current value differs from the required one.
+func changedEthtoolSettings(current, required ethtool.Settings) ethtool.Settings {
+	changes := ethtool.Settings{}
+	for name, value := range required {
+		if current[name] != value {
+			changes[name] = value
+		}
+	}
+	return changes
+}
+
+// Formats settings as 'name value' pairs, sorted by name.
+func formatEthtoolSettings(settings ethtool.Settings) string {
+	var pairs []string
+	for name, value := range settings {
+		pairs = append(pairs, fmt.Sprintf("%s %s", name, value))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ", ")
+}
+
+// ethtool fails with EOPNOTSUPP when the driver doesn't implement the
+// operation.
+func isUnsupportedByDriver(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "Operation not supported")
+}
@@ -0,0 +1,143 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/ethtool"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/irq"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/network"
+)
+
+// Returns the output of 'ethtool <args>' from outputs, failing like ethtool
+// does for the drivers which lack an operation if there's none.
+type ethtoolProcMock struct {
+	outputs map[string]string
+}
+
+func (p *ethtoolProcMock) RunWithSystemLdPath(
+	_ time.Duration, _ string, args ...string,
+) ([]string, error) {
+	output, ok := p.outputs[strings.Join(args, " ")]
+	if !ok {
+		return nil, errors.New("err=exit status 94, stderr=Operation not supported")
+	}
+	return strings.Split(output, "\n"), nil
+}
+
+func (*ethtoolProcMock) IsRunning(_ time.Duration, _ string) bool {
+	return false
+}
+
+const eth0Rings = `Ring parameters for eth0:
+Pre-set maximums:
+RX:		4096
+RX Mini:	n/a
+RX Jumbo:	0
+TX:		4096
+Current hardware settings:
+RX:		512
+RX Mini:	n/a
+RX Jumbo:	0
+TX:		4096
+`
+
+func physicalNics(t *testing.T, fs afero.Fs, interfaces ...string) []network.Nic {
+	require.NoError(t, fs.MkdirAll("/sys/class/net/eth0/device", 0755))
+	require.NoError(t, fs.MkdirAll("/sys/class/net/lo", 0755))
+	eth, err := ethtool.NewEthtoolWrapper()
+	require.NoError(t, err)
+	procFile := irq.NewProcFile(fs)
+	return tuners.PhysicalNics(
+		fs,
+		procFile,
+		irq.NewDeviceInfo(fs, procFile),
+		eth,
+		interfaces,
+	)
+}
+
+func TestEthtoolTuner(t *testing.T) {
+	const scriptPath = "/tune.sh"
+	fs := afero.NewMemMapFs()
+	nics := physicalNics(t, fs, "eth0", "lo")
+	require.Len(t, nics, 1)
+	proc := &ethtoolProcMock{outputs: map[string]string{"-g eth0": eth0Rings}}
+	tuner := tuners.NewEthtoolTuner(
+		nics,
+		nil,
+		proc,
+		time.Second,
+		executors.NewScriptRenderingExecutor(fs, scriptPath),
+	)
+	res := tuner.Tune()
+	require.NoError(t, res.Error())
+	require.True(t, res.IsChanged())
+
+	script, err := afero.ReadFile(fs, scriptPath)
+	require.NoError(t, err)
+	// The coalesce settings are skipped, as the driver doesn't support
+	// reading them.
+	require.True(
+		t,
+		strings.HasSuffix(string(script), "\nethtool -G eth0 rx 4096\n"),
+		string(script),
+	)
+}
+
+func TestEthtoolTunerRingSizes(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	nics := physicalNics(t, fs, "eth0")
+	proc := &ethtoolProcMock{outputs: map[string]string{"-g eth0": eth0Rings}}
+	// The tx ring is already at its maximum, so it's capped to it.
+	tuner := tuners.NewEthtoolTuner(
+		nics,
+		map[string]int{"rx": 1024, "tx": 8192},
+		proc,
+		time.Second,
+		executors.NewDryRunExecutor(),
+	)
+	results, err := tuners.CheckTunable(tuner)
+	require.NoError(t, err)
+	require.Equal(t, "rx 512, tx 4096", results[0].Current)
+	require.Equal(t, "rx 1024, tx 4096", results[0].Required)
+}
+
+func TestEthtoolCheckers(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	nics := physicalNics(t, fs, "eth0")
+	proc := &ethtoolProcMock{outputs: map[string]string{
+		"-g eth0": eth0Rings,
+		"-c eth0": "Coalesce parameters for eth0:\nAdaptive RX: on  TX: n/a\nrx-usecs: 3\n",
+	}}
+
+	rings := tuners.NewNicRingsCheckers(nics, proc, time.Second)
+	require.Len(t, rings, 1)
+	res := rings[0].Check()
+	require.NoError(t, res.Err)
+	require.False(t, res.IsOk)
+	require.Equal(t, "rx 512, tx 4096", res.Current)
+	require.Equal(t, "rx 4096, tx 4096", res.Required)
+
+	coalesce := tuners.NewNicCoalesceCheckers(nics, proc, time.Second)
+	require.Len(t, coalesce, 1)
+	res = coalesce[0].Check()
+	require.NoError(t, res.Err)
+	require.True(t, res.IsOk)
+	require.Equal(t, "adaptive-rx on", res.Current)
+}
@@ -0,0 +1,63 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package executors
+
+import (
+	"context"
+	"sync"
+
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors/commands"
+)
+
+type collectingExecutor struct {
+	executor Executor
+	mu       sync.Mutex
+	results  []commands.Result
+}
+
+// Wraps executor, keeping the results of the commands executed through it
+// alone, e.g. to tell whether a single tuner changed anything while others
+// share the wrapped executor. The commands which don't report their results
+// (see commands.ResultReporter) are assumed to have changed their target.
+// With a lazy executor, nothing is executed, so no results are kept.
+func NewCollectingExecutor(executor Executor) ResultCollector {
+	return &collectingExecutor{executor: executor}
+}
+
+func (e *collectingExecutor) Execute(cmd commands.Command) error {
+	return e.ExecuteContext(context.Background(), cmd)
+}
+
+func (e *collectingExecutor) ExecuteContext(
+	ctx context.Context, cmd commands.Command,
+) error {
+	err := ExecuteContext(ctx, e.executor, cmd)
+	if err != nil || e.executor.IsLazy() {
+		return err
+	}
+	res := commands.Result{Target: cmd.Describe().Target, Changed: true}
+	if r, ok := cmd.(commands.ResultReporter); ok {
+		res = r.Result()
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.results = append(e.results, res)
+	return nil
+}
+
+func (e *collectingExecutor) Results() []commands.Result {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]commands.Result(nil), e.results...)
+}
+
+func (e *collectingExecutor) IsLazy() bool {
+	return e.executor.IsLazy()
+}
@@ -0,0 +1,54 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package executors_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors/commands"
+)
+
+func TestCollectingExecutor(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/some/file", []byte("1"), 0644))
+	shared := executors.NewDirectExecutor()
+	e := executors.NewCollectingExecutor(shared)
+
+	require.NoError(t, e.Execute(commands.NewWriteFileCmd(fs, "/some/file", "1")))
+	require.NoError(t, e.Execute(&sleepCommand{}))
+	require.Error(t, e.Execute(&sleepCommand{err: errors.New("boom")}))
+	// Commands executed through the shared executor alone aren't kept.
+	require.NoError(t, shared.Execute(commands.NewWriteFileCmd(fs, "/other", "2")))
+
+	require.Equal(
+		t,
+		[]commands.Result{
+			{Target: "/some/file", Changed: false, Old: "1", New: "1"},
+			// A command which doesn't report its result is assumed to
+			// have changed its target.
+			{Changed: true},
+		},
+		e.Results(),
+	)
+}
+
+func TestCollectingExecutorLazy(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	e := executors.NewCollectingExecutor(
+		executors.NewScriptRenderingExecutor(fs, "/tune.sh"),
+	)
+	require.True(t, e.IsLazy())
+	require.NoError(t, e.Execute(commands.NewWriteFileCmd(fs, "/some/file", "1")))
+	require.Empty(t, e.Results())
+}
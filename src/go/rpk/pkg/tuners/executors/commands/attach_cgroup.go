@@ -0,0 +1,123 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+)
+
+type attachCgroupCommand struct {
+	fs     afero.Fs
+	dir    string
+	pid    int
+	result Result
+}
+
+// Moves the process with the given PID, along with all of its threads, into
+// the cgroup at dir, by writing the PID to its cgroup.procs file. It's not
+// reversible, as the cgroup the process leaves may not exist anymore by the
+// time it's moved back.
+func NewAttachCgroupCmd(fs afero.Fs, dir string, pid int) Command {
+	return &attachCgroupCommand{fs: fs, dir: dir, pid: pid}
+}
+
+func (c *attachCgroupCommand) Execute() error {
+	c.result = Result{Target: c.procsPath(), New: strconv.Itoa(c.pid)}
+	attached, err := c.isAttached()
+	if err != nil {
+		return err
+	}
+	if attached {
+		log.Debugf("Process %d is already in '%s', no change needed", c.pid, c.dir)
+		return nil
+	}
+	log.Debugf("Moving process %d into '%s'", c.pid, c.dir)
+	// cgroup.procs can't be truncated: each write moves a process in.
+	file, err := c.fs.OpenFile(c.procsPath(), os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = file.WriteString(c.result.New)
+	if err != nil {
+		return fmt.Errorf("couldn't move process %d into '%s': %w", c.pid, c.dir, err)
+	}
+	c.result.Changed = true
+	return nil
+}
+
+func (c *attachCgroupCommand) RenderScript(w *bufio.Writer) error {
+	return c.RenderScriptContext(nil, w)
+}
+
+func (c *attachCgroupCommand) RenderScriptContext(
+	ctx *RenderContext, w *bufio.Writer,
+) error {
+	fmt.Fprintf(w, "echo %d > %s\n", c.pid, ctx.Path(c.procsPath()))
+	return w.Flush()
+}
+
+func (c *attachCgroupCommand) Describe() Description {
+	return Description{
+		Type:   "attach_cgroup",
+		Target: c.procsPath(),
+		Args:   []string{strconv.Itoa(c.pid)},
+		Desc:   fmt.Sprintf("Move process %d into the cgroup '%s'", c.pid, c.dir),
+	}
+}
+
+func (c *attachCgroupCommand) Preview() (Result, error) {
+	res := Result{Target: c.procsPath(), New: strconv.Itoa(c.pid), Changed: true}
+	attached, err := c.isAttached()
+	if err != nil {
+		return res, err
+	}
+	res.Changed = !attached
+	return res, nil
+}
+
+func (c *attachCgroupCommand) Result() Result {
+	return c.result
+}
+
+func (c *attachCgroupCommand) procsPath() string {
+	return filepath.Join(c.dir, "cgroup.procs")
+}
+
+// Returns whether the process is listed in the cgroup's cgroup.procs. It's
+// missing until the cgroup is created.
+func (c *attachCgroupCommand) isAttached() (bool, error) {
+	content, err := afero.ReadFile(c.fs, c.procsPath())
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return IsCgroupMember(string(content), c.pid), nil
+}
+
+// Returns whether pid is listed in content, read from a cgroup.procs file.
+func IsCgroupMember(content string, pid int) bool {
+	for _, field := range strings.Fields(content) {
+		if field == strconv.Itoa(pid) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package commands_test
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors/commands"
+)
+
+func TestAttachCgroupCmdExecute(t *testing.T) {
+	tests := []struct {
+		name     string
+		procs    string
+		expected string
+		changed  bool
+	}{
+		{
+			name:     "it should write the PID to cgroup.procs",
+			procs:    "",
+			expected: "1234",
+			changed:  true,
+		},
+		{
+			name:     "it should be a no-op if the process is in the cgroup",
+			procs:    "1\n1234\n",
+			expected: "1\n1234\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			fs := afero.NewMemMapFs()
+			path := "/sys/fs/cgroup/redpanda/cgroup.procs"
+			require.NoError(st, afero.WriteFile(fs, path, []byte(tt.procs), 0644))
+			cmd := commands.NewAttachCgroupCmd(fs, "/sys/fs/cgroup/redpanda", 1234)
+			require.NoError(st, cmd.Execute())
+			require.Equal(st, tt.changed, cmd.(commands.ResultReporter).Result().Changed)
+			procs, err := afero.ReadFile(fs, path)
+			require.NoError(st, err)
+			require.Equal(st, tt.expected, string(procs))
+		})
+	}
+}
+
+func TestAttachCgroupCmdRender(t *testing.T) {
+	cmd := commands.NewAttachCgroupCmd(afero.NewMemMapFs(), "/sys/fs/cgroup/redpanda", 1234)
+	var buf bytes.Buffer
+	require.NoError(t, cmd.RenderScript(bufio.NewWriter(&buf)))
+	require.Equal(t, "echo 1234 > /sys/fs/cgroup/redpanda/cgroup.procs\n", buf.String())
+}
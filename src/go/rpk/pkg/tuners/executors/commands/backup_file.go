@@ -45,3 +45,11 @@ func (c *backupFileCommand) RenderScript(w *bufio.Writer) error {
 	fmt.Fprintf(w, "cp %s %s.vectorized.${md_5}.bk\n", c.path, c.path)
 	return w.Flush()
 }
+
+func (c *backupFileCommand) Describe() Description {
+	return Description{
+		Type:   "backup_file",
+		Target: c.path,
+		Desc:   fmt.Sprintf("Back up '%s'", c.path),
+	}
+}
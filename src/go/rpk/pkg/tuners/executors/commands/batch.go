@@ -0,0 +1,174 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package commands
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type batchCommand struct {
+	cmds []Command
+}
+
+// Creates a command which applies cmds in order, as a unit. If one of them
+// fails, the ones applied before it are reverted, in reverse order, using
+// their inverse commands (see Reversible). Commands which aren't reversible
+// are executed but can't be reverted.
+// The rendered script runs the commands in a subshell with 'set -e', and
+// traps errors to run the rollback of the commands applied so far.
+func NewBatchCmd(cmds ...Command) Command {
+	return &batchCommand{cmds: cmds}
+}
+
+func (c *batchCommand) Execute() error {
+	return c.ExecuteThrough(Command.Execute)
+}
+
+// Executes the commands, and the rollback if one of them fails, through run.
+func (c *batchCommand) ExecuteThrough(run func(Command) error) error {
+	var applied []Command
+	for i, cmd := range c.cmds {
+		var inverse Command
+		if r, ok := cmd.(Reversible); ok {
+			var err error
+			inverse, err = r.Inverse()
+			if err != nil {
+				return c.rollback(run, applied, i, cmd, err)
+			}
+		}
+		err := run(cmd)
+		if err != nil {
+			return c.rollback(run, applied, i, cmd, err)
+		}
+		if inverse != nil {
+			applied = append(applied, inverse)
+		}
+	}
+	return nil
+}
+
+func (c *batchCommand) rollback(
+	run func(Command) error,
+	applied []Command,
+	index int,
+	failed Command,
+	cause error,
+) error {
+	err := fmt.Errorf(
+		"batch command %d ('%s') failed: %w",
+		index,
+		failed.Describe().Desc,
+		cause,
+	)
+	for i := len(applied) - 1; i >= 0; i-- {
+		log.Debugf("Rolling back: %s", applied[i].Describe().Desc)
+		rerr := run(applied[i])
+		if rerr != nil {
+			return fmt.Errorf(
+				"%v; rolling back '%s' also failed: %w",
+				err,
+				applied[i].Describe().Desc,
+				rerr,
+			)
+		}
+	}
+	return err
+}
+
+func (c *batchCommand) RenderScript(w *bufio.Writer) error {
+	return c.RenderScriptContext(nil, w)
+}
+
+// Renders the commands, and their rollback, in ctx.
+func (c *batchCommand) RenderScriptContext(ctx *RenderContext, w *bufio.Writer) error {
+	var body strings.Builder
+	var undo []string
+	for i, cmd := range c.cmds {
+		script, err := renderToString(ctx, cmd)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&body, "%sapplied=%d\n", script, i+1)
+
+		r, ok := cmd.(Reversible)
+		if !ok {
+			continue
+		}
+		inverse, err := r.Inverse()
+		if err != nil {
+			// The state can't be read on the host rendering the script, so
+			// there's nothing to roll back to.
+			log.Debugf("Can't render the rollback of '%s': %v", cmd.Describe().Desc, err)
+			continue
+		}
+		inverseScript, err := renderToString(ctx, inverse)
+		if err != nil {
+			return err
+		}
+		undo = append(undo, fmt.Sprintf(
+			"if [ \"$applied\" -ge %d ]; then\n%sfi\n",
+			i+1,
+			inverseScript,
+		))
+	}
+	fmt.Fprintln(w, "(")
+	fmt.Fprintln(w, "set -e")
+	fmt.Fprintln(w, "applied=0")
+	fmt.Fprintln(w, "rollback() {")
+	for i := len(undo) - 1; i >= 0; i-- {
+		fmt.Fprint(w, undo[i])
+	}
+	fmt.Fprintln(w, ":")
+	fmt.Fprintln(w, "}")
+	fmt.Fprintln(w, "trap 'rollback; exit 1' ERR")
+	fmt.Fprint(w, body.String())
+	fmt.Fprintln(w, ")")
+	return w.Flush()
+}
+
+func (c *batchCommand) Commands() []Command {
+	return append([]Command(nil), c.cmds...)
+}
+
+func (c *batchCommand) Describe() Description {
+	var descs []string
+	for _, cmd := range c.cmds {
+		descs = append(descs, cmd.Describe().Desc)
+	}
+	return Description{
+		Type: "batch",
+		Args: descs,
+		Desc: fmt.Sprintf("Apply atomically: %s", strings.Join(descs, "; ")),
+	}
+}
+
+// Renders cmd's script in ctx, ensuring it ends in a newline.
+func renderToString(ctx *RenderContext, cmd Command) (string, error) {
+	var buf bytes.Buffer
+	w := bufio.NewWriter(&buf)
+	err := RenderScriptContext(ctx, cmd, w)
+	if err != nil {
+		return "", err
+	}
+	err = w.Flush()
+	if err != nil {
+		return "", err
+	}
+	script := buf.String()
+	if script != "" && !strings.HasSuffix(script, "\n") {
+		script += "\n"
+	}
+	return script, nil
+}
@@ -0,0 +1,118 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package commands_test
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors/commands"
+)
+
+type failingCommand struct {
+	commands.Command
+}
+
+func (*failingCommand) Execute() error {
+	return errors.New("device busy")
+}
+
+func (*failingCommand) RenderScript(w *bufio.Writer) error {
+	_, err := w.WriteString("false\n")
+	return err
+}
+
+func (*failingCommand) Describe() commands.Description {
+	return commands.Description{Type: "fail", Desc: "Fail"}
+}
+
+func TestBatchCmdExecute(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	paths := []string{"/proc/irq/1/smp_affinity", "/proc/irq/2/smp_affinity"}
+	for _, p := range paths {
+		require.NoError(t, afero.WriteFile(fs, p, []byte("ff"), 0644))
+	}
+
+	cmd := commands.NewBatchCmd(
+		commands.NewWriteFileCmd(fs, paths[0], "1"),
+		commands.NewWriteFileCmd(fs, paths[1], "2"),
+	)
+	require.NoError(t, cmd.Execute())
+
+	for i, p := range paths {
+		content, err := afero.ReadFile(fs, p)
+		require.NoError(t, err)
+		require.Equal(t, []string{"1", "2"}[i], string(content))
+	}
+}
+
+func TestBatchCmdExecuteRollsBackOnFailure(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	paths := []string{"/proc/irq/1/smp_affinity", "/proc/irq/2/smp_affinity"}
+	for _, p := range paths {
+		require.NoError(t, afero.WriteFile(fs, p, []byte("ff"), 0644))
+	}
+	newFile := "/etc/new.conf"
+
+	cmd := commands.NewBatchCmd(
+		commands.NewWriteFileCmd(fs, paths[0], "1"),
+		commands.NewWriteFileCmd(fs, newFile, "a=b"),
+		commands.NewWriteFileCmd(fs, paths[1], "2"),
+		&failingCommand{},
+		commands.NewWriteFileCmd(fs, paths[0], "3"),
+	)
+	err := cmd.Execute()
+	require.EqualError(t, err, "batch command 3 ('Fail') failed: device busy")
+
+	for _, p := range paths {
+		content, err := afero.ReadFile(fs, p)
+		require.NoError(t, err)
+		require.Equal(t, "ff", string(content))
+	}
+	exists, err := afero.Exists(fs, newFile)
+	require.NoError(t, err)
+	require.False(t, exists)
+}
+
+func TestBatchCmdRender(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	path := "/proc/irq/1/smp_affinity"
+	require.NoError(t, afero.WriteFile(fs, path, []byte("ff\n"), 0644))
+
+	cmd := commands.NewBatchCmd(
+		commands.NewWriteFileCmd(fs, path, "1"),
+		&failingCommand{},
+	)
+	var buf bytes.Buffer
+	w := bufio.NewWriter(&buf)
+	require.NoError(t, cmd.RenderScript(w))
+
+	expected := `(
+set -e
+applied=0
+rollback() {
+if [ "$applied" -ge 1 ]; then
+echo 'ff' > /proc/irq/1/smp_affinity
+fi
+:
+}
+trap 'rollback; exit 1' ERR
+echo '1' > /proc/irq/1/smp_affinity
+applied=1
+false
+applied=2
+)
+`
+	require.Equal(t, expected, buf.String())
+}
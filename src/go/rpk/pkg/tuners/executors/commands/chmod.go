@@ -0,0 +1,66 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+)
+
+type chmodCommand struct {
+	fs   afero.Fs
+	path string
+	mode os.FileMode
+}
+
+// Changes the mode of the file at the given path. Like WriteFileCmd, it
+// follows symlinks, so the mode of the link's target is changed.
+func NewChmodCmd(fs afero.Fs, path string, mode os.FileMode) Command {
+	return &chmodCommand{fs: fs, path: path, mode: mode}
+}
+
+func (c *chmodCommand) Execute() error {
+	log.Debugf("Changing the mode of '%s' to '%04o'", c.path, uint32(c.mode.Perm()))
+	return c.fs.Chmod(c.path, c.mode)
+}
+
+func (c *chmodCommand) RenderScript(w *bufio.Writer) error {
+	fmt.Fprintf(w, "chmod %04o %s\n", uint32(c.mode.Perm()), c.path)
+	return w.Flush()
+}
+
+func (c *chmodCommand) Describe() Description {
+	return Description{
+		Type:   "chmod",
+		Target: c.path,
+		Args:   []string{fmt.Sprintf("%04o", uint32(c.mode.Perm()))},
+		Desc: fmt.Sprintf(
+			"Change the mode of '%s' to '%04o'",
+			c.path,
+			uint32(c.mode.Perm()),
+		),
+	}
+}
+
+func (c *chmodCommand) Inverse() (Command, error) {
+	info, err := c.fs.Stat(c.path)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"couldn't read the current mode of '%s': %w",
+			c.path,
+			err,
+		)
+	}
+	return NewChmodCmd(c.fs, c.path, info.Mode()), nil
+}
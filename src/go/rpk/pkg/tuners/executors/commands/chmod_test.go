@@ -0,0 +1,108 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package commands_test
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors/commands"
+)
+
+func TestChmodCmdRender(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     os.FileMode
+		expected string
+	}{
+		{
+			name:     "it should render the mode with a leading zero",
+			mode:     0644,
+			expected: "chmod 0644 /some/file\n",
+		},
+		{
+			name:     "it should pad short modes",
+			mode:     0007,
+			expected: "chmod 0007 /some/file\n",
+		},
+		{
+			name:     "it should only render the permission bits",
+			mode:     04755,
+			expected: "chmod 0755 /some/file\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			cmd := commands.NewChmodCmd(afero.NewMemMapFs(), "/some/file", tt.mode)
+			var buf bytes.Buffer
+			w := bufio.NewWriter(&buf)
+			require.NoError(st, cmd.RenderScript(w))
+			require.Equal(st, tt.expected, buf.String())
+		})
+	}
+}
+
+func TestChmodCmdExecute(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	path := "/some/file"
+	require.NoError(t, afero.WriteFile(fs, path, []byte{}, 0600))
+
+	require.NoError(t, commands.NewChmodCmd(fs, path, 0640).Execute())
+
+	info, err := fs.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0640), info.Mode().Perm())
+}
+
+func TestChmodCmdInverse(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	path := "/some/file"
+	require.NoError(t, afero.WriteFile(fs, path, []byte{}, 0600))
+
+	cmd := commands.NewChmodCmd(fs, path, 0640)
+	inverse, err := cmd.(commands.Reversible).Inverse()
+	require.NoError(t, err)
+	require.NoError(t, cmd.Execute())
+	require.NoError(t, inverse.Execute())
+
+	info, err := fs.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
+
+func TestChmodCmdExecuteNonExistentPath(t *testing.T) {
+	err := commands.NewChmodCmd(afero.NewMemMapFs(), "/doesnt/exist", 0644).Execute()
+	require.True(t, os.IsNotExist(err))
+}
+
+func TestChownCmdRender(t *testing.T) {
+	cmd := commands.NewChownCmd(afero.NewMemMapFs(), "/some/file", 1000, 100)
+	var buf bytes.Buffer
+	w := bufio.NewWriter(&buf)
+	require.NoError(t, cmd.RenderScript(w))
+	require.Equal(t, "chown 1000:100 /some/file\n", buf.String())
+}
+
+func TestChownCmdExecute(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	path := "/some/file"
+	require.NoError(t, afero.WriteFile(fs, path, []byte{}, 0600))
+
+	require.NoError(t, commands.NewChownCmd(fs, path, 1000, 100).Execute())
+}
+
+func TestChownCmdExecuteNonExistentPath(t *testing.T) {
+	err := commands.NewChownCmd(afero.NewMemMapFs(), "/doesnt/exist", 1000, 100).Execute()
+	require.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,56 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"strconv"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+)
+
+type chownCommand struct {
+	fs   afero.Fs
+	path string
+	uid  int
+	gid  int
+}
+
+// Changes the owner and group of the file at the given path. Like
+// WriteFileCmd, it follows symlinks, so the link's target is changed.
+func NewChownCmd(fs afero.Fs, path string, uid, gid int) Command {
+	return &chownCommand{fs: fs, path: path, uid: uid, gid: gid}
+}
+
+func (c *chownCommand) Execute() error {
+	log.Debugf("Changing the owner of '%s' to '%d:%d'", c.path, c.uid, c.gid)
+	return c.fs.Chown(c.path, c.uid, c.gid)
+}
+
+func (c *chownCommand) RenderScript(w *bufio.Writer) error {
+	fmt.Fprintf(w, "chown %d:%d %s\n", c.uid, c.gid, c.path)
+	return w.Flush()
+}
+
+func (c *chownCommand) Describe() Description {
+	return Description{
+		Type:   "chown",
+		Target: c.path,
+		Args:   []string{strconv.Itoa(c.uid), strconv.Itoa(c.gid)},
+		Desc: fmt.Sprintf(
+			"Change the owner of '%s' to '%d:%d'",
+			c.path,
+			c.uid,
+			c.gid,
+		),
+	}
+}
@@ -9,9 +9,50 @@
 
 package commands
 
-import "bufio"
+import (
+	"bufio"
+	"context"
+)
 
 type Command interface {
 	Execute() error
 	RenderScript(*bufio.Writer) error
+	Describe() Description
+}
+
+// ContextCommand is implemented by commands which can be aborted through a
+// context, e.g. those launching a process, which is killed when the context
+// is done.
+type ContextCommand interface {
+	Command
+	ExecuteContext(ctx context.Context) error
+}
+
+// Composite is implemented by commands made of others, which they execute in
+// order, so that what each of them does can be listed on its own.
+type Composite interface {
+	Command
+	Commands() []Command
+}
+
+// Delegating is implemented by commands made of others, which they execute
+// through run, so that an executor handles each of them as it does a single
+// command, e.g. retrying it or timing it out. Execute is the same as
+// ExecuteThrough with Command.Execute.
+type Delegating interface {
+	Command
+	ExecuteThrough(run func(Command) error) error
+}
+
+// Description is a stable, machine-readable summary of what a command does.
+// It's used to render commands in formats other than a shell script.
+type Description struct {
+	// The kind of command, e.g. 'write_file'.
+	Type string `json:"type"`
+	// The file, sysctl key, unit or interface the command acts upon, if any.
+	Target string `json:"target,omitempty"`
+	// The command's arguments, e.g. the content written to a file.
+	Args []string `json:"args,omitempty"`
+	// A human-readable description of the command.
+	Desc string `json:"description"`
 }
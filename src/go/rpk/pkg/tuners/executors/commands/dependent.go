@@ -0,0 +1,78 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package commands
+
+import (
+	"bufio"
+	"fmt"
+)
+
+// CommandID identifies the commands acting upon the same thing, in the form
+// '<type>:<target>', e.g. 'write_file:/etc/redpanda/redpanda.yaml'. See
+// IDOf.
+type CommandID string
+
+// Returns the ID of cmd, derived from its description.
+func IDOf(cmd Command) CommandID {
+	desc := cmd.Describe()
+	return CommandID(fmt.Sprintf("%s:%s", desc.Type, desc.Target))
+}
+
+// Dependent is implemented by commands which must be executed after others,
+// e.g. a file write which needs its directory to be created first.
+// Dependencies are honored by the DependencyResolvingExecutor.
+type Dependent interface {
+	Command
+	// Returns the IDs of the commands which must be executed before this
+	// one.
+	DependsOn() []CommandID
+}
+
+type dependentCommand struct {
+	cmd  Command
+	deps []CommandID
+}
+
+// Wraps cmd, declaring it depends on the commands identified by deps.
+// The wrapper only implements Command and Dependent: executors honoring the
+// dependencies (see executors.NewDependencyResolvingExecutor) hand the
+// wrapped command, with all of its interfaces, to the executor running it.
+func NewDependentCmd(cmd Command, deps ...CommandID) Command {
+	return &dependentCommand{cmd: cmd, deps: deps}
+}
+
+func (c *dependentCommand) Execute() error {
+	return c.cmd.Execute()
+}
+
+func (c *dependentCommand) RenderScript(w *bufio.Writer) error {
+	return c.cmd.RenderScript(w)
+}
+
+func (c *dependentCommand) Describe() Description {
+	return c.cmd.Describe()
+}
+
+func (c *dependentCommand) DependsOn() []CommandID {
+	return c.deps
+}
+
+func (c *dependentCommand) Unwrap() Command {
+	return c.cmd
+}
+
+// Returns the command wrapped by NewDependentCmd, or cmd itself if it isn't
+// a wrapper.
+func Unwrap(cmd Command) Command {
+	if w, ok := cmd.(interface{ Unwrap() Command }); ok {
+		return w.Unwrap()
+	}
+	return cmd
+}
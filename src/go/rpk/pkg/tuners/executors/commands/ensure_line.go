@@ -0,0 +1,214 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+)
+
+type ensureLineInFileCommand struct {
+	fs   afero.Fs
+	path string
+	line string
+	// If set, the lines matching it are replaced by line, in place.
+	match  *regexp.Regexp
+	result Result
+}
+
+// Creates a command appending line to the file at path, unless the file
+// already has a line exactly equal to it, e.g. to add a module to
+// /etc/modules-load.d or a setting to /etc/sysctl.d without duplicating it
+// on every run. The file is created if it doesn't exist.
+func NewEnsureLineInFileCmd(fs afero.Fs, path, line string) Command {
+	return &ensureLineInFileCommand{fs: fs, path: path, line: line}
+}
+
+// Creates a command like NewEnsureLineInFileCmd, which replaces the lines
+// matching match (a POSIX extended regular expression, e.g.
+// '^[[:space:]]*vm\.swappiness[[:space:]]*=') by line in place, instead of
+// appending line, e.g. to update a setting whose value changed. match must be
+// anchored to the start of the line, as the rendered script replaces from
+// the match to the end of the line.
+func NewEnsureLineInFileReplacingCmd(
+	fs afero.Fs, path, line string, match *regexp.Regexp,
+) Command {
+	return &ensureLineInFileCommand{fs: fs, path: path, line: line, match: match}
+}
+
+func (c *ensureLineInFileCommand) Execute() error {
+	c.result = Result{Target: c.path, New: c.line}
+	current, err := c.read()
+	if err != nil {
+		return err
+	}
+	if replaced, ok := c.replaced(current); ok {
+		if replaced == current {
+			log.Debugf("'%s' already has the line '%s'", c.path, c.line)
+			return nil
+		}
+		log.Debugf("Replacing the lines matching '%s' in '%s'", c.match, c.path)
+		err = afero.WriteFile(c.fs, c.path, []byte(replaced), defaultMode)
+		if err != nil {
+			return err
+		}
+		c.result.Changed = true
+		return nil
+	}
+	if hasExactLine(current, c.line) {
+		log.Debugf("'%s' already has the line '%s'", c.path, c.line)
+		return nil
+	}
+	log.Debugf("Appending '%s' to file '%s'", c.line, c.path)
+	file, err := c.fs.OpenFile(c.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, defaultMode)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = file.WriteString(c.appended(current))
+	if err != nil {
+		return err
+	}
+	c.result.Changed = true
+	return nil
+}
+
+func (c *ensureLineInFileCommand) RenderScript(w *bufio.Writer) error {
+	if c.match != nil {
+		pattern := strings.ReplaceAll(c.match.String(), "/", `\/`)
+		replacement := strings.NewReplacer(`\`, `\\`, "&", `\&`, "/", `\/`).
+			Replace(c.line)
+		fmt.Fprintf(
+			w,
+			"if grep -qE %s %s 2>/dev/null; then\n"+
+				"sed -i -E %s %s\n"+
+				"else\n",
+			ShellQuote(c.match.String()),
+			ShellQuote(c.path),
+			ShellQuote(fmt.Sprintf("s/%s.*/%s/", pattern, replacement)),
+			ShellQuote(c.path),
+		)
+	} else {
+		fmt.Fprintf(
+			w,
+			"if ! grep -qxF %s %s 2>/dev/null; then\n",
+			ShellQuote(c.line),
+			ShellQuote(c.path),
+		)
+	}
+	c.renderAppend(w)
+	fmt.Fprint(w, "fi\n")
+	return w.Flush()
+}
+
+// Renders the script appending the line to the file, which is preceded by a
+// newline if the file doesn't end with one, like Execute does. printf is
+// used, as some shells' echo interpret backslashes.
+func (c *ensureLineInFileCommand) renderAppend(w *bufio.Writer) {
+	fmt.Fprintf(
+		w,
+		"if [ -s %[1]s ] && [ -n \"$(tail -c1 %[1]s)\" ]; then\n"+
+			"printf '\\n%%s' %[2]s >> %[1]s\n"+
+			"else\n"+
+			"printf '%%s\\n' %[2]s >> %[1]s\n"+
+			"fi\n",
+		ShellQuote(c.path),
+		ShellQuote(c.line),
+	)
+}
+
+func (c *ensureLineInFileCommand) Describe() Description {
+	return Description{
+		Type:   "ensure_line_in_file",
+		Target: c.path,
+		Args:   []string{c.line},
+		Desc:   fmt.Sprintf("Ensure '%s' has the line '%s'", c.path, c.line),
+	}
+}
+
+func (c *ensureLineInFileCommand) ProducedFiles() []string {
+	return []string{c.path}
+}
+
+func (c *ensureLineInFileCommand) Inverse() (Command, error) {
+	return restoreFileCmd(c.fs, c.path)
+}
+
+func (c *ensureLineInFileCommand) Preview() (Result, error) {
+	res := Result{Target: c.path, New: c.line}
+	current, err := c.read()
+	if err != nil {
+		return res, err
+	}
+	if replaced, ok := c.replaced(current); ok {
+		res.Changed = replaced != current
+		return res, nil
+	}
+	res.Changed = !hasExactLine(current, c.line)
+	return res, nil
+}
+
+func (c *ensureLineInFileCommand) Result() Result {
+	return c.result
+}
+
+// Returns the file's current content, which is empty if it doesn't exist.
+func (c *ensureLineInFileCommand) read() (string, error) {
+	current, err := afero.ReadFile(c.fs, c.path)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("couldn't read '%s': %w", c.path, err)
+	}
+	return string(current), nil
+}
+
+// Returns current with the lines matching c.match replaced by c.line, and
+// whether there were any.
+func (c *ensureLineInFileCommand) replaced(current string) (string, bool) {
+	if c.match == nil {
+		return "", false
+	}
+	found := false
+	lines := strings.Split(current, "\n")
+	for i, l := range lines {
+		if c.match.MatchString(l) {
+			lines[i] = c.line
+			found = true
+		}
+	}
+	return strings.Join(lines, "\n"), found
+}
+
+// Returns what's appended to a file holding current. The file keeps ending
+// with a newline, or without one, as it did before.
+func (c *ensureLineInFileCommand) appended(current string) string {
+	if current == "" || strings.HasSuffix(current, "\n") {
+		return c.line + "\n"
+	}
+	return "\n" + c.line
+}
+
+// Lines which only differ in (e.g. trailing) whitespace don't match.
+func hasExactLine(content, line string) bool {
+	for _, l := range strings.Split(content, "\n") {
+		if l == line {
+			return true
+		}
+	}
+	return false
+}
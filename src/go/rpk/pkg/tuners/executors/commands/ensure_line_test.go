@@ -0,0 +1,248 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package commands_test
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors/commands"
+)
+
+func TestEnsureLineInFileCmdExecute(t *testing.T) {
+	const path = "/etc/modules-load.d/redpanda.conf"
+	tests := []struct {
+		name     string
+		before   *string
+		expected string
+		changed  bool
+	}{
+		{
+			name:     "it should create the file if it doesn't exist",
+			expected: "tcp_bbr\n",
+			changed:  true,
+		},
+		{
+			name:     "it should append the line if it's absent",
+			before:   strPtr("ip_vs\n"),
+			expected: "ip_vs\ntcp_bbr\n",
+			changed:  true,
+		},
+		{
+			name:     "it should keep the file without a trailing newline",
+			before:   strPtr("ip_vs"),
+			expected: "ip_vs\ntcp_bbr",
+			changed:  true,
+		},
+		{
+			name:     "it should leave the file as is if it has the line",
+			before:   strPtr("tcp_bbr\nip_vs"),
+			expected: "tcp_bbr\nip_vs",
+		},
+		{
+			name:     "it should append the line if it only matches with trailing whitespace",
+			before:   strPtr("tcp_bbr \n"),
+			expected: "tcp_bbr \ntcp_bbr\n",
+			changed:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			fs := afero.NewMemMapFs()
+			if tt.before != nil {
+				err := afero.WriteFile(fs, path, []byte(*tt.before), 0644)
+				require.NoError(st, err)
+			}
+			cmd := commands.NewEnsureLineInFileCmd(fs, path, "tcp_bbr")
+			preview, err := cmd.(commands.Previewer).Preview()
+			require.NoError(st, err)
+			require.Equal(st, tt.changed, preview.Changed)
+
+			require.NoError(st, cmd.Execute())
+			content, err := afero.ReadFile(fs, path)
+			require.NoError(st, err)
+			require.Equal(st, tt.expected, string(content))
+			require.Equal(st, tt.changed, cmd.(commands.ResultReporter).Result().Changed)
+
+			// Running it again must not duplicate the line.
+			require.NoError(st, commands.NewEnsureLineInFileCmd(fs, path, "tcp_bbr").Execute())
+			content, err = afero.ReadFile(fs, path)
+			require.NoError(st, err)
+			require.Equal(st, tt.expected, string(content))
+		})
+	}
+}
+
+func TestEnsureLineInFileCmdRender(t *testing.T) {
+	cmd := commands.NewEnsureLineInFileCmd(
+		afero.NewMemMapFs(),
+		"/etc/sysctl.d/99-redpanda.conf",
+		"vm.swappiness = 1",
+	)
+	var buf bytes.Buffer
+	require.NoError(t, cmd.RenderScript(bufio.NewWriter(&buf)))
+	expected := `if ! grep -qxF 'vm.swappiness = 1' /etc/sysctl.d/99-redpanda.conf 2>/dev/null; then
+if [ -s /etc/sysctl.d/99-redpanda.conf ] && [ -n "$(tail -c1 /etc/sysctl.d/99-redpanda.conf)" ]; then
+printf '\n%s' 'vm.swappiness = 1' >> /etc/sysctl.d/99-redpanda.conf
+else
+printf '%s\n' 'vm.swappiness = 1' >> /etc/sysctl.d/99-redpanda.conf
+fi
+fi
+`
+	require.Equal(t, expected, buf.String())
+}
+
+func TestEnsureLineInFileCmdRenderRun(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ensure line")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "redpanda's.conf")
+	const line = `it's a \n line`
+	tests := []struct {
+		name string
+		// If nil, the file doesn't exist.
+		before *string
+	}{
+		{name: "it should create the file if it doesn't exist"},
+		{name: "it should append the line", before: strPtr("a\n")},
+		{name: "it should append the line after the last one", before: strPtr("a")},
+		{name: "it shouldn't duplicate the line", before: strPtr("a\n" + line)},
+		{name: "it should append the line to an empty file", before: strPtr("")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			require.NoError(st, os.RemoveAll(path))
+			if tt.before != nil {
+				require.NoError(st, ioutil.WriteFile(path, []byte(*tt.before), 0644))
+			}
+			// The script must leave the file as Execute does.
+			fs := afero.NewMemMapFs()
+			if tt.before != nil {
+				require.NoError(st, afero.WriteFile(fs, path, []byte(*tt.before), 0644))
+			}
+			require.NoError(st, commands.NewEnsureLineInFileCmd(fs, path, line).Execute())
+			expected, err := afero.ReadFile(fs, path)
+			require.NoError(st, err)
+
+			cmd := commands.NewEnsureLineInFileCmd(afero.NewOsFs(), path, line)
+			var buf bytes.Buffer
+			require.NoError(st, cmd.RenderScript(bufio.NewWriter(&buf)))
+			out, err := exec.Command("sh", "-c", buf.String()).CombinedOutput()
+			require.NoError(st, err, string(out))
+			content, err := ioutil.ReadFile(path)
+			require.NoError(st, err)
+			require.Equal(st, string(expected), string(content))
+		})
+	}
+}
+
+func TestEnsureLineInFileReplacingCmdExecute(t *testing.T) {
+	const path = "/etc/sysctl.d/99-redpanda.conf"
+	match := regexp.MustCompile(`^[[:space:]]*vm\.swappiness[[:space:]]*=`)
+	tests := []struct {
+		name     string
+		before   *string
+		expected string
+		changed  bool
+	}{
+		{
+			name:     "it should create the file if it doesn't exist",
+			expected: "vm.swappiness = 1\n",
+			changed:  true,
+		},
+		{
+			name:     "it should append the line if no line matches",
+			before:   strPtr("vm.swappiness_max = 10\n"),
+			expected: "vm.swappiness_max = 10\nvm.swappiness = 1\n",
+			changed:  true,
+		},
+		{
+			name:     "it should update the key's value in place",
+			before:   strPtr("vm.swappiness=60\nfs.aio-max-nr = 1048576\n"),
+			expected: "vm.swappiness = 1\nfs.aio-max-nr = 1048576\n",
+			changed:  true,
+		},
+		{
+			name:     "it should leave the file as is if it has the line",
+			before:   strPtr("fs.aio-max-nr = 1048576\nvm.swappiness = 1"),
+			expected: "fs.aio-max-nr = 1048576\nvm.swappiness = 1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			fs := afero.NewMemMapFs()
+			if tt.before != nil {
+				err := afero.WriteFile(fs, path, []byte(*tt.before), 0644)
+				require.NoError(st, err)
+			}
+			cmd := commands.NewEnsureLineInFileReplacingCmd(fs, path, "vm.swappiness = 1", match)
+			preview, err := cmd.(commands.Previewer).Preview()
+			require.NoError(st, err)
+			require.Equal(st, tt.changed, preview.Changed)
+
+			require.NoError(st, cmd.Execute())
+			content, err := afero.ReadFile(fs, path)
+			require.NoError(st, err)
+			require.Equal(st, tt.expected, string(content))
+			require.Equal(st, tt.changed, cmd.(commands.ResultReporter).Result().Changed)
+		})
+	}
+}
+
+func TestEnsureLineInFileReplacingCmdRender(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ensure-line")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "99-redpanda.conf")
+	cmd := commands.NewEnsureLineInFileReplacingCmd(
+		afero.NewOsFs(),
+		path,
+		"net.ipv4.tcp_rmem = 4096 87380 16777216",
+		regexp.MustCompile(`^[[:space:]]*net\.ipv4\.tcp_rmem[[:space:]]*=`),
+	)
+	var buf bytes.Buffer
+	require.NoError(t, cmd.RenderScript(bufio.NewWriter(&buf)))
+	expected := fmt.Sprintf(`if grep -qE '^[[:space:]]*net\.ipv4\.tcp_rmem[[:space:]]*=' %[1]s 2>/dev/null; then
+sed -i -E 's/^[[:space:]]*net\.ipv4\.tcp_rmem[[:space:]]*=.*/net.ipv4.tcp_rmem = 4096 87380 16777216/' %[1]s
+else
+if [ -s %[1]s ] && [ -n "$(tail -c1 %[1]s)" ]; then
+printf '\n%%s' 'net.ipv4.tcp_rmem = 4096 87380 16777216' >> %[1]s
+else
+printf '%%s\n' 'net.ipv4.tcp_rmem = 4096 87380 16777216' >> %[1]s
+fi
+fi
+`, path)
+	require.Equal(t, expected, buf.String())
+
+	// The script appends the line, and then updates it in place.
+	for _, before := range []string{"vm.swappiness = 1\n", "tcp_rmem = 1\n net.ipv4.tcp_rmem=1 1 1\n"} {
+		require.NoError(t, ioutil.WriteFile(path, []byte(before), 0644))
+		require.NoError(t, exec.Command("sh", "-c", buf.String()).Run())
+		content, err := ioutil.ReadFile(path)
+		require.NoError(t, err)
+		lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+		require.Equal(t, "net.ipv4.tcp_rmem = 4096 87380 16777216", lines[len(lines)-1])
+		require.Len(t, lines, 2)
+	}
+}
+
+func strPtr(s string) *string {
+	return &s
+}
@@ -12,6 +12,8 @@ package commands
 import (
 	"bufio"
 	"fmt"
+	"sort"
+	"strings"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/ethtool"
@@ -51,3 +53,29 @@ func (c *ethtoolChangeCommand) RenderScript(w *bufio.Writer) error {
 	fmt.Fprintln(w)
 	return w.Flush()
 }
+
+func (c *ethtoolChangeCommand) Describe() Description {
+	var features []string
+	for feature := range c.config {
+		features = append(features, feature)
+	}
+	sort.Strings(features)
+	var args []string
+	for _, feature := range features {
+		stateString := "on"
+		if !c.config[feature] {
+			stateString = "off"
+		}
+		args = append(args, feature, stateString)
+	}
+	return Description{
+		Type:   "ethtool_change",
+		Target: c.intf,
+		Args:   args,
+		Desc: fmt.Sprintf(
+			"Change features of interface '%s': %s",
+			c.intf,
+			strings.Join(args, " "),
+		),
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package commands
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/os"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/ethtool"
+)
+
+// The option reading the settings each supported option sets, along with the
+// parser of its output.
+var ethtoolGetters = map[string]struct {
+	option string
+	parse  func(lines []string) ethtool.Settings
+}{
+	"-G": {"-g", currentEthtoolCounts(ethtool.ParseRingParameters)},
+	"-L": {"-l", currentEthtoolCounts(ethtool.ParseChannelParameters)},
+	"-C": {"-c", ethtool.ParseCoalesce},
+}
+
+func currentEthtoolCounts(
+	parse func(lines []string) (maximums, current map[string]int),
+) func(lines []string) ethtool.Settings {
+	return func(lines []string) ethtool.Settings {
+		_, current := parse(lines)
+		settings := ethtool.Settings{}
+		for name, value := range current {
+			settings[name] = fmt.Sprint(value)
+		}
+		return settings
+	}
+}
+
+type ethtoolSetCommand struct {
+	proc     os.Proc
+	timeout  time.Duration
+	option   string
+	intf     string
+	settings map[string]string
+}
+
+// Creates a command running 'ethtool <option> <intf> <name> <value>...',
+// e.g. 'ethtool -G eth0 rx 4096 tx 4096', to change the given settings.
+func NewEthtoolSetCmd(
+	proc os.Proc,
+	timeout time.Duration,
+	option string,
+	intf string,
+	settings map[string]string,
+) Command {
+	return &ethtoolSetCommand{
+		proc:     proc,
+		timeout:  timeout,
+		option:   option,
+		intf:     intf,
+		settings: settings,
+	}
+}
+
+func (c *ethtoolSetCommand) Execute() error {
+	return c.ExecuteContext(context.Background())
+}
+
+func (c *ethtoolSetCommand) ExecuteContext(ctx context.Context) error {
+	timeout := c.timeout
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < timeout {
+		timeout = time.Until(deadline)
+	}
+	_, err := c.proc.RunWithSystemLdPath(timeout, "ethtool", c.args()...)
+	if err != nil {
+		return fmt.Errorf("'ethtool %s' failed: %w", strings.Join(c.args(), " "), err)
+	}
+	return nil
+}
+
+func (c *ethtoolSetCommand) RenderScript(w *bufio.Writer) error {
+	fmt.Fprintf(w, "ethtool %s\n", strings.Join(c.args(), " "))
+	return w.Flush()
+}
+
+func (c *ethtoolSetCommand) Describe() Description {
+	args := c.args()
+	return Description{
+		Type:   "ethtool_set",
+		Target: c.intf,
+		Args:   args,
+		Desc: fmt.Sprintf(
+			"Change settings of interface '%s': %s",
+			c.intf,
+			strings.Join(args[2:], " "),
+		),
+	}
+}
+
+// Returns a command restoring the current values of the settings, read with
+// the matching get option (e.g. '-g' for '-G'). Only '-G', '-L' and '-C' are
+// supported.
+func (c *ethtoolSetCommand) Inverse() (Command, error) {
+	getter, ok := ethtoolGetters[c.option]
+	if !ok {
+		return nil, fmt.Errorf("'ethtool %s' can't be reverted", c.option)
+	}
+	lines, err := c.proc.RunWithSystemLdPath(c.timeout, "ethtool", getter.option, c.intf)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"couldn't read the current settings of interface '%s': %w",
+			c.intf,
+			err,
+		)
+	}
+	current := getter.parse(lines)
+	previous := map[string]string{}
+	for name := range c.settings {
+		value, ok := current[name]
+		if !ok {
+			return nil, fmt.Errorf(
+				"interface '%s' doesn't report the current value of '%s'",
+				c.intf,
+				name,
+			)
+		}
+		previous[name] = value
+	}
+	return NewEthtoolSetCmd(c.proc, c.timeout, c.option, c.intf, previous), nil
+}
+
+// Returns ethtool's arguments, with the settings sorted by name.
+func (c *ethtoolSetCommand) args() []string {
+	var names []string
+	for name := range c.settings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	args := []string{c.option, c.intf}
+	for _, name := range names {
+		args = append(args, name, c.settings[name])
+	}
+	return args
+}
@@ -0,0 +1,113 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package commands_test
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors/commands"
+)
+
+// Returns the given output for each 'ethtool' invocation, keyed by its
+// arguments.
+type ethtoolOutputsProcMock struct {
+	outputs map[string]string
+}
+
+func (p *ethtoolOutputsProcMock) RunWithSystemLdPath(
+	_ time.Duration, _ string, args ...string,
+) ([]string, error) {
+	output, ok := p.outputs[strings.Join(args, " ")]
+	if !ok {
+		return nil, errors.New("Operation not supported")
+	}
+	return strings.Split(output, "\n"), nil
+}
+
+func (*ethtoolOutputsProcMock) IsRunning(_ time.Duration, _ string) bool {
+	return false
+}
+
+func TestEthtoolSetCmdInverse(t *testing.T) {
+	proc := &ethtoolOutputsProcMock{outputs: map[string]string{
+		"-l eth0": `Channel parameters for eth0:
+Pre-set maximums:
+RX:		n/a
+TX:		n/a
+Other:		1
+Combined:	63
+Current hardware settings:
+RX:		n/a
+TX:		n/a
+Other:		1
+Combined:	8`,
+		"-c eth0": `Coalesce parameters for eth0:
+Adaptive RX: off  TX: off`,
+	}}
+	tests := []struct {
+		name          string
+		option        string
+		settings      map[string]string
+		expected      string
+		expectedError string
+	}{
+		{
+			name:     "it should restore the channel counts",
+			option:   "-L",
+			settings: map[string]string{"combined": "16"},
+			expected: "ethtool -L eth0 combined 8\n",
+		},
+		{
+			name:     "it should restore the coalescing settings",
+			option:   "-C",
+			settings: map[string]string{"adaptive-rx": "on", "adaptive-tx": "on"},
+			expected: "ethtool -C eth0 adaptive-rx off adaptive-tx off\n",
+		},
+		{
+			name:          "it should fail if the setting isn't reported",
+			option:        "-L",
+			settings:      map[string]string{"rx": "16"},
+			expectedError: "interface 'eth0' doesn't report the current value of 'rx'",
+		},
+		{
+			name:          "it should fail if the settings can't be read",
+			option:        "-G",
+			settings:      map[string]string{"rx": "4096"},
+			expectedError: "couldn't read the current settings of interface 'eth0': Operation not supported",
+		},
+		{
+			name:          "it should fail for the options it can't revert",
+			option:        "-K",
+			settings:      map[string]string{"ntuple": "on"},
+			expectedError: "'ethtool -K' can't be reverted",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			cmd := commands.NewEthtoolSetCmd(proc, time.Second, tt.option, "eth0", tt.settings)
+			inverse, err := cmd.(commands.Reversible).Inverse()
+			if tt.expectedError != "" {
+				require.EqualError(st, err, tt.expectedError)
+				return
+			}
+			require.NoError(st, err)
+			var buf bytes.Buffer
+			w := bufio.NewWriter(&buf)
+			require.NoError(st, inverse.RenderScript(w))
+			require.Equal(st, tt.expected, buf.String())
+		})
+	}
+}
@@ -11,7 +11,9 @@ package commands
 
 import (
 	"bufio"
+	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/os"
@@ -37,7 +39,22 @@ func NewLaunchCmd(
 }
 
 func (c *executeCommand) Execute() error {
-	_, err := c.proc.RunWithSystemLdPath(c.timeout, c.cmd, c.args...)
+	return c.ExecuteContext(context.Background())
+}
+
+// Runs the process, which is killed once ctx is done. If the command's Proc
+// can't be given a context (see os.ContextProc), it's only killed once its
+// timeout, capped by ctx's deadline, elapses.
+func (c *executeCommand) ExecuteContext(ctx context.Context) error {
+	if p, ok := c.proc.(os.ContextProc); ok {
+		_, err := p.RunWithSystemLdPathContext(ctx, c.timeout, c.cmd, c.args...)
+		return err
+	}
+	timeout := c.timeout
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < timeout {
+		timeout = time.Until(deadline)
+	}
+	_, err := c.proc.RunWithSystemLdPath(timeout, c.cmd, c.args...)
 	return err
 }
 
@@ -48,3 +65,11 @@ func (c *executeCommand) RenderScript(w *bufio.Writer) error {
 	}
 	return w.Flush()
 }
+
+func (c *executeCommand) Describe() Description {
+	return Description{
+		Type: "launch",
+		Args: append([]string{c.cmd}, c.args...),
+		Desc: fmt.Sprintf("Run '%s'", strings.Join(append([]string{c.cmd}, c.args...), " ")),
+	}
+}
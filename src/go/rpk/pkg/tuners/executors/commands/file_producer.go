@@ -0,0 +1,19 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package commands
+
+// FileProducer is implemented by commands which create or write files, so
+// that executors can hand them over to another owner once written (see
+// executors.NewOwningExecutor).
+type FileProducer interface {
+	Command
+	// Returns the paths of the files the command writes.
+	ProducedFiles() []string
+}
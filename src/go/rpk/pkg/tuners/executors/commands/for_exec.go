@@ -0,0 +1,183 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package commands
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/os"
+)
+
+// The arguments which don't need quoting in a shell.
+var shellSafeRegex = regexp.MustCompile(`^[A-Za-z0-9_@%+=:,./-]+$`)
+
+// What a process run by a command wrote, and the code it exited with.
+type ExecOutput struct {
+	Stdout   string
+	Stderr   string
+	ExitCode int
+}
+
+// OutputCapturer is implemented by the commands capturing the output of the
+// process they run (see NewForExecCmd).
+type OutputCapturer interface {
+	// Returns the output of the last execution.
+	Output() ExecOutput
+}
+
+// The error a process run by a command fails with, carrying what it wrote to
+// stderr.
+type ExecError struct {
+	// The command line, quoted as it's rendered.
+	Cmdline  string
+	ExitCode int
+	Stderr   string
+	// The error returned by os/exec, e.g. an *exec.ExitError.
+	Err error
+}
+
+func (e *ExecError) Error() string {
+	stderr := strings.TrimSpace(e.Stderr)
+	if e.ExitCode < 0 {
+		return fmt.Sprintf("'%s' failed: %v", e.Cmdline, e.Err)
+	}
+	if stderr == "" {
+		return fmt.Sprintf("'%s' exited with code %d", e.Cmdline, e.ExitCode)
+	}
+	return fmt.Sprintf("'%s' exited with code %d: %s", e.Cmdline, e.ExitCode, stderr)
+}
+
+func (e *ExecError) Unwrap() error {
+	return e.Err
+}
+
+type ForExecParams struct {
+	// The non-zero exit codes the command succeeds with, e.g. 1 for tools
+	// which use it to tell that nothing changed.
+	AllowedExitCodes []int
+	// How long the process may run for before it's killed. No limit if 0,
+	// besides the deadline of the context it's executed with, if any.
+	Timeout time.Duration
+}
+
+type forExecCommand struct {
+	params ForExecParams
+	name   string
+	args   []string
+	output ExecOutput
+}
+
+// Creates a command running the program name with args, without a shell, so
+// that the args are passed as is. It succeeds if the program exits with 0
+// and fails otherwise, with an *ExecError carrying its stderr. Its output is
+// captured (see OutputCapturer).
+func NewForExecCmd(name string, args []string) Command {
+	return NewForExecCmdWithParams(ForExecParams{}, name, args)
+}
+
+func NewForExecCmdWithParams(
+	params ForExecParams, name string, args []string,
+) Command {
+	return &forExecCommand{params: params, name: name, args: args}
+}
+
+func (c *forExecCommand) Execute() error {
+	return c.ExecuteContext(context.Background())
+}
+
+func (c *forExecCommand) ExecuteContext(ctx context.Context) error {
+	if c.params.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.params.Timeout)
+		defer cancel()
+	}
+	log.Debugf("Running '%s'", c.cmdline())
+	cmd := exec.CommandContext(ctx, c.name, c.args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	cmd.Env = os.SystemLdPathEnv()
+	err := cmd.Run()
+	c.output = ExecOutput{
+		Stdout:   stdout.String(),
+		Stderr:   stderr.String(),
+		ExitCode: cmd.ProcessState.ExitCode(),
+	}
+	if err == nil {
+		return nil
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && ctx.Err() == nil && c.isAllowed(c.output.ExitCode) {
+		log.Debugf("'%s' exited with the allowed code %d", c.cmdline(), c.output.ExitCode)
+		return nil
+	}
+	if ctx.Err() != nil {
+		err = fmt.Errorf("%w (%v)", ctx.Err(), err)
+	}
+	return &ExecError{
+		Cmdline:  c.cmdline(),
+		ExitCode: c.output.ExitCode,
+		Stderr:   c.output.Stderr,
+		Err:      err,
+	}
+}
+
+func (c *forExecCommand) RenderScript(w *bufio.Writer) error {
+	fmt.Fprintln(w, c.cmdline())
+	return w.Flush()
+}
+
+func (c *forExecCommand) Describe() Description {
+	return Description{
+		Type:   "exec",
+		Target: c.name,
+		Args:   append([]string{c.name}, c.args...),
+		Desc:   fmt.Sprintf("Run '%s'", c.cmdline()),
+	}
+}
+
+func (c *forExecCommand) Output() ExecOutput {
+	return c.output
+}
+
+func (c *forExecCommand) isAllowed(code int) bool {
+	for _, allowed := range c.params.AllowedExitCodes {
+		if code == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *forExecCommand) cmdline() string {
+	words := make([]string, 0, len(c.args)+1)
+	for _, word := range append([]string{c.name}, c.args...) {
+		words = append(words, ShellQuote(word))
+	}
+	return strings.Join(words, " ")
+}
+
+// Quotes s so that a POSIX shell reads it as a single word, as is. It's left
+// as is if no quoting is needed.
+func ShellQuote(s string) string {
+	if shellSafeRegex.MatchString(s) {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
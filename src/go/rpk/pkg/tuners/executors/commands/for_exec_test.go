@@ -0,0 +1,135 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package commands_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors/commands"
+)
+
+func TestForExecCmdRender(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "it should leave plain args as is",
+			args:     []string{"-w", "vm.swappiness=1"},
+			expected: "sysctl -w vm.swappiness=1\n",
+		},
+		{
+			name:     "it should quote args with spaces",
+			args:     []string{"-p", "/etc/sysctl.d/my file.conf"},
+			expected: "sysctl -p '/etc/sysctl.d/my file.conf'\n",
+		},
+		{
+			name:     "it should escape single quotes",
+			args:     []string{"it's", ""},
+			expected: "sysctl 'it'\\''s' ''\n",
+		},
+		{
+			name:     "it should quote shell metacharacters",
+			args:     []string{"$HOME", "a;b", "*"},
+			expected: "sysctl '$HOME' 'a;b' '*'\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := commands.NewForExecCmd("sysctl", tt.args)
+			var buf bytes.Buffer
+			require.NoError(t, cmd.RenderScript(bufio.NewWriter(&buf)))
+			require.Equal(t, tt.expected, buf.String())
+		})
+	}
+}
+
+func TestForExecCmdRenderedArgsRoundTrip(t *testing.T) {
+	args := []string{"a b", "it's", "$HOME", ""}
+	cmd := commands.NewForExecCmd("printf", append([]string{"[%s]"}, args...))
+	var buf bytes.Buffer
+	require.NoError(t, cmd.RenderScript(bufio.NewWriter(&buf)))
+	out, err := exec.Command("sh", "-c", buf.String()).Output()
+	require.NoError(t, err)
+	require.Equal(t, "[a b][it's][$HOME][]", string(out))
+}
+
+func TestForExecCmdExecute(t *testing.T) {
+	tests := []struct {
+		name        string
+		script      string
+		allowed     []int
+		expectedErr string
+		expected    commands.ExecOutput
+	}{
+		{
+			name:     "it should capture the output",
+			script:   "echo out; echo err >&2",
+			expected: commands.ExecOutput{Stdout: "out\n", Stderr: "err\n"},
+		},
+		{
+			name:        "it should fail with the stderr on a non-zero exit code",
+			script:      "echo 'no such key' >&2; exit 3",
+			expectedErr: "'sh -c 'echo '\\''no such key'\\'' >&2; exit 3'' exited with code 3: no such key",
+			expected:    commands.ExecOutput{Stderr: "no such key\n", ExitCode: 3},
+		},
+		{
+			name:     "it should succeed with an allowed exit code",
+			script:   "echo unchanged; exit 1",
+			allowed:  []int{1},
+			expected: commands.ExecOutput{Stdout: "unchanged\n", ExitCode: 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := commands.NewForExecCmdWithParams(
+				commands.ForExecParams{AllowedExitCodes: tt.allowed},
+				"sh",
+				[]string{"-c", tt.script},
+			)
+			err := cmd.Execute()
+			if tt.expectedErr != "" {
+				require.EqualError(t, err, tt.expectedErr)
+				var execErr *commands.ExecError
+				require.True(t, errors.As(err, &execErr))
+				require.Equal(t, tt.expected.ExitCode, execErr.ExitCode)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tt.expected, cmd.(commands.OutputCapturer).Output())
+		})
+	}
+}
+
+func TestForExecCmdTimeout(t *testing.T) {
+	cmd := commands.NewForExecCmdWithParams(
+		commands.ForExecParams{Timeout: 10 * time.Millisecond, AllowedExitCodes: []int{-1}},
+		"sleep",
+		[]string{"5"},
+	)
+	err := cmd.Execute()
+	require.Error(t, err)
+	require.True(t, errors.Is(err, context.DeadlineExceeded))
+}
+
+func TestForExecCmdMissingProgram(t *testing.T) {
+	cmd := commands.NewForExecCmd("/nonexistent/tool", []string{"--help"})
+	err := cmd.Execute()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "'/nonexistent/tool --help' failed:")
+}
@@ -0,0 +1,210 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/os"
+)
+
+// PackageManager describes how to query and install packages with a
+// distribution's package manager.
+type PackageManager struct {
+	// The binary the package manager is detected by, e.g. 'apt-get'.
+	Binary string
+	// The arguments installing the packages they're followed by,
+	// non-interactively.
+	InstallArgs []string
+	// The command (and its arguments) which succeeds if the package it's
+	// followed by is installed.
+	Query []string
+	// If set, the query must also print it for the package to be considered
+	// installed, e.g. as it succeeds for the packages which were removed but
+	// whose configuration files were kept.
+	InstalledStatus string
+}
+
+// The supported package managers, in the order they're detected in. dnf
+// goes before yum, as distributions shipping both alias yum to it.
+var PackageManagers = []PackageManager{
+	{
+		Binary:          "apt-get",
+		InstallArgs:     []string{"install", "-y", "-q"},
+		Query:           []string{"dpkg-query", "-W", "-f=${Status}"},
+		InstalledStatus: "install ok installed",
+	},
+	{
+		Binary:      "dnf",
+		InstallArgs: []string{"install", "-y", "-q"},
+		Query:       []string{"rpm", "-q"},
+	},
+	{
+		Binary:      "yum",
+		InstallArgs: []string{"install", "-y", "-q"},
+		Query:       []string{"rpm", "-q"},
+	},
+	{
+		Binary:      "zypper",
+		InstallArgs: []string{"--non-interactive", "install"},
+		Query:       []string{"rpm", "-q"},
+	},
+}
+
+// Returns the first of PackageManagers whose binary is in the PATH.
+func DetectPackageManager() (PackageManager, error) {
+	for _, manager := range PackageManagers {
+		if _, err := exec.LookPath(manager.Binary); err == nil {
+			return manager, nil
+		}
+	}
+	var binaries []string
+	for _, manager := range PackageManagers {
+		binaries = append(binaries, manager.Binary)
+	}
+	return PackageManager{}, fmt.Errorf(
+		"no supported package manager found (looked for %s)",
+		strings.Join(binaries, ", "),
+	)
+}
+
+type InstallPackagesParams struct {
+	Proc    os.Proc
+	Timeout time.Duration
+	// Detects the package manager when the command is executed.
+	// DetectPackageManager if nil.
+	Detect func() (PackageManager, error)
+}
+
+type installPackagesCommand struct {
+	params InstallPackagesParams
+	pkgs   []string
+}
+
+// Creates a command installing pkgs with the package manager detected at
+// runtime. Packages which are installed already are skipped, so the command
+// succeeds without doing anything if all of them are.
+// The rendered script detects the package manager itself, so that it can be
+// run on a host other than the one it was rendered on.
+func NewInstallPackagesCmd(pkgs ...string) Command {
+	return NewInstallPackagesCmdWithParams(
+		InstallPackagesParams{Proc: os.NewProc(), Timeout: 10 * time.Minute},
+		pkgs...,
+	)
+}
+
+func NewInstallPackagesCmdWithParams(
+	params InstallPackagesParams, pkgs ...string,
+) Command {
+	if params.Detect == nil {
+		params.Detect = DetectPackageManager
+	}
+	return &installPackagesCommand{params: params, pkgs: pkgs}
+}
+
+func (c *installPackagesCommand) Execute() error {
+	manager, err := c.params.Detect()
+	if err != nil {
+		return fmt.Errorf(
+			"couldn't install '%s': %w",
+			strings.Join(c.pkgs, " "),
+			err,
+		)
+	}
+	var missing []string
+	for _, pkg := range c.pkgs {
+		query := append(append([]string(nil), manager.Query[1:]...), pkg)
+		output, err := c.params.Proc.RunWithSystemLdPath(
+			c.params.Timeout,
+			manager.Query[0],
+			query...,
+		)
+		status := strings.TrimSpace(strings.Join(output, "\n"))
+		if err != nil ||
+			(manager.InstalledStatus != "" && status != manager.InstalledStatus) {
+			missing = append(missing, pkg)
+		}
+	}
+	if len(missing) == 0 {
+		log.Debugf("'%s' are installed already", strings.Join(c.pkgs, " "))
+		return nil
+	}
+	log.Infof("Installing '%s' with %s", strings.Join(missing, " "), manager.Binary)
+	args := append(append([]string(nil), manager.InstallArgs...), missing...)
+	_, err = c.params.Proc.RunWithSystemLdPath(
+		c.params.Timeout,
+		manager.Binary,
+		args...,
+	)
+	if err != nil {
+		return fmt.Errorf("couldn't install '%s': %w", strings.Join(missing, " "), err)
+	}
+	return nil
+}
+
+func (c *installPackagesCommand) RenderScript(w *bufio.Writer) error {
+	pkgs := strings.Join(c.pkgs, " ")
+	for i, manager := range PackageManagers {
+		keyword := "elif"
+		if i == 0 {
+			keyword = "if"
+		}
+		fmt.Fprintf(w, "%s command -v %s >/dev/null; then\n", keyword, manager.Binary)
+		query := make([]string, len(manager.Query))
+		for j, word := range manager.Query {
+			query[j] = ShellQuote(word)
+		}
+		install := fmt.Sprintf(
+			"%s %s %s",
+			manager.Binary,
+			strings.Join(manager.InstallArgs, " "),
+			pkgs,
+		)
+		if manager.InstalledStatus == "" {
+			fmt.Fprintf(
+				w,
+				"  %s %s >/dev/null 2>&1 || %s\n",
+				strings.Join(query, " "),
+				pkgs,
+				install,
+			)
+			continue
+		}
+		fmt.Fprintln(w, "  installed=true")
+		fmt.Fprintf(w, "  for pkg in %s; do\n", pkgs)
+		fmt.Fprintf(
+			w,
+			"    [ \"$(%s \"$pkg\" 2>/dev/null)\" = %s ] || installed=false\n",
+			strings.Join(query, " "),
+			ShellQuote(manager.InstalledStatus),
+		)
+		fmt.Fprintln(w, "  done")
+		fmt.Fprintf(w, "  $installed || %s\n", install)
+	}
+	fmt.Fprintln(w, "else")
+	fmt.Fprintf(w, "  echo \"No supported package manager found to install %s\" >&2\n", pkgs)
+	fmt.Fprintln(w, "  exit 1")
+	fmt.Fprintln(w, "fi")
+	return w.Flush()
+}
+
+func (c *installPackagesCommand) Describe() Description {
+	return Description{
+		Type:   "install_packages",
+		Target: strings.Join(c.pkgs, " "),
+		Args:   c.pkgs,
+		Desc:   fmt.Sprintf("Install '%s'", strings.Join(c.pkgs, " ")),
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package commands_test
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors/commands"
+)
+
+// Records the commands it's asked to run. Queries for the packages in
+// installed succeed, the rest fail, except for those in removed, which dpkg
+// reports as removed with their configuration files kept.
+type packagesProcMock struct {
+	installed map[string]bool
+	removed   map[string]bool
+	runs      []string
+}
+
+func (p *packagesProcMock) RunWithSystemLdPath(
+	_ time.Duration, command string, args ...string,
+) ([]string, error) {
+	p.runs = append(p.runs, strings.Join(append([]string{command}, args...), " "))
+	pkg := args[len(args)-1]
+	switch {
+	case command == "dpkg-query" && p.removed[pkg]:
+		return []string{"deinstall ok config-files"}, nil
+	case command == "dpkg-query" && p.installed[pkg]:
+		return []string{"install ok installed"}, nil
+	case command == "rpm" && p.installed[pkg]:
+		return nil, nil
+	case command == "dpkg-query" || command == "rpm":
+		return nil, errors.New("not installed")
+	}
+	return nil, nil
+}
+
+func (*packagesProcMock) IsRunning(_ time.Duration, _ string) bool {
+	return false
+}
+
+func TestInstallPackagesCmdExecute(t *testing.T) {
+	tests := []struct {
+		name      string
+		manager   int
+		installed map[string]bool
+		removed   map[string]bool
+		expected  []string
+	}{
+		{
+			name:      "it should install the missing packages with apt-get",
+			manager:   0,
+			installed: map[string]bool{"ethtool": true},
+			expected: []string{
+				"dpkg-query -W -f=${Status} ethtool",
+				"dpkg-query -W -f=${Status} tuned",
+				"apt-get install -y -q tuned",
+			},
+		},
+		{
+			name:    "it should install the packages apt-get removed",
+			manager: 0,
+			removed: map[string]bool{"ethtool": true, "tuned": true},
+			expected: []string{
+				"dpkg-query -W -f=${Status} ethtool",
+				"dpkg-query -W -f=${Status} tuned",
+				"apt-get install -y -q ethtool tuned",
+			},
+		},
+		{
+			name:    "it should install the missing packages with dnf",
+			manager: 1,
+			expected: []string{
+				"rpm -q ethtool",
+				"rpm -q tuned",
+				"dnf install -y -q ethtool tuned",
+			},
+		},
+		{
+			name:    "it should install the missing packages with yum",
+			manager: 2,
+			expected: []string{
+				"rpm -q ethtool",
+				"rpm -q tuned",
+				"yum install -y -q ethtool tuned",
+			},
+		},
+		{
+			name:    "it should install the missing packages with zypper",
+			manager: 3,
+			expected: []string{
+				"rpm -q ethtool",
+				"rpm -q tuned",
+				"zypper --non-interactive install ethtool tuned",
+			},
+		},
+		{
+			name:      "it shouldn't do anything if the packages are installed",
+			manager:   3,
+			installed: map[string]bool{"ethtool": true, "tuned": true},
+			expected:  []string{"rpm -q ethtool", "rpm -q tuned"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			proc := &packagesProcMock{installed: tt.installed, removed: tt.removed}
+			cmd := commands.NewInstallPackagesCmdWithParams(
+				commands.InstallPackagesParams{
+					Proc:    proc,
+					Timeout: time.Second,
+					Detect: func() (commands.PackageManager, error) {
+						return commands.PackageManagers[tt.manager], nil
+					},
+				},
+				"ethtool",
+				"tuned",
+			)
+			require.NoError(st, cmd.Execute())
+			require.Equal(st, tt.expected, proc.runs)
+		})
+	}
+}
+
+func TestInstallPackagesCmdNoPackageManager(t *testing.T) {
+	proc := &packagesProcMock{}
+	cmd := commands.NewInstallPackagesCmdWithParams(
+		commands.InstallPackagesParams{
+			Proc: proc,
+			Detect: func() (commands.PackageManager, error) {
+				return commands.PackageManager{}, errors.New("no supported package manager found")
+			},
+		},
+		"ethtool",
+	)
+	require.EqualError(
+		t,
+		cmd.Execute(),
+		"couldn't install 'ethtool': no supported package manager found",
+	)
+	require.Empty(t, proc.runs)
+}
+
+func TestInstallPackagesCmdRender(t *testing.T) {
+	cmd := commands.NewInstallPackagesCmd("ethtool", "tuned")
+	var buf bytes.Buffer
+	w := bufio.NewWriter(&buf)
+	require.NoError(t, cmd.RenderScript(w))
+	expected := `if command -v apt-get >/dev/null; then
+  installed=true
+  for pkg in ethtool tuned; do
+    [ "$(dpkg-query -W '-f=${Status}' "$pkg" 2>/dev/null)" = 'install ok installed' ] || installed=false
+  done
+  $installed || apt-get install -y -q ethtool tuned
+elif command -v dnf >/dev/null; then
+  rpm -q ethtool tuned >/dev/null 2>&1 || dnf install -y -q ethtool tuned
+elif command -v yum >/dev/null; then
+  rpm -q ethtool tuned >/dev/null 2>&1 || yum install -y -q ethtool tuned
+elif command -v zypper >/dev/null; then
+  rpm -q ethtool tuned >/dev/null 2>&1 || zypper --non-interactive install ethtool tuned
+else
+  echo "No supported package manager found to install ethtool tuned" >&2
+  exit 1
+fi
+`
+	require.Equal(t, expected, buf.String())
+}
@@ -0,0 +1,169 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+)
+
+type mkdirAllCommand struct {
+	fs     afero.Fs
+	path   string
+	mode   os.FileMode
+	result Result
+}
+
+// Creates the directory at path with the given mode, along with any missing
+// parents, like 'mkdir -p -m'. If the directory already exists, its mode is
+// left as is, even if it differs from the given one, which is reported.
+func NewMkdirAllCmd(fs afero.Fs, path string, mode os.FileMode) Command {
+	return &mkdirAllCommand{fs: fs, path: path, mode: mode}
+}
+
+func (c *mkdirAllCommand) Execute() error {
+	c.result = Result{Target: c.path, New: c.modeString(c.mode)}
+	info, err := c.fs.Stat(c.path)
+	if err == nil {
+		if !info.IsDir() {
+			return fmt.Errorf("'%s' already exists and isn't a directory", c.path)
+		}
+		c.result.Old = c.modeString(info.Mode())
+		if info.Mode().Perm() != c.mode.Perm() {
+			log.Warnf(
+				"'%s' already exists with mode %s instead of %s, keeping it",
+				c.path,
+				c.result.Old,
+				c.result.New,
+			)
+			return nil
+		}
+		log.Debugf("'%s' already exists, no change needed", c.path)
+		return nil
+	}
+	if !os.IsNotExist(err) {
+		return err
+	}
+	log.Debugf("Creating '%s' with mode %s", c.path, c.result.New)
+	err = c.fs.MkdirAll(c.path, c.mode)
+	if err != nil {
+		return err
+	}
+	// The umask may have masked some of the bits out.
+	err = c.fs.Chmod(c.path, c.mode)
+	if err != nil {
+		return err
+	}
+	c.result.Changed = true
+	return nil
+}
+
+func (c *mkdirAllCommand) RenderScript(w *bufio.Writer) error {
+	fmt.Fprintf(w, "mkdir -p -m %s %s\n", c.modeString(c.mode), c.path)
+	return w.Flush()
+}
+
+func (c *mkdirAllCommand) Describe() Description {
+	return Description{
+		Type:   "mkdir_all",
+		Target: c.path,
+		Args:   []string{c.modeString(c.mode)},
+		Desc:   fmt.Sprintf("Create the directory '%s' with mode %s", c.path, c.modeString(c.mode)),
+	}
+}
+
+func (c *mkdirAllCommand) ProducedFiles() []string {
+	return []string{c.path}
+}
+
+// Returns a command removing the directories which don't exist yet, from
+// the deepest one up.
+func (c *mkdirAllCommand) Inverse() (Command, error) {
+	var missing []string
+	for dir := filepath.Clean(c.path); ; dir = filepath.Dir(dir) {
+		_, err := c.fs.Stat(dir)
+		if err == nil {
+			break
+		}
+		if !os.IsNotExist(err) {
+			return nil, fmt.Errorf(
+				"couldn't read the current state of '%s': %w",
+				dir,
+				err,
+			)
+		}
+		missing = append(missing, dir)
+		if dir == filepath.Dir(dir) {
+			break
+		}
+	}
+	return &removeDirsCommand{fs: c.fs, dirs: missing}, nil
+}
+
+func (c *mkdirAllCommand) Preview() (Result, error) {
+	res := Result{Target: c.path, New: c.modeString(c.mode), Changed: true}
+	info, err := c.fs.Stat(c.path)
+	if os.IsNotExist(err) {
+		return res, nil
+	}
+	if err != nil {
+		return res, err
+	}
+	res.Old = c.modeString(info.Mode())
+	res.Changed = false
+	return res, nil
+}
+
+func (c *mkdirAllCommand) Result() Result {
+	return c.result
+}
+
+func (*mkdirAllCommand) modeString(mode os.FileMode) string {
+	return fmt.Sprintf("%04o", uint32(mode.Perm()))
+}
+
+// Removes the given empty directories, in order. It's the inverse of
+// MkdirAllCmd.
+type removeDirsCommand struct {
+	fs   afero.Fs
+	dirs []string
+}
+
+func (c *removeDirsCommand) Execute() error {
+	for _, dir := range c.dirs {
+		log.Debugf("Removing '%s'", dir)
+		err := c.fs.Remove(dir)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *removeDirsCommand) RenderScript(w *bufio.Writer) error {
+	if len(c.dirs) > 0 {
+		fmt.Fprintf(w, "rmdir %s\n", strings.Join(c.dirs, " "))
+	}
+	return w.Flush()
+}
+
+func (c *removeDirsCommand) Describe() Description {
+	return Description{
+		Type: "remove_dirs",
+		Args: c.dirs,
+		Desc: fmt.Sprintf("Remove the directories '%s'", strings.Join(c.dirs, "', '")),
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package commands_test
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors/commands"
+)
+
+func TestMkdirAllCmdExecute(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, fs.MkdirAll("/etc", 0755))
+	cmd := commands.NewMkdirAllCmd(fs, "/etc/sysctl.d/redpanda", 0750)
+	inverse, err := cmd.(commands.Reversible).Inverse()
+	require.NoError(t, err)
+	require.NoError(t, cmd.Execute())
+	require.True(t, cmd.(commands.ResultReporter).Result().Changed)
+
+	info, err := fs.Stat("/etc/sysctl.d/redpanda")
+	require.NoError(t, err)
+	require.True(t, info.IsDir())
+	require.Equal(t, os.FileMode(0750), info.Mode().Perm())
+	info, err = fs.Stat("/etc/sysctl.d")
+	require.NoError(t, err)
+	require.True(t, info.IsDir())
+
+	// Only the directories which were created are removed.
+	require.NoError(t, inverse.Execute())
+	exists, err := afero.DirExists(fs, "/etc/sysctl.d")
+	require.NoError(t, err)
+	require.False(t, exists)
+	exists, err = afero.DirExists(fs, "/etc")
+	require.NoError(t, err)
+	require.True(t, exists)
+}
+
+func TestMkdirAllCmdExecuteExisting(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     os.FileMode
+		expected os.FileMode
+	}{
+		{
+			name:     "it should be a no-op if the mode is the same",
+			mode:     0755,
+			expected: 0755,
+		},
+		{
+			name:     "it shouldn't widen the mode of the directory",
+			mode:     0777,
+			expected: 0700,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			fs := afero.NewMemMapFs()
+			require.NoError(st, fs.MkdirAll("/var/lib/redpanda", tt.expected))
+			cmd := commands.NewMkdirAllCmd(fs, "/var/lib/redpanda", tt.mode)
+			require.NoError(st, cmd.Execute())
+			res := cmd.(commands.ResultReporter).Result()
+			require.False(st, res.Changed)
+			info, err := fs.Stat("/var/lib/redpanda")
+			require.NoError(st, err)
+			require.Equal(st, tt.expected, info.Mode().Perm())
+		})
+	}
+}
+
+func TestMkdirAllCmdExecuteFile(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/var/lib/redpanda", []byte{}, 0644))
+	err := commands.NewMkdirAllCmd(fs, "/var/lib/redpanda", 0755).Execute()
+	require.EqualError(t, err, "'/var/lib/redpanda' already exists and isn't a directory")
+}
+
+func TestMkdirAllCmdRender(t *testing.T) {
+	cmd := commands.NewMkdirAllCmd(afero.NewMemMapFs(), "/sys/fs/cgroup/redpanda", 0755)
+	var buf bytes.Buffer
+	require.NoError(t, cmd.RenderScript(bufio.NewWriter(&buf)))
+	require.Equal(t, "mkdir -p -m 0755 /sys/fs/cgroup/redpanda\n", buf.String())
+}
@@ -0,0 +1,88 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+)
+
+type reconfigureCommand struct {
+	configure Command
+	restart   Command
+}
+
+// Creates a command executing configure, e.g. a write to a service's config
+// file, and then restart, so that the service picks the change up. Its
+// inverse reverts configure and then executes restart again, which reverting
+// each of them in reverse order wouldn't do. So configure must be reversible
+// (see Reversible) for the command to be.
+func NewReconfigureCmd(configure Command, restart Command) Command {
+	return &reconfigureCommand{configure: configure, restart: restart}
+}
+
+func (c *reconfigureCommand) Execute() error {
+	err := c.configure.Execute()
+	if err != nil {
+		return err
+	}
+	return c.restart.Execute()
+}
+
+func (c *reconfigureCommand) RenderScript(w *bufio.Writer) error {
+	return c.RenderScriptContext(nil, w)
+}
+
+func (c *reconfigureCommand) RenderScriptContext(ctx *RenderContext, w *bufio.Writer) error {
+	// The description may span several lines, e.g. if it has the content
+	// written.
+	for _, line := range strings.Split(c.Describe().Desc, "\n") {
+		fmt.Fprintf(w, "# %s\n", line)
+	}
+	for _, cmd := range []Command{c.configure, c.restart} {
+		script, err := renderToString(ctx, cmd)
+		if err != nil {
+			return err
+		}
+		fmt.Fprint(w, script)
+	}
+	return w.Flush()
+}
+
+func (c *reconfigureCommand) Commands() []Command {
+	return []Command{c.configure, c.restart}
+}
+
+func (c *reconfigureCommand) Describe() Description {
+	configure := c.configure.Describe()
+	return Description{
+		Type:   "reconfigure",
+		Target: configure.Target,
+		Args:   []string{configure.Desc, c.restart.Describe().Desc},
+		Desc: fmt.Sprintf(
+			"Reconfigure and restart: %s; %s",
+			configure.Desc,
+			c.restart.Describe().Desc,
+		),
+	}
+}
+
+func (c *reconfigureCommand) Inverse() (Command, error) {
+	r, ok := c.configure.(Reversible)
+	if !ok {
+		return nil, fmt.Errorf("'%s' can't be reverted", c.configure.Describe().Desc)
+	}
+	inverse, err := r.Inverse()
+	if err != nil {
+		return nil, err
+	}
+	return NewReconfigureCmd(inverse, c.restart), nil
+}
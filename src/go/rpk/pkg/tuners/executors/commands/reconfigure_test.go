@@ -0,0 +1,52 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package commands_test
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors/commands"
+)
+
+func TestReconfigureCmd(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/etc/default/svc", []byte("A=1\n"), 0644))
+	restart := commands.NewForExecCmd("systemctl", []string{"try-restart", "svc"})
+	cmd := commands.NewReconfigureCmd(
+		commands.NewWriteFileCmd(fs, "/etc/default/svc", "A=2\n"),
+		restart,
+	)
+
+	var buf bytes.Buffer
+	require.NoError(t, cmd.RenderScript(bufio.NewWriter(&buf)))
+	expected := `# Reconfigure and restart: Write 'A=2
+# ' to '/etc/default/svc'; Run 'systemctl try-restart svc'
+echo 'A=2
+' > /etc/default/svc
+systemctl try-restart svc
+`
+	require.Equal(t, expected, buf.String())
+
+	// The inverse restores the config first, and then restarts the
+	// service again.
+	inverse, err := cmd.(commands.Reversible).Inverse()
+	require.NoError(t, err)
+	buf.Reset()
+	require.NoError(t, inverse.RenderScript(bufio.NewWriter(&buf)))
+	expected = `# Reconfigure and restart: Write 'A=1' to '/etc/default/svc'; Run 'systemctl try-restart svc'
+echo 'A=1' > /etc/default/svc
+systemctl try-restart svc
+`
+	require.Equal(t, expected, buf.String())
+}
@@ -0,0 +1,51 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+)
+
+type removeFileCommand struct {
+	fs   afero.Fs
+	path string
+}
+
+// Removes the file at the given path. It succeeds if the file doesn't exist.
+func NewRemoveFileCmd(fs afero.Fs, path string) Command {
+	return &removeFileCommand{fs: fs, path: path}
+}
+
+func (c *removeFileCommand) Execute() error {
+	log.Debugf("Removing '%s'", c.path)
+	err := c.fs.Remove(c.path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (c *removeFileCommand) RenderScript(w *bufio.Writer) error {
+	fmt.Fprintf(w, "rm -f %s\n", c.path)
+	return w.Flush()
+}
+
+func (c *removeFileCommand) Describe() Description {
+	return Description{
+		Type:   "remove_file",
+		Target: c.path,
+		Desc:   fmt.Sprintf("Remove '%s'", c.path),
+	}
+}
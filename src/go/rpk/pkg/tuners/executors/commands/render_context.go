@@ -0,0 +1,114 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// RenderContext tells the commands rendering a script which of the values
+// they render are late-bound, i.e. were discovered on the host rendering the
+// script (e.g. the data directory's device), but must be resolved by the
+// script when it runs, on the host it runs on. The late-bound values are
+// rendered as expressions of shell variables, which the script's prologue
+// sets. A nil context binds nothing.
+type RenderContext struct {
+	prologue    []string
+	bindings    []pathBinding
+	guardWrites bool
+}
+
+// A path, and the shell expression it's replaced with, e.g. '/dev/sdb' and
+// '/dev/${DATA_DEVICE}'.
+type pathBinding struct {
+	path string
+	expr string
+}
+
+// ContextRenderer is implemented by commands rendering discovered paths,
+// which can be late-bound.
+type ContextRenderer interface {
+	Command
+	// Renders the command like RenderScript, but with the paths bound in
+	// ctx replaced by their expressions.
+	RenderScriptContext(ctx *RenderContext, w *bufio.Writer) error
+}
+
+func NewRenderContext() *RenderContext {
+	return &RenderContext{}
+}
+
+// Adds lines to the script's prologue, which run before any command, e.g. to
+// set the variables the bound paths are replaced with.
+func (c *RenderContext) AddPrologue(lines ...string) {
+	c.prologue = append(c.prologue, lines...)
+}
+
+func (c *RenderContext) Prologue() []string {
+	if c == nil {
+		return nil
+	}
+	return c.prologue
+}
+
+// Makes the file writes render guarded, so that the script only writes the
+// files which don't already hold the content, and can be re-run without
+// rewriting them.
+func (c *RenderContext) GuardWrites() {
+	c.guardWrites = true
+}
+
+// Returns whether the file writes must render guarded (see GuardWrites).
+func (c *RenderContext) WritesGuarded() bool {
+	return c != nil && c.guardWrites
+}
+
+// Binds path, and the paths under it, to the shell expression expr, which
+// may hold variables (e.g. '/sys/block/${DATA_DEVICE}'). When several bound
+// paths hold a path, the longest one is used.
+func (c *RenderContext) Bind(path, expr string) {
+	c.bindings = append(c.bindings, pathBinding{path: filepath.Clean(path), expr: expr})
+}
+
+// Returns path as it must be rendered: as is, if it isn't bound, or with the
+// bound part replaced by its double-quoted expression, e.g.
+// '"/sys/block/${DATA_DEVICE}"/queue/scheduler'. The rest of the path is left
+// unquoted, so that it can still hold globs.
+func (c *RenderContext) Path(path string) string {
+	if c == nil {
+		return path
+	}
+	var found *pathBinding
+	for i := range c.bindings {
+		b := &c.bindings[i]
+		if path != b.path && !strings.HasPrefix(path, b.path+"/") {
+			continue
+		}
+		if found == nil || len(b.path) > len(found.path) {
+			found = b
+		}
+	}
+	if found == nil {
+		return path
+	}
+	return fmt.Sprintf("\"%s\"%s", found.expr, strings.TrimPrefix(path, found.path))
+}
+
+// Renders cmd's script, with the paths bound in ctx replaced if it's a
+// ContextRenderer.
+func RenderScriptContext(ctx *RenderContext, cmd Command, w *bufio.Writer) error {
+	if r, ok := cmd.(ContextRenderer); ok {
+		return r.RenderScriptContext(ctx, w)
+	}
+	return cmd.RenderScript(w)
+}
@@ -0,0 +1,100 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package commands_test
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors/commands"
+)
+
+func TestRenderContextPath(t *testing.T) {
+	ctx := commands.NewRenderContext()
+	ctx.Bind("/sys/block/sdb", "/sys/block/${DATA_DEVICE}")
+	ctx.Bind("/sys/devices/pci0000:00/block/sdb", "/sys/block/${DATA_DEVICE}")
+	ctx.Bind("/sys/devices", "/devices")
+
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"/sys/block/sdb/queue/scheduler", `"/sys/block/${DATA_DEVICE}"/queue/scheduler`},
+		{"/sys/block/sdb", `"/sys/block/${DATA_DEVICE}"`},
+		// The longest bound path wins.
+		{"/sys/devices/pci0000:00/block/sdb/queue/nomerges", `"/sys/block/${DATA_DEVICE}"/queue/nomerges`},
+		// Only whole path elements are bound.
+		{"/sys/block/sdba/queue/scheduler", "/sys/block/sdba/queue/scheduler"},
+		{"/proc/sys/vm/swappiness", "/proc/sys/vm/swappiness"},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.expected, ctx.Path(tt.path))
+	}
+
+	var nilCtx *commands.RenderContext
+	require.Equal(t, "/sys/block/sdb", nilCtx.Path("/sys/block/sdb"))
+}
+
+func TestRenderScriptContext(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/sys/block/sdb/queue/scheduler", []byte("none\n"), 0644))
+	require.NoError(t, afero.WriteFile(fs, "/sys/block/sdb/queue/nomerges", []byte("0\n"), 0644))
+	ctx := commands.NewRenderContext()
+	ctx.Bind("/sys/block/sdb", "/sys/block/${DATA_DEVICE}")
+
+	tests := []struct {
+		name     string
+		cmd      commands.Command
+		expected string
+	}{
+		{
+			name:     "it should bind the written file",
+			cmd:      commands.NewWriteFileCmd(fs, "/sys/block/sdb/queue/scheduler", "noop"),
+			expected: "echo 'noop' > \"/sys/block/${DATA_DEVICE}\"/queue/scheduler\n",
+		},
+		{
+			name: "it should bind the glob, leaving the pattern unquoted",
+			cmd:  commands.NewWriteToGlobCmd(fs, "/sys/block/sdb/queue/*", "2"),
+			expected: "for f in \"/sys/block/${DATA_DEVICE}\"/queue/*; do" +
+				" [ -e \"$f\" ] || continue; echo '2' > \"$f\"; done\n",
+		},
+		{
+			name: "it should bind the batched commands and their rollback",
+			cmd: commands.NewBatchCmd(
+				commands.NewWriteFileCmd(fs, "/sys/block/sdb/queue/nomerges", "2"),
+			),
+			expected: `(
+set -e
+applied=0
+rollback() {
+if [ "$applied" -ge 1 ]; then
+echo '0' > "/sys/block/${DATA_DEVICE}"/queue/nomerges
+fi
+:
+}
+trap 'rollback; exit 1' ERR
+echo '2' > "/sys/block/${DATA_DEVICE}"/queue/nomerges
+applied=1
+)
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			var buf bytes.Buffer
+			w := bufio.NewWriter(&buf)
+			require.NoError(st, commands.RenderScriptContext(ctx, tt.cmd, w))
+			require.Equal(st, tt.expected, buf.String())
+		})
+	}
+}
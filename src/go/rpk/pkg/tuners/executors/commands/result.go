@@ -0,0 +1,37 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package commands
+
+// Result describes the effect an executed command had on the system.
+type Result struct {
+	// The file or key the command acted upon.
+	Target string
+	// Whether the command actually modified the target. It's false when the
+	// target was already in the desired state.
+	Changed bool
+	// The target's value before and after the command was executed.
+	Old string
+	New string
+}
+
+// ResultReporter is implemented by commands which, once executed, can tell
+// whether they changed anything.
+type ResultReporter interface {
+	Result() Result
+}
+
+// Previewer is implemented by commands which can tell, without being
+// executed, the effect they'd have: their target's current value and whether
+// they'd change it. Commands which don't implement it can only be described
+// (see Command.Describe).
+type Previewer interface {
+	Command
+	Preview() (Result, error)
+}
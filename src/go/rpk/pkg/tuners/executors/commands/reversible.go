@@ -0,0 +1,86 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+// Reversible is implemented by commands which mutate the system and are able
+// to produce a command restoring its current state.
+type Reversible interface {
+	Command
+	// Inverse snapshots the state the command is about to change, and returns
+	// a command which restores it. It must be called before Execute. If the
+	// current state can't be read, it returns an error instead of recording
+	// an empty value.
+	Inverse() (Command, error)
+}
+
+// Returns a command restoring the current content and mode of the file at
+// path, or removing it if it doesn't exist.
+func restoreFileCmd(fs afero.Fs, path string) (Command, error) {
+	info, err := fs.Stat(path)
+	if os.IsNotExist(err) {
+		return NewRemoveFileCmd(fs, path), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf(
+			"couldn't read the current state of '%s': %w",
+			path,
+			err,
+		)
+	}
+	content, err := afero.ReadFile(fs, path)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"couldn't read the current value of '%s': %w",
+			path,
+			err,
+		)
+	}
+	return NewWriteFileModeCmd(
+		fs,
+		path,
+		selectedValue(string(content)),
+		info.Mode(),
+	), nil
+}
+
+// Returns the value to write back to restore a file's content. Single-line
+// files, such as the ones in sysfs and procfs, are stripped of the trailing
+// newline the kernel appends when they're read. Some sysfs files list all the
+// valid options, wrapping the selected one in brackets, e.g.
+// 'always [madvise] never', in which case the selected option is returned.
+func selectedValue(content string) string {
+	trimmed := strings.TrimSuffix(content, "\n")
+	if strings.Contains(trimmed, "\n") {
+		return content
+	}
+	selected := ""
+	for _, field := range strings.Fields(trimmed) {
+		if len(field) > 2 &&
+			strings.HasPrefix(field, "[") &&
+			strings.HasSuffix(field, "]") {
+			if selected != "" {
+				return trimmed
+			}
+			selected = field[1 : len(field)-1]
+		}
+	}
+	if selected == "" {
+		return trimmed
+	}
+	return selected
+}
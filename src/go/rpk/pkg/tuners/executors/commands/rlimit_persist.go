@@ -0,0 +1,153 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"syscall"
+
+	"github.com/spf13/afero"
+)
+
+const limitsFileTemplate = "/etc/security/limits.d/99-redpanda-%s.conf"
+
+type persistentRlimitCommand struct {
+	resource int
+	soft     uint64
+	hard     uint64
+	set      Command
+	write    Command
+	result   Result
+}
+
+// Creates a command setting the current process' limits for resource like
+// NewSetRlimitCmd, and persisting them in a file in /etc/security/limits.d/
+// (e.g. 99-redpanda-nofile.conf), so that they apply to the sessions opened
+// from then on too. Its inverse restores the file, and sets the limits back
+// to their current values.
+func NewPersistentRlimitCmd(
+	fs afero.Fs, resource int, soft, hard uint64,
+) Command {
+	item := rlimitName(resource)
+	content := fmt.Sprintf(
+		"* soft %s %s\n* hard %s %s\n",
+		item,
+		limitString(soft),
+		item,
+		limitString(hard),
+	)
+	return &persistentRlimitCommand{
+		resource: resource,
+		soft:     soft,
+		hard:     hard,
+		set:      NewSetRlimitCmd(resource, soft, hard),
+		write:    NewWriteFileCmd(fs, LimitsFile(resource), content),
+	}
+}
+
+// Returns the file the limits of resource are persisted to.
+func LimitsFile(resource int) string {
+	return fmt.Sprintf(limitsFileTemplate, rlimitName(resource))
+}
+
+func (c *persistentRlimitCommand) Execute() error {
+	if _, ok := rlimitNames[c.resource]; !ok {
+		return fmt.Errorf("can't persist the limits of resource %d", c.resource)
+	}
+	c.result = Result{
+		Target: rlimitName(c.resource),
+		New:    fmt.Sprintf("%d %d", c.soft, c.hard),
+	}
+	var current syscall.Rlimit
+	err := syscall.Getrlimit(c.resource, &current)
+	if err != nil {
+		return err
+	}
+	c.result.Old = fmt.Sprintf("%d %d", current.Cur, current.Max)
+	err = c.set.Execute()
+	if err != nil {
+		return err
+	}
+	err = c.write.Execute()
+	if err != nil {
+		return err
+	}
+	written := c.write.(ResultReporter).Result()
+	c.result.Changed = c.result.Old != c.result.New || written.Changed
+	return nil
+}
+
+func (c *persistentRlimitCommand) RenderScript(w *bufio.Writer) error {
+	for _, cmd := range c.Commands() {
+		script, err := renderToString(nil, cmd)
+		if err != nil {
+			return err
+		}
+		fmt.Fprint(w, script)
+	}
+	return w.Flush()
+}
+
+func (c *persistentRlimitCommand) Commands() []Command {
+	return []Command{c.set, c.write}
+}
+
+func (c *persistentRlimitCommand) Describe() Description {
+	name := rlimitName(c.resource)
+	file := LimitsFile(c.resource)
+	return Description{
+		Type:   "rlimit_persist",
+		Target: name,
+		Args:   []string{fmt.Sprint(c.soft), fmt.Sprint(c.hard), file},
+		Desc: fmt.Sprintf(
+			"Set the limits of '%s' to %d (soft), %d (hard) and persist them in '%s'",
+			name,
+			c.soft,
+			c.hard,
+			file,
+		),
+	}
+}
+
+func (c *persistentRlimitCommand) ProducedFiles() []string {
+	return []string{LimitsFile(c.resource)}
+}
+
+func (c *persistentRlimitCommand) Inverse() (Command, error) {
+	restore, err := c.write.(Reversible).Inverse()
+	if err != nil {
+		return nil, err
+	}
+	reset, err := c.set.(Reversible).Inverse()
+	if err != nil {
+		return nil, err
+	}
+	return NewBatchCmd(restore, reset), nil
+}
+
+// Previews the change to the process' limits, which are reported as changed
+// too if the file would be.
+func (c *persistentRlimitCommand) Preview() (Result, error) {
+	res, err := c.set.(Previewer).Preview()
+	if err != nil {
+		return res, err
+	}
+	file, err := c.write.(Previewer).Preview()
+	if err != nil {
+		return res, err
+	}
+	res.Changed = res.Changed || file.Changed
+	return res, nil
+}
+
+func (c *persistentRlimitCommand) Result() Result {
+	return c.result
+}
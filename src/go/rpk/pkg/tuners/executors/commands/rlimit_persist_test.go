@@ -0,0 +1,64 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package commands_test
+
+import (
+	"bufio"
+	"bytes"
+	"syscall"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors/commands"
+)
+
+func TestPersistentRlimitCmdRender(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	cmd := commands.NewPersistentRlimitCmd(fs, syscall.RLIMIT_CORE, 0, ^uint64(0))
+	var buf bytes.Buffer
+	require.NoError(t, cmd.RenderScript(bufio.NewWriter(&buf)))
+	expected := `ulimit -Hc unlimited
+ulimit -Sc 0
+echo '* soft core 0
+* hard core unlimited
+' > /etc/security/limits.d/99-redpanda-core.conf
+chmod 644 /etc/security/limits.d/99-redpanda-core.conf
+`
+	require.Equal(t, expected, buf.String())
+}
+
+func TestPersistentRlimitCmdExecute(t *testing.T) {
+	var limit syscall.Rlimit
+	require.NoError(t, syscall.Getrlimit(syscall.RLIMIT_NOFILE, &limit))
+	fs := afero.NewMemMapFs()
+	// Lowering the soft limit doesn't require privileges.
+	cmd := commands.NewPersistentRlimitCmd(fs, syscall.RLIMIT_NOFILE, limit.Cur-1, limit.Max)
+	inverse, err := cmd.(commands.Reversible).Inverse()
+	require.NoError(t, err)
+	require.NoError(t, cmd.Execute())
+	require.True(t, cmd.(commands.ResultReporter).Result().Changed)
+
+	var current syscall.Rlimit
+	require.NoError(t, syscall.Getrlimit(syscall.RLIMIT_NOFILE, &current))
+	require.Equal(t, limit.Cur-1, current.Cur)
+	file := commands.LimitsFile(syscall.RLIMIT_NOFILE)
+	require.Equal(t, "/etc/security/limits.d/99-redpanda-nofile.conf", file)
+	content, err := afero.ReadFile(fs, file)
+	require.NoError(t, err)
+	require.Contains(t, string(content), "* soft nofile ")
+
+	require.NoError(t, inverse.Execute())
+	require.NoError(t, syscall.Getrlimit(syscall.RLIMIT_NOFILE, &current))
+	require.Equal(t, limit, current)
+	exists, err := afero.Exists(fs, file)
+	require.NoError(t, err)
+	require.False(t, exists)
+}
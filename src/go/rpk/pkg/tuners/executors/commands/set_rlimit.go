@@ -0,0 +1,132 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"syscall"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// The names ulimit(1) and limits.conf(5) know each resource by.
+var rlimitNames = map[int]struct {
+	ulimitFlag string
+	item       string
+}{
+	syscall.RLIMIT_NOFILE: {"n", "nofile"},
+	syscall.RLIMIT_CORE:   {"c", "core"},
+}
+
+// RLIM_INFINITY, the value of the limits which aren't enforced.
+const rlimInfinity = ^uint64(0)
+
+type setRlimitCommand struct {
+	resource int
+	soft     uint64
+	hard     uint64
+}
+
+// Creates a command setting the current process' soft and hard limits for
+// resource (e.g. syscall.RLIMIT_NOFILE). They're inherited by the processes
+// it launches, such as redpanda when started with 'rpk start'. Raising the
+// hard limit requires privileges, otherwise Execute fails with an error
+// wrapping syscall.EPERM.
+// The rendered script sets the limits with ulimit. See
+// NewPersistentRlimitCmd to have them apply to new sessions too.
+func NewSetRlimitCmd(resource int, soft, hard uint64) Command {
+	return &setRlimitCommand{resource: resource, soft: soft, hard: hard}
+}
+
+func (c *setRlimitCommand) Execute() error {
+	log.Debugf("Setting the limits of '%s' to %d (soft), %d (hard)", c.name(), c.soft, c.hard)
+	err := syscall.Setrlimit(
+		c.resource,
+		&syscall.Rlimit{Cur: c.soft, Max: c.hard},
+	)
+	if err != nil {
+		return fmt.Errorf("couldn't set the limits of '%s': %w", c.name(), err)
+	}
+	return nil
+}
+
+func (c *setRlimitCommand) RenderScript(w *bufio.Writer) error {
+	names, ok := rlimitNames[c.resource]
+	if !ok {
+		return fmt.Errorf("can't render the limits of resource %d", c.resource)
+	}
+	// The hard limit goes first, as the soft one can't exceed it.
+	fmt.Fprintf(w, "ulimit -H%s %s\n", names.ulimitFlag, limitString(c.hard))
+	fmt.Fprintf(w, "ulimit -S%s %s\n", names.ulimitFlag, limitString(c.soft))
+	return w.Flush()
+}
+
+func (c *setRlimitCommand) Describe() Description {
+	return Description{
+		Type:   "set_rlimit",
+		Target: c.name(),
+		Args:   []string{fmt.Sprint(c.soft), fmt.Sprint(c.hard)},
+		Desc: fmt.Sprintf(
+			"Set the limits of '%s' to %d (soft), %d (hard)",
+			c.name(),
+			c.soft,
+			c.hard,
+		),
+	}
+}
+
+func (c *setRlimitCommand) Inverse() (Command, error) {
+	var current syscall.Rlimit
+	err := syscall.Getrlimit(c.resource, &current)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"couldn't read the current limits of '%s': %w",
+			c.name(),
+			err,
+		)
+	}
+	return NewSetRlimitCmd(c.resource, current.Cur, current.Max), nil
+}
+
+func (c *setRlimitCommand) Preview() (Result, error) {
+	res := Result{
+		Target:  c.name(),
+		New:     fmt.Sprintf("%d %d", c.soft, c.hard),
+		Changed: true,
+	}
+	var current syscall.Rlimit
+	err := syscall.Getrlimit(c.resource, &current)
+	if err != nil {
+		return res, err
+	}
+	res.Old = fmt.Sprintf("%d %d", current.Cur, current.Max)
+	res.Changed = res.Old != res.New
+	return res, nil
+}
+
+func (c *setRlimitCommand) name() string {
+	return rlimitName(c.resource)
+}
+
+func rlimitName(resource int) string {
+	if names, ok := rlimitNames[resource]; ok {
+		return names.item
+	}
+	return fmt.Sprint(resource)
+}
+
+// Returns limit as ulimit(1) and limits.conf(5) take it.
+func limitString(limit uint64) string {
+	if limit == rlimInfinity {
+		return "unlimited"
+	}
+	return fmt.Sprint(limit)
+}
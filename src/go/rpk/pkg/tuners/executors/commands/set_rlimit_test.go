@@ -0,0 +1,72 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package commands_test
+
+import (
+	"bufio"
+	"bytes"
+	"syscall"
+	"testing"
+
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors/commands"
+)
+
+func TestSetRlimitCmdRender(t *testing.T) {
+	cmd := commands.NewSetRlimitCmd(syscall.RLIMIT_NOFILE, 1048576, 2097152)
+	var buf bytes.Buffer
+	w := bufio.NewWriter(&buf)
+	err := cmd.RenderScript(w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `ulimit -Hn 2097152
+ulimit -Sn 1048576
+`
+	if buf.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
+
+func TestSetRlimitCmdExecute(t *testing.T) {
+	var limit syscall.Rlimit
+	err := syscall.Getrlimit(syscall.RLIMIT_NOFILE, &limit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Lowering the soft limit doesn't require privileges.
+	cmd := commands.NewSetRlimitCmd(syscall.RLIMIT_NOFILE, limit.Cur-1, limit.Max)
+	inverse, err := cmd.(commands.Reversible).Inverse()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cmd.Execute()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var current syscall.Rlimit
+	err = syscall.Getrlimit(syscall.RLIMIT_NOFILE, &current)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if current.Cur != limit.Cur-1 {
+		t.Errorf("expected the soft limit to be %d, got %d", limit.Cur-1, current.Cur)
+	}
+	err = inverse.Execute()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = syscall.Getrlimit(syscall.RLIMIT_NOFILE, &current)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if current != limit {
+		t.Errorf("expected the limits to be restored to %+v, got %+v", limit, current)
+	}
+}
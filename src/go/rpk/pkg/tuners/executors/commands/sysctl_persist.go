@@ -0,0 +1,129 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"regexp"
+
+	"github.com/spf13/afero"
+)
+
+// The file the sysctl properties persisted by rpk are written to. Its name
+// sorts after the other files in /etc/sysctl.d, so its values take
+// precedence when they're loaded at boot.
+const SysctlDropInFile = "/etc/sysctl.d/99-redpanda.conf"
+
+type persistentSysctlSetCommand struct {
+	fs     afero.Fs
+	key    string
+	value  string
+	ensure Command
+	load   Command
+}
+
+// Creates a command setting the sysctl property key to value, and persisting
+// it so that it's set again on every boot: the 'key = value' line is written
+// to SysctlDropInFile (replacing the key's line if the file has it already,
+// e.g. with another value), which is then loaded with 'sysctl -p', applying
+// the value right away. Its inverse restores the file, and sets the property
+// back to its current value.
+func NewPersistentSysctlSetCmd(fs afero.Fs, key, value string) Command {
+	match := regexp.MustCompile(
+		fmt.Sprintf(`^[[:space:]]*%s[[:space:]]*=`, regexp.QuoteMeta(key)),
+	)
+	return &persistentSysctlSetCommand{
+		fs:    fs,
+		key:   key,
+		value: value,
+		ensure: NewEnsureLineInFileReplacingCmd(
+			fs,
+			SysctlDropInFile,
+			fmt.Sprintf("%s = %s", key, value),
+			match,
+		),
+		load: NewForExecCmd("sysctl", []string{"-p", SysctlDropInFile}),
+	}
+}
+
+func (c *persistentSysctlSetCommand) Execute() error {
+	err := c.ensure.Execute()
+	if err != nil {
+		return err
+	}
+	return c.load.Execute()
+}
+
+func (c *persistentSysctlSetCommand) RenderScript(w *bufio.Writer) error {
+	return c.RenderScriptContext(nil, w)
+}
+
+func (c *persistentSysctlSetCommand) RenderScriptContext(
+	ctx *RenderContext, w *bufio.Writer,
+) error {
+	for _, cmd := range []Command{c.ensure, c.load} {
+		script, err := renderToString(ctx, cmd)
+		if err != nil {
+			return err
+		}
+		fmt.Fprint(w, script)
+	}
+	return w.Flush()
+}
+
+func (c *persistentSysctlSetCommand) Commands() []Command {
+	return []Command{c.ensure, c.load}
+}
+
+func (c *persistentSysctlSetCommand) Describe() Description {
+	return Description{
+		Type:   "sysctl_persist",
+		Target: c.key,
+		Args:   []string{c.value, SysctlDropInFile},
+		Desc: fmt.Sprintf(
+			"Set sysctl '%s' to '%s' and persist it in '%s'",
+			c.key,
+			c.value,
+			SysctlDropInFile,
+		),
+	}
+}
+
+func (c *persistentSysctlSetCommand) ProducedFiles() []string {
+	return []string{SysctlDropInFile}
+}
+
+func (c *persistentSysctlSetCommand) Inverse() (Command, error) {
+	restore, err := c.ensure.(Reversible).Inverse()
+	if err != nil {
+		return nil, err
+	}
+	reset, err := NewSysctlSetCmd(c.key, c.value).(Reversible).Inverse()
+	if err != nil {
+		return nil, err
+	}
+	return NewBatchCmd(restore, reset), nil
+}
+
+// Previews the change to the property's live value, which is reported as
+// changed too if the file would be.
+func (c *persistentSysctlSetCommand) Preview() (Result, error) {
+	res, err := NewSysctlSetCmd(c.key, c.value).(Previewer).Preview()
+	if err != nil {
+		return res, err
+	}
+	file, err := c.ensure.(Previewer).Preview()
+	if err != nil {
+		return res, err
+	}
+	res.Changed = res.Changed || file.Changed
+	return res, nil
+}
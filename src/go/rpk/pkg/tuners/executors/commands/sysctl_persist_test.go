@@ -0,0 +1,51 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package commands_test
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors/commands"
+)
+
+func TestPersistentSysctlSetCmdRender(t *testing.T) {
+	cmd := commands.NewPersistentSysctlSetCmd(
+		afero.NewMemMapFs(),
+		"vm.max_map_count",
+		"262144",
+	)
+	var buf bytes.Buffer
+	require.NoError(t, cmd.RenderScript(bufio.NewWriter(&buf)))
+	expected := `if grep -qE '^[[:space:]]*vm\.max_map_count[[:space:]]*=' /etc/sysctl.d/99-redpanda.conf 2>/dev/null; then
+sed -i -E 's/^[[:space:]]*vm\.max_map_count[[:space:]]*=.*/vm.max_map_count = 262144/' /etc/sysctl.d/99-redpanda.conf
+else
+if [ -s /etc/sysctl.d/99-redpanda.conf ] && [ -n "$(tail -c1 /etc/sysctl.d/99-redpanda.conf)" ]; then
+printf '\n%s' 'vm.max_map_count = 262144' >> /etc/sysctl.d/99-redpanda.conf
+else
+printf '%s\n' 'vm.max_map_count = 262144' >> /etc/sysctl.d/99-redpanda.conf
+fi
+fi
+sysctl -p /etc/sysctl.d/99-redpanda.conf
+`
+	require.Equal(t, expected, buf.String())
+
+	desc := cmd.Describe()
+	require.Equal(t, "sysctl_persist", desc.Type)
+	require.Equal(t, "vm.max_map_count", desc.Target)
+	require.Equal(
+		t,
+		[]string{commands.SysctlDropInFile},
+		cmd.(commands.FileProducer).ProducedFiles(),
+	)
+}
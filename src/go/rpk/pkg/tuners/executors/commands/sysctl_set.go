@@ -12,6 +12,7 @@ package commands
 import (
 	"bufio"
 	"fmt"
+	"strings"
 
 	"github.com/lorenzosaino/go-sysctl"
 	log "github.com/sirupsen/logrus"
@@ -36,6 +37,44 @@ func (c *sysctlSetCommand) Execute() error {
 }
 
 func (c *sysctlSetCommand) RenderScript(w *bufio.Writer) error {
-	fmt.Fprintf(w, "sysctl -w %s=%s\n", c.key, c.value)
+	value := c.value
+	// Some properties, like net.ipv4.tcp_rmem, hold several values.
+	if strings.ContainsAny(value, " \t") {
+		value = fmt.Sprintf("'%s'", value)
+	}
+	fmt.Fprintf(w, "sysctl -w %s=%s\n", c.key, value)
 	return w.Flush()
 }
+
+func (c *sysctlSetCommand) Describe() Description {
+	return Description{
+		Type:   "sysctl_set",
+		Target: c.key,
+		Args:   []string{c.value},
+		Desc:   fmt.Sprintf("Set sysctl '%s' to '%s'", c.key, c.value),
+	}
+}
+
+func (c *sysctlSetCommand) Inverse() (Command, error) {
+	value, err := sysctl.Get(c.key)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"couldn't read the current value of '%s': %w",
+			c.key,
+			err,
+		)
+	}
+	return NewSysctlSetCmd(c.key, value), nil
+}
+
+func (c *sysctlSetCommand) Preview() (Result, error) {
+	res := Result{Target: c.key, New: c.value, Changed: true}
+	current, err := sysctl.Get(c.key)
+	if err != nil {
+		return res, err
+	}
+	res.Old = current
+	res.Changed = strings.Join(strings.Fields(current), " ") !=
+		strings.Join(strings.Fields(c.value), " ")
+	return res, nil
+}
@@ -53,3 +53,12 @@ EOF
 	_, err = fmt.Fprint(w, "sudo systemctl daemon-reload\n")
 	return err
 }
+
+func (cmd *installSystemdUnitCommand) Describe() Description {
+	return Description{
+		Type:   "systemd_install_unit",
+		Target: systemd.UnitPath(cmd.name),
+		Args:   []string{cmd.body},
+		Desc:   fmt.Sprintf("Install systemd unit '%s'", cmd.name),
+	}
+}
@@ -38,3 +38,11 @@ func (cmd *startSystemdUnitCommand) RenderScript(w *bufio.Writer) error {
 	_, err := fmt.Fprintf(w, "sudo systemctl start %s\n", cmd.name)
 	return err
 }
+
+func (cmd *startSystemdUnitCommand) Describe() Description {
+	return Description{
+		Type:   "systemd_start_unit",
+		Target: cmd.name,
+		Desc:   fmt.Sprintf("Start systemd unit '%s'", cmd.name),
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package commands
+
+import (
+	"strings"
+)
+
+// Verifiable is implemented by commands whose effect can be read back, e.g.
+// file writes. Some writes to sysfs and procfs succeed but are silently
+// ignored by the kernel (e.g. if it doesn't accept the value), which Verify
+// catches.
+type Verifiable interface {
+	Command
+	// Verify re-reads the state the command changed, returning an error if
+	// it doesn't hold what was written. It must be called after Execute.
+	Verify() error
+}
+
+// Returns whether read, the value read back from a file, reflects written.
+// Besides the exact value, it accepts different whitespace (e.g. tabs instead
+// of spaces between values), the bracketed selection format used by files such
+// as THP's 'enabled', where writing 'madvise' reads 'always [madvise] never',
+// and zero-padded hex groups, as CPU masks are read back, where writing 'ff,1'
+// reads '000000ff,00000001'.
+func readBackMatches(written, read string) bool {
+	writtenFields := strings.Fields(written)
+	readFields := strings.Fields(read)
+	if strings.Join(writtenFields, " ") == strings.Join(readFields, " ") {
+		return true
+	}
+	if len(writtenFields) == 1 {
+		for _, f := range readFields {
+			if f == "["+writtenFields[0]+"]" {
+				return true
+			}
+		}
+	}
+	if len(writtenFields) != 1 || len(readFields) != 1 {
+		return false
+	}
+	writtenGroups := strings.Split(writtenFields[0], ",")
+	readGroups := strings.Split(readFields[0], ",")
+	if len(writtenGroups) != len(readGroups) {
+		return false
+	}
+	for i := range writtenGroups {
+		w := strings.TrimLeft(writtenGroups[i], "0")
+		r := strings.TrimLeft(readGroups[i], "0")
+		if w != r || !isHex(w) {
+			return false
+		}
+	}
+	return true
+}
+
+func isHex(s string) bool {
+	for _, c := range s {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", c) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,186 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+// +build linux
+
+package commands
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"golang.org/x/sys/unix"
+)
+
+// The size of the chunks of zeros written when fallocate isn't supported.
+const zerosChunkSize = 1 << 20
+
+type allocatedFileCommand struct {
+	path      string
+	sizeBytes int64
+	result    Result
+}
+
+// Creates a file of size sizeBytes at the given path, whose blocks are all
+// allocated, unlike those of the sparse files created by truncate. It's
+// meant for the files disk benchmarks run on, whose numbers would be skewed
+// by the filesystem allocating the blocks as they're written.
+// The blocks are reserved with fallocate. If the filesystem doesn't support
+// it, zeros are written to the whole file instead, which overwrites its
+// content if it already existed. Which of the two was used is logged. The
+// script rendered through a ScriptRenderingExecutor calls `fallocate`, and
+// falls back to `dd` in the same cases.
+func NewAllocatedFileCmd(path string, sizeBytes int64) Command {
+	return &allocatedFileCommand{
+		path:      path,
+		sizeBytes: sizeBytes,
+		result:    Result{Target: path, New: strconv.FormatInt(sizeBytes, 10)},
+	}
+}
+
+func (c *allocatedFileCommand) Execute() error {
+	fi, err := os.Stat(c.path)
+	if err == nil {
+		c.result.Old = strconv.FormatInt(fi.Size(), 10)
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	log.Debugf("Creating '%s' (%d B), with its blocks allocated", c.path, c.sizeBytes)
+
+	// See NewWriteSizedFileCmd for why 'os' is used instead of 'afero'.
+	f, err := os.OpenFile(c.path, os.O_WRONLY|os.O_CREATE, 0666)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	err = unix.Fallocate(int(f.Fd()), 0, 0, c.sizeBytes)
+	switch {
+	case err == nil:
+		log.Infof("Allocated '%s' (%d B) with fallocate", c.path, c.sizeBytes)
+	case errors.Is(err, unix.EOPNOTSUPP) || errors.Is(err, unix.ENOSYS):
+		log.Infof(
+			"The filesystem of '%s' doesn't support fallocate, writing"+
+				" %d B of zeros to it instead",
+			c.path,
+			c.sizeBytes,
+		)
+		err = writeZeros(f, c.sizeBytes)
+		if err != nil {
+			return fmt.Errorf("could not write zeros to '%s': %w", c.path, err)
+		}
+	default:
+		return fmt.Errorf("could not allocate the requested size while"+
+			" creating the file at '%s': %w",
+			c.path,
+			err,
+		)
+	}
+
+	// Both fallocate and the zeros leave a larger file as large as it was.
+	err = f.Truncate(c.sizeBytes)
+	if err != nil {
+		return fmt.Errorf("could not resize the file at '%s' to the requested size: %w",
+			c.path,
+			err,
+		)
+	}
+	err = f.Sync()
+	if err != nil {
+		return fmt.Errorf("unable to sync the file at %s: %w", c.path, err)
+	}
+	c.result.Changed = c.result.Old != c.result.New
+	return nil
+}
+
+// Writes sizeBytes zeros from the start of f.
+func writeZeros(f *os.File, sizeBytes int64) error {
+	zeros := make([]byte, zerosChunkSize)
+	for offset := int64(0); offset < sizeBytes; offset += zerosChunkSize {
+		chunk := zeros
+		if left := sizeBytes - offset; left < zerosChunkSize {
+			chunk = zeros[:left]
+		}
+		_, err := f.WriteAt(chunk, offset)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *allocatedFileCommand) RenderScript(w *bufio.Writer) error {
+	// See 'man fallocate' and 'man dd'. Like Execute, the script only falls
+	// back to dd when the filesystem doesn't support fallocate (EOPNOTSUPP
+	// or ENOSYS), and fails on any other error, e.g. ENOSPC. dd rounds the
+	// size up to a whole number of chunks, which truncate then trims.
+	chunks := (c.sizeBytes + zerosChunkSize - 1) / zerosChunkSize
+	path := ShellQuote(c.path)
+	fmt.Fprintf(
+		w,
+		"if ! err=$(LC_ALL=C fallocate -l %[1]d %[2]s 2>&1); then\n"+
+			"case \"$err\" in\n"+
+			"*'not supported'*|*'not implemented'*)\n"+
+			"dd if=/dev/zero of=%[2]s bs=%[3]d count=%[4]d conv=fsync status=none\n"+
+			";;\n"+
+			"*)\n"+
+			"echo \"$err\" >&2\n"+
+			"exit 1\n"+
+			";;\n"+
+			"esac\n"+
+			"fi\n",
+		c.sizeBytes,
+		path,
+		zerosChunkSize,
+		chunks,
+	)
+	fmt.Fprintf(w, "truncate -s %d %s\n", c.sizeBytes, path)
+	return w.Flush()
+}
+
+func (c *allocatedFileCommand) Describe() Description {
+	return Description{
+		Type:   "allocated_file",
+		Target: c.path,
+		Args:   []string{strconv.FormatInt(c.sizeBytes, 10)},
+		Desc:   fmt.Sprintf("Create '%s' (%d B), with its blocks allocated", c.path, c.sizeBytes),
+	}
+}
+
+func (c *allocatedFileCommand) Result() Result {
+	return c.result
+}
+
+// Returns a command restoring the file's size, or removing it if it doesn't
+// exist yet. The content the zeros overwrote, when fallocate isn't
+// supported, isn't restored, as the command is meant for scratch files,
+// which aren't worth backing up.
+func (c *allocatedFileCommand) Inverse() (Command, error) {
+	fi, err := os.Stat(c.path)
+	if os.IsNotExist(err) {
+		return NewRemoveFileCmd(afero.NewOsFs(), c.path), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf(
+			"couldn't read the current size of '%s': %w",
+			c.path,
+			err,
+		)
+	}
+	return NewWriteSizedFileCmd(c.path, fi.Size(), true), nil
+}
+
+func (c *allocatedFileCommand) ProducedFiles() []string {
+	return []string{c.path}
+}
@@ -0,0 +1,127 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+// +build linux
+
+package commands_test
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors/commands"
+)
+
+func TestAllocatedFileCmdRender(t *testing.T) {
+	cmd := commands.NewAllocatedFileCmd("/mnt/bench/file", int64(3<<20+1))
+
+	expected := `if ! err=$(LC_ALL=C fallocate -l 3145729 /mnt/bench/file 2>&1); then
+case "$err" in
+*'not supported'*|*'not implemented'*)
+dd if=/dev/zero of=/mnt/bench/file bs=1048576 count=4 conv=fsync status=none
+;;
+*)
+echo "$err" >&2
+exit 1
+;;
+esac
+fi
+truncate -s 3145729 /mnt/bench/file
+`
+	var buf bytes.Buffer
+
+	w := bufio.NewWriter(&buf)
+	require.NoError(t, cmd.RenderScript(w))
+
+	require.Equal(t, expected, buf.String())
+}
+
+func TestAllocatedFileCmdRenderFallback(t *testing.T) {
+	tests := []struct {
+		name      string
+		failure   string
+		expectErr bool
+	}{
+		{
+			name:    "it should write zeros if fallocate isn't supported",
+			failure: "fallocate: fallocate failed: Operation not supported",
+		},
+		{
+			name:      "it should fail if fallocate fails otherwise",
+			failure:   "fallocate: fallocate failed: No space left on device",
+			expectErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			dir := st.TempDir()
+			// A fallocate failing with the given message.
+			fake := "#!/bin/sh\necho '" + tt.failure + "' >&2\nexit 1\n"
+			require.NoError(st, os.WriteFile(filepath.Join(dir, "fallocate"), []byte(fake), 0755))
+			path := filepath.Join(dir, "bench file")
+			cmd := commands.NewAllocatedFileCmd(path, int64(1<<20+1))
+			var buf bytes.Buffer
+			require.NoError(st, cmd.RenderScript(bufio.NewWriter(&buf)))
+
+			sh := exec.Command("sh", "-c", buf.String())
+			sh.Env = append(os.Environ(), "PATH="+dir+":"+os.Getenv("PATH"))
+			out, err := sh.CombinedOutput()
+			if tt.expectErr {
+				require.Error(st, err)
+				require.Contains(st, string(out), tt.failure)
+				_, err = os.Stat(path)
+				require.True(st, os.IsNotExist(err))
+				return
+			}
+			require.NoError(st, err, string(out))
+			fi, err := os.Stat(path)
+			require.NoError(st, err)
+			require.Equal(st, int64(1<<20+1), fi.Size())
+		})
+	}
+}
+
+func TestAllocatedFileCmdExecute(t *testing.T) {
+	// tmpfs is tried along with the test's filesystem, as their support for
+	// fallocate differs.
+	dirs := []string{t.TempDir()}
+	if tmpfs, err := os.MkdirTemp("/dev/shm", "rpk-test-"); err == nil {
+		defer os.RemoveAll(tmpfs)
+		dirs = append(dirs, tmpfs)
+	}
+	for _, dir := range dirs {
+		path := filepath.Join(dir, "bench")
+		// A larger sparse file must be shrunk, and have its blocks
+		// allocated.
+		require.NoError(t, os.WriteFile(path, nil, 0644))
+		require.NoError(t, os.Truncate(path, 3<<20))
+
+		size := int64(2<<20 + 17)
+		cmd := commands.NewAllocatedFileCmd(path, size)
+		require.NoError(t, cmd.Execute())
+
+		res := cmd.(commands.ResultReporter).Result()
+		require.True(t, res.Changed)
+		require.Equal(t, "3145728", res.Old)
+		require.Equal(t, "2097169", res.New)
+
+		fi, err := os.Stat(path)
+		require.NoError(t, err)
+		require.Equal(t, size, fi.Size())
+		// The blocks are counted in 512 B units, see 'man 2 stat'.
+		blocks := fi.Sys().(*syscall.Stat_t).Blocks
+		require.GreaterOrEqual(t, blocks*512, size, "'%s' is sparse", path)
+	}
+}
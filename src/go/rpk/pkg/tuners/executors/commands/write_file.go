@@ -13,6 +13,7 @@ import (
 	"bufio"
 	"fmt"
 	"os"
+	"strings"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/afero"
@@ -25,12 +26,13 @@ type writeFileCommand struct {
 	path    string
 	content string
 	mode    os.FileMode
+	result  Result
 }
 
 func NewWriteFileModeCmd(
 	fs afero.Fs, path string, content string, mode os.FileMode,
 ) Command {
-	return &writeFileCommand{fs, path, content, mode}
+	return &writeFileCommand{fs: fs, path: path, content: content, mode: mode}
 }
 
 func NewWriteFileCmd(fs afero.Fs, path string, content string) Command {
@@ -38,6 +40,19 @@ func NewWriteFileCmd(fs afero.Fs, path string, content string) Command {
 }
 
 func (c *writeFileCommand) Execute() error {
+	c.result = Result{Target: c.path, New: c.content}
+	current, err := afero.ReadFile(c.fs, c.path)
+	if err == nil {
+		c.result.Old = string(current)
+		if sameContent(c.result.Old, c.content) {
+			log.Debugf("'%s' already holds '%s', no change needed", c.path, c.content)
+			return nil
+		}
+	} else if !os.IsNotExist(err) {
+		// Some files (e.g. in sysfs) can't be read, so they're written
+		// blindly.
+		log.Debugf("Couldn't read '%s': %v", c.path, err)
+	}
 	log.Debugf("Writing '%s' to file '%s'", c.content, c.path)
 	mode := c.mode
 	info, err := c.fs.Stat(c.path)
@@ -62,16 +77,89 @@ func (c *writeFileCommand) Execute() error {
 	if n != contentLength {
 		return fmt.Errorf("wrote less bytes than expected: %d out of %d", n, contentLength)
 	}
+	c.result.Changed = true
 	return nil
 }
 
 func (c *writeFileCommand) RenderScript(w *bufio.Writer) error {
-	fmt.Fprintf(w, "echo '%s' > %s\n", c.content, c.path)
+	return c.RenderScriptContext(nil, w)
+}
+
+func (c *writeFileCommand) RenderScriptContext(ctx *RenderContext, w *bufio.Writer) error {
+	path := ctx.Path(c.path)
+	// grep can only match whole single lines.
+	line := strings.TrimSuffix(c.content, "\n")
+	if ctx.WritesGuarded() && !strings.Contains(line, "\n") {
+		fmt.Fprintf(w, "grep -qxF -- %s %s 2>/dev/null || ", ShellQuote(line), path)
+	}
+	fmt.Fprintf(w, "echo '%s' > %s\n", c.content, path)
 	_, err := c.fs.Stat(c.path)
 	// If the file doesn't exist, include a chmod command to set
 	// its mode.
 	if os.IsNotExist(err) {
-		fmt.Fprintf(w, "chmod %o %s\n", uint32(c.mode), c.path)
+		fmt.Fprintf(w, "chmod %o %s\n", uint32(c.mode), path)
 	}
 	return w.Flush()
 }
+
+func (c *writeFileCommand) Describe() Description {
+	return Description{
+		Type:   "write_file",
+		Target: c.path,
+		Args:   []string{c.content, fmt.Sprintf("%o", uint32(c.mode))},
+		Desc:   fmt.Sprintf("Write '%s' to '%s'", c.content, c.path),
+	}
+}
+
+func (c *writeFileCommand) ProducedFiles() []string {
+	return []string{c.path}
+}
+
+func (c *writeFileCommand) Inverse() (Command, error) {
+	return restoreFileCmd(c.fs, c.path)
+}
+
+func (c *writeFileCommand) Preview() (Result, error) {
+	res := Result{Target: c.path, New: c.content, Changed: true}
+	current, err := afero.ReadFile(c.fs, c.path)
+	if os.IsNotExist(err) {
+		return res, nil
+	}
+	if err != nil {
+		return res, err
+	}
+	res.Old = string(current)
+	res.Changed = !sameContent(res.Old, c.content)
+	return res, nil
+}
+
+func (c *writeFileCommand) Verify() error {
+	current, err := afero.ReadFile(c.fs, c.path)
+	if os.IsPermission(err) {
+		log.Debugf("Can't read '%s' to verify the write: %v", c.path, err)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("couldn't verify the write to '%s': %w", c.path, err)
+	}
+	if !readBackMatches(c.content, string(current)) {
+		return fmt.Errorf(
+			"wrote '%s' to '%s' but it now reads '%s'",
+			c.content,
+			c.path,
+			strings.TrimSpace(string(current)),
+		)
+	}
+	return nil
+}
+
+func (c *writeFileCommand) Result() Result {
+	return c.result
+}
+
+// Compares a file's current content to the one about to be written. Files in
+// sysfs and procfs end with a newline when read, even if none was written, so
+// a single trailing newline is ignored.
+func sameContent(current, content string) bool {
+	return strings.TrimSuffix(current, "\n") == strings.TrimSuffix(content, "\n")
+}
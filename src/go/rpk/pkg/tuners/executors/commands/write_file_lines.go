@@ -47,3 +47,20 @@ func (c *writeFileLinesCommand) RenderScript(w *bufio.Writer) error {
 	fmt.Fprintln(w, "EOF")
 	return w.Flush()
 }
+
+func (c *writeFileLinesCommand) Describe() Description {
+	return Description{
+		Type:   "write_file_lines",
+		Target: c.path,
+		Args:   c.lines,
+		Desc:   fmt.Sprintf("Write %d lines to '%s'", len(c.lines), c.path),
+	}
+}
+
+func (c *writeFileLinesCommand) ProducedFiles() []string {
+	return []string{c.path}
+}
+
+func (c *writeFileLinesCommand) Inverse() (Command, error) {
+	return restoreFileCmd(c.fs, c.path)
+}
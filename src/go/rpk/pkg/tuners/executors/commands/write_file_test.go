@@ -143,3 +143,175 @@ func TestWriteFileCmdRenderExistingFile(t *testing.T) {
 		t.Errorf("expected:\n\"%s\"\ngot:\n\"%s\"\n", expected, buf.String())
 	}
 }
+
+func TestWriteFileCmdExecuteUnchanged(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	path := "/sys/block/sda/queue/nomerges"
+	mode := os.FileMode(0600)
+	// sysfs files end in a newline when they're read.
+	err := afero.WriteFile(fs, path, []byte("2\n"), mode)
+	if err != nil {
+		t.Errorf("got an error writing the file: %v", err)
+	}
+	cmd := commands.NewWriteFileCmd(fs, path, "2")
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("an error happened while executing: %v", err)
+	}
+	content, err := afero.ReadFile(fs, path)
+	if err != nil {
+		t.Errorf("got an error reading the file: %v", err)
+	}
+	if string(content) != "2\n" {
+		t.Errorf("expected the file not to be written, got '%s'", content)
+	}
+	res := cmd.(commands.ResultReporter).Result()
+	expected := commands.Result{Target: path, Old: "2\n", New: "2"}
+	if res != expected {
+		t.Errorf("expected result %+v, got %+v", expected, res)
+	}
+}
+
+func TestWriteFileCmdExecuteChanged(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	path := "/sys/block/sda/queue/nomerges"
+	err := afero.WriteFile(fs, path, []byte("0\n"), 0644)
+	if err != nil {
+		t.Errorf("got an error writing the file: %v", err)
+	}
+	cmd := commands.NewWriteFileCmd(fs, path, "2")
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("an error happened while executing: %v", err)
+	}
+	content, err := afero.ReadFile(fs, path)
+	if err != nil {
+		t.Errorf("got an error reading the file: %v", err)
+	}
+	if string(content) != "2" {
+		t.Errorf("expected the file to hold '2', got '%s'", content)
+	}
+	res := cmd.(commands.ResultReporter).Result()
+	expected := commands.Result{Target: path, Changed: true, Old: "0\n", New: "2"}
+	if res != expected {
+		t.Errorf("expected result %+v, got %+v", expected, res)
+	}
+}
+
+func TestWriteFileCmdRenderGuarded(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{
+			name:    "it should skip the write if the file holds the content",
+			content: "mq-deadline",
+			expected: `grep -qxF -- mq-deadline /usr/file 2>/dev/null || echo 'mq-deadline' > /usr/file
+`,
+		},
+		{
+			name:    "it should quote the content it looks for",
+			content: "a b",
+			expected: `grep -qxF -- 'a b' /usr/file 2>/dev/null || echo 'a b' > /usr/file
+`,
+		},
+		{
+			name:    "it shouldn't guard multi-line contents",
+			content: "a\nb\n",
+			expected: `echo 'a
+b
+' > /usr/file
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			path := "/usr/file"
+			err := afero.WriteFile(fs, path, []byte("none\n"), 0644)
+			if err != nil {
+				t.Errorf("got an error writing the file: %v", err)
+			}
+			ctx := commands.NewRenderContext()
+			ctx.GuardWrites()
+			cmd := commands.NewWriteFileCmd(fs, path, tt.content)
+
+			var buf bytes.Buffer
+			writer := bufio.NewWriter(&buf)
+			if err := commands.RenderScriptContext(ctx, cmd, writer); err != nil {
+				t.Errorf("got an error while rendering the script: %v", err)
+			}
+			if buf.String() != tt.expected {
+				t.Errorf("expected:\n\"%s\"\ngot:\n\"%s\"\n", tt.expected, buf.String())
+			}
+		})
+	}
+}
+
+func TestWriteFileCmdVerify(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string
+		readBack  string
+		expectErr bool
+	}{
+		{
+			name:     "it should pass if the file holds the content",
+			content:  "1",
+			readBack: "1\n",
+		},
+		{
+			name:     "it should accept the bracketed selection format",
+			content:  "madvise",
+			readBack: "always [madvise] never\n",
+		},
+		{
+			name:     "it should accept zero-padded mask groups",
+			content:  "ff,1",
+			readBack: "000000ff,00000001\n",
+		},
+		{
+			name:     "it should accept different whitespace",
+			content:  "4096 131072 16777216",
+			readBack: "4096\t131072\t16777216\n",
+		},
+		{
+			name:      "it should fail if the kernel kept another value",
+			content:   "madvise",
+			readBack:  "[always] madvise never\n",
+			expectErr: true,
+		},
+		{
+			name:      "it should fail if the value changed",
+			content:   "1",
+			readBack:  "60\n",
+			expectErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			path := "/sys/file"
+			cmd := commands.NewWriteFileCmd(fs, path, tt.content)
+			err := cmd.Execute()
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Simulate how the kernel presents the value.
+			err = afero.WriteFile(fs, path, []byte(tt.readBack), 0644)
+			if err != nil {
+				t.Fatal(err)
+			}
+			v, ok := cmd.(commands.Verifiable)
+			if !ok {
+				t.Fatal("expected the command to be Verifiable")
+			}
+			err = v.Verify()
+			if tt.expectErr && err == nil {
+				t.Error("expected an error, got nil")
+			}
+			if !tt.expectErr && err != nil {
+				t.Errorf("got an unexpected error: %v", err)
+			}
+		})
+	}
+}
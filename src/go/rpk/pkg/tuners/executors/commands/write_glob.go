@@ -0,0 +1,140 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+)
+
+type writeToGlobCommand struct {
+	fs      afero.Fs
+	glob    string
+	content string
+	result  Result
+}
+
+// Creates a command writing content to every file matching glob, e.g.
+// '/sys/class/net/eth0/queues/rx-*/rps_cpus'. The glob is expanded when the
+// command is executed. It stops at the first write that fails, and the
+// returned error lists the files which were written before it. If no files
+// match the glob, nothing is written and the command succeeds.
+func NewWriteToGlobCmd(fs afero.Fs, glob string, content string) Command {
+	return &writeToGlobCommand{fs: fs, glob: glob, content: content}
+}
+
+func (c *writeToGlobCommand) Execute() error {
+	c.result = Result{Target: c.glob, New: c.content}
+	paths, err := c.expand()
+	if err != nil {
+		return err
+	}
+	if len(paths) == 0 {
+		log.Infof("Skipping the write of '%s', as no files match '%s'", c.content, c.glob)
+		return nil
+	}
+	var written []string
+	for _, path := range paths {
+		cmd := NewWriteFileCmd(c.fs, path, c.content)
+		err := cmd.Execute()
+		if err != nil {
+			if len(written) == 0 {
+				return fmt.Errorf("couldn't write '%s' to '%s': %w", c.content, path, err)
+			}
+			return fmt.Errorf(
+				"couldn't write '%s' to '%s', after writing it to '%s': %w",
+				c.content,
+				path,
+				strings.Join(written, "', '"),
+				err,
+			)
+		}
+		written = append(written, path)
+		if cmd.(ResultReporter).Result().Changed {
+			c.result.Changed = true
+		}
+	}
+	return nil
+}
+
+func (c *writeToGlobCommand) RenderScript(w *bufio.Writer) error {
+	return c.RenderScriptContext(nil, w)
+}
+
+func (c *writeToGlobCommand) RenderScriptContext(ctx *RenderContext, w *bufio.Writer) error {
+	// The files are checked for existence, as the pattern is kept as is
+	// when nothing matches it.
+	fmt.Fprintf(
+		w,
+		"for f in %s; do [ -e \"$f\" ] || continue; echo '%s' > \"$f\"; done\n",
+		ctx.Path(c.glob),
+		c.content,
+	)
+	return w.Flush()
+}
+
+func (c *writeToGlobCommand) Describe() Description {
+	return Description{
+		Type:   "write_to_glob",
+		Target: c.glob,
+		Args:   []string{c.content},
+		Desc:   fmt.Sprintf("Write '%s' to the files matching '%s'", c.content, c.glob),
+	}
+}
+
+func (c *writeToGlobCommand) Inverse() (Command, error) {
+	paths, err := c.expand()
+	if err != nil {
+		return nil, err
+	}
+	cmds := make([]Command, 0, len(paths))
+	for _, path := range paths {
+		cmd, err := restoreFileCmd(c.fs, path)
+		if err != nil {
+			return nil, err
+		}
+		cmds = append(cmds, cmd)
+	}
+	return NewBatchCmd(cmds...), nil
+}
+
+func (c *writeToGlobCommand) Preview() (Result, error) {
+	res := Result{Target: c.glob, New: c.content}
+	paths, err := c.expand()
+	if err != nil {
+		return res, err
+	}
+	for _, path := range paths {
+		current, err := afero.ReadFile(c.fs, path)
+		if err != nil {
+			return res, err
+		}
+		if !sameContent(string(current), c.content) {
+			res.Changed = true
+		}
+	}
+	return res, nil
+}
+
+func (c *writeToGlobCommand) Result() Result {
+	return c.result
+}
+
+func (c *writeToGlobCommand) expand() ([]string, error) {
+	paths, err := afero.Glob(c.fs, c.glob)
+	if err != nil {
+		return nil, fmt.Errorf("invalid glob '%s': %w", c.glob, err)
+	}
+	return paths, nil
+}
@@ -0,0 +1,120 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package commands_test
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors/commands"
+)
+
+const queuesGlob = "/sys/class/net/eth0/queues/rx-*/rps_cpus"
+
+// Fails opening the file at path.
+type failingOpenFs struct {
+	afero.Fs
+	path string
+}
+
+func (fs *failingOpenFs) OpenFile(
+	name string, flag int, perm os.FileMode,
+) (afero.File, error) {
+	if name == fs.path {
+		return nil, errors.New("device or resource busy")
+	}
+	return fs.Fs.OpenFile(name, flag, perm)
+}
+
+func writeQueues(t *testing.T, fs afero.Fs, queues ...string) {
+	for _, q := range queues {
+		path := "/sys/class/net/eth0/queues/" + q + "/rps_cpus"
+		require.NoError(t, afero.WriteFile(fs, path, []byte("0\n"), 0644))
+	}
+}
+
+func TestWriteToGlobCmdExecute(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	writeQueues(t, fs, "rx-0", "rx-1", "tx-0")
+	cmd := commands.NewWriteToGlobCmd(fs, queuesGlob, "ff")
+	require.NoError(t, cmd.Execute())
+	for _, q := range []string{"rx-0", "rx-1"} {
+		content, err := afero.ReadFile(fs, "/sys/class/net/eth0/queues/"+q+"/rps_cpus")
+		require.NoError(t, err)
+		require.Equal(t, "ff", string(content))
+	}
+	content, err := afero.ReadFile(fs, "/sys/class/net/eth0/queues/tx-0/rps_cpus")
+	require.NoError(t, err)
+	require.Equal(t, "0\n", string(content))
+	require.True(t, cmd.(commands.ResultReporter).Result().Changed)
+
+	// It's a no-op the second time.
+	require.NoError(t, cmd.Execute())
+	require.False(t, cmd.(commands.ResultReporter).Result().Changed)
+}
+
+func TestWriteToGlobCmdExecuteNoMatches(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	cmd := commands.NewWriteToGlobCmd(fs, queuesGlob, "ff")
+	require.NoError(t, cmd.Execute())
+	require.False(t, cmd.(commands.ResultReporter).Result().Changed)
+}
+
+func TestWriteToGlobCmdExecuteFailure(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	writeQueues(t, fs, "rx-0", "rx-1", "rx-2")
+	failing := &failingOpenFs{
+		Fs:   fs,
+		path: "/sys/class/net/eth0/queues/rx-1/rps_cpus",
+	}
+	cmd := commands.NewWriteToGlobCmd(failing, queuesGlob, "ff")
+	err := cmd.Execute()
+	require.EqualError(
+		t,
+		err,
+		"couldn't write 'ff' to '/sys/class/net/eth0/queues/rx-1/rps_cpus',"+
+			" after writing it to '/sys/class/net/eth0/queues/rx-0/rps_cpus':"+
+			" device or resource busy",
+	)
+	content, err := afero.ReadFile(fs, "/sys/class/net/eth0/queues/rx-2/rps_cpus")
+	require.NoError(t, err)
+	require.Equal(t, "0\n", string(content))
+}
+
+func TestWriteToGlobCmdInverse(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	writeQueues(t, fs, "rx-0", "rx-1")
+	cmd := commands.NewWriteToGlobCmd(fs, queuesGlob, "ff")
+	inverse, err := cmd.(commands.Reversible).Inverse()
+	require.NoError(t, err)
+	require.NoError(t, cmd.Execute())
+	require.NoError(t, inverse.Execute())
+	for _, q := range []string{"rx-0", "rx-1"} {
+		content, err := afero.ReadFile(fs, "/sys/class/net/eth0/queues/"+q+"/rps_cpus")
+		require.NoError(t, err)
+		require.Equal(t, "0", string(content))
+	}
+}
+
+func TestWriteToGlobCmdRender(t *testing.T) {
+	cmd := commands.NewWriteToGlobCmd(afero.NewMemMapFs(), queuesGlob, "ff")
+	var buf bytes.Buffer
+	require.NoError(t, cmd.RenderScript(bufio.NewWriter(&buf)))
+	require.Equal(
+		t,
+		`for f in /sys/class/net/eth0/queues/rx-*/rps_cpus; do [ -e "$f" ] || continue; echo 'ff' > "$f"; done`+"\n",
+		buf.String(),
+	)
+}
@@ -15,14 +15,19 @@ import (
 	"bufio"
 	"fmt"
 	"os"
+	"strconv"
+	"syscall"
 
 	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
 	"golang.org/x/sys/unix"
 )
 
 type writeSizedFileCommand struct {
-	path      string
-	sizeBytes int64
+	path        string
+	sizeBytes   int64
+	skipIfSized bool
+	result      Result
 }
 
 // Creates a file of size sizeBytes at the given path.
@@ -31,11 +36,38 @@ type writeSizedFileCommand struct {
 // down if needed.
 // The script rendered throug a ScriptRenderingExecutor calls `truncate`,
 // which has the same behavior.
-func NewWriteSizedFileCmd(path string, sizeBytes int64) Command {
-	return &writeSizedFileCommand{path, sizeBytes}
+// If skipIfSized is true, the file is left untouched when it already has the
+// requested size, both when executed directly and in the rendered script.
+// When executed directly, its blocks must also be allocated, so that a sparse
+// file of the right size is still fallocated.
+func NewWriteSizedFileCmd(
+	path string, sizeBytes int64, skipIfSized bool,
+) Command {
+	return &writeSizedFileCommand{
+		path:        path,
+		sizeBytes:   sizeBytes,
+		skipIfSized: skipIfSized,
+		result:      Result{Target: path, New: strconv.FormatInt(sizeBytes, 10)},
+	}
 }
 
 func (c *writeSizedFileCommand) Execute() error {
+	allocated := false
+	fi, err := os.Stat(c.path)
+	if err == nil {
+		c.result.Old = strconv.FormatInt(fi.Size(), 10)
+		allocated = isAllocated(fi, c.sizeBytes)
+		if c.skipIfSized && fi.Size() == c.sizeBytes && allocated {
+			log.Debugf(
+				"'%s' already has the requested size (%d B), no change needed",
+				c.path,
+				c.sizeBytes,
+			)
+			return nil
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
 	log.Debugf("Creating '%s' (%d B)", c.path, c.sizeBytes)
 
 	// the 'os' package needs to be used instead of 'afero', because the file
@@ -70,10 +102,33 @@ func (c *writeSizedFileCommand) Execute() error {
 	if err != nil {
 		return fmt.Errorf("unable to sync the file at %s: %w", c.path, err)
 	}
+	c.result.Changed = c.result.Old != c.result.New || !allocated
 	return nil
 }
 
+// Returns true if at least sizeBytes are allocated to the file, i.e. if it
+// isn't sparse.
+func isAllocated(fi os.FileInfo, sizeBytes int64) bool {
+	stat, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return false
+	}
+	// Stat_t.Blocks is always in 512 B units, regardless of the block size.
+	return stat.Blocks*512 >= sizeBytes
+}
+
 func (c *writeSizedFileCommand) RenderScript(w *bufio.Writer) error {
+	if c.skipIfSized {
+		fmt.Fprintf(
+			w,
+			"[ \"$(stat -c%%s %s 2>/dev/null)\" = \"%d\" ] || truncate -s %d %s\n",
+			c.path,
+			c.sizeBytes,
+			c.sizeBytes,
+			c.path,
+		)
+		return w.Flush()
+	}
 	// See 'man truncate'.
 	fmt.Fprintf(
 		w,
@@ -83,3 +138,35 @@ func (c *writeSizedFileCommand) RenderScript(w *bufio.Writer) error {
 	)
 	return w.Flush()
 }
+
+func (c *writeSizedFileCommand) Describe() Description {
+	return Description{
+		Type:   "write_sized_file",
+		Target: c.path,
+		Args:   []string{strconv.FormatInt(c.sizeBytes, 10)},
+		Desc:   fmt.Sprintf("Create '%s' (%d B)", c.path, c.sizeBytes),
+	}
+}
+
+func (c *writeSizedFileCommand) Result() Result {
+	return c.result
+}
+
+func (c *writeSizedFileCommand) Inverse() (Command, error) {
+	fi, err := os.Stat(c.path)
+	if os.IsNotExist(err) {
+		return NewRemoveFileCmd(afero.NewOsFs(), c.path), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf(
+			"couldn't read the current size of '%s': %w",
+			c.path,
+			err,
+		)
+	}
+	return NewWriteSizedFileCmd(c.path, fi.Size(), true), nil
+}
+
+func (c *writeSizedFileCommand) ProducedFiles() []string {
+	return []string{c.path}
+}
@@ -0,0 +1,67 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+// +build !linux
+
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"runtime"
+)
+
+type unsupportedCommand struct {
+	desc Description
+}
+
+// fallocate isn't available outside of Linux, so the returned command fails
+// when it's executed, and is rendered as a comment.
+func NewWriteSizedFileCmd(
+	path string, sizeBytes int64, skipIfSized bool,
+) Command {
+	return &unsupportedCommand{
+		desc: Description{
+			Type:   "write_sized_file",
+			Target: path,
+			Args:   []string{fmt.Sprint(sizeBytes)},
+			Desc:   fmt.Sprintf("Create '%s' (%d B)", path, sizeBytes),
+		},
+	}
+}
+
+// fallocate isn't available outside of Linux, so the returned command fails
+// when it's executed, and is rendered as a comment.
+func NewAllocatedFileCmd(path string, sizeBytes int64) Command {
+	return &unsupportedCommand{
+		desc: Description{
+			Type:   "allocated_file",
+			Target: path,
+			Args:   []string{fmt.Sprint(sizeBytes)},
+			Desc:   fmt.Sprintf("Create '%s' (%d B), with its blocks allocated", path, sizeBytes),
+		},
+	}
+}
+
+func (c *unsupportedCommand) Execute() error {
+	return fmt.Errorf(
+		"couldn't run '%s': tuning is unsupported on %s",
+		c.desc.Desc,
+		runtime.GOOS,
+	)
+}
+
+func (c *unsupportedCommand) RenderScript(w *bufio.Writer) error {
+	fmt.Fprintf(w, "# Unsupported on %s: %s\n", runtime.GOOS, c.desc.Desc)
+	return w.Flush()
+}
+
+func (c *unsupportedCommand) Describe() Description {
+	return c.desc
+}
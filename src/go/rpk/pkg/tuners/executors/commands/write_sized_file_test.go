@@ -14,6 +14,9 @@ package commands_test
 import (
 	"bufio"
 	"bytes"
+	"os"
+	"path/filepath"
+	"syscall"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -24,6 +27,7 @@ func TestWriteSizedFileCmdRender(t *testing.T) {
 	cmd := commands.NewWriteSizedFileCmd(
 		"/some/made/up/filepath.txt",
 		int64(1),
+		false,
 	)
 
 	expected := "truncate -s 1 /some/made/up/filepath.txt"
@@ -35,3 +39,70 @@ func TestWriteSizedFileCmdRender(t *testing.T) {
 
 	require.Equal(t, expected, buf.String())
 }
+
+func TestWriteSizedFileCmdRenderSkipIfSized(t *testing.T) {
+	cmd := commands.NewWriteSizedFileCmd(
+		"/some/made/up/filepath.txt",
+		int64(1),
+		true,
+	)
+
+	expected := `[ "$(stat -c%s /some/made/up/filepath.txt 2>/dev/null)" = "1" ]` +
+		" || truncate -s 1 /some/made/up/filepath.txt\n"
+	var buf bytes.Buffer
+
+	w := bufio.NewWriter(&buf)
+	cmd.RenderScript(w)
+	require.NoError(t, w.Flush())
+
+	require.Equal(t, expected, buf.String())
+}
+
+func TestWriteSizedFileCmdExecuteSkipIfSized(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ballast")
+	require.NoError(t, os.WriteFile(path, []byte("abcd"), 0644))
+	before, err := os.Stat(path)
+	require.NoError(t, err)
+
+	cmd := commands.NewWriteSizedFileCmd(path, int64(4), true)
+	require.NoError(t, cmd.Execute())
+
+	res := cmd.(commands.ResultReporter).Result()
+	require.False(t, res.Changed)
+	require.Equal(t, "4", res.Old)
+
+	after, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, before.ModTime(), after.ModTime())
+	// The content must be preserved, since the file wasn't touched.
+	bs, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "abcd", string(bs))
+
+	cmd = commands.NewWriteSizedFileCmd(path, int64(8), true)
+	require.NoError(t, cmd.Execute())
+	res = cmd.(commands.ResultReporter).Result()
+	require.True(t, res.Changed)
+	require.Equal(t, "4", res.Old)
+	require.Equal(t, "8", res.New)
+}
+
+func TestWriteSizedFileCmdExecuteSkipIfSizedSparse(t *testing.T) {
+	const size = 1 << 20
+	path := filepath.Join(t.TempDir(), "ballast")
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	require.NoError(t, f.Truncate(size))
+	require.NoError(t, f.Close())
+
+	// The file has the requested size, but no blocks allocated to it.
+	cmd := commands.NewWriteSizedFileCmd(path, int64(size), true)
+	require.NoError(t, cmd.Execute())
+
+	res := cmd.(commands.ResultReporter).Result()
+	require.True(t, res.Changed)
+	fi, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, int64(size), fi.Size())
+	require.GreaterOrEqual(t, fi.Sys().(*syscall.Stat_t).Blocks*512, int64(size))
+}
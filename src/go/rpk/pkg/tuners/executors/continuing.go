@@ -0,0 +1,136 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package executors
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors/commands"
+)
+
+// A command which failed, along with the error it failed with.
+type CommandError struct {
+	Command commands.Description
+	Err     error
+}
+
+func (e CommandError) Error() string {
+	return fmt.Sprintf("'%s' failed: %v", e.Command.Desc, e.Err)
+}
+
+func (e CommandError) Unwrap() error {
+	return e.Err
+}
+
+// MultiError holds every command which failed, in the order they did.
+type MultiError struct {
+	Errors []CommandError
+}
+
+func (e *MultiError) Error() string {
+	if len(e.Errors) == 1 {
+		return e.Errors[0].Error()
+	}
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = "  - " + err.Error()
+	}
+	return fmt.Sprintf(
+		"%d commands failed:\n%s",
+		len(e.Errors),
+		strings.Join(msgs, "\n"),
+	)
+}
+
+// ErrorCollector is implemented by executors which keep track of the
+// commands which fail.
+type ErrorCollector interface {
+	Executor
+	// Returns a *MultiError with the commands which failed so far, or nil
+	// if none did.
+	Err() error
+}
+
+type continuingExecutor struct {
+	executor Executor
+	// Whether a failed command's error is swallowed, so that the tuner
+	// executing it goes on.
+	cont bool
+	mu   sync.Mutex
+	errs []CommandError
+}
+
+// Wraps executor, collecting every command which fails, so that Err reports
+// them all once the tuners sharing it completed. A failed command doesn't
+// fail the tuner executing it, which carries on with its next commands. A
+// composite command (see commands.Composite), such as a batch, is still
+// aborted as a whole when one of its commands fails, and its error is
+// returned, as the commands following it may depend on it. So is the error
+// of a command executed once its context is done.
+func NewContinuingExecutor(executor Executor) ErrorCollector {
+	return &continuingExecutor{executor: executor, cont: true}
+}
+
+// Wraps executor like NewContinuingExecutor, except that the failed
+// commands' errors are returned, so that a tuner stops at the first one.
+func NewErrorCollectingExecutor(executor Executor) ErrorCollector {
+	return &continuingExecutor{executor: executor}
+}
+
+func (e *continuingExecutor) Execute(cmd commands.Command) error {
+	return e.ExecuteContext(context.Background(), cmd)
+}
+
+func (e *continuingExecutor) ExecuteContext(
+	ctx context.Context, cmd commands.Command,
+) error {
+	err := ExecuteContext(ctx, e.executor, cmd)
+	if err == nil {
+		return nil
+	}
+	desc := cmd.Describe()
+	log.Debugf("'%s' failed: %v", desc.Desc, err)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.errs = append(e.errs, CommandError{Command: desc, Err: err})
+	// Once ctx is done, the tuner must stop rather than fail at each of
+	// its next commands.
+	_, composite := cmd.(commands.Composite)
+	if !e.cont || composite || ctx.Err() != nil {
+		return err
+	}
+	return nil
+}
+
+func (e *continuingExecutor) Err() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if len(e.errs) == 0 {
+		return nil
+	}
+	return &MultiError{Errors: append([]CommandError(nil), e.errs...)}
+}
+
+func (e *continuingExecutor) IsLazy() bool {
+	return e.executor.IsLazy()
+}
+
+// Returns the results collected by the wrapped executor, if it's a
+// ResultCollector.
+func (e *continuingExecutor) Results() []commands.Result {
+	if c, ok := e.executor.(ResultCollector); ok {
+		return c.Results()
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package executors_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors/commands"
+)
+
+func TestContinuingExecutor(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	e := executors.NewContinuingExecutor(executors.NewDirectExecutor())
+
+	require.NoError(t, e.Execute(commands.NewWriteFileCmd(fs, "/a", "1")))
+	require.NoError(t, e.Err())
+
+	boom := errors.New("boom")
+	// The error is only reported by Err, so that the tuner executing the
+	// command goes on.
+	require.NoError(t, e.Execute(&sleepCommand{err: boom}))
+	require.NoError(t, e.Execute(commands.NewWriteFileCmd(fs, "/b", "2")))
+	require.NoError(t, e.Execute(&sleepCommand{err: errors.New("bang")}))
+
+	content, err := afero.ReadFile(fs, "/b")
+	require.NoError(t, err)
+	require.Equal(t, "2", string(content))
+
+	err = e.Err()
+	var multi *executors.MultiError
+	require.True(t, errors.As(err, &multi))
+	require.Len(t, multi.Errors, 2)
+	require.Equal(t, "Sleep 0s", multi.Errors[0].Command.Desc)
+	require.True(t, errors.Is(multi.Errors[0], boom))
+	require.EqualError(
+		t,
+		err,
+		"2 commands failed:\n  - 'Sleep 0s' failed: boom\n  - 'Sleep 0s' failed: bang",
+	)
+}
+
+func TestContinuingExecutorBatch(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	e := executors.NewContinuingExecutor(executors.NewDirectExecutor())
+	// The batch is aborted, and the write applied before the failure is
+	// reverted.
+	batch := commands.NewBatchCmd(
+		commands.NewWriteFileCmd(fs, "/a", "1"),
+		&sleepCommand{err: errors.New("boom")},
+		commands.NewWriteFileCmd(fs, "/b", "2"),
+	)
+	require.EqualError(t, e.Execute(batch), "batch command 1 ('Sleep 0s') failed: boom")
+	exists, err := afero.Exists(fs, "/a")
+	require.NoError(t, err)
+	require.False(t, exists)
+	exists, err = afero.Exists(fs, "/b")
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	var multi *executors.MultiError
+	require.True(t, errors.As(e.Err(), &multi))
+	require.Len(t, multi.Errors, 1)
+}
+
+func TestContinuingExecutorContextDone(t *testing.T) {
+	e := executors.NewContinuingExecutor(executors.NewDirectExecutor())
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	// The tuner stops, instead of going through its next commands.
+	err := executors.ExecuteContext(ctx, e, &sleepCommand{})
+	require.EqualError(t, err, "command 'Sleep 0s' wasn't executed: context canceled")
+	require.Error(t, e.Err())
+}
+
+func TestErrorCollectingExecutor(t *testing.T) {
+	e := executors.NewErrorCollectingExecutor(executors.NewDirectExecutor())
+	boom := errors.New("boom")
+	// The error is returned, so that the tuner executing the command
+	// stops.
+	require.Equal(t, boom, e.Execute(&sleepCommand{err: boom}))
+	require.EqualError(t, e.Err(), "'Sleep 0s' failed: boom")
+}
@@ -0,0 +1,248 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package executors
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors/commands"
+)
+
+// DependencyResolvingExecutor executes commands through another executor, so
+// that each runs after the ones it depends on (see commands.Dependent).
+type DependencyResolvingExecutor interface {
+	Executor
+	// Executes the commands held back so far, as what they depend on
+	// wasn't executed, stopping at the first one which fails. It fails
+	// without executing any if their dependencies are cyclic.
+	Flush() error
+}
+
+type dependencyResolvingExecutor struct {
+	executor Executor
+	mu       sync.Mutex
+	executed map[commands.CommandID]bool
+	held     []commands.Command
+}
+
+// Wraps executor, executing the commands right away, in the order they're
+// given, except for those depending on commands which weren't executed yet:
+// they're held back until their dependencies are, or until Flush is called.
+// Then, the dependencies on commands which weren't executed are considered
+// met.
+func NewDependencyResolvingExecutor(
+	executor Executor,
+) DependencyResolvingExecutor {
+	return &dependencyResolvingExecutor{
+		executor: executor,
+		executed: map[commands.CommandID]bool{},
+	}
+}
+
+func (e *dependencyResolvingExecutor) Execute(cmd commands.Command) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if !e.isReady(cmd) {
+		log.Debugf(
+			"Holding '%s' back until the commands it depends on are executed",
+			cmd.Describe().Desc,
+		)
+		e.held = append(e.held, cmd)
+		return nil
+	}
+	err := e.execute(cmd)
+	if err != nil {
+		return err
+	}
+	return e.release()
+}
+
+func (e *dependencyResolvingExecutor) IsLazy() bool {
+	return e.executor.IsLazy()
+}
+
+func (e *dependencyResolvingExecutor) Flush() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	held := e.held
+	e.held = nil
+	ordered, err := sortByDependencies(held)
+	if err != nil {
+		return err
+	}
+	for _, cmd := range ordered {
+		err := e.execute(cmd)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *dependencyResolvingExecutor) execute(cmd commands.Command) error {
+	err := e.executor.Execute(commands.Unwrap(cmd))
+	if err != nil {
+		return err
+	}
+	e.executed[commands.IDOf(cmd)] = true
+	return nil
+}
+
+// Executes the held commands whose dependencies were executed since, in the
+// order they were held back in.
+func (e *dependencyResolvingExecutor) release() error {
+	for i := 0; i < len(e.held); i++ {
+		cmd := e.held[i]
+		if !e.isReady(cmd) {
+			continue
+		}
+		e.held = append(e.held[:i:i], e.held[i+1:]...)
+		err := e.execute(cmd)
+		if err != nil {
+			return err
+		}
+		// It may be what the ones held before it were waiting for.
+		i = -1
+	}
+	return nil
+}
+
+func (e *dependencyResolvingExecutor) isReady(cmd commands.Command) bool {
+	d, ok := cmd.(commands.Dependent)
+	if !ok {
+		return true
+	}
+	for _, dep := range d.DependsOn() {
+		if !e.executed[dep] {
+			return false
+		}
+	}
+	return true
+}
+
+// Sorts cmds topologically. Among the commands whose dependencies are met,
+// the one given first goes first, so that commands without dependencies
+// keep their relative order. The dependencies on commands which aren't in
+// cmds are considered met.
+func sortByDependencies(cmds []commands.Command) ([]commands.Command, error) {
+	ids := make([]commands.CommandID, len(cmds))
+	byID := map[commands.CommandID][]int{}
+	for i, cmd := range cmds {
+		ids[i] = commands.IDOf(cmd)
+		byID[ids[i]] = append(byID[ids[i]], i)
+	}
+	// prereqs[i] holds the indexes of the commands cmds[i] depends on.
+	prereqs := make([][]int, len(cmds))
+	for i, cmd := range cmds {
+		d, ok := cmd.(commands.Dependent)
+		if !ok {
+			continue
+		}
+		for _, dep := range d.DependsOn() {
+			indexes, found := byID[dep]
+			if !found {
+				log.Debugf(
+					"'%s' depends on '%s', which wasn't executed",
+					cmd.Describe().Desc,
+					dep,
+				)
+				continue
+			}
+			for _, j := range indexes {
+				if j != i {
+					prereqs[i] = append(prereqs[i], j)
+				}
+			}
+		}
+	}
+
+	done := make([]bool, len(cmds))
+	var ordered []commands.Command
+	for len(ordered) < len(cmds) {
+		next := -1
+		for i := range cmds {
+			if !done[i] && allDone(prereqs[i], done) {
+				next = i
+				break
+			}
+		}
+		if next == -1 {
+			cycle := findCycle(prereqs, done)
+			var names []string
+			for _, i := range cycle {
+				names = append(names, string(ids[i]))
+			}
+			return nil, fmt.Errorf(
+				"the commands' dependencies are cyclic (each depends on the next): %s",
+				strings.Join(names, " -> "),
+			)
+		}
+		done[next] = true
+		ordered = append(ordered, cmds[next])
+	}
+	return ordered, nil
+}
+
+func allDone(indexes []int, done []bool) bool {
+	for _, i := range indexes {
+		if !done[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Returns a cycle among the commands which aren't done, as the indexes of
+// the commands in it, starting and ending with the same one.
+func findCycle(prereqs [][]int, done []bool) []int {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(prereqs))
+	var path []int
+	var visit func(i int) []int
+	visit = func(i int) []int {
+		state[i] = visiting
+		path = append(path, i)
+		for _, j := range prereqs[i] {
+			if done[j] {
+				continue
+			}
+			if state[j] == visiting {
+				for k, p := range path {
+					if p == j {
+						return append(append([]int(nil), path[k:]...), j)
+					}
+				}
+			}
+			if state[j] == unvisited {
+				if cycle := visit(j); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[i] = visited
+		return nil
+	}
+	for i := range prereqs {
+		if !done[i] && state[i] == unvisited {
+			if cycle := visit(i); cycle != nil {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package executors_test
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors/commands"
+)
+
+// Records the targets of the commands it executes, in order.
+type orderRecordingExecutor struct {
+	targets []string
+}
+
+func (e *orderRecordingExecutor) Execute(cmd commands.Command) error {
+	if _, ok := cmd.(commands.Dependent); ok {
+		panic("dependent commands should be unwrapped")
+	}
+	e.targets = append(e.targets, cmd.Describe().Target)
+	return nil
+}
+
+func (*orderRecordingExecutor) IsLazy() bool {
+	return false
+}
+
+func TestDependencyResolvingExecutor(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	write := func(path string) commands.Command {
+		return commands.NewWriteFileCmd(fs, path, "1")
+	}
+	tests := []struct {
+		name string
+		cmds []commands.Command
+		// The commands executed before Flush is called.
+		executed []string
+		expected []string
+		errMsg   string
+	}{
+		{
+			name:     "it should keep the order of independent commands",
+			cmds:     []commands.Command{write("/c"), write("/a"), write("/b")},
+			executed: []string{"/c", "/a", "/b"},
+			expected: []string{"/c", "/a", "/b"},
+		},
+		{
+			name: "it should execute commands after their dependencies",
+			cmds: []commands.Command{
+				write("/a"),
+				commands.NewDependentCmd(write("/b"), "write_file:/d"),
+				write("/c"),
+				write("/d"),
+			},
+			executed: []string{"/a", "/c", "/d", "/b"},
+			expected: []string{"/a", "/c", "/d", "/b"},
+		},
+		{
+			name: "it should execute commands whose dependencies were executed right away",
+			cmds: []commands.Command{
+				write("/a"),
+				commands.NewDependentCmd(write("/b"), "write_file:/a"),
+				write("/c"),
+			},
+			executed: []string{"/a", "/b", "/c"},
+			expected: []string{"/a", "/b", "/c"},
+		},
+		{
+			name: "it should follow transitive dependencies",
+			cmds: []commands.Command{
+				commands.NewDependentCmd(write("/a"), "write_file:/b"),
+				commands.NewDependentCmd(write("/b"), "write_file:/c"),
+				write("/c"),
+			},
+			executed: []string{"/c", "/b", "/a"},
+			expected: []string{"/c", "/b", "/a"},
+		},
+		{
+			name: "it should consider dependencies which weren't executed met once flushed",
+			cmds: []commands.Command{
+				commands.NewDependentCmd(write("/a"), "write_file:/missing"),
+				commands.NewDependentCmd(write("/b"), "write_file:/a"),
+				write("/c"),
+			},
+			executed: []string{"/c"},
+			expected: []string{"/c", "/a", "/b"},
+		},
+		{
+			name: "it should fail if the dependencies are cyclic",
+			cmds: []commands.Command{
+				write("/a"),
+				commands.NewDependentCmd(write("/b"), "write_file:/c"),
+				commands.NewDependentCmd(write("/c"), "write_file:/b"),
+			},
+			executed: []string{"/a"},
+			expected: []string{"/a"},
+			errMsg: "the commands' dependencies are cyclic (each depends on the next): " +
+				"write_file:/b -> write_file:/c -> write_file:/b",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			recorder := &orderRecordingExecutor{}
+			e := executors.NewDependencyResolvingExecutor(recorder)
+			require.False(st, e.IsLazy())
+			for _, cmd := range tt.cmds {
+				require.NoError(st, e.Execute(cmd))
+			}
+			require.Equal(st, tt.executed, recorder.targets)
+			err := e.Flush()
+			require.Equal(st, tt.expected, recorder.targets)
+			if tt.errMsg != "" {
+				require.EqualError(st, err, tt.errMsg)
+				return
+			}
+			require.NoError(st, err)
+		})
+	}
+}
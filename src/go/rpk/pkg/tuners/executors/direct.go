@@ -9,20 +9,241 @@
 
 package executors
 
-import "github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors/commands"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors/commands"
+)
+
+type DirectExecutorParams struct {
+	// The maximum time a single command may take to execute. If it's
+	// exceeded, an error is returned. A command which takes a context (see
+	// commands.ContextCommand) is aborted, while the others (e.g. a sysfs
+	// write blocked in a wedged driver) can't be, so they're abandoned: they
+	// may still complete after the error is returned. Zero means commands
+	// may run indefinitely.
+	CommandTimeout time.Duration
+	// Whether to re-read what the commands which support it (see
+	// commands.Verifiable) wrote, failing if it doesn't hold the intended
+	// value.
+	VerifyWrites bool
+	// The number of times a command is retried when it fails with EBUSY or
+	// EAGAIN, as some sysfs files (e.g. IRQ masks) intermittently reject
+	// writes while the device settles. Other errors fail right away.
+	Retries int
+	// How long to wait before the first retry. The wait doubles with each
+	// retry. If zero, defaultRetryBackoff is used.
+	RetryBackoff time.Duration
+}
+
+const defaultRetryBackoff = 50 * time.Millisecond
 
 type directExecutor struct {
 	Executor
+	params  DirectExecutorParams
+	mu      sync.Mutex
+	results []commands.Result
 }
 
+// Creates an executor which runs the commands right away. It implements
+// ResultCollector.
 func NewDirectExecutor() Executor {
-	return &directExecutor{}
+	return NewDirectExecutorWithParams(DirectExecutorParams{})
 }
 
+func NewDirectExecutorWithParams(params DirectExecutorParams) Executor {
+	return &directExecutor{params: params}
+}
+
+// Executes cmd, logging how long it took along with its outcome.
 func (e *directExecutor) Execute(cmd commands.Command) error {
-	return cmd.Execute()
+	return e.ExecuteContext(context.Background(), cmd)
+}
+
+// Executes cmd like Execute, unless ctx is done. A command which takes a
+// context (see commands.ContextCommand) is aborted once ctx is done, while
+// the others, which can't be interrupted, are abandoned, and may still
+// complete.
+func (e *directExecutor) ExecuteContext(
+	ctx context.Context, cmd commands.Command,
+) error {
+	start := time.Now()
+	err := e.executeAndCollect(ctx, cmd)
+	desc := cmd.Describe()
+	entry := log.WithFields(log.Fields{
+		"command":  desc.Desc,
+		"type":     desc.Type,
+		"duration": time.Since(start),
+	})
+	if err != nil {
+		entry.WithError(err).Debug("Command failed")
+	} else {
+		entry.Debug("Command executed")
+	}
+	return err
+}
+
+func (e *directExecutor) executeAndCollect(
+	ctx context.Context, cmd commands.Command,
+) error {
+	// A batch's commands are each retried, timed out, verified and
+	// collected on their own, as is its rollback.
+	if d, ok := cmd.(commands.Delegating); ok {
+		return d.ExecuteThrough(func(c commands.Command) error {
+			return e.ExecuteContext(ctx, c)
+		})
+	}
+	err := e.executeWithRetries(ctx, cmd)
+	if err != nil {
+		return err
+	}
+	if v, ok := cmd.(commands.Verifiable); ok && e.params.VerifyWrites {
+		err = v.Verify()
+		if err != nil {
+			return err
+		}
+	}
+	if r, ok := cmd.(commands.ResultReporter); ok {
+		e.mu.Lock()
+		e.results = append(e.results, r.Result())
+		e.mu.Unlock()
+	}
+	return nil
+}
+
+func (e *directExecutor) Results() []commands.Result {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]commands.Result(nil), e.results...)
+}
+
+func (e *directExecutor) executeWithRetries(
+	ctx context.Context, cmd commands.Command,
+) error {
+	backoff := e.params.RetryBackoff
+	if backoff <= 0 {
+		backoff = defaultRetryBackoff
+	}
+	var errs []error
+	for attempt := 0; ; attempt++ {
+		err := e.execute(ctx, cmd)
+		if err == nil || !isTransient(err) {
+			return err
+		}
+		errs = append(errs, err)
+		if attempt >= e.params.Retries {
+			if attempt == 0 {
+				return err
+			}
+			return &retriesError{desc: cmd.Describe().Desc, errs: errs}
+		}
+		log.Debugf(
+			"Command '%s' failed with a transient error, retrying in %s: %v",
+			cmd.Describe().Desc,
+			backoff,
+			err,
+		)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			errs = append(errs, notExecutedError(ctx, cmd))
+			return &retriesError{desc: cmd.Describe().Desc, errs: errs}
+		}
+		backoff *= 2
+	}
+}
+
+func (e *directExecutor) execute(ctx context.Context, cmd commands.Command) error {
+	if err := notExecutedError(ctx, cmd); err != nil {
+		return err
+	}
+	cctx, cancel := ctx, func() {}
+	if e.params.CommandTimeout > 0 {
+		cctx, cancel = context.WithTimeout(ctx, e.params.CommandTimeout)
+	}
+	defer cancel()
+	// Commands launching processes get the context, so that the child is
+	// killed.
+	if c, ok := cmd.(commands.ContextCommand); ok {
+		err := c.ExecuteContext(cctx)
+		if err != nil && ctx.Err() == nil && cctx.Err() == context.DeadlineExceeded {
+			return timeoutError(cmd, e.params.CommandTimeout)
+		}
+		if err != nil && ctx.Err() != nil {
+			return fmt.Errorf("command '%s' was aborted: %w", cmd.Describe().Desc, ctx.Err())
+		}
+		return err
+	}
+	if cctx.Done() == nil {
+		return cmd.Execute()
+	}
+	// The others (e.g. file writes) can't be interrupted, so they're
+	// abandoned once cctx is done, rather than hanging the tuner on a write
+	// the kernel never completes. They may still complete afterwards.
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Execute()
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-cctx.Done():
+	}
+	if ctx.Err() != nil {
+		return fmt.Errorf(
+			"command '%s' was abandoned, and may still complete: %w",
+			cmd.Describe().Desc,
+			ctx.Err(),
+		)
+	}
+	return timeoutError(cmd, e.params.CommandTimeout)
 }
 
 func (e *directExecutor) IsLazy() bool {
 	return false
 }
+
+func timeoutError(cmd commands.Command, timeout time.Duration) error {
+	return fmt.Errorf(
+		"command '%s' didn't complete within %s",
+		cmd.Describe().Desc,
+		timeout,
+	)
+}
+
+// The error returned when a command keeps failing with transient errors. It
+// unwraps to the last one.
+type retriesError struct {
+	desc string
+	errs []error
+}
+
+func (e *retriesError) Error() string {
+	msgs := make([]string, len(e.errs))
+	for i, err := range e.errs {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf(
+		"command '%s' failed %d times: [%s]",
+		e.desc,
+		len(e.errs),
+		strings.Join(msgs, "; "),
+	)
+}
+
+func (e *retriesError) Unwrap() error {
+	return e.errs[len(e.errs)-1]
+}
+
+// Returns whether err is one the kernel returns for a write it may accept if
+// it's retried later.
+func isTransient(err error) bool {
+	return errors.Is(err, syscall.EBUSY) || errors.Is(err, syscall.EAGAIN)
+}
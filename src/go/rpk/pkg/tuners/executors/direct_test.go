@@ -0,0 +1,377 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package executors_test
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	goos "os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/os"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors/commands"
+)
+
+type sleepCommand struct {
+	d   time.Duration
+	err error
+}
+
+func (c *sleepCommand) Execute() error {
+	time.Sleep(c.d)
+	return c.err
+}
+
+func (*sleepCommand) RenderScript(_ *bufio.Writer) error {
+	return nil
+}
+
+func (c *sleepCommand) Describe() commands.Description {
+	return commands.Description{Type: "sleep", Desc: "Sleep " + c.d.String()}
+}
+
+// A sleepCommand which can be interrupted through its context.
+type ctxSleepCommand struct {
+	sleepCommand
+}
+
+func (c *ctxSleepCommand) ExecuteContext(ctx context.Context) error {
+	select {
+	case <-time.After(c.d):
+		return c.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// A command which doesn't take a context, blocking until it's released, like
+// a write to a wedged device.
+type blockingCommand struct {
+	release chan struct{}
+}
+
+func (c *blockingCommand) Execute() error {
+	<-c.release
+	return nil
+}
+
+func (*blockingCommand) RenderScript(_ *bufio.Writer) error {
+	return nil
+}
+
+func (*blockingCommand) Describe() commands.Description {
+	return commands.Description{Type: "block", Desc: "Block"}
+}
+
+func TestDirectExecutorTimeout(t *testing.T) {
+	tests := []struct {
+		name        string
+		cmd         commands.Command
+		timeout     time.Duration
+		expectedErr string
+	}{
+		{
+			name:    "it should return nil if the command completes in time",
+			cmd:     &sleepCommand{d: time.Millisecond},
+			timeout: time.Second,
+		},
+		{
+			name:        "it should return the command's error if it completes in time",
+			cmd:         &sleepCommand{d: time.Millisecond, err: errors.New("oops")},
+			timeout:     time.Second,
+			expectedErr: "oops",
+		},
+		{
+			name:        "it should fail if the deadline fires",
+			cmd:         &ctxSleepCommand{sleepCommand{d: 10 * time.Second}},
+			timeout:     50 * time.Millisecond,
+			expectedErr: "command 'Sleep 10s' didn't complete within 50ms",
+		},
+		{
+			name:        "it should abandon commands which don't take a context if the deadline fires",
+			cmd:         &blockingCommand{release: make(chan struct{})},
+			timeout:     50 * time.Millisecond,
+			expectedErr: "command 'Block' didn't complete within 50ms",
+		},
+		{
+			name: "it should kill launched processes if the deadline fires",
+			cmd: commands.NewLaunchCmd(
+				os.NewProc(), 10*time.Second, "sleep", "10",
+			),
+			timeout:     50 * time.Millisecond,
+			expectedErr: "command 'Run 'sleep 10'' didn't complete within 50ms",
+		},
+		{
+			name:    "it shouldn't time out commands if no timeout is set",
+			cmd:     &sleepCommand{d: 100 * time.Millisecond},
+			timeout: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			e := executors.NewDirectExecutorWithParams(
+				executors.DirectExecutorParams{CommandTimeout: tt.timeout},
+			)
+			start := time.Now()
+			err := e.Execute(tt.cmd)
+			if b, ok := tt.cmd.(*blockingCommand); ok {
+				close(b.release)
+			}
+			if tt.expectedErr != "" {
+				require.EqualError(st, err, tt.expectedErr)
+				require.Less(st, int64(time.Since(start)), int64(5*time.Second))
+				return
+			}
+			require.NoError(st, err)
+		})
+	}
+}
+
+func TestDirectExecutorContext(t *testing.T) {
+	e := executors.NewDirectExecutor().(executors.ContextExecutor)
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+	}()
+	// The context is given to the commands which take one.
+	start := time.Now()
+	err := e.ExecuteContext(ctx, &ctxSleepCommand{sleepCommand{d: 10 * time.Second}})
+	require.EqualError(t, err, "command 'Sleep 10s' was aborted: context canceled")
+	require.Less(t, int64(time.Since(start)), int64(5*time.Second))
+
+	// Once it's done, no command is executed.
+	cmd := &countingCommand{}
+	err = e.ExecuteContext(ctx, cmd)
+	require.EqualError(t, err, "command 'Count' wasn't executed: context canceled")
+	require.Zero(t, cmd.count)
+
+	// Launched processes are killed, even if the context has no deadline.
+	ctx, cancel = context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+	}()
+	start = time.Now()
+	err = e.ExecuteContext(ctx, commands.NewLaunchCmd(os.NewProc(), 10*time.Second, "sleep", "10"))
+	require.EqualError(t, err, "command 'Run 'sleep 10'' was aborted: context canceled")
+	require.Less(t, int64(time.Since(start)), int64(5*time.Second))
+
+	// The ones which don't take one are abandoned.
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	blocking := &blockingCommand{release: make(chan struct{})}
+	defer close(blocking.release)
+	err = e.ExecuteContext(ctx, blocking)
+	require.EqualError(t, err, "command 'Block' was abandoned, and may still complete: context deadline exceeded")
+}
+
+func TestWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	// The wrapped executors pass the context on.
+	e := executors.WithContext(
+		ctx,
+		executors.NewTimingExecutor(executors.NewDirectExecutor()),
+	)
+	cmd := &countingCommand{}
+	require.NoError(t, e.Execute(cmd))
+	cancel()
+	require.Error(t, e.Execute(cmd))
+	require.Equal(t, 1, cmd.count)
+}
+
+// Counts how many times it's executed.
+type countingCommand struct {
+	count int
+}
+
+func (c *countingCommand) Execute() error {
+	c.count++
+	return nil
+}
+
+func (*countingCommand) RenderScript(_ *bufio.Writer) error {
+	return nil
+}
+
+func (*countingCommand) Describe() commands.Description {
+	return commands.Description{Type: "count", Desc: "Count"}
+}
+
+func TestDirectExecutorResults(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/a", []byte("1\n"), 0644))
+	require.NoError(t, afero.WriteFile(fs, "/b", []byte("1\n"), 0644))
+	e := executors.NewDirectExecutor()
+
+	require.NoError(t, e.Execute(commands.NewWriteFileCmd(fs, "/a", "1")))
+	require.NoError(t, e.Execute(commands.NewWriteFileCmd(fs, "/b", "2")))
+	// Commands which don't report results are left out.
+	require.NoError(t, e.Execute(&sleepCommand{}))
+
+	c, ok := e.(executors.ResultCollector)
+	require.True(t, ok)
+	expected := []commands.Result{
+		{Target: "/a", Changed: false, Old: "1\n", New: "1"},
+		{Target: "/b", Changed: true, Old: "1\n", New: "2"},
+	}
+	require.Equal(t, expected, c.Results())
+}
+
+type ignoredWriteCommand struct {
+	sleepCommand
+	verified bool
+}
+
+func (c *ignoredWriteCommand) Verify() error {
+	c.verified = true
+	return errors.New("wrote '1' to '/f' but it now reads '0'")
+}
+
+func TestDirectExecutorVerifyWrites(t *testing.T) {
+	cmd := &ignoredWriteCommand{}
+	require.NoError(t, executors.NewDirectExecutor().Execute(cmd))
+	require.False(t, cmd.verified)
+
+	e := executors.NewDirectExecutorWithParams(
+		executors.DirectExecutorParams{VerifyWrites: true},
+	)
+	err := e.Execute(cmd)
+	require.EqualError(t, err, "wrote '1' to '/f' but it now reads '0'")
+	require.True(t, cmd.verified)
+}
+
+// Fails to open files for writing with err, the given number of times.
+type flakyFs struct {
+	afero.Fs
+	failures int
+	err      error
+	attempts int
+}
+
+func (fs *flakyFs) OpenFile(
+	name string, flag int, perm goos.FileMode,
+) (afero.File, error) {
+	if flag&goos.O_RDWR != 0 {
+		fs.attempts++
+		if fs.attempts <= fs.failures {
+			return nil, fmt.Errorf("write %s: %w", name, fs.err)
+		}
+	}
+	return fs.Fs.OpenFile(name, flag, perm)
+}
+
+func TestDirectExecutorRetries(t *testing.T) {
+	tests := []struct {
+		name             string
+		failures         int
+		err              error
+		expectedAttempts int
+		expectedErr      string
+	}{
+		{
+			name:             "it should retry writes failing with EBUSY",
+			failures:         2,
+			err:              syscall.EBUSY,
+			expectedAttempts: 3,
+		},
+		{
+			name:             "it should retry writes failing with EAGAIN",
+			failures:         1,
+			err:              syscall.EAGAIN,
+			expectedAttempts: 2,
+		},
+		{
+			name:             "it should give up after the given retries",
+			failures:         10,
+			err:              syscall.EBUSY,
+			expectedAttempts: 4,
+			expectedErr: "command 'Write '1' to '/f'' failed 4 times: [" +
+				"write /f: device or resource busy; " +
+				"write /f: device or resource busy; " +
+				"write /f: device or resource busy; " +
+				"write /f: device or resource busy]",
+		},
+		{
+			name:             "it shouldn't retry writes failing with EACCES",
+			failures:         1,
+			err:              syscall.EACCES,
+			expectedAttempts: 1,
+			expectedErr:      "write /f: permission denied",
+		},
+		{
+			name:             "it shouldn't retry writes failing with ENOENT",
+			failures:         1,
+			err:              syscall.ENOENT,
+			expectedAttempts: 1,
+			expectedErr:      "write /f: no such file or directory",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			fs := &flakyFs{
+				Fs:       afero.NewMemMapFs(),
+				failures: tt.failures,
+				err:      tt.err,
+			}
+			e := executors.NewDirectExecutorWithParams(
+				executors.DirectExecutorParams{
+					Retries:      3,
+					RetryBackoff: time.Millisecond,
+				},
+			)
+			err := e.Execute(commands.NewWriteFileCmd(fs, "/f", "1"))
+			require.Equal(st, tt.expectedAttempts, fs.attempts)
+			if tt.expectedErr != "" {
+				require.EqualError(st, err, tt.expectedErr)
+				require.True(st, errors.Is(err, tt.err))
+				return
+			}
+			require.NoError(st, err)
+			content, err := afero.ReadFile(fs, "/f")
+			require.NoError(st, err)
+			require.Equal(st, "1", string(content))
+		})
+	}
+}
+
+func TestDirectExecutorBatch(t *testing.T) {
+	fs := &flakyFs{
+		Fs:       afero.NewMemMapFs(),
+		failures: 1,
+		err:      syscall.EBUSY,
+	}
+	require.NoError(t, afero.WriteFile(fs, "/a", []byte("1"), 0644))
+	e := executors.NewDirectExecutorWithParams(
+		executors.DirectExecutorParams{
+			Retries:      1,
+			RetryBackoff: time.Millisecond,
+		},
+	)
+	// The batch's commands are retried and collected on their own.
+	err := e.Execute(commands.NewBatchCmd(
+		commands.NewWriteFileCmd(fs, "/a", "2"),
+		commands.NewWriteFileCmd(fs, "/b", "3"),
+	))
+	require.NoError(t, err)
+	require.Equal(t, 3, fs.attempts)
+	expected := []commands.Result{
+		{Target: "/a", Changed: true, Old: "1", New: "2"},
+		{Target: "/b", Changed: true, New: "3"},
+	}
+	require.Equal(t, expected, e.(executors.ResultCollector).Results())
+}
@@ -0,0 +1,69 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package executors
+
+import (
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors/commands"
+)
+
+type dryRunExecutor struct {
+	mu      sync.Mutex
+	results []commands.Result
+}
+
+// Creates an executor which doesn't execute the commands, but logs what they
+// would do. Unlike the rendering executors, it's meant to be used on the host
+// being tuned, in place of a DirectExecutor: the tuners run as they would
+// otherwise, and the commands which support it (see commands.Previewer) read
+// their target's current value to log whether it would change. The previewed
+// results can be retrieved, as it implements ResultCollector.
+func NewDryRunExecutor() Executor {
+	return &dryRunExecutor{}
+}
+
+func (e *dryRunExecutor) Execute(cmd commands.Command) error {
+	desc := cmd.Describe().Desc
+	p, ok := cmd.(commands.Previewer)
+	if !ok {
+		log.Infof("Would run: %s", desc)
+		return nil
+	}
+	res, err := p.Preview()
+	if err != nil {
+		log.Infof("Would run: %s (couldn't read the current value: %v)", desc, err)
+	} else if !res.Changed {
+		log.Infof("Wouldn't run: %s ('%s' already holds it)", desc, res.Target)
+	} else {
+		log.Infof(
+			"Would run: %s (current value: '%s')",
+			desc,
+			strings.TrimSpace(res.Old),
+		)
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.results = append(e.results, res)
+	return nil
+}
+
+func (e *dryRunExecutor) Results() []commands.Result {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]commands.Result(nil), e.results...)
+}
+
+// Nothing is changed, so the tuners mustn't expect the changes to be applied.
+func (e *dryRunExecutor) IsLazy() bool {
+	return true
+}
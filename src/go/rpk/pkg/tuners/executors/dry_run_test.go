@@ -0,0 +1,59 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package executors_test
+
+import (
+	"bytes"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors/commands"
+)
+
+func TestDryRunExecutor(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/proc/sys/vm/swappiness", []byte("60\n"), 0644))
+	require.NoError(t, afero.WriteFile(fs, "/proc/sys/fs/aio-max-nr", []byte("1048576\n"), 0644))
+	var out bytes.Buffer
+	defer log.SetOutput(log.StandardLogger().Out)
+	log.SetOutput(&out)
+
+	e := executors.NewDryRunExecutor()
+	require.True(t, e.IsLazy())
+	cmds := []commands.Command{
+		commands.NewWriteFileCmd(fs, "/proc/sys/vm/swappiness", "1"),
+		commands.NewWriteFileCmd(fs, "/proc/sys/fs/aio-max-nr", "1048576"),
+		&sleepCommand{},
+	}
+	for _, c := range cmds {
+		require.NoError(t, e.Execute(c))
+	}
+
+	// Nothing should have been changed.
+	bs, err := afero.ReadFile(fs, "/proc/sys/vm/swappiness")
+	require.NoError(t, err)
+	require.Equal(t, "60\n", string(bs))
+
+	logs := out.String()
+	require.Contains(t, logs, "Would run: Write '1' to '/proc/sys/vm/swappiness' (current value: '60')")
+	require.Contains(t, logs, "Wouldn't run: Write '1048576' to '/proc/sys/fs/aio-max-nr'")
+	require.Contains(t, logs, "Would run: Sleep 0s")
+
+	c, ok := e.(executors.ResultCollector)
+	require.True(t, ok)
+	expected := []commands.Result{
+		{Target: "/proc/sys/vm/swappiness", Changed: true, Old: "60\n", New: "1"},
+		{Target: "/proc/sys/fs/aio-max-nr", Changed: false, Old: "1048576\n", New: "1048576"},
+	}
+	require.Equal(t, expected, c.Results())
+}
@@ -9,9 +9,88 @@
 
 package executors
 
-import "github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors/commands"
+import (
+	"context"
+	"fmt"
+
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors/commands"
+)
 
 type Executor interface {
 	Execute(commands.Command) error
 	IsLazy() bool
 }
+
+// ResultCollector is implemented by executors which keep the results reported
+// by the commands they execute (see commands.ResultReporter).
+type ResultCollector interface {
+	Executor
+	// Returns the results of the commands executed so far, in order.
+	Results() []commands.Result
+}
+
+// ContextExecutor is implemented by executors which stop executing commands
+// once a context is done, e.g. as the tuner executing them timed out. The
+// commands which take a context (see commands.ContextCommand) are given it,
+// so that they're aborted.
+type ContextExecutor interface {
+	Executor
+	ExecuteContext(ctx context.Context, cmd commands.Command) error
+}
+
+// Executes cmd through executor, with ctx if it's a ContextExecutor.
+// Otherwise, cmd is only executed if ctx isn't done yet.
+func ExecuteContext(
+	ctx context.Context, executor Executor, cmd commands.Command,
+) error {
+	if e, ok := executor.(ContextExecutor); ok {
+		return e.ExecuteContext(ctx, cmd)
+	}
+	if err := notExecutedError(ctx, cmd); err != nil {
+		return err
+	}
+	return executor.Execute(cmd)
+}
+
+// Returns an error if ctx is done, so that cmd must not be executed.
+func notExecutedError(ctx context.Context, cmd commands.Command) error {
+	if ctx.Err() == nil {
+		return nil
+	}
+	return fmt.Errorf(
+		"command '%s' wasn't executed: %w",
+		cmd.Describe().Desc,
+		ctx.Err(),
+	)
+}
+
+type boundExecutor struct {
+	ctx      context.Context
+	executor Executor
+}
+
+// Wraps executor, so that the commands executed through it are executed with
+// ctx (see ExecuteContext). Once ctx is done, the commands which didn't start
+// fail right away, so that a tuner which timed out stops changing the
+// system. The command it's executing, if it doesn't take a context, is
+// abandoned by the direct executor, and may still complete.
+func WithContext(ctx context.Context, executor Executor) Executor {
+	return &boundExecutor{ctx: ctx, executor: executor}
+}
+
+func (e *boundExecutor) Execute(cmd commands.Command) error {
+	return ExecuteContext(e.ctx, e.executor, cmd)
+}
+
+func (e *boundExecutor) IsLazy() bool {
+	return e.executor.IsLazy()
+}
+
+// Returns the results collected by the wrapped executor, if it's a
+// ResultCollector.
+func (e *boundExecutor) Results() []commands.Result {
+	if c, ok := e.executor.(ResultCollector); ok {
+		return c.Results()
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package executors
+
+import (
+	"encoding/json"
+
+	"github.com/spf13/afero"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors/commands"
+)
+
+type jsonRenderingExecutor struct {
+	fs       afero.Fs
+	filename string
+	deffered error
+	cmds     []commands.Description
+}
+
+// Creates an executor which, instead of executing the commands, collects
+// their descriptions and writes them to filename as a JSON array. The file
+// is rewritten after every command, so it always holds a valid document.
+func NewJSONRenderingExecutor(fs afero.Fs, filename string) Executor {
+	e := &jsonRenderingExecutor{
+		fs:       fs,
+		filename: filename,
+		cmds:     []commands.Description{},
+	}
+	e.deffered = e.flush()
+	return e
+}
+
+func (e *jsonRenderingExecutor) Execute(cmd commands.Command) error {
+	if e.deffered != nil {
+		return e.deffered
+	}
+	e.cmds = append(e.cmds, cmd.Describe())
+	return e.flush()
+}
+
+func (e *jsonRenderingExecutor) IsLazy() bool {
+	return true
+}
+
+func (e *jsonRenderingExecutor) flush() error {
+	bs, err := json.MarshalIndent(e.cmds, "", "  ")
+	if err != nil {
+		return err
+	}
+	return afero.WriteFile(e.fs, e.filename, append(bs, '\n'), 0644)
+}
@@ -0,0 +1,66 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package executors_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors/commands"
+)
+
+func TestJSONRenderingExecutor(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	out := "/tune.json"
+	e := executors.NewJSONRenderingExecutor(fs, out)
+	require.True(t, e.IsLazy())
+
+	bs, err := afero.ReadFile(fs, out)
+	require.NoError(t, err)
+	require.Equal(t, "[]\n", string(bs))
+
+	cmds := []commands.Command{
+		commands.NewWriteFileCmd(fs, "/proc/sys/vm/swappiness", "1"),
+		commands.NewSysctlSetCmd("fs.aio-max-nr", "1048576"),
+		commands.NewBackupFileCmd(fs, "/etc/default/irqbalance"),
+	}
+	for _, c := range cmds {
+		require.NoError(t, e.Execute(c))
+	}
+
+	bs, err = afero.ReadFile(fs, out)
+	require.NoError(t, err)
+	var descs []commands.Description
+	require.NoError(t, json.Unmarshal(bs, &descs))
+
+	expected := []commands.Description{{
+		Type:   "write_file",
+		Target: "/proc/sys/vm/swappiness",
+		Args:   []string{"1", "644"},
+		Desc:   "Write '1' to '/proc/sys/vm/swappiness'",
+	}, {
+		Type:   "sysctl_set",
+		Target: "fs.aio-max-nr",
+		Args:   []string{"1048576"},
+		Desc:   "Set sysctl 'fs.aio-max-nr' to '1048576'",
+	}, {
+		Type:   "backup_file",
+		Target: "/etc/default/irqbalance",
+		Desc:   "Back up '/etc/default/irqbalance'",
+	}}
+	require.Equal(t, expected, descs)
+	// Nothing should have been executed.
+	exists, err := afero.Exists(fs, "/proc/sys/vm/swappiness")
+	require.NoError(t, err)
+	require.False(t, exists)
+}
@@ -0,0 +1,197 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package executors
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/afero"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors/commands"
+)
+
+type ManifestFormat string
+
+const (
+	ManifestJSON ManifestFormat = "json"
+	ManifestCSV  ManifestFormat = "csv"
+)
+
+// A manifest row, listing what a command in the rendered script touches.
+type ManifestEntry struct {
+	// The kind of command, e.g. 'write_file' or 'sysctl_set'.
+	Type string `json:"type"`
+	// The file, sysctl key, unit or interface the command acts upon. For
+	// the commands running a program, it's the program.
+	Target string `json:"target"`
+	// The values the command sets, space-separated, e.g. the content written
+	// to a file and its mode. For the commands running a program, it's the
+	// command line.
+	Value string `json:"value"`
+}
+
+// ManifestRenderingExecutor renders commands to a script, keeping track of
+// what they touch.
+type ManifestRenderingExecutor interface {
+	Executor
+	// Writes the manifest of every command rendered so far.
+	WriteManifest() error
+}
+
+type manifestRenderingExecutor struct {
+	script   Executor
+	ctx      *commands.RenderContext
+	fs       afero.Fs
+	filename string
+	format   ManifestFormat
+	deffered error
+	entries  []ManifestEntry
+}
+
+// Creates an executor rendering the commands to scriptFile, like the one
+// returned by NewScriptRenderingExecutor, which also keeps a manifest of
+// what the script touches, for operators to review, with a row for each
+// command rendered (see ManifestEntry). The commands made of others (see
+// commands.Composite) get a row for each of them instead. The manifest is
+// written to manifestFile by WriteManifest.
+func NewManifestRenderingExecutor(
+	fs afero.Fs, scriptFile, manifestFile string, format ManifestFormat,
+) ManifestRenderingExecutor {
+	return NewManifestRenderingExecutorWithContext(
+		fs, scriptFile, nil, manifestFile, format,
+	)
+}
+
+// Creates an executor like the one returned by NewManifestRenderingExecutor,
+// rendering the script in ctx (see NewScriptRenderingExecutorWithContext).
+// The manifest lists the targets as the script renders them, e.g.
+// '"/sys/block/${DATA_DEVICE}"/queue/nomerges'.
+func NewManifestRenderingExecutorWithContext(
+	fs afero.Fs,
+	scriptFile string,
+	ctx *commands.RenderContext,
+	manifestFile string,
+	format ManifestFormat,
+) ManifestRenderingExecutor {
+	e := &manifestRenderingExecutor{
+		script:   NewScriptRenderingExecutorWithContext(fs, scriptFile, ctx),
+		ctx:      ctx,
+		fs:       fs,
+		filename: manifestFile,
+		format:   format,
+		entries:  []ManifestEntry{},
+	}
+	if format != ManifestJSON && format != ManifestCSV {
+		e.deffered = fmt.Errorf(
+			"unsupported manifest format '%s', only %s are supported",
+			format,
+			[]ManifestFormat{ManifestJSON, ManifestCSV},
+		)
+	}
+	return e
+}
+
+// Renders cmd to the script and, only if that succeeded, adds its rows to
+// the manifest, so that both hold the same commands.
+func (e *manifestRenderingExecutor) Execute(cmd commands.Command) error {
+	if e.deffered != nil {
+		return e.deffered
+	}
+	err := e.script.Execute(cmd)
+	if err != nil {
+		return err
+	}
+	entries := manifestEntries(cmd)
+	for i := range entries {
+		entries[i].Target = e.ctx.Path(entries[i].Target)
+	}
+	e.entries = append(e.entries, entries...)
+	return nil
+}
+
+func manifestEntries(cmd commands.Command) []ManifestEntry {
+	c, ok := cmd.(commands.Composite)
+	if !ok {
+		return []ManifestEntry{NewManifestEntry(cmd.Describe())}
+	}
+	var entries []ManifestEntry
+	for _, sub := range c.Commands() {
+		entries = append(entries, manifestEntries(sub)...)
+	}
+	return entries
+}
+
+func (e *manifestRenderingExecutor) IsLazy() bool {
+	return true
+}
+
+func (e *manifestRenderingExecutor) WriteManifest() error {
+	if e.deffered != nil {
+		return e.deffered
+	}
+	var bs []byte
+	var err error
+	if e.format == ManifestCSV {
+		bs, err = renderManifestCSV(e.entries)
+	} else {
+		bs, err = json.MarshalIndent(e.entries, "", "  ")
+		bs = append(bs, '\n')
+	}
+	if err != nil {
+		return err
+	}
+	return afero.WriteFile(e.fs, e.filename, bs, 0644)
+}
+
+// Returns the manifest row of the command described by desc.
+func NewManifestEntry(desc commands.Description) ManifestEntry {
+	switch desc.Type {
+	case "exec", "launch":
+		// They're described by the program they run and its arguments.
+		words := make([]string, len(desc.Args))
+		for i, arg := range desc.Args {
+			words[i] = commands.ShellQuote(arg)
+		}
+		target := desc.Target
+		if target == "" && len(desc.Args) > 0 {
+			target = desc.Args[0]
+		}
+		return ManifestEntry{
+			Type:   desc.Type,
+			Target: target,
+			Value:  strings.Join(words, " "),
+		}
+	}
+	return ManifestEntry{
+		Type:   desc.Type,
+		Target: desc.Target,
+		Value:  strings.Join(desc.Args, " "),
+	}
+}
+
+func renderManifestCSV(entries []ManifestEntry) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	err := w.Write([]string{"type", "target", "value"})
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		err = w.Write([]string{entry.Type, entry.Target, entry.Value})
+		if err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
@@ -0,0 +1,169 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package executors_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors/commands"
+)
+
+type unrenderableCmd struct {
+	commands.Command
+}
+
+func (*unrenderableCmd) RenderScript(*bufio.Writer) error {
+	return errors.New("can't render")
+}
+
+func (*unrenderableCmd) Describe() commands.Description {
+	return commands.Description{Type: "unrenderable"}
+}
+
+func manifestTestCommands(fs afero.Fs) []commands.Command {
+	return []commands.Command{
+		commands.NewSysctlSetCmd("fs.aio-max-nr", "1048576"),
+		commands.NewForExecCmd("systemctl", []string{"try-restart", "irqbalance"}),
+		commands.NewPersistentSysctlSetCmd(fs, "vm.max_map_count", "262144"),
+		&unrenderableCmd{},
+		commands.NewWriteFileCmd(fs, "/sys/block/sda/queue/nomerges", "2"),
+	}
+}
+
+func TestManifestRenderingExecutorJSON(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	e := executors.NewManifestRenderingExecutor(
+		fs, "/tune.sh", "/tune.json", executors.ManifestJSON,
+	)
+	require.True(t, e.IsLazy())
+	require.NoError(t, e.WriteManifest())
+	bs, err := afero.ReadFile(fs, "/tune.json")
+	require.NoError(t, err)
+	require.Equal(t, "[]\n", string(bs))
+
+	for _, cmd := range manifestTestCommands(fs) {
+		err := e.Execute(cmd)
+		if _, ok := cmd.(*unrenderableCmd); ok {
+			require.EqualError(t, err, "can't render")
+			continue
+		}
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, e.WriteManifest())
+	bs, err = afero.ReadFile(fs, "/tune.json")
+	require.NoError(t, err)
+	var entries []executors.ManifestEntry
+	require.NoError(t, json.Unmarshal(bs, &entries))
+	// The command which couldn't be rendered isn't listed, and the one
+	// persisting the sysctl is listed as the two it's made of.
+	expected := []executors.ManifestEntry{
+		{Type: "sysctl_set", Target: "fs.aio-max-nr", Value: "1048576"},
+		{Type: "exec", Target: "systemctl", Value: "systemctl try-restart irqbalance"},
+		{Type: "ensure_line_in_file", Target: commands.SysctlDropInFile, Value: "vm.max_map_count = 262144"},
+		{Type: "exec", Target: "sysctl", Value: "sysctl -p /etc/sysctl.d/99-redpanda.conf"},
+		{Type: "write_file", Target: "/sys/block/sda/queue/nomerges", Value: "2 644"},
+	}
+	require.Equal(t, expected, entries)
+
+	// Every row matches a step of the script.
+	script, err := afero.ReadFile(fs, "/tune.sh")
+	require.NoError(t, err)
+	for _, s := range []string{
+		"sysctl -w fs.aio-max-nr=1048576",
+		"systemctl try-restart irqbalance",
+		"printf '%s\\n' 'vm.max_map_count = 262144' >> /etc/sysctl.d/99-redpanda.conf",
+		"sysctl -p /etc/sysctl.d/99-redpanda.conf",
+		"echo '2' > /sys/block/sda/queue/nomerges",
+	} {
+		require.Contains(t, string(script), s)
+	}
+	// Nothing should have been executed.
+	exists, err := afero.Exists(fs, "/sys/block/sda/queue/nomerges")
+	require.NoError(t, err)
+	require.False(t, exists)
+}
+
+func TestManifestRenderingExecutorCSV(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	e := executors.NewManifestRenderingExecutor(
+		fs, "/tune.sh", "/tune.csv", executors.ManifestCSV,
+	)
+	cmds := []commands.Command{
+		commands.NewWriteFileCmd(fs, "/etc/default/irqbalance", "A=1\nB=\"2, 3\"\n"),
+		commands.NewForExecCmd("sh", []string{"-c", "echo it's"}),
+	}
+	for _, cmd := range cmds {
+		require.NoError(t, e.Execute(cmd))
+	}
+	// Nothing is written until the manifest is complete.
+	exists, err := afero.Exists(fs, "/tune.csv")
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	require.NoError(t, e.WriteManifest())
+	bs, err := afero.ReadFile(fs, "/tune.csv")
+	require.NoError(t, err)
+	expected := strings.Join([]string{
+		"type,target,value",
+		"write_file,/etc/default/irqbalance,\"A=1",
+		"B=\"\"2, 3\"\"",
+		" 644\"",
+		"exec,sh,sh -c 'echo it'\\''s'",
+		"",
+	}, "\n")
+	require.Equal(t, expected, string(bs))
+}
+
+func TestManifestRenderingExecutorUnsupportedFormat(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	e := executors.NewManifestRenderingExecutor(fs, "/tune.sh", "/tune.xml", "xml")
+	err := e.Execute(commands.NewSysctlSetCmd("vm.swappiness", "1"))
+	require.EqualError(t, err, "unsupported manifest format 'xml', only [json csv] are supported")
+	require.EqualError(t, e.WriteManifest(), err.Error())
+}
+
+func TestManifestRenderingExecutorWithContext(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	ctx := &commands.RenderContext{}
+	ctx.Bind("/sys/block/sdb", "/sys/block/${DATA_DEVICE}")
+	e := executors.NewManifestRenderingExecutorWithContext(
+		fs, "/tune.sh", ctx, "/tune.json", executors.ManifestJSON,
+	)
+	cmds := []commands.Command{
+		commands.NewWriteFileCmd(fs, "/sys/block/sdb/queue/nomerges", "2"),
+		commands.NewSysctlSetCmd("vm.swappiness", "1"),
+	}
+	for _, cmd := range cmds {
+		require.NoError(t, e.Execute(cmd))
+	}
+	require.NoError(t, e.WriteManifest())
+
+	bs, err := afero.ReadFile(fs, "/tune.json")
+	require.NoError(t, err)
+	var entries []executors.ManifestEntry
+	require.NoError(t, json.Unmarshal(bs, &entries))
+	// The targets are listed as the script renders them.
+	expected := []executors.ManifestEntry{
+		{Type: "write_file", Target: `"/sys/block/${DATA_DEVICE}"/queue/nomerges`, Value: "2 644"},
+		{Type: "sysctl_set", Target: "vm.swappiness", Value: "1"},
+	}
+	require.Equal(t, expected, entries)
+	script, err := afero.ReadFile(fs, "/tune.sh")
+	require.NoError(t, err)
+	require.Contains(t, string(script), `echo '2' > "/sys/block/${DATA_DEVICE}"/queue/nomerges`)
+}
@@ -0,0 +1,100 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package executors
+
+import (
+	"context"
+	"sync"
+
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors/commands"
+)
+
+// The stage a command's execution is at.
+type CommandStatus int
+
+const (
+	CommandStarted CommandStatus = iota
+	CommandSucceeded
+	CommandFailed
+)
+
+func (s CommandStatus) String() string {
+	switch s {
+	case CommandStarted:
+		return "started"
+	case CommandSucceeded:
+		return "succeeded"
+	case CommandFailed:
+		return "failed"
+	}
+	return "unknown"
+}
+
+// An update on a command's execution. Err is only set when it failed.
+type CommandProgress struct {
+	Command commands.Description
+	Status  CommandStatus
+	Err     error
+}
+
+// ProgressObserver is called as each command starts and finishes.
+type ProgressObserver func(CommandProgress)
+
+type observingExecutor struct {
+	executor Executor
+	observer ProgressObserver
+	mu       sync.Mutex
+}
+
+// Wraps executor, calling observer right before each command is executed and
+// right after it finishes, e.g. to render a progress indicator. The calls
+// are serialized, so observer doesn't need to be safe for concurrent use
+// even when the tuners run in parallel, but it blocks the command it's called
+// for until it returns, so it must be quick.
+func NewObservingExecutor(executor Executor, observer ProgressObserver) Executor {
+	return &observingExecutor{executor: executor, observer: observer}
+}
+
+func (e *observingExecutor) Execute(cmd commands.Command) error {
+	return e.ExecuteContext(context.Background(), cmd)
+}
+
+func (e *observingExecutor) ExecuteContext(
+	ctx context.Context, cmd commands.Command,
+) error {
+	desc := cmd.Describe()
+	e.notify(CommandProgress{Command: desc, Status: CommandStarted})
+	err := ExecuteContext(ctx, e.executor, cmd)
+	if err != nil {
+		e.notify(CommandProgress{Command: desc, Status: CommandFailed, Err: err})
+		return err
+	}
+	e.notify(CommandProgress{Command: desc, Status: CommandSucceeded})
+	return nil
+}
+
+func (e *observingExecutor) notify(progress CommandProgress) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.observer(progress)
+}
+
+func (e *observingExecutor) IsLazy() bool {
+	return e.executor.IsLazy()
+}
+
+// Returns the results collected by the wrapped executor, if it's a
+// ResultCollector.
+func (e *observingExecutor) Results() []commands.Result {
+	if c, ok := e.executor.(ResultCollector); ok {
+		return c.Results()
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package executors_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors"
+)
+
+func TestObservingExecutor(t *testing.T) {
+	var updates []executors.CommandProgress
+	e := executors.NewObservingExecutor(
+		executors.NewDirectExecutor(),
+		func(p executors.CommandProgress) {
+			updates = append(updates, p)
+		},
+	)
+	require.NoError(t, e.Execute(&sleepCommand{}))
+	err := e.Execute(&sleepCommand{err: errors.New("boom")})
+	require.EqualError(t, err, "boom")
+
+	require.Len(t, updates, 4)
+	statuses := make([]executors.CommandStatus, len(updates))
+	for i, u := range updates {
+		require.Equal(t, "Sleep 0s", u.Command.Desc)
+		statuses[i] = u.Status
+	}
+	require.Equal(
+		t,
+		[]executors.CommandStatus{
+			executors.CommandStarted,
+			executors.CommandSucceeded,
+			executors.CommandStarted,
+			executors.CommandFailed,
+		},
+		statuses,
+	)
+	require.NoError(t, updates[1].Err)
+	require.EqualError(t, updates[3].Err, "boom")
+}
+
+func TestObservingExecutorSerializesCalls(t *testing.T) {
+	running, maxRunning, calls := 0, 0, 0
+	e := executors.NewObservingExecutor(
+		executors.NewDirectExecutor(),
+		func(executors.CommandProgress) {
+			// Not synchronized: the race detector and the
+			// counter catch concurrent calls.
+			running++
+			if running > maxRunning {
+				maxRunning = running
+			}
+			time.Sleep(time.Millisecond)
+			calls++
+			running--
+		},
+	)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			require.NoError(t, e.Execute(&sleepCommand{}))
+		}()
+	}
+	wg.Wait()
+	require.Equal(t, 16, calls)
+	require.Equal(t, 1, maxRunning)
+}
@@ -0,0 +1,144 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package executors
+
+import (
+	"context"
+	"fmt"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/afero"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors/commands"
+)
+
+// Owner is the user and group the files written under Dirs are handed over
+// to, e.g. so that the files tuning creates in the data directory as root
+// can be read by redpanda, running as an unprivileged user.
+type Owner struct {
+	UID  int
+	GID  int
+	Dirs []string
+}
+
+// Resolves spec, in the form 'user[:group]', where each of them can also be
+// numeric. If the group is omitted, the user's primary group is used.
+func LookupOwner(spec string, dirs []string) (Owner, error) {
+	userName, groupName := spec, ""
+	if i := strings.Index(spec, ":"); i >= 0 {
+		userName, groupName = spec[:i], spec[i+1:]
+	}
+	u, err := lookupUser(userName)
+	if err != nil {
+		return Owner{}, fmt.Errorf("couldn't resolve the user '%s': %w", userName, err)
+	}
+	uid, err := strconv.Atoi(u.Uid)
+	if err != nil {
+		return Owner{}, fmt.Errorf("user '%s' has a non-numeric uid '%s'", userName, u.Uid)
+	}
+	gidStr := u.Gid
+	if groupName != "" {
+		g, err := lookupGroup(groupName)
+		if err != nil {
+			return Owner{}, fmt.Errorf("couldn't resolve the group '%s': %w", groupName, err)
+		}
+		gidStr = g.Gid
+	}
+	gid, err := strconv.Atoi(gidStr)
+	if err != nil {
+		return Owner{}, fmt.Errorf("'%s' has a non-numeric gid '%s'", spec, gidStr)
+	}
+	return Owner{UID: uid, GID: gid, Dirs: dirs}, nil
+}
+
+func lookupUser(name string) (*user.User, error) {
+	if _, err := strconv.Atoi(name); err == nil {
+		return user.LookupId(name)
+	}
+	return user.Lookup(name)
+}
+
+func lookupGroup(name string) (*user.Group, error) {
+	if _, err := strconv.Atoi(name); err == nil {
+		return user.LookupGroupId(name)
+	}
+	return user.LookupGroup(name)
+}
+
+// Returns whether path is within one of the owner's directories.
+func (o Owner) owns(path string) bool {
+	for _, dir := range o.Dirs {
+		rel, err := filepath.Rel(filepath.Clean(dir), filepath.Clean(path))
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, "../") {
+			return true
+		}
+	}
+	return false
+}
+
+type owningExecutor struct {
+	fs       afero.Fs
+	executor Executor
+	owner    Owner
+}
+
+// Wraps executor, so that after executing a command which writes files (see
+// commands.FileProducer) under the owner's directories, it executes a
+// ChownCmd handing each of them over to the owner. Files written elsewhere,
+// e.g. to /proc or /etc, keep their owner.
+func NewOwningExecutor(fs afero.Fs, executor Executor, owner Owner) Executor {
+	return &owningExecutor{fs: fs, executor: executor, owner: owner}
+}
+
+func (e *owningExecutor) Execute(cmd commands.Command) error {
+	return e.ExecuteContext(context.Background(), cmd)
+}
+
+func (e *owningExecutor) ExecuteContext(
+	ctx context.Context, cmd commands.Command,
+) error {
+	err := ExecuteContext(ctx, e.executor, cmd)
+	if err != nil {
+		return err
+	}
+	p, ok := cmd.(commands.FileProducer)
+	if !ok {
+		return nil
+	}
+	for _, path := range p.ProducedFiles() {
+		if !e.owner.owns(path) {
+			continue
+		}
+		err = ExecuteContext(
+			ctx,
+			e.executor,
+			commands.NewChownCmd(e.fs, path, e.owner.UID, e.owner.GID),
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *owningExecutor) IsLazy() bool {
+	return e.executor.IsLazy()
+}
+
+// Returns the results collected by the wrapped executor, if it's a
+// ResultCollector.
+func (e *owningExecutor) Results() []commands.Result {
+	if c, ok := e.executor.(ResultCollector); ok {
+		return c.Results()
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package executors_test
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors/commands"
+)
+
+func TestLookupOwner(t *testing.T) {
+	uid, gid := os.Getuid(), os.Getgid()
+	owner, err := executors.LookupOwner(
+		fmt.Sprintf("%d:%d", uid, gid),
+		[]string{"/var/lib/redpanda/data"},
+	)
+	require.NoError(t, err)
+	require.Equal(t, uid, owner.UID)
+	require.Equal(t, gid, owner.GID)
+
+	_, err = executors.LookupOwner("no-such-user-here", nil)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "couldn't resolve the user 'no-such-user-here'")
+}
+
+func TestOwningExecutorScript(t *testing.T) {
+	const scriptPath = "/tune.sh"
+	fs := afero.NewMemMapFs()
+	owner := executors.Owner{UID: 101, GID: 102, Dirs: []string{"/var/lib/redpanda/data/"}}
+	e := executors.NewOwningExecutor(
+		fs,
+		executors.NewScriptRenderingExecutor(fs, scriptPath),
+		owner,
+	)
+	require.True(t, e.IsLazy())
+	cmds := []commands.Command{
+		commands.NewWriteFileCmd(fs, "/proc/sys/vm/swappiness", "1"),
+		commands.NewWriteSizedFileCmd("/var/lib/redpanda/data/ballast", 1024, true),
+		commands.NewWriteFileCmd(fs, "/var/lib/redpanda/data/sub/file", "x"),
+		commands.NewWriteFileCmd(fs, "/var/lib/redpanda/database", "x"),
+	}
+	for _, cmd := range cmds {
+		require.NoError(t, e.Execute(cmd))
+	}
+	script, err := afero.ReadFile(fs, scriptPath)
+	require.NoError(t, err)
+	var chowns []string
+	for _, line := range strings.Split(string(script), "\n") {
+		if strings.HasPrefix(line, "chown ") {
+			chowns = append(chowns, line)
+		}
+	}
+	require.Equal(
+		t,
+		[]string{
+			"chown 101:102 /var/lib/redpanda/data/ballast",
+			"chown 101:102 /var/lib/redpanda/data/sub/file",
+		},
+		chowns,
+	)
+}
+
+func TestOwningExecutorDirect(t *testing.T) {
+	dir := t.TempDir()
+	// Chowning a file to its own owner doesn't require privileges.
+	owner, err := executors.LookupOwner(fmt.Sprint(os.Getuid()), []string{dir})
+	require.NoError(t, err)
+	e := executors.NewOwningExecutor(
+		afero.NewOsFs(),
+		executors.NewDirectExecutor(),
+		owner,
+	)
+	path := dir + "/file"
+	require.NoError(t, e.Execute(
+		commands.NewWriteFileCmd(afero.NewOsFs(), path, "content"),
+	))
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "content", string(content))
+}
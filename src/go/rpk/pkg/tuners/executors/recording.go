@@ -0,0 +1,113 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package executors
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors/commands"
+)
+
+// RecordingExecutor executes commands through another executor, keeping
+// track of how to revert them.
+type RecordingExecutor interface {
+	Executor
+	// Renders a script which reverts, in reverse order, every command
+	// successfully executed so far.
+	RenderUndoScript(w *bufio.Writer) error
+}
+
+type undoEntry struct {
+	// The command reverting the executed one, or nil if it's irreversible.
+	inverse commands.Command
+	desc    commands.Description
+}
+
+type recordingExecutor struct {
+	executor Executor
+	mu       sync.Mutex
+	entries  []undoEntry
+}
+
+// Wraps executor, snapshotting the state each command is about to change
+// before executing it. It's meant to wrap a DirectExecutor.
+func NewRecordingExecutor(executor Executor) RecordingExecutor {
+	return &recordingExecutor{executor: executor}
+}
+
+func (e *recordingExecutor) Execute(cmd commands.Command) error {
+	return e.ExecuteContext(context.Background(), cmd)
+}
+
+func (e *recordingExecutor) ExecuteContext(
+	ctx context.Context, cmd commands.Command,
+) error {
+	entry := undoEntry{desc: cmd.Describe()}
+	if r, ok := cmd.(commands.Reversible); ok {
+		inverse, err := r.Inverse()
+		if err != nil {
+			return fmt.Errorf(
+				"couldn't record how to undo '%s', so it wasn't executed: %w",
+				entry.desc.Desc,
+				err,
+			)
+		}
+		entry.inverse = inverse
+	}
+	err := ExecuteContext(ctx, e.executor, cmd)
+	if err != nil {
+		return err
+	}
+	if entry.inverse == nil {
+		log.Debugf("'%s' can't be undone", entry.desc.Desc)
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.entries = append(e.entries, entry)
+	return nil
+}
+
+// Returns the results collected by the wrapped executor, if it's a
+// ResultCollector.
+func (e *recordingExecutor) Results() []commands.Result {
+	if c, ok := e.executor.(ResultCollector); ok {
+		return c.Results()
+	}
+	return nil
+}
+
+func (e *recordingExecutor) IsLazy() bool {
+	return e.executor.IsLazy()
+}
+
+func (e *recordingExecutor) RenderUndoScript(w *bufio.Writer) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	_, err := fmt.Fprint(w, scriptHeader("Redpanda Tuning Undo Script"))
+	if err != nil {
+		return err
+	}
+	for i := len(e.entries) - 1; i >= 0; i-- {
+		entry := e.entries[i]
+		if entry.inverse == nil {
+			fmt.Fprintf(w, "# '%s' can't be undone\n", entry.desc.Desc)
+			continue
+		}
+		err = entry.inverse.RenderScript(w)
+		if err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
@@ -0,0 +1,94 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package executors_test
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/os"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors/commands"
+)
+
+type unreadableFs struct {
+	afero.Fs
+}
+
+func (*unreadableFs) Open(_ string) (afero.File, error) {
+	return nil, errors.New("permission denied")
+}
+
+func TestRecordingExecutorUndoScript(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/proc/sys/vm/swappiness", []byte("60\n"), 0644))
+	require.NoError(t, afero.WriteFile(
+		fs,
+		"/sys/kernel/mm/transparent_hugepage/enabled",
+		[]byte("always [madvise] never\n"),
+		0644,
+	))
+
+	e := executors.NewRecordingExecutor(executors.NewDirectExecutor())
+	cmds := []commands.Command{
+		commands.NewWriteFileCmd(fs, "/proc/sys/vm/swappiness", "1"),
+		commands.NewWriteFileCmd(fs, "/sys/kernel/mm/transparent_hugepage/enabled", "always"),
+		commands.NewWriteFileCmd(fs, "/var/lib/redpanda/new_file", "content"),
+		commands.NewLaunchCmd(os.NewProc(), time.Second, "true"),
+	}
+	for _, c := range cmds {
+		require.NoError(t, e.Execute(c))
+	}
+	content, err := afero.ReadFile(fs, "/proc/sys/vm/swappiness")
+	require.NoError(t, err)
+	require.Equal(t, "1", string(content))
+
+	var buf bytes.Buffer
+	w := bufio.NewWriter(&buf)
+	require.NoError(t, e.RenderUndoScript(w))
+
+	expected := `#!/bin/bash
+
+# Redpanda Tuning Undo Script
+# ----------------------------------
+# This file was autogenerated by RPK
+
+# 'Run 'true'' can't be undone
+rm -f /var/lib/redpanda/new_file
+echo 'madvise' > /sys/kernel/mm/transparent_hugepage/enabled
+echo '60' > /proc/sys/vm/swappiness
+`
+	require.Equal(t, expected, buf.String())
+}
+
+func TestRecordingExecutorUnreadablePriorValue(t *testing.T) {
+	fs := &unreadableFs{afero.NewMemMapFs()}
+	path := "/proc/sys/vm/swappiness"
+	require.NoError(t, afero.WriteFile(fs.Fs, path, []byte("60"), 0644))
+
+	e := executors.NewRecordingExecutor(executors.NewDirectExecutor())
+	err := e.Execute(commands.NewWriteFileCmd(fs, path, "1"))
+	require.EqualError(
+		t,
+		err,
+		"couldn't record how to undo 'Write '1' to '/proc/sys/vm/swappiness'',"+
+			" so it wasn't executed: couldn't read the current value of"+
+			" '/proc/sys/vm/swappiness': permission denied",
+	)
+	// The command must not have been executed.
+	content, err := afero.ReadFile(fs.Fs, path)
+	require.NoError(t, err)
+	require.Equal(t, "60", string(content))
+}
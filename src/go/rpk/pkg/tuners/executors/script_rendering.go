@@ -21,11 +21,22 @@ import (
 type scriptRenderingExecutor struct {
 	deffered error
 	writer   *bufio.Writer
+	ctx      *commands.RenderContext
 }
 
 // FIXME: @david
 // This should also return an error.
 func NewScriptRenderingExecutor(fs afero.Fs, filename string) Executor {
+	return NewScriptRenderingExecutorWithContext(fs, filename, nil)
+}
+
+// Creates an executor like the one returned by NewScriptRenderingExecutor,
+// but which renders the commands in ctx, so that the paths it binds are
+// resolved when the script runs. ctx's prologue is rendered right after the
+// script's header.
+func NewScriptRenderingExecutorWithContext(
+	fs afero.Fs, filename string, ctx *commands.RenderContext,
+) Executor {
 	file, err := fs.OpenFile(filename, os.O_CREATE|os.O_TRUNC|os.O_RDWR, 0755)
 	if err != nil {
 		return &scriptRenderingExecutor{
@@ -33,24 +44,24 @@ func NewScriptRenderingExecutor(fs afero.Fs, filename string) Executor {
 			writer:   nil,
 		}
 	}
-	header := `#!/bin/bash
-
-# Redpanda Tuning Script
-# ----------------------------------
-# This file was autogenerated by RPK
-
-`
 	w := bufio.NewWriter(file)
-	_, _ = fmt.Fprint(w, header)
+	_, _ = fmt.Fprint(w, scriptHeader("Redpanda Tuning Script"))
+	if prologue := ctx.Prologue(); len(prologue) > 0 {
+		for _, line := range prologue {
+			_, _ = fmt.Fprintln(w, line)
+		}
+		_, _ = fmt.Fprintln(w)
+	}
 	_ = w.Flush()
 	return &scriptRenderingExecutor{
 		deffered: nil,
 		writer:   w,
+		ctx:      ctx,
 	}
 }
 
 func (e *scriptRenderingExecutor) Execute(cmd commands.Command) error {
-	err := cmd.RenderScript(e.writer)
+	err := commands.RenderScriptContext(e.ctx, cmd, e.writer)
 	if err != nil {
 		return err
 	}
@@ -60,3 +71,13 @@ func (e *scriptRenderingExecutor) Execute(cmd commands.Command) error {
 func (e *scriptRenderingExecutor) IsLazy() bool {
 	return true
 }
+
+func scriptHeader(title string) string {
+	return fmt.Sprintf(`#!/bin/bash
+
+# %s
+# ----------------------------------
+# This file was autogenerated by RPK
+
+`, title)
+}
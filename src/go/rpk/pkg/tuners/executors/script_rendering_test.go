@@ -0,0 +1,49 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package executors_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors/commands"
+)
+
+func TestScriptRenderingExecutorWithContext(t *testing.T) {
+	const scriptPath = "/tune.sh"
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/sys/block/sdb/queue/nomerges", []byte("0"), 0644))
+	require.NoError(t, afero.WriteFile(fs, "/proc/sys/vm/swappiness", []byte("60"), 0644))
+	ctx := commands.NewRenderContext()
+	ctx.AddPrologue(`DATA_DEVICE="${DATA_DEVICE:-sdb}"`)
+	ctx.Bind("/sys/block/sdb", "/sys/block/${DATA_DEVICE}")
+
+	executor := executors.NewScriptRenderingExecutorWithContext(fs, scriptPath, ctx)
+	require.NoError(t, executor.Execute(
+		commands.NewWriteFileCmd(fs, "/sys/block/sdb/queue/nomerges", "2"),
+	))
+	// The values which aren't bound are rendered inline.
+	require.NoError(t, executor.Execute(
+		commands.NewWriteFileCmd(fs, "/proc/sys/vm/swappiness", "1"),
+	))
+
+	script, err := afero.ReadFile(fs, scriptPath)
+	require.NoError(t, err)
+	require.True(t, strings.HasSuffix(string(script), `# This file was autogenerated by RPK
+
+DATA_DEVICE="${DATA_DEVICE:-sdb}"
+
+echo '2' > "/sys/block/${DATA_DEVICE}"/queue/nomerges
+echo '1' > /proc/sys/vm/swappiness
+`), string(script))
+}
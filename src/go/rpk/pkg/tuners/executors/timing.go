@@ -0,0 +1,107 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package executors
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors/commands"
+)
+
+// How long a command took to execute, and the error it failed with, if any.
+type CommandTiming struct {
+	Command  commands.Description
+	Duration time.Duration
+	Err      error
+}
+
+// TimingExecutor executes commands through another executor, keeping track
+// of how long each of them took.
+type TimingExecutor interface {
+	Executor
+	// Returns the timings of the commands executed so far, in order.
+	Timings() []CommandTiming
+}
+
+type timingExecutor struct {
+	executor Executor
+	mu       sync.Mutex
+	timings  []CommandTiming
+}
+
+// Wraps executor, measuring around each command's execution. The time
+// includes whatever the wrapped executor does along with executing the
+// command, e.g. retrying it.
+func NewTimingExecutor(executor Executor) TimingExecutor {
+	return &timingExecutor{executor: executor}
+}
+
+func (e *timingExecutor) Execute(cmd commands.Command) error {
+	return e.ExecuteContext(context.Background(), cmd)
+}
+
+func (e *timingExecutor) ExecuteContext(
+	ctx context.Context, cmd commands.Command,
+) error {
+	start := time.Now()
+	err := ExecuteContext(ctx, e.executor, cmd)
+	timing := CommandTiming{
+		Command:  cmd.Describe(),
+		Duration: time.Since(start),
+		Err:      err,
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.timings = append(e.timings, timing)
+	return err
+}
+
+func (e *timingExecutor) Timings() []CommandTiming {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]CommandTiming(nil), e.timings...)
+}
+
+func (e *timingExecutor) IsLazy() bool {
+	return e.executor.IsLazy()
+}
+
+// Returns the results collected by the wrapped executor, if it's a
+// ResultCollector.
+func (e *timingExecutor) Results() []commands.Result {
+	if c, ok := e.executor.(ResultCollector); ok {
+		return c.Results()
+	}
+	return nil
+}
+
+// Summarizes timings, e.g. '12 commands, 340ms'.
+func SummarizeTimings(timings []CommandTiming) string {
+	var total time.Duration
+	failed := 0
+	for _, t := range timings {
+		total += t.Duration
+		if t.Err != nil {
+			failed++
+		}
+	}
+	noun := "commands"
+	if len(timings) == 1 {
+		noun = "command"
+	}
+	summary := fmt.Sprintf("%d %s, %s", len(timings), noun, total.Round(time.Millisecond))
+	if failed > 0 {
+		summary += fmt.Sprintf(" (%d failed)", failed)
+	}
+	return summary
+}
@@ -0,0 +1,44 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package executors_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors"
+)
+
+func TestTimingExecutor(t *testing.T) {
+	e := executors.NewTimingExecutor(executors.NewDirectExecutor())
+	require.NoError(t, e.Execute(&sleepCommand{d: 20 * time.Millisecond}))
+	err := e.Execute(&sleepCommand{err: errors.New("boom")})
+	require.EqualError(t, err, "boom")
+
+	timings := e.Timings()
+	require.Len(t, timings, 2)
+	require.Equal(t, "Sleep 20ms", timings[0].Command.Desc)
+	require.GreaterOrEqual(t, int64(timings[0].Duration), int64(20*time.Millisecond))
+	require.NoError(t, timings[0].Err)
+	require.EqualError(t, timings[1].Err, "boom")
+}
+
+func TestSummarizeTimings(t *testing.T) {
+	timings := []executors.CommandTiming{
+		{Duration: 300 * time.Millisecond},
+		{Duration: 40*time.Millisecond + 200*time.Microsecond},
+	}
+	require.Equal(t, "2 commands, 340ms", executors.SummarizeTimings(timings))
+
+	timings = []executors.CommandTiming{{Duration: time.Second, Err: errors.New("boom")}}
+	require.Equal(t, "1 command, 1s (1 failed)", executors.SummarizeTimings(timings))
+}
@@ -7,19 +7,23 @@
 // the Business Source License, use of this software will be governed
 // by the Apache License, Version 2.0
 
-// +build linux
-
 package factory
 
 import (
+	"errors"
+	"fmt"
+	"net"
+	"path/filepath"
 	"runtime"
+	"strconv"
+	"sync"
 	"time"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/afero"
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/cloud/gcp"
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/config"
-	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/net"
+	vnet "github.com/vectorizedio/redpanda/src/go/rpk/pkg/net"
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/os"
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/system"
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners"
@@ -31,33 +35,32 @@ import (
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors"
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/hwloc"
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/irq"
-)
-
-var (
-	allTuners = map[string]func(*tunersFactory, *TunerParams) tuners.Tunable{
-		"disk_irq":              (*tunersFactory).newDiskIRQTuner,
-		"disk_scheduler":        (*tunersFactory).newDiskSchedulerTuner,
-		"disk_nomerges":         (*tunersFactory).newDiskNomergesTuner,
-		"disk_write_cache":      (*tunersFactory).newGcpWriteCacheTuner,
-		"fstrim":                (*tunersFactory).newFstrimTuner,
-		"net":                   (*tunersFactory).newNetworkTuner,
-		"cpu":                   (*tunersFactory).newCpuTuner,
-		"aio_events":            (*tunersFactory).newMaxAIOEventsTuner,
-		"clocksource":           (*tunersFactory).newClockSourceTuner,
-		"swappiness":            (*tunersFactory).newSwappinessTuner,
-		"transparent_hugepages": (*tunersFactory).newTHPTuner,
-		"coredump":              (*tunersFactory).newCoredumpTuner,
-		"ballast_file":          (*tunersFactory).newBallastFileTuner,
-	}
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/network"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/topology"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/utils"
 )
 
 type TunerParams struct {
 	Mode          string
 	CpuMask       string
+	CpuSet        string
 	RebootAllowed bool
 	Disks         []string
 	Directories   []string
 	Nics          []string
+	Profile       *TuningProfile
+	// Whether to install the packages providing the binaries the tuners run
+	// (see tunersPackages) when they're missing.
+	InstallPackages bool
+	// The process moved into redpanda's cgroup. If 0, it's the running
+	// redpanda's, read from its PID file.
+	CgroupPid int
+}
+
+// The packages providing the binaries run by the tuners which don't ship
+// with rpk.
+var tunersPackages = map[string]tuners.RequiredPackage{
+	"ethtool": {Name: "ethtool", Binaries: []string{"ethtool"}},
 }
 
 type TunersFactory interface {
@@ -74,7 +77,8 @@ type tunersFactory struct {
 	blockDevices      disk.BlockDevices
 	proc              os.Proc
 	grub              system.Grub
-	executor          executors.Executor
+	executor          executors.DependencyResolvingExecutor
+	timeout           time.Duration
 }
 
 func NewDirectExecutorTunersFactory(
@@ -97,6 +101,20 @@ func NewScriptRenderingTunersFactory(
 	return newTunersFactory(fs, conf, irqProcFile, proc, irqDeviceInfo, executor, timeout)
 }
 
+// Creates a factory whose tuners run their commands through the given
+// executor.
+func NewTunersFactory(
+	fs afero.Fs,
+	conf config.Config,
+	executor executors.Executor,
+	timeout time.Duration,
+) TunersFactory {
+	irqProcFile := irq.NewProcFile(fs)
+	proc := os.NewProc()
+	irqDeviceInfo := irq.NewDeviceInfo(fs, irqProcFile)
+	return newTunersFactory(fs, conf, irqProcFile, proc, irqDeviceInfo, executor, timeout)
+}
+
 func newTunersFactory(
 	fs afero.Fs,
 	conf config.Config,
@@ -106,6 +124,10 @@ func newTunersFactory(
 	executor executors.Executor,
 	timeout time.Duration,
 ) TunersFactory {
+	// The commands are ordered after the ones they depend on, whichever
+	// executor runs them.
+	resolver := executors.NewDependencyResolvingExecutor(executor)
+	executor = resolver
 	return &tunersFactory{
 		fs:                fs,
 		conf:              conf,
@@ -116,20 +138,31 @@ func newTunersFactory(
 		blockDevices:      disk.NewBlockDevices(fs, irqDeviceInfo, irqProcFile, proc, timeout),
 		grub:              system.NewGrub(os.NewCommands(proc), proc, fs, executor, timeout),
 		proc:              proc,
-		executor:          executor,
+		executor:          resolver,
+		timeout:           timeout,
 	}
 }
 
 func AvailableTuners() []string {
-	var keys []string
-	for key := range allTuners {
-		keys = append(keys, key)
-	}
-	return keys
+	return DefaultRegistry.Names()
 }
 
 func IsTunerAvailable(tuner string) bool {
-	return allTuners[tuner] != nil
+	return DefaultRegistry.Has(tuner)
+}
+
+// Returns whether tuner only changes state no other tuner touches, so that
+// it can run concurrently with the rest. The ones that aren't (e.g. the ones
+// that distribute IRQs, which share the irqbalance config and the CPU masks)
+// must run one after the other, in the order they were requested. The
+// nic_channels tuner changes the NICs' IRQs, which the net tuner distributes,
+// and the irq_balance one rewrites the irqbalance config.
+func IsTunerIndependent(tuner string) bool {
+	switch tuner {
+	case "disk_irq", "net", "cpu", "nic_channels", "irq_balance":
+		return false
+	}
+	return true
 }
 
 func IsTunerEnabled(tuner string, rpkConfig config.RpkConfig) bool {
@@ -160,6 +193,37 @@ func IsTunerEnabled(tuner string, rpkConfig config.RpkConfig) bool {
 		return rpkConfig.TuneCoredump
 	case "ballast_file":
 		return rpkConfig.TuneBallastFile
+	case "files_limit":
+		return rpkConfig.TuneFilesLimit
+	case "ethtool":
+		return rpkConfig.TuneEthtool
+	case "cgroup":
+		return rpkConfig.TuneCgroup
+	case "nic_channels":
+		return rpkConfig.TuneNicChannels
+	case "block_queue":
+		return rpkConfig.TuneBlockQueue
+	case "max_map_count":
+		return rpkConfig.TuneMaxMapCount
+	case "dirty_ratio":
+		return rpkConfig.TuneDirtyRatio
+	case "filesystem_check":
+		return rpkConfig.TuneFilesystemCheck
+	case "cstate":
+		// It's only enabled by a low latency profile (see
+		// TuningProfile), as it raises the power consumption.
+		return false
+	case "kernel_threads":
+		// It's only enabled by a profile, as it takes CPUs away from
+		// the kernel, and only makes sense along with --cpu-set.
+		return false
+	case "irq_balance":
+		// Likewise, as it needs --cpu-set, and restarts irqbalance.
+		return false
+	case "task_scheduler":
+		// It's only enabled by a profile, as it trades latency for
+		// throughput.
+		return false
 	}
 	return false
 }
@@ -167,7 +231,80 @@ func IsTunerEnabled(tuner string, rpkConfig config.RpkConfig) bool {
 func (factory *tunersFactory) CreateTuner(
 	tunerName string, tunerParams *TunerParams,
 ) tuners.Tunable {
-	return allTuners[tunerName](factory, tunerParams)
+	if runtime.GOOS != "linux" {
+		return &unsupportedTuner{
+			reason: fmt.Sprintf("Tuning is unsupported on %s", runtime.GOOS),
+		}
+	}
+	tuner := DefaultRegistry.factories[tunerName](factory, tunerParams)
+	if pkg, ok := tunersPackages[tunerName]; ok && tunerParams.InstallPackages {
+		tuner = tuners.NewPackageInstallingTuner(tuner, pkg, factory.executor)
+	}
+	return &resolvingTuner{Tunable: tuner, executor: factory.executor}
+}
+
+// A tuner executing the commands it held back, as what they depend on
+// wasn't executed, once it's tuned.
+type resolvingTuner struct {
+	tuners.Tunable
+	executor executors.DependencyResolvingExecutor
+}
+
+func (t *resolvingTuner) Tune() tuners.TuneResult {
+	res := t.Tunable.Tune()
+	// A tuner stops at its first failure, and so do the commands it held
+	// back.
+	if res.IsFailed() {
+		return res
+	}
+	err := t.executor.Flush()
+	if err != nil {
+		return tuners.NewTuneError(err)
+	}
+	return res
+}
+
+// The tuners rely on sysfs, procfs and other Linux-only interfaces, so
+// elsewhere they're all reported as unsupported instead of being created.
+type unsupportedTuner struct {
+	reason string
+}
+
+func (t *unsupportedTuner) CheckIfSupported() (bool, string) {
+	return false, t.reason
+}
+
+func (t *unsupportedTuner) Tune() tuners.TuneResult {
+	return tuners.NewTuneError(errors.New(t.reason))
+}
+
+func (t *unsupportedTuner) Check() ([]tuners.CheckResult, error) {
+	return nil, errors.New(t.reason)
+}
+
+// A tuner created the first time it's used, for those whose creation probes
+// the system, so that it's skipped when they're disabled.
+type lazyTuner struct {
+	create func() tuners.Tunable
+	once   sync.Once
+	tuner  tuners.Tunable
+}
+
+func (t *lazyTuner) get() tuners.Tunable {
+	t.once.Do(func() { t.tuner = t.create() })
+	return t.tuner
+}
+
+func (t *lazyTuner) CheckIfSupported() (bool, string) {
+	return t.get().CheckIfSupported()
+}
+
+func (t *lazyTuner) Tune() tuners.TuneResult {
+	return t.get().Tune()
+}
+
+func (t *lazyTuner) Check() ([]tuners.CheckResult, error) {
+	return tuners.CheckTunable(t.get())
 }
 
 func (factory *tunersFactory) newDiskIRQTuner(
@@ -214,6 +351,19 @@ func (factory *tunersFactory) newDiskNomergesTuner(
 	)
 }
 
+func (factory *tunersFactory) newBlockQueueTuner(
+	params *TunerParams,
+) tuners.Tunable {
+	return tuners.NewBlockQueueTuner(
+		factory.fs,
+		params.Directories,
+		params.Disks,
+		factory.blockDevices,
+		tuners.RecommendedQueueParameters,
+		factory.executor,
+	)
+}
+
 func (factory *tunersFactory) newGcpWriteCacheTuner(
 	params *TunerParams,
 ) tuners.Tunable {
@@ -277,11 +427,27 @@ func (factory *tunersFactory) newClockSourceTuner(
 func (factory *tunersFactory) newSwappinessTuner(
 	params *TunerParams,
 ) tuners.Tunable {
-	return tuners.NewSwappinessTuner(factory.fs, factory.executor)
+	return tuners.NewSwappinessTuner(
+		factory.fs,
+		params.Profile.swappiness(),
+		factory.executor,
+	)
+}
+
+func (factory *tunersFactory) newDirtyRatioTuner(
+	_ *TunerParams,
+) tuners.Tunable {
+	return tuners.NewDirtyRatioTuner(factory.fs, factory.executor)
 }
 
-func (factory *tunersFactory) newTHPTuner(_ *TunerParams) tuners.Tunable {
-	return tuners.NewEnableTHPTuner(factory.fs, factory.executor)
+func (factory *tunersFactory) newTHPTuner(
+	params *TunerParams,
+) tuners.Tunable {
+	return tuners.NewTransparentHugePagesTuner(
+		factory.fs,
+		factory.executor,
+		params.Profile.thpMode(),
+	)
 }
 
 func (factory *tunersFactory) newCoredumpTuner(
@@ -296,6 +462,212 @@ func (factory *tunersFactory) newBallastFileTuner(
 	return ballast.NewBallastFileTuner(factory.conf, factory.executor)
 }
 
+func (factory *tunersFactory) newFilesLimitTuner(
+	_ *TunerParams,
+) tuners.Tunable {
+	// Core dumps are left enabled for the coredump tuner, which sets up
+	// their collection.
+	return tuners.NewFilesLimitTuner(
+		factory.fs,
+		!factory.conf.Rpk.TuneCoredump,
+		factory.executor,
+	)
+}
+
+func (factory *tunersFactory) newEthtoolTuner(
+	params *TunerParams,
+) tuners.Tunable {
+	ethtool, err := ethtool.NewEthtoolWrapper()
+	if err != nil {
+		panic(err)
+	}
+	return tuners.NewEthtoolTuner(
+		tuners.PhysicalNics(
+			factory.fs,
+			factory.irqProcFile,
+			factory.irqDeviceInfo,
+			ethtool,
+			params.Nics,
+		),
+		params.Profile.ringSizes(),
+		factory.proc,
+		factory.timeout,
+		factory.executor,
+	)
+}
+
+func (factory *tunersFactory) newCgroupTuner(
+	params *TunerParams,
+) tuners.Tunable {
+	return tuners.NewCgroupTuner(
+		factory.fs,
+		params.CpuSet,
+		params.Directories,
+		params.Disks,
+		factory.blockDevices,
+		params.CgroupPid,
+		factory.conf.PIDFile(),
+		factory.executor,
+	)
+}
+
+func (factory *tunersFactory) newCStateTuner(
+	params *TunerParams,
+) tuners.Tunable {
+	return tuners.NewCStateTuner(
+		factory.fs,
+		params.Profile.maxCStateLatency(),
+		factory.executor,
+	)
+}
+
+func (factory *tunersFactory) newKernelThreadsTuner(
+	params *TunerParams,
+) tuners.Tunable {
+	return tuners.NewKernelThreadIsolationTuner(
+		factory.fs,
+		params.CpuSet,
+		factory.executor,
+	)
+}
+
+func (factory *tunersFactory) newIRQBalanceTuner(
+	params *TunerParams,
+) tuners.Tunable {
+	return tuners.NewIRQBalanceTuner(
+		params.CpuSet,
+		factory.irqBalanceService,
+		factory.executor,
+	)
+}
+
+func (factory *tunersFactory) newTaskSchedulerTuner(
+	_ *TunerParams,
+) tuners.Tunable {
+	return tuners.NewTaskSchedulerTuner(factory.fs, factory.executor)
+}
+
+func (factory *tunersFactory) newMaxMapCountTuner(
+	_ *TunerParams,
+) tuners.Tunable {
+	return tuners.NewMaxMapCountTuner(factory.fs, factory.executor)
+}
+
+// The NICs and the interfaces carrying the RPC connections are only looked
+// up once the tuner is used, as it takes resolving and dialing the RPC
+// address or the seed servers.
+func (factory *tunersFactory) newNicChannelsTuner(
+	params *TunerParams,
+) tuners.Tunable {
+	return &lazyTuner{create: func() tuners.Tunable {
+		return factory.discoverNicChannelsTuner(params)
+	}}
+}
+
+func (factory *tunersFactory) discoverNicChannelsTuner(
+	params *TunerParams,
+) tuners.Tunable {
+	ethtool, err := ethtool.NewEthtoolWrapper()
+	if err != nil {
+		panic(err)
+	}
+	topo, err := topology.Read(factory.fs)
+	if err != nil {
+		return &unsupportedTuner{
+			reason: fmt.Sprintf("Couldn't read the CPU topology: %v", err),
+		}
+	}
+	physicalNics := func(interfaces []string) []network.Nic {
+		return tuners.PhysicalNics(
+			factory.fs,
+			factory.irqProcFile,
+			factory.irqDeviceInfo,
+			ethtool,
+			interfaces,
+		)
+	}
+	rpcInterfaces, err := factory.rpcInterfaces()
+	if err != nil {
+		return &unsupportedTuner{
+			reason: fmt.Sprintf(
+				"Couldn't find the interface carrying the RPC connections,"+
+					" whose link mustn't be reset: %v",
+				err,
+			),
+		}
+	}
+	var excluded []string
+	for _, nic := range physicalNics(rpcInterfaces) {
+		excluded = append(excluded, nic.Name())
+	}
+	return tuners.NewNicChannelsTuner(
+		physicalNics(params.Nics),
+		topo.NumCores(nil),
+		excluded,
+		factory.proc,
+		factory.timeout,
+		factory.executor,
+	)
+}
+
+// Returns the interfaces carrying redpanda's RPC connections: the one the RPC
+// server is bound to or, if it's bound to every address, those routing to
+// the seed servers, or the default route's if there are none, e.g. on the
+// first node of a cluster.
+func (factory *tunersFactory) rpcInterfaces() ([]string, error) {
+	rpc := factory.conf.Redpanda.RPCServer
+	ip := net.ParseIP(rpc.Address)
+	var (
+		interfaces []string
+		err        error
+	)
+	switch {
+	case ip != nil && !ip.IsUnspecified():
+		interfaces, err = vnet.GetInterfacesWithIp(rpc.Address)
+	case ip == nil:
+		// A hostname, resolved to the address the server is bound to.
+		interfaces, err = vnet.GetInterfacesRoutingTo(
+			factory.timeout,
+			net.JoinHostPort(rpc.Address, strconv.Itoa(rpc.Port)),
+		)
+	case len(factory.conf.Redpanda.SeedServers) > 0:
+		var seeds []string
+		for _, seed := range factory.conf.Redpanda.SeedServers {
+			seeds = append(seeds, net.JoinHostPort(
+				seed.Host.Address,
+				strconv.Itoa(seed.Host.Port),
+			))
+		}
+		interfaces, err = vnet.GetInterfacesRoutingTo(factory.timeout, seeds...)
+	default:
+		interfaces, err = vnet.GetDefaultRouteInterfaces(factory.fs)
+	}
+	if err == nil && len(interfaces) == 0 {
+		err = fmt.Errorf("no interface carries the traffic to '%s'", rpc.Address)
+	}
+	return interfaces, err
+}
+
+func (factory *tunersFactory) newFilesystemCheckTuner(
+	params *TunerParams,
+) tuners.Tunable {
+	// The mounts are looked up by path, so the symlinks (e.g. to a volume)
+	// must be resolved. The directories which don't exist yet are kept as
+	// they are.
+	dirs := make([]string, 0, len(params.Directories))
+	for _, dir := range params.Directories {
+		resolved, err := utils.EvalSymlinks(factory.fs, dir)
+		if err != nil {
+			resolved = dir
+			if abs, err := filepath.Abs(dir); err == nil {
+				resolved = abs
+			}
+		}
+		dirs = append(dirs, resolved)
+	}
+	return tuners.NewFilesystemCheckTuner(factory.fs, dirs)
+}
+
 func MergeTunerParamsConfig(
 	params *TunerParams, conf *config.Config,
 ) (*TunerParams, error) {
@@ -304,7 +676,7 @@ func MergeTunerParamsConfig(
 		if len(conf.Redpanda.KafkaApi) > 0 {
 			addrs = append(addrs, conf.Redpanda.KafkaApi[0].Address)
 		}
-		nics, err := net.GetInterfacesByIps(
+		nics, err := vnet.GetInterfacesByIps(
 			addrs...,
 		)
 		if err != nil {
@@ -325,7 +697,7 @@ func FillTunerParamsWithValuesFromConfig(
 	if len(conf.Redpanda.KafkaApi) > 0 {
 		addrs = append(addrs, conf.Redpanda.KafkaApi[0].Address)
 	}
-	nics, err := net.GetInterfacesByIps(
+	nics, err := vnet.GetInterfacesByIps(
 		addrs...,
 	)
 	if err != nil {
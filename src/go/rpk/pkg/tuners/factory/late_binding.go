@@ -0,0 +1,94 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package factory
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/os"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/disk"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors/commands"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/irq"
+)
+
+// The environment variables the late-bound data device is resolved from.
+const (
+	DataDirVariable    = "DATA_DIR"
+	DataDeviceVariable = "DATA_DEVICE"
+)
+
+// Returns a context rendering the paths of the device dir is on (its node
+// under /dev and its sysfs directory) with the DATA_DEVICE variable, so that
+// a script rendered on a host can tune the data device of another one (e.g.
+// when it's run while building an image). The script's prologue sets
+// DATA_DEVICE, unless it's already set, to the disk holding DATA_DIR, which
+// defaults to dir. It fails if dir is on more than one device (e.g. on a
+// RAID array), as a single variable can't stand for all of them.
+func NewDataDeviceRenderContext(
+	fs afero.Fs, dir string, timeout time.Duration,
+) (*commands.RenderContext, error) {
+	irqProcFile := irq.NewProcFile(fs)
+	irqDeviceInfo := irq.NewDeviceInfo(fs, irqProcFile)
+	blockDevices := disk.NewBlockDevices(
+		fs,
+		irqDeviceInfo,
+		irqProcFile,
+		os.NewProc(),
+		timeout,
+	)
+	devices, err := blockDevices.GetDirectoryDevices(dir)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't find the device of '%s': %w", dir, err)
+	}
+	if len(devices) != 1 {
+		return nil, fmt.Errorf(
+			"the data device can only be late-bound if '%s' is on a single device, but it's on %v",
+			dir,
+			devices,
+		)
+	}
+	device := devices[0]
+	ctx := commands.NewRenderContext()
+	ctx.AddPrologue(dataDevicePrologue(dir)...)
+	node := filepath.Join("/dev", device)
+	ctx.Bind(node, fmt.Sprintf("/dev/${%s}", DataDeviceVariable))
+	sysBlock := fmt.Sprintf("/sys/block/${%s}", DataDeviceVariable)
+	ctx.Bind(filepath.Join("/sys/block", device), sysBlock)
+	// The tuners find the device's files through its path under
+	// /sys/devices, which depends on the host's hardware.
+	syspath, err := blockDevices.GetDeviceSystemPath(node)
+	if err == nil {
+		ctx.Bind(syspath, sysBlock)
+	}
+	return ctx, nil
+}
+
+// Returns the lines setting DATA_DEVICE to the name of the disk holding
+// DATA_DIR, i.e. the parent of its mount's source if it's a partition.
+func dataDevicePrologue(dir string) []string {
+	return []string{
+		fmt.Sprintf(`%s="${%s:-%s}"`, DataDirVariable, DataDirVariable, dir),
+		fmt.Sprintf(`if [ -z "${%s:-}" ]; then`, DataDeviceVariable),
+		fmt.Sprintf(`  data_source="$(findmnt -n -f -o SOURCE --target "$%s")"`, DataDirVariable),
+		`  # Bind mounts' sources are suffixed with the mounted directory.`,
+		`  data_source="${data_source%%\[*}"`,
+		fmt.Sprintf(`  %s="$(lsblk -n -o PKNAME "$data_source" 2>/dev/null | head -n 1)"`, DataDeviceVariable),
+		fmt.Sprintf(`  [ -n "$%s" ] || %s="$(basename "$data_source")"`, DataDeviceVariable, DataDeviceVariable),
+		`fi`,
+		fmt.Sprintf(
+			`[ -d "/sys/block/$%s" ] || { echo "Couldn't find the disk holding '$%s'" >&2; exit 1; }`,
+			DataDeviceVariable,
+			DataDirVariable,
+		),
+	}
+}
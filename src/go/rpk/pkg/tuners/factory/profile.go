@@ -0,0 +1,170 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package factory
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/spf13/afero"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/config"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners"
+	"gopkg.in/yaml.v2"
+)
+
+// The valid Transparent Huge Pages modes.
+var thpModes = []string{"always", "madvise", "never"}
+
+// TuningProfile overrides the values the tuners set by default, so that the
+// same rpk can tune different kinds of deployments (e.g. cloud VMs, bare
+// metal or dev machines) with a preset for each of them. The unset fields
+// keep the built-in defaults. A nil profile is valid, and changes nothing.
+//
+// A profile looks like:
+//
+//	swappiness: 10
+//	transparent_hugepages: never
+//	low_latency: true
+//	max_cstate_latency: 2
+//	ring_sizes:
+//	  rx: 4096
+//	tuners:
+//	  ballast_file: false
+//	  clocksource: true
+type TuningProfile struct {
+	// The swappiness set by the swappiness tuner, instead of
+	// tuners.ExpectedSwappiness.
+	Swappiness *int `yaml:"swappiness,omitempty"`
+	// The THP mode set by the transparent_hugepages tuner, instead of
+	// tuners.RecommendedTHPMode.
+	TransparentHugePages *string `yaml:"transparent_hugepages,omitempty"`
+	// The size the ethtool tuner sets each NIC ring buffer ('rx' or 'tx')
+	// to, instead of the maximum one. Sizes over the maximum are capped to
+	// it.
+	RingSizes map[string]int `yaml:"ring_sizes,omitempty"`
+	// Whether to tune for latency at the expense of power consumption. It
+	// enables the cstate tuner, unless Tuners disables it.
+	LowLatency bool `yaml:"low_latency,omitempty"`
+	// The exit latency, in microseconds, above which the cstate tuner
+	// disables the CPU idle states, instead of
+	// tuners.RecommendedMaxCStateLatency.
+	MaxCStateLatency *int `yaml:"max_cstate_latency,omitempty"`
+	// Whether each tuner is enabled, overriding the rpk config (see
+	// IsTunerEnabled).
+	Tuners map[string]bool `yaml:"tuners,omitempty"`
+}
+
+// Reads the profile at path. Unknown keys and values of the wrong type are
+// rejected, so that a typo fails instead of silently keeping the default.
+func LoadTuningProfile(fs afero.Fs, path string) (*TuningProfile, error) {
+	content, err := afero.ReadFile(fs, path)
+	if err != nil {
+		return nil, err
+	}
+	profile := &TuningProfile{}
+	err = yaml.UnmarshalStrict(content, profile)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tuning profile '%s': %w", path, err)
+	}
+	err = profile.validate()
+	if err != nil {
+		return nil, fmt.Errorf("invalid tuning profile '%s': %w", path, err)
+	}
+	return profile, nil
+}
+
+func (p *TuningProfile) validate() error {
+	if p.Swappiness != nil && (*p.Swappiness < 0 || *p.Swappiness > 200) {
+		return fmt.Errorf("swappiness must be between 0 and 200, got %d", *p.Swappiness)
+	}
+	if p.TransparentHugePages != nil && !contains(thpModes, *p.TransparentHugePages) {
+		return fmt.Errorf(
+			"transparent_hugepages must be one of %v, got '%s'",
+			thpModes,
+			*p.TransparentHugePages,
+		)
+	}
+	if p.MaxCStateLatency != nil && *p.MaxCStateLatency < 0 {
+		return fmt.Errorf("max_cstate_latency can't be negative, got %d", *p.MaxCStateLatency)
+	}
+	for ring, size := range p.RingSizes {
+		if !contains(tuners.RecommendedRingSizesToMax, ring) {
+			return fmt.Errorf(
+				"unknown ring '%s', the ring sizes can be set for %v",
+				ring,
+				tuners.RecommendedRingSizesToMax,
+			)
+		}
+		if size <= 0 {
+			return fmt.Errorf("the %s ring size must be positive, got %d", ring, size)
+		}
+	}
+	names := make([]string, 0, len(p.Tuners))
+	for name := range p.Tuners {
+		names = append(names, name)
+	}
+	// Sorted, so that the same tuner is reported for an invalid profile.
+	sort.Strings(names)
+	_, err := DefaultRegistry.Enabled(names...)
+	return err
+}
+
+// Returns whether tuner is enabled in the profile or, if the profile doesn't
+// say, in the rpk config.
+func (p *TuningProfile) IsTunerEnabled(
+	tuner string, rpkConfig config.RpkConfig,
+) bool {
+	if p != nil {
+		if enabled, ok := p.Tuners[tuner]; ok {
+			return enabled
+		}
+		if tuner == "cstate" && p.LowLatency {
+			return true
+		}
+	}
+	return IsTunerEnabled(tuner, rpkConfig)
+}
+
+func (p *TuningProfile) swappiness() int {
+	if p == nil || p.Swappiness == nil {
+		return tuners.ExpectedSwappiness
+	}
+	return *p.Swappiness
+}
+
+func (p *TuningProfile) thpMode() string {
+	if p == nil || p.TransparentHugePages == nil {
+		return tuners.RecommendedTHPMode
+	}
+	return *p.TransparentHugePages
+}
+
+func (p *TuningProfile) maxCStateLatency() int {
+	if p == nil || p.MaxCStateLatency == nil {
+		return tuners.RecommendedMaxCStateLatency
+	}
+	return *p.MaxCStateLatency
+}
+
+func (p *TuningProfile) ringSizes() map[string]int {
+	if p == nil {
+		return nil
+	}
+	return p.RingSizes
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
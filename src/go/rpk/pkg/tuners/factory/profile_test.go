@@ -0,0 +1,119 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package factory_test
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/config"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/factory"
+)
+
+func TestLoadTuningProfile(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		expected    *factory.TuningProfile
+		expectedErr string
+	}{
+		{
+			name: "it should load all the fields",
+			content: `swappiness: 10
+transparent_hugepages: never
+ring_sizes:
+  rx: 1024
+tuners:
+  ballast_file: false
+`,
+			expected: &factory.TuningProfile{
+				Swappiness:           intPtr(10),
+				TransparentHugePages: strPtr("never"),
+				RingSizes:            map[string]int{"rx": 1024},
+				Tuners:               map[string]bool{"ballast_file": false},
+			},
+		},
+		{
+			name:     "it should leave the missing fields unset",
+			content:  "swappiness: 0\n",
+			expected: &factory.TuningProfile{Swappiness: intPtr(0)},
+		},
+		{
+			name:        "it should reject unknown keys",
+			content:     "swapiness: 10\n",
+			expectedErr: "field swapiness not found",
+		},
+		{
+			name:        "it should reject values of the wrong type",
+			content:     "swappiness: low\n",
+			expectedErr: "cannot unmarshal !!str `low` into int",
+		},
+		{
+			name:        "it should reject invalid THP modes",
+			content:     "transparent_hugepages: sometimes\n",
+			expectedErr: "transparent_hugepages must be one of [always madvise never], got 'sometimes'",
+		},
+		{
+			name:        "it should reject unknown rings",
+			content:     "ring_sizes:\n  rxx: 1024\n",
+			expectedErr: "unknown ring 'rxx', the ring sizes can be set for [rx tx]",
+		},
+		{
+			name:        "it should reject unknown tuners",
+			content:     "tuners:\n  fstrm: true\n",
+			expectedErr: "unknown tuner: fstrm (did you mean fstrim?)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			fs := afero.NewMemMapFs()
+			path := "/etc/redpanda/profile.yaml"
+			require.NoError(st, afero.WriteFile(fs, path, []byte(tt.content), 0644))
+			profile, err := factory.LoadTuningProfile(fs, path)
+			if tt.expectedErr != "" {
+				require.Error(st, err)
+				require.Contains(st, err.Error(), tt.expectedErr)
+				return
+			}
+			require.NoError(st, err)
+			require.Equal(st, tt.expected, profile)
+		})
+	}
+}
+
+func TestTuningProfileIsTunerEnabled(t *testing.T) {
+	rpkConfig := config.RpkConfig{TuneFstrim: true, TuneClocksource: false}
+	profile := &factory.TuningProfile{
+		Tuners: map[string]bool{"fstrim": false, "clocksource": true},
+	}
+	require.False(t, profile.IsTunerEnabled("fstrim", rpkConfig))
+	require.True(t, profile.IsTunerEnabled("clocksource", rpkConfig))
+
+	// The cstate tuner is only enabled by a low latency profile.
+	require.False(t, profile.IsTunerEnabled("cstate", rpkConfig))
+	profile.LowLatency = true
+	require.True(t, profile.IsTunerEnabled("cstate", rpkConfig))
+	profile.Tuners["cstate"] = false
+	require.False(t, profile.IsTunerEnabled("cstate", rpkConfig))
+
+	// Without a profile, the config is used.
+	var none *factory.TuningProfile
+	require.True(t, none.IsTunerEnabled("fstrim", rpkConfig))
+	require.False(t, none.IsTunerEnabled("clocksource", rpkConfig))
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func strPtr(s string) *string {
+	return &s
+}
@@ -0,0 +1,174 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package factory
+
+import (
+	"fmt"
+
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners"
+)
+
+// Creates a tuner out of the components shared by all of them (e.g. the
+// executor) and the user's params.
+type TunerFactory func(*tunersFactory, *TunerParams) tuners.Tunable
+
+// TunerRegistry holds the tuners rpk knows about, by name, in the order they
+// were registered.
+type TunerRegistry struct {
+	names     []string
+	factories map[string]TunerFactory
+}
+
+func NewTunerRegistry() *TunerRegistry {
+	return &TunerRegistry{factories: map[string]TunerFactory{}}
+}
+
+// The registry the tuners run by 'rpk redpanda tune' are looked up in.
+var DefaultRegistry = NewTunerRegistry()
+
+func init() {
+	DefaultRegistry.Register("disk_irq", (*tunersFactory).newDiskIRQTuner)
+	DefaultRegistry.Register("disk_scheduler", (*tunersFactory).newDiskSchedulerTuner)
+	DefaultRegistry.Register("disk_nomerges", (*tunersFactory).newDiskNomergesTuner)
+	DefaultRegistry.Register("disk_write_cache", (*tunersFactory).newGcpWriteCacheTuner)
+	DefaultRegistry.Register("fstrim", (*tunersFactory).newFstrimTuner)
+	DefaultRegistry.Register("net", (*tunersFactory).newNetworkTuner)
+	DefaultRegistry.Register("cpu", (*tunersFactory).newCpuTuner)
+	DefaultRegistry.Register("aio_events", (*tunersFactory).newMaxAIOEventsTuner)
+	DefaultRegistry.Register("clocksource", (*tunersFactory).newClockSourceTuner)
+	DefaultRegistry.Register("swappiness", (*tunersFactory).newSwappinessTuner)
+	DefaultRegistry.Register("transparent_hugepages", (*tunersFactory).newTHPTuner)
+	DefaultRegistry.Register("coredump", (*tunersFactory).newCoredumpTuner)
+	DefaultRegistry.Register("ballast_file", (*tunersFactory).newBallastFileTuner)
+	DefaultRegistry.Register("files_limit", (*tunersFactory).newFilesLimitTuner)
+	DefaultRegistry.Register("ethtool", (*tunersFactory).newEthtoolTuner)
+	DefaultRegistry.Register("cgroup", (*tunersFactory).newCgroupTuner)
+	DefaultRegistry.Register("cstate", (*tunersFactory).newCStateTuner)
+	DefaultRegistry.Register("nic_channels", (*tunersFactory).newNicChannelsTuner)
+	DefaultRegistry.Register("filesystem_check", (*tunersFactory).newFilesystemCheckTuner)
+	DefaultRegistry.Register("block_queue", (*tunersFactory).newBlockQueueTuner)
+	DefaultRegistry.Register("kernel_threads", (*tunersFactory).newKernelThreadsTuner)
+	DefaultRegistry.Register("irq_balance", (*tunersFactory).newIRQBalanceTuner)
+	DefaultRegistry.Register("task_scheduler", (*tunersFactory).newTaskSchedulerTuner)
+	DefaultRegistry.Register("max_map_count", (*tunersFactory).newMaxMapCountTuner)
+	DefaultRegistry.Register("dirty_ratio", (*tunersFactory).newDirtyRatioTuner)
+}
+
+// Adds a tuner to the registry. It panics if a tuner with the same name was
+// already registered, as the registrations are hardcoded.
+func (r *TunerRegistry) Register(name string, factory TunerFactory) {
+	if _, exists := r.factories[name]; exists {
+		panic(fmt.Sprintf("the '%s' tuner is already registered", name))
+	}
+	r.names = append(r.names, name)
+	r.factories[name] = factory
+}
+
+// Returns the names of the registered tuners, in the order they were
+// registered.
+func (r *TunerRegistry) Names() []string {
+	return append([]string{}, r.names...)
+}
+
+func (r *TunerRegistry) Has(name string) bool {
+	_, exists := r.factories[name]
+	return exists
+}
+
+// Returns the given tuners, in the given order. It fails if any of them
+// isn't registered.
+func (r *TunerRegistry) Enabled(names ...string) ([]string, error) {
+	err := r.validate(names)
+	if err != nil {
+		return nil, err
+	}
+	return append([]string{}, names...), nil
+}
+
+// Returns the registered tuners, except for the given ones. It fails if any
+// of them isn't registered.
+func (r *TunerRegistry) Disabled(names ...string) ([]string, error) {
+	err := r.validate(names)
+	if err != nil {
+		return nil, err
+	}
+	disabled := map[string]bool{}
+	for _, name := range names {
+		disabled[name] = true
+	}
+	var enabled []string
+	for _, name := range r.names {
+		if !disabled[name] {
+			enabled = append(enabled, name)
+		}
+	}
+	return enabled, nil
+}
+
+func (r *TunerRegistry) validate(names []string) error {
+	for _, name := range names {
+		if r.Has(name) {
+			continue
+		}
+		if suggestion := r.closest(name); suggestion != "" {
+			return fmt.Errorf("unknown tuner: %s (did you mean %s?)", name, suggestion)
+		}
+		return fmt.Errorf("unknown tuner: %s", name)
+	}
+	return nil
+}
+
+// Returns the registered tuner whose name is the closest to name, if it's
+// close enough to be a likely typo.
+func (r *TunerRegistry) closest(name string) string {
+	maxDistance := len(name) / 3
+	if maxDistance < 2 {
+		maxDistance = 2
+	}
+	closest, closestDistance := "", maxDistance+1
+	for _, candidate := range r.names {
+		distance := editDistance(name, candidate)
+		if distance < closestDistance {
+			closest, closestDistance = candidate, distance
+		}
+	}
+	return closest
+}
+
+// Returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+func min(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
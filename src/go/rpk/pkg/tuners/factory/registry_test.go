@@ -0,0 +1,76 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package factory_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/factory"
+)
+
+func TestTunerRegistryEnabled(t *testing.T) {
+	tests := []struct {
+		name        string
+		names       []string
+		expected    []string
+		expectedErr string
+	}{
+		{
+			name:     "it should return the tuners in the given order",
+			names:    []string{"fstrim", "disk_irq"},
+			expected: []string{"fstrim", "disk_irq"},
+		},
+		{
+			name:        "it should suggest the closest tuner",
+			names:       []string{"fstrim", "clocksourc"},
+			expectedErr: "unknown tuner: clocksourc (did you mean clocksource?)",
+		},
+		{
+			name:        "it should fail without a suggestion if none is close",
+			names:       []string{"kernel"},
+			expectedErr: "unknown tuner: kernel",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			enabled, err := factory.DefaultRegistry.Enabled(tt.names...)
+			if tt.expectedErr != "" {
+				require.EqualError(st, err, tt.expectedErr)
+				return
+			}
+			require.NoError(st, err)
+			require.Equal(st, tt.expected, enabled)
+		})
+	}
+}
+
+func TestTunerRegistryDisabled(t *testing.T) {
+	all := factory.DefaultRegistry.Names()
+	enabled, err := factory.DefaultRegistry.Disabled("disk_irq", "clocksource")
+	require.NoError(t, err)
+	require.Len(t, enabled, len(all)-2)
+	require.NotContains(t, enabled, "disk_irq")
+	require.NotContains(t, enabled, "clocksource")
+	require.Contains(t, enabled, "fstrim")
+
+	_, err = factory.DefaultRegistry.Disabled("disk_iqr")
+	require.EqualError(t, err, "unknown tuner: disk_iqr (did you mean disk_irq?)")
+}
+
+func TestTunerRegistryRegister(t *testing.T) {
+	registry := factory.NewTunerRegistry()
+	registry.Register("a", nil)
+	registry.Register("b", nil)
+	require.Equal(t, []string{"a", "b"}, registry.Names())
+	require.True(t, registry.Has("a"))
+	require.False(t, registry.Has("c"))
+	require.Panics(t, func() { registry.Register("a", nil) })
+}
@@ -0,0 +1,166 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"runtime"
+	"syscall"
+
+	"github.com/spf13/afero"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors/commands"
+)
+
+// The minimum open files limit recommended for redpanda, which keeps a file
+// descriptor open per segment and per connection. It's the default value of
+// fs.nr_open, the highest limit the kernel allows.
+const RecommendedFilesLimit uint64 = 1048576
+
+func getFilesLimit() (syscall.Rlimit, error) {
+	return getRlimit(syscall.RLIMIT_NOFILE)
+}
+
+func getRlimit(resource int) (syscall.Rlimit, error) {
+	var limit syscall.Rlimit
+	err := syscall.Getrlimit(resource, &limit)
+	return limit, err
+}
+
+// Returns the soft limit of resource, capped so that it fits in an int.
+func readSoftRlimit(resource int) (int, error) {
+	limit, err := getRlimit(resource)
+	if err != nil {
+		return 0, err
+	}
+	// RLIM_INFINITY doesn't fit in an int.
+	if limit.Cur > math.MaxInt32 {
+		return math.MaxInt32, nil
+	}
+	return int(limit.Cur), nil
+}
+
+func NewFilesLimitChecker() Checker {
+	return NewIntChecker(
+		FilesLimitChecker,
+		"Open files limit",
+		Warning,
+		func(current int) bool {
+			return uint64(current) >= RecommendedFilesLimit
+		},
+		func() string {
+			return fmt.Sprintf(">= %d", RecommendedFilesLimit)
+		},
+		func() (int, error) {
+			return readSoftRlimit(syscall.RLIMIT_NOFILE)
+		},
+	)
+}
+
+func NewCoreLimitChecker() Checker {
+	return NewIntChecker(
+		CoreLimitChecker,
+		"Core dumps limit",
+		Warning,
+		func(current int) bool {
+			return current == 0
+		},
+		func() string {
+			return "0"
+		},
+		func() (int, error) {
+			return readSoftRlimit(syscall.RLIMIT_CORE)
+		},
+	)
+}
+
+// Creates a tuner raising the open files limit (RLIMIT_NOFILE) to
+// RecommendedFilesLimit and, if disableCoreDumps is set, disabling core dumps
+// by setting the soft RLIMIT_CORE to 0. Limits which are already higher
+// aren't lowered. The limits are set for rpk's process, and the processes it
+// starts, and persisted in /etc/security/limits.d/ for new sessions (see
+// commands.NewPersistentRlimitCmd).
+func NewFilesLimitTuner(
+	fs afero.Fs, disableCoreDumps bool, executor executors.Executor,
+) Tunable {
+	tunables := []Tunable{newFilesLimitTunable(fs, executor)}
+	if disableCoreDumps {
+		tunables = append(tunables, newCoreLimitTunable(fs, executor))
+	}
+	return NewAggregatedTunable(tunables)
+}
+
+func newFilesLimitTunable(fs afero.Fs, executor executors.Executor) Tunable {
+	return NewCheckedTunable(
+		NewFilesLimitChecker(),
+		func() TuneResult {
+			limit, err := getFilesLimit()
+			if err != nil {
+				return NewTuneError(err)
+			}
+			soft := limit.Cur
+			if soft < RecommendedFilesLimit {
+				soft = RecommendedFilesLimit
+			}
+			hard := limit.Max
+			if hard < soft {
+				hard = soft
+			}
+			err = executor.Execute(commands.NewPersistentRlimitCmd(
+				fs, syscall.RLIMIT_NOFILE, soft, hard,
+			))
+			if errors.Is(err, syscall.EPERM) {
+				return NewTuneError(fmt.Errorf(
+					"raising the hard open files limit from %d to %d needs root",
+					limit.Max,
+					hard,
+				))
+			}
+			if err != nil {
+				return NewTuneError(err)
+			}
+			return NewTuneResult(false)
+		},
+		rlimitsSupported,
+		executor.IsLazy(),
+	)
+}
+
+func newCoreLimitTunable(fs afero.Fs, executor executors.Executor) Tunable {
+	return NewCheckedTunable(
+		NewCoreLimitChecker(),
+		func() TuneResult {
+			limit, err := getRlimit(syscall.RLIMIT_CORE)
+			if err != nil {
+				return NewTuneError(err)
+			}
+			// The hard limit is kept, so that core dumps can still be
+			// enabled to debug a crash, e.g. with 'ulimit -c unlimited'.
+			err = executor.Execute(commands.NewPersistentRlimitCmd(
+				fs, syscall.RLIMIT_CORE, 0, limit.Max,
+			))
+			if err != nil {
+				return NewTuneError(err)
+			}
+			return NewTuneResult(false)
+		},
+		rlimitsSupported,
+		executor.IsLazy(),
+	)
+}
+
+func rlimitsSupported() (bool, string) {
+	if runtime.GOOS != "linux" {
+		return false, "Setting resource limits is only supported on Linux"
+	}
+	return true, ""
+}
@@ -0,0 +1,114 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners_test
+
+import (
+	"fmt"
+	"syscall"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors/commands"
+)
+
+type failingExecutor struct {
+	err error
+}
+
+func (e *failingExecutor) Execute(cmd commands.Command) error {
+	return fmt.Errorf("couldn't run '%s': %w", cmd.Describe().Desc, e.err)
+}
+
+func (*failingExecutor) IsLazy() bool {
+	return false
+}
+
+// Sets the process' soft open files limit for the duration of the test.
+func setSoftFilesLimit(t *testing.T, soft uint64) syscall.Rlimit {
+	var limit syscall.Rlimit
+	require.NoError(t, syscall.Getrlimit(syscall.RLIMIT_NOFILE, &limit))
+	require.NoError(t, syscall.Setrlimit(
+		syscall.RLIMIT_NOFILE,
+		&syscall.Rlimit{Cur: soft, Max: limit.Max},
+	))
+	t.Cleanup(func() {
+		require.NoError(t, syscall.Setrlimit(syscall.RLIMIT_NOFILE, &limit))
+	})
+	return limit
+}
+
+func TestFilesLimitTunerRender(t *testing.T) {
+	limit := setSoftFilesLimit(t, 1024)
+	hard := limit.Max
+	if hard < tuners.RecommendedFilesLimit {
+		hard = tuners.RecommendedFilesLimit
+	}
+	fs := afero.NewMemMapFs()
+	const scriptPath = "/tune.sh"
+	tuner := tuners.NewFilesLimitTuner(
+		fs,
+		false,
+		executors.NewScriptRenderingExecutor(fs, scriptPath),
+	)
+	res := tuner.Tune()
+	require.NoError(t, res.Error())
+
+	script, err := afero.ReadFile(fs, scriptPath)
+	require.NoError(t, err)
+	require.Contains(t, string(script), fmt.Sprintf("ulimit -Hn %d\n", hard))
+	require.Contains(t, string(script), "ulimit -Sn 1048576\n")
+	require.Contains(t, string(script), "* soft nofile 1048576\n")
+	require.Contains(t, string(script), "' > "+commands.LimitsFile(syscall.RLIMIT_NOFILE)+"\n")
+	require.NotContains(t, string(script), "core")
+}
+
+func TestFilesLimitTunerCoreDumps(t *testing.T) {
+	var limit syscall.Rlimit
+	require.NoError(t, syscall.Getrlimit(syscall.RLIMIT_CORE, &limit))
+	if limit.Max == 0 {
+		t.Skip("core dumps can't be enabled for the test")
+	}
+	require.NoError(t, syscall.Setrlimit(
+		syscall.RLIMIT_CORE,
+		&syscall.Rlimit{Cur: limit.Max, Max: limit.Max},
+	))
+	t.Cleanup(func() {
+		require.NoError(t, syscall.Setrlimit(syscall.RLIMIT_CORE, &limit))
+	})
+	fs := afero.NewMemMapFs()
+	const scriptPath = "/tune.sh"
+	tuner := tuners.NewFilesLimitTuner(
+		fs,
+		true,
+		executors.NewScriptRenderingExecutor(fs, scriptPath),
+	)
+	res := tuner.Tune()
+	require.NoError(t, res.Error())
+
+	script, err := afero.ReadFile(fs, scriptPath)
+	require.NoError(t, err)
+	require.Contains(t, string(script), "ulimit -Sc 0\n")
+	require.Contains(t, string(script), "* soft core 0\n")
+}
+
+func TestFilesLimitTunerNeedsRoot(t *testing.T) {
+	setSoftFilesLimit(t, 1024)
+	tuner := tuners.NewFilesLimitTuner(
+		afero.NewMemMapFs(),
+		false,
+		&failingExecutor{err: syscall.EPERM},
+	)
+	res := tuner.Tune()
+	require.Error(t, res.Error())
+	require.Contains(t, res.Error().Error(), "needs root")
+}
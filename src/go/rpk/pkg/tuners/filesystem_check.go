@@ -0,0 +1,205 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/system/filesystem"
+)
+
+// The filesystems redpanda's data directory is supported on, the first one
+// being the recommended one.
+var SupportedFilesystems = []string{"xfs", "ext4"}
+
+// The mount options risking the data, along with why.
+var riskyMountOptions = map[string]string{
+	"nobarrier": "the write barriers are disabled, so a power loss may corrupt the data",
+	"barrier=0": "the write barriers are disabled, so a power loss may corrupt the data",
+}
+
+// How many overlays and bind mounts are followed to find the mount really
+// holding a directory.
+const maxMountIndirections = 8
+
+type filesystemCheckTuner struct {
+	fs          afero.Fs
+	directories []string
+}
+
+// Creates a tuner checking that the given directories, whose symlinks must
+// be resolved, are on a supported filesystem (see SupportedFilesystems),
+// mounted without risky options (e.g. 'nobarrier'). Bind mounts and overlays
+// are resolved to the mount holding their files, when it's visible in the
+// mount namespace. It changes nothing: tuning only warns about the failed
+// checks.
+func NewFilesystemCheckTuner(fs afero.Fs, directories []string) Tunable {
+	return &filesystemCheckTuner{fs: fs, directories: directories}
+}
+
+func (t *filesystemCheckTuner) CheckIfSupported() (supported bool, reason string) {
+	if _, err := t.fs.Stat(filesystem.MountInfoFile); err != nil {
+		return false, fmt.Sprintf("Couldn't read the mounts: %v", err)
+	}
+	return true, ""
+}
+
+func (t *filesystemCheckTuner) Tune() TuneResult {
+	results, warnings, err := t.inspect()
+	if err != nil {
+		return NewTuneError(err)
+	}
+	for i, res := range results {
+		if !res.IsOk {
+			log.Warnf("%s: %s", res.Desc, warnings[i])
+		}
+	}
+	return NewUnchangedTuneResult()
+}
+
+func (t *filesystemCheckTuner) Check() ([]CheckResult, error) {
+	results, _, err := t.inspect()
+	return results, err
+}
+
+// Returns the check results, along with the advice to give for each of them
+// if it failed.
+func (t *filesystemCheckTuner) inspect() ([]CheckResult, []string, error) {
+	mounts, err := filesystem.ReadMounts(t.fs)
+	if err != nil {
+		return nil, nil, err
+	}
+	var results []CheckResult
+	var warnings []string
+	for _, dir := range t.directories {
+		mount := filesystem.FindMount(mounts, dir)
+		if mount == nil {
+			return nil, nil, fmt.Errorf("couldn't find the mount holding '%s'", dir)
+		}
+		backing, via := backingMount(t.fs, mounts, mount)
+		current := backing.FsType
+		if len(via) > 0 {
+			current = fmt.Sprintf("%s (through %s)", current, strings.Join(via, ", "))
+		}
+		results = append(results, CheckResult{
+			CheckerId: DataDirFilesystemChecker,
+			IsOk:      contains(SupportedFilesystems, backing.FsType),
+			Desc:      fmt.Sprintf("Data directory '%s' filesystem", dir),
+			Severity:  Warning,
+			Current:   current,
+			Required:  strings.Join(SupportedFilesystems, " or "),
+		})
+		warnings = append(warnings, fmt.Sprintf(
+			"%s isn't supported, move the data directory to an %s (recommended) or %s filesystem",
+			backing.FsType,
+			SupportedFilesystems[0],
+			strings.Join(SupportedFilesystems[1:], ", "),
+		))
+
+		risky, reasons := riskyOptions(backing)
+		res := CheckResult{
+			CheckerId: DataDirMountOptionsChecker,
+			IsOk:      len(risky) == 0,
+			Desc:      fmt.Sprintf("Data directory '%s' mount options", dir),
+			Severity:  Warning,
+			Current:   strings.Join(risky, ","),
+			Required:  "no " + strings.Join(sortedKeys(riskyMountOptions), ", no "),
+		}
+		if res.IsOk {
+			res.Current = res.Required
+		}
+		results = append(results, res)
+		warnings = append(warnings, fmt.Sprintf(
+			"%s, remount %s without %s",
+			strings.Join(reasons, "; "),
+			backing.MountPoint,
+			strings.Join(risky, ", "),
+		))
+	}
+	return results, warnings, nil
+}
+
+// Returns the mount holding the files of mount, following its overlay's
+// upper directory (where the writes go) and its bind mount source, along
+// with a description of each mount that was followed.
+func backingMount(
+	fs afero.Fs, mounts []filesystem.Mount, mount *filesystem.Mount,
+) (*filesystem.Mount, []string) {
+	var via []string
+	for i := 0; i < maxMountIndirections; i++ {
+		if mount.FsType == "overlay" {
+			upper, ok := mount.SuperOption("upperdir")
+			if !ok {
+				break
+			}
+			// In a container, the upper directory is in the host's
+			// mount namespace, so it can't be followed.
+			if _, err := fs.Stat(upper); err != nil {
+				break
+			}
+			next := filesystem.FindMount(mounts, upper)
+			if next == nil || next == mount {
+				break
+			}
+			via = append(via, fmt.Sprintf("an overlay on %s", mount.MountPoint))
+			mount = next
+			continue
+		}
+		if mount.Root != "/" {
+			source := filesystem.FindBindSource(mounts, mount)
+			if source == nil {
+				break
+			}
+			via = append(via, fmt.Sprintf("a bind mount on %s", mount.MountPoint))
+			mount = source
+			continue
+		}
+		break
+	}
+	return mount, via
+}
+
+// Returns the risky options mount is mounted with, sorted, along with why
+// each of them is.
+func riskyOptions(mount *filesystem.Mount) (options, reasons []string) {
+	set := map[string]bool{}
+	for _, opt := range append(append([]string{}, mount.Options...), mount.SuperOptions...) {
+		set[opt] = true
+	}
+	for _, opt := range sortedKeys(riskyMountOptions) {
+		if set[opt] {
+			options = append(options, opt)
+			reasons = append(reasons, fmt.Sprintf("'%s': %s", opt, riskyMountOptions[opt]))
+		}
+	}
+	return options, reasons
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
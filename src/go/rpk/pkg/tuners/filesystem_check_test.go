@@ -0,0 +1,97 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners_test
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/system/filesystem"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners"
+)
+
+func TestFilesystemCheckTuner(t *testing.T) {
+	const mountInfo = `22 1 8:1 / / rw,relatime shared:1 - ext4 /dev/sda1 rw
+23 22 8:2 / /mnt/data rw,noatime shared:2 - xfs /dev/sdb1 rw,nobarrier
+24 22 8:2 /redpanda /var/lib/redpanda rw,noatime shared:2 - xfs /dev/sdb1 rw,nobarrier
+25 22 0:40 / /merged rw,relatime - overlay overlay rw,lowerdir=/l,upperdir=/srv/upper,workdir=/srv/work
+26 22 0:41 / /srv rw - xfs /dev/sdc1 rw
+27 22 0:42 / /container rw,relatime - overlay overlay rw,lowerdir=/l,upperdir=/var/lib/docker/upper,workdir=/w
+28 22 0:43 / /tmp rw - tmpfs tmpfs rw
+`
+	tests := []struct {
+		name             string
+		dir              string
+		expectedOk       []bool
+		expectedCurrent  []string
+		expectedRequired []string
+	}{
+		{
+			name:             "it should pass on ext4",
+			dir:              "/var/lib/data",
+			expectedOk:       []bool{true, true},
+			expectedCurrent:  []string{"ext4", "no barrier=0, no nobarrier"},
+			expectedRequired: []string{"xfs or ext4", "no barrier=0, no nobarrier"},
+		},
+		{
+			name:             "it should report the risky options of the bind mount source",
+			dir:              "/var/lib/redpanda/data",
+			expectedOk:       []bool{true, false},
+			expectedCurrent:  []string{"xfs (through a bind mount on /var/lib/redpanda)", "nobarrier"},
+			expectedRequired: []string{"xfs or ext4", "no barrier=0, no nobarrier"},
+		},
+		{
+			name:             "it should follow the overlay's upper directory",
+			dir:              "/merged/data",
+			expectedOk:       []bool{true, true},
+			expectedCurrent:  []string{"xfs (through an overlay on /merged)", "no barrier=0, no nobarrier"},
+			expectedRequired: []string{"xfs or ext4", "no barrier=0, no nobarrier"},
+		},
+		{
+			name:             "it should fail on an overlay whose upper directory isn't visible",
+			dir:              "/container/data",
+			expectedOk:       []bool{false, true},
+			expectedCurrent:  []string{"overlay", "no barrier=0, no nobarrier"},
+			expectedRequired: []string{"xfs or ext4", "no barrier=0, no nobarrier"},
+		},
+		{
+			name:             "it should fail on tmpfs",
+			dir:              "/tmp/data",
+			expectedOk:       []bool{false, true},
+			expectedCurrent:  []string{"tmpfs", "no barrier=0, no nobarrier"},
+			expectedRequired: []string{"xfs or ext4", "no barrier=0, no nobarrier"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			fs := afero.NewMemMapFs()
+			require.NoError(st, afero.WriteFile(fs, filesystem.MountInfoFile, []byte(mountInfo), 0444))
+			require.NoError(st, fs.MkdirAll("/srv/upper", 0755))
+			tuner := tuners.NewFilesystemCheckTuner(fs, []string{tt.dir})
+			supported, _ := tuner.CheckIfSupported()
+			require.True(st, supported)
+
+			results, err := tuners.CheckTunable(tuner)
+			require.NoError(st, err)
+			require.Len(st, results, 2)
+			for i, res := range results {
+				require.Equal(st, tt.expectedOk[i], res.IsOk, res.Desc)
+				require.Equal(st, tt.expectedCurrent[i], res.Current)
+				require.Equal(st, tt.expectedRequired[i], res.Required)
+			}
+
+			// Nothing is changed, even if the checks fail.
+			res := tuner.Tune()
+			require.NoError(st, res.Error())
+			require.False(st, res.IsChanged())
+		})
+	}
+}
@@ -77,6 +77,10 @@ func (t *fstrimTuner) Tune() TuneResult {
 	return tuneFstrim(t.fs, t.executor, c, os.NewProc())
 }
 
+func (t *fstrimTuner) Check() ([]CheckResult, error) {
+	return []CheckResult{*NewFstrimChecker().Check()}, nil
+}
+
 func tuneFstrim(
 	fs afero.Fs, exe executors.Executor, c systemd.Client, proc os.Proc,
 ) TuneResult {
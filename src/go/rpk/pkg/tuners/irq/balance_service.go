@@ -31,9 +31,24 @@ type balanceServiceInfo struct {
 	systemd    bool
 }
 
+// The irqbalance setting holding the CPUs it leaves alone, as a CPU mask, and
+// the one superseding it in newer versions, as a CPU list.
+const (
+	bannedCpusKey    = "IRQBALANCE_BANNED_CPUS"
+	bannedCpuListKey = "IRQBALANCE_BANNED_CPULIST"
+)
+
 type BalanceService interface {
 	BanIRQsAndRestart(bannedIRQs []int) error
 	GetBannedIRQs() ([]int, error)
+	// Sets IRQBALANCE_BANNED_CPUS in irqbalance's config to mask, in the
+	// kernel's bitmap format (e.g. 'ff,00000000'), and restarts it so
+	// that it leaves those CPUs' IRQs alone. Reverting it restores the
+	// config and restarts irqbalance again.
+	BanCPUsAndRestart(mask string) error
+	// Returns the mask IRQBALANCE_BANNED_CPUS is set to, or "" if it
+	// isn't.
+	GetBannedCPUs() (string, error)
 	IsRunning() bool
 }
 
@@ -137,6 +152,95 @@ func (balanceService *balanceService) BanIRQsAndRestart(
 	return nil
 }
 
+func (balanceService *balanceService) BanCPUsAndRestart(mask string) error {
+	serviceInfo, err := balanceService.getBalanceServiceInfo()
+	if err != nil {
+		return err
+	}
+	configLines, err := readConfigLines(balanceService.fs, serviceInfo.configFile)
+	if err != nil {
+		return err
+	}
+	setting := fmt.Sprintf("%s=%s", bannedCpusKey, mask)
+	bannedCpusPattern := settingPattern(bannedCpusKey)
+	bannedCpuListPattern := settingPattern(bannedCpuListKey)
+	var newLines []string
+	set := false
+	for _, line := range configLines {
+		switch {
+		case bannedCpusPattern.MatchString(line):
+			if !set {
+				newLines = append(newLines, setting)
+				set = true
+			}
+		case bannedCpuListPattern.MatchString(line):
+			// It would take precedence over the mask.
+			log.Warnf(
+				"Removing '%s' from '%s', as it would override %s",
+				strings.TrimSpace(line),
+				serviceInfo.configFile,
+				bannedCpusKey,
+			)
+		default:
+			newLines = append(newLines, line)
+		}
+	}
+	if !set {
+		newLines = append(newLines, setting)
+	}
+	log.Infof("Configuring 'irqbalance' with banned CPUs '%s'", mask)
+	return balanceService.executor.Execute(commands.NewReconfigureCmd(
+		commands.NewWriteFileLinesCmd(balanceService.fs, serviceInfo.configFile, newLines),
+		balanceService.restartCmd(serviceInfo),
+	))
+}
+
+func (balanceService *balanceService) GetBannedCPUs() (string, error) {
+	serviceInfo, err := balanceService.getBalanceServiceInfo()
+	if err != nil {
+		return "", err
+	}
+	configLines, err := readConfigLines(balanceService.fs, serviceInfo.configFile)
+	if err != nil {
+		return "", err
+	}
+	pattern := settingPattern(bannedCpusKey)
+	mask := ""
+	// The last one wins, as the file is sourced by a shell.
+	for _, line := range configLines {
+		if match := pattern.FindStringSubmatch(line); match != nil {
+			mask = strings.Trim(strings.TrimSpace(match[1]), "\"'")
+		}
+	}
+	return mask, nil
+}
+
+func (balanceService *balanceService) restartCmd(
+	serviceInfo *balanceServiceInfo,
+) commands.Command {
+	if serviceInfo.systemd {
+		return commands.NewLaunchCmd(
+			balanceService.proc, balanceService.timeout, "systemctl", "try-restart", "irqbalance")
+	}
+	return commands.NewLaunchCmd(
+		balanceService.proc, balanceService.timeout, "/etc/init.d/irqbalance", "restart")
+}
+
+// Matches an active (i.e. not commented out) assignment of key, capturing
+// its value.
+func settingPattern(key string) *regexp.Regexp {
+	return regexp.MustCompile(fmt.Sprintf(`^\s*(?:export\s+)?%s=(.*)$`, key))
+}
+
+// Reads the lines of the config file, which is empty if it doesn't exist.
+func readConfigLines(fs afero.Fs, configFile string) ([]string, error) {
+	exists, err := afero.Exists(fs, configFile)
+	if err != nil || !exists {
+		return nil, err
+	}
+	return utils.ReadFileLines(fs, configFile)
+}
+
 func (balanceService *balanceService) IsRunning() bool {
 	return balanceService.proc.IsRunning(balanceService.timeout, "irqbalance")
 }
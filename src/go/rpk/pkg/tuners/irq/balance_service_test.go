@@ -10,9 +10,12 @@
 package irq
 
 import (
+	"bufio"
+	"bytes"
 	"crypto/md5"
 	"encoding/hex"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -359,3 +362,77 @@ func calcMd5(lines []string) string {
 	hash := md5.Sum(data)
 	return hex.EncodeToString(hash[:16])
 }
+
+func Test_BalanceService_BanCPUsAndRestart(t *testing.T) {
+	tests := []struct {
+		name       string
+		configFile []string
+		expected   []string
+	}{
+		{
+			name:       "Shall append the banned CPUs, leaving the comments intact",
+			configFile: []string{"ONE_SHOT=true", "#IRQBALANCE_BANNED_CPUS="},
+			expected:   []string{"ONE_SHOT=true", "#IRQBALANCE_BANNED_CPUS=", "IRQBALANCE_BANNED_CPUS=fc"},
+		},
+		{
+			name: "Shall replace the banned CPUs, and drop the CPU list overriding them",
+			configFile: []string{
+				"IRQBALANCE_BANNED_CPUS=\"3\"",
+				"IRQBALANCE_BANNED_CPULIST=0-1",
+				"IRQBALANCE_ARGS=\" --banirq=5\"",
+				"IRQBALANCE_BANNED_CPUS=1",
+			},
+			expected: []string{"IRQBALANCE_BANNED_CPUS=fc", "IRQBALANCE_ARGS=\" --banirq=5\""},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			require.NoError(t, utils.WriteFileLines(fs, tt.configFile, "/etc/sysconfig/irqbalance"))
+			restarts := 0
+			proc := &procMock{
+				run: func(command string, args ...string) ([]string, error) {
+					require.Equal(t, "systemctl", command)
+					require.Equal(t, []string{"try-restart", "irqbalance"}, args)
+					restarts++
+					return nil, nil
+				},
+			}
+			recorder := executors.NewRecordingExecutor(executors.NewDirectExecutor())
+			balanceService := NewBalanceService(fs, proc, recorder, time.Second)
+			require.NoError(t, balanceService.BanCPUsAndRestart("fc"))
+			require.Equal(t, 1, restarts)
+			lines, err := utils.ReadFileLines(fs, "/etc/sysconfig/irqbalance")
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, lines)
+
+			mask, err := balanceService.GetBannedCPUs()
+			require.NoError(t, err)
+			require.Equal(t, "fc", mask)
+
+			// The undo script restores the config before restarting
+			// irqbalance.
+			var out bytes.Buffer
+			w := bufio.NewWriter(&out)
+			require.NoError(t, recorder.RenderUndoScript(w))
+			script := out.String()
+			restore := strings.Index(script, "/etc/sysconfig/irqbalance")
+			restart := strings.Index(script, "systemctl")
+			require.True(t, restore >= 0 && restart > restore, script)
+		})
+	}
+}
+
+func Test_balanceService_GetBannedCPUs(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	balanceService := NewBalanceService(fs, &procMock{}, executors.NewDirectExecutor(), time.Second)
+	require.NoError(t, utils.WriteFileLines(fs, []string{"#IRQBALANCE_BANNED_CPUS=ff"}, "/etc/sysconfig/irqbalance"))
+	mask, err := balanceService.GetBannedCPUs()
+	require.NoError(t, err)
+	require.Equal(t, "", mask)
+
+	require.NoError(t, utils.WriteFileLines(fs, []string{"export IRQBALANCE_BANNED_CPUS=\"00000001,00000000\""}, "/etc/sysconfig/irqbalance"))
+	mask, err = balanceService.GetBannedCPUs()
+	require.NoError(t, err)
+	require.Equal(t, "00000001,00000000", mask)
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors"
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors/commands"
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/hwloc"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/topology"
 )
 
 type CpuMasks interface {
@@ -31,6 +32,7 @@ type CpuMasks interface {
 	DistributeIRQs(irqsDistribution map[int]string)
 	GetDistributionMasks(count uint) ([]string, error)
 	GetIRQsDistributionMasks(IRQs []int, cpuMask string) (map[int]string, error)
+	RestrictToNumaNode(cpuMask string, node int) (string, error)
 	GetNumberOfCores(mask string) (uint, error)
 	GetNumberOfPUs(mask string) (uint, error)
 	GetAllCpusMask() (string, error)
@@ -124,13 +126,13 @@ func (masks *cpuMasks) SetMask(path string, mask string) error {
 	if _, err := masks.fs.Stat(path); err != nil {
 		return fmt.Errorf("SMP affinity file '%s' not exist", path)
 	}
-	var formattedMask = strings.Replace(mask, "0x", "", -1)
-	for strings.Contains(formattedMask, ",,") {
-		formattedMask = strings.Replace(formattedMask, ",,", ",0,", -1)
+	formattedMask, err := FormatMask(mask, GetNrCpus(masks.fs))
+	if err != nil {
+		return err
 	}
 
 	log.Debugf("Setting mask '%s' in '%s'", formattedMask, path)
-	err := masks.executor.Execute(
+	err = masks.executor.Execute(
 		commands.NewWriteFileModeCmd(masks.fs, path, formattedMask, 0555))
 	if err != nil {
 		return err
@@ -138,24 +140,96 @@ func (masks *cpuMasks) SetMask(path string, mask string) error {
 	return nil
 }
 
+// Returns count masks covering all the online CPUs, spread over the physical
+// cores before their hyperthread siblings.
 func (masks *cpuMasks) GetDistributionMasks(count uint) ([]string, error) {
-	return masks.hwloc.Distribute(count)
+	topo, err := topology.Read(masks.fs)
+	if err != nil {
+		log.Debugf("Couldn't read the CPU topology, using hwloc: %v", err)
+		return masks.hwloc.Distribute(count)
+	}
+	order := topo.SpreadOrder(nil)
+	distribMasks := make([]string, count)
+	if count == 0 {
+		return distribMasks, nil
+	}
+	// If there are more CPUs than masks, each mask gets several of them.
+	groups := make([][]int, count)
+	for i := 0; i < len(order) || i < int(count); i++ {
+		groups[i%int(count)] = append(groups[i%int(count)], order[i%len(order)])
+	}
+	for i, group := range groups {
+		distribMasks[i] = CpusMask(group)
+	}
+	return distribMasks, nil
 }
 
+// Returns a single-CPU mask for each IRQ, out of the CPUs in cpuMask, using
+// a CPU of every physical core before using their hyperthread siblings.
 func (masks *cpuMasks) GetIRQsDistributionMasks(
 	IRQs []int, cpuMask string,
 ) (map[int]string, error) {
-	distribMasks, err := masks.hwloc.DistributeRestrict(uint(len(IRQs)), cpuMask)
+	distribMasks, err := masks.distributeSingle(uint(len(IRQs)), cpuMask)
 	if err != nil {
 		return nil, err
 	}
+	nrCpus := GetNrCpus(masks.fs)
 	irqsDistribution := make(map[int]string)
 	for i, mask := range distribMasks {
+		err = ValidateMask(mask, nrCpus)
+		if err != nil {
+			return nil, err
+		}
 		irqsDistribution[IRQs[i]] = mask
 	}
 	return irqsDistribution, nil
 }
 
+func (masks *cpuMasks) distributeSingle(
+	count uint, cpuMask string,
+) ([]string, error) {
+	topo, err := topology.Read(masks.fs)
+	if err != nil {
+		log.Debugf("Couldn't read the CPU topology, using hwloc: %v", err)
+		return masks.hwloc.DistributeRestrict(count, cpuMask)
+	}
+	cpus, err := MaskCpus(cpuMask)
+	if err != nil {
+		return nil, err
+	}
+	order := topo.SpreadOrder(cpus)
+	if len(order) == 0 {
+		return nil, fmt.Errorf("none of the CPUs in mask '%s' are online", cpuMask)
+	}
+	var distribMasks []string
+	for i := 0; i < int(count); i++ {
+		distribMasks = append(distribMasks, CpusMask([]int{order[i%len(order)]}))
+	}
+	return distribMasks, nil
+}
+
+// Returns the CPUs in cpuMask which belong to the given NUMA node. If node is
+// negative (i.e. unknown), or none of the CPUs belong to it, cpuMask is
+// returned as is.
+func (masks *cpuMasks) RestrictToNumaNode(
+	cpuMask string, node int,
+) (string, error) {
+	if node < 0 {
+		return cpuMask, nil
+	}
+	// The 'x' prefix intersects the mask with the node's CPUs.
+	restricted, err := masks.hwloc.Calc(cpuMask, fmt.Sprintf("xnode:%d", node))
+	if err != nil {
+		return "", err
+	}
+	if masks.hwloc.CheckIfMaskIsEmpty(restricted) {
+		log.Debugf("None of the CPUs in '%s' belong to NUMA node %d", cpuMask, node)
+		return cpuMask, nil
+	}
+	log.Debugf("CPU mask '%s' restricted to NUMA node %d: '%s'", cpuMask, node, restricted)
+	return restricted, nil
+}
+
 func (masks *cpuMasks) DistributeIRQs(irqsDistribution map[int]string) {
 	log.Debugf("Distributing IRQs '%v' ", irqsDistribution)
 	errMsg := "An IRQ's affinity couldn't be set. This might be because the" +
@@ -204,12 +278,38 @@ func (masks *cpuMasks) ReadIRQMask(IRQ int) (string, error) {
 	return masks.ReadMask(irqAffinityPath(IRQ))
 }
 
+// Returns the number of physical cores with CPUs in mask.
 func (masks *cpuMasks) GetNumberOfCores(mask string) (uint, error) {
-	return masks.hwloc.GetNumberOfCores(mask)
+	topo, cpus, err := masks.readTopology(mask)
+	if err != nil {
+		log.Debugf("Couldn't read the CPU topology, using hwloc: %v", err)
+		return masks.hwloc.GetNumberOfCores(mask)
+	}
+	return uint(topo.NumCores(cpus)), nil
 }
 
+// Returns the number of online logical CPUs (i.e. hyperthreads) in mask.
 func (masks *cpuMasks) GetNumberOfPUs(mask string) (uint, error) {
-	return masks.hwloc.GetNumberOfPUs(mask)
+	topo, cpus, err := masks.readTopology(mask)
+	if err != nil {
+		log.Debugf("Couldn't read the CPU topology, using hwloc: %v", err)
+		return masks.hwloc.GetNumberOfPUs(mask)
+	}
+	return uint(topo.NumThreads(cpus)), nil
+}
+
+func (masks *cpuMasks) readTopology(
+	mask string,
+) (*topology.Topology, []int, error) {
+	topo, err := topology.Read(masks.fs)
+	if err != nil {
+		return nil, nil, err
+	}
+	cpus, err := MaskCpus(mask)
+	if err != nil {
+		return nil, nil, err
+	}
+	return topo, cpus, nil
 }
 
 func (masks *cpuMasks) GetLogicalCoreIdsFromPhysCore(
@@ -246,6 +346,77 @@ func MasksEqual(a, b string) (bool, error) {
 	return true, nil
 }
 
+// Formats mask the way the kernel expects CPU masks to be written: as
+// comma-separated groups of up to 32 bits (8 hex digits), with the most
+// significant one first and without the '0x' prefix. Empty groups are
+// written as '0', groups longer than 32 bits are split, and zero groups are
+// prepended until there are enough of them to hold nrCpus bits, e.g. '0x1'
+// becomes '0,1' on a machine with 48 CPUs.
+func FormatMask(mask string, nrCpus int) (string, error) {
+	var groups []string
+	for _, group := range strings.Split(mask, ",") {
+		group = strings.TrimPrefix(strings.TrimSpace(group), "0x")
+		if group == "" {
+			group = "0"
+		}
+		var split []string
+		for len(group) > 8 {
+			split = append([]string{group[len(group)-8:]}, split...)
+			group = group[:len(group)-8]
+		}
+		groups = append(groups, append([]string{group}, split...)...)
+	}
+	for _, group := range groups {
+		if _, err := parseMask(group); err != nil {
+			return "", fmt.Errorf("invalid CPU mask '%s': %w", mask, err)
+		}
+	}
+	required := (nrCpus + 31) / 32
+	for len(groups) < required {
+		groups = append([]string{"0"}, groups...)
+	}
+	return strings.Join(groups, ","), nil
+}
+
+// Returns the ids of the CPUs in mask, made of comma-separated 32-bit groups
+// with the most significant one first (e.g. '0x00000001,0xffffffff'), in
+// ascending order.
+func MaskCpus(mask string) ([]int, error) {
+	cpus := []int{}
+	groups := strings.Split(mask, ",")
+	for i := len(groups) - 1; i >= 0; i-- {
+		value, err := parseMask(strings.TrimSpace(groups[i]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid CPU mask '%s': %w", mask, err)
+		}
+		offset := (len(groups) - 1 - i) * 32
+		for bit := 0; value != 0; bit++ {
+			if value&1 == 1 {
+				cpus = append(cpus, offset+bit)
+			}
+			value >>= 1
+		}
+	}
+	return cpus, nil
+}
+
+// Returns the mask of the given CPUs, in the format hwloc-calc prints them,
+// e.g. '0x00000001,0x00000003' for CPUs 0, 1 and 32.
+func CpusMask(cpus []int) string {
+	groups := []uint32{0}
+	for _, cpu := range cpus {
+		for cpu/32 >= len(groups) {
+			groups = append(groups, 0)
+		}
+		groups[cpu/32] |= 1 << uint(cpu%32)
+	}
+	var formatted []string
+	for i := len(groups) - 1; i >= 0; i-- {
+		formatted = append(formatted, fmt.Sprintf("0x%08x", groups[i]))
+	}
+	return strings.Join(formatted, ",")
+}
+
 func parseMask(mask string) (uint, error) {
 	if mask == "" {
 		return 0, nil
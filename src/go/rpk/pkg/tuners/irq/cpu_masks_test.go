@@ -10,6 +10,7 @@
 package irq
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/spf13/afero"
@@ -71,3 +72,115 @@ func Test_masksEqual(t *testing.T) {
 		})
 	}
 }
+
+func TestFormatMask(t *testing.T) {
+	tests := []struct {
+		name     string
+		mask     string
+		nrCpus   int
+		expected string
+	}{
+		{
+			name:     "it should keep a single group with 32 CPUs",
+			mask:     "0xffffffff",
+			nrCpus:   32,
+			expected: "ffffffff",
+		},
+		{
+			name:     "it should add a group with 33 CPUs",
+			mask:     "0x1ffffffff",
+			nrCpus:   33,
+			expected: "1,ffffffff",
+		},
+		{
+			name:     "it should pad the mask with 33 CPUs",
+			mask:     "0x1",
+			nrCpus:   33,
+			expected: "0,1",
+		},
+		{
+			name:     "it should put bits 32-47 in the high group with 48 CPUs",
+			mask:     "0xffffffffffff",
+			nrCpus:   48,
+			expected: "ffff,ffffffff",
+		},
+		{
+			name:     "it should split the mask in two groups with 64 CPUs",
+			mask:     "0xffffffffffffffff",
+			nrCpus:   64,
+			expected: "ffffffff,ffffffff",
+		},
+		{
+			name:     "it should use three groups with 65 CPUs",
+			mask:     "0x100000000000000ff",
+			nrCpus:   65,
+			expected: "1,00000000,000000ff",
+		},
+		{
+			name:     "it should keep grouped masks, filling the empty groups",
+			mask:     "0x00000001,,0x000000ff",
+			nrCpus:   65,
+			expected: "00000001,0,000000ff",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FormatMask(tt.mask, tt.nrCpus)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestFormatMaskInvalid(t *testing.T) {
+	_, err := FormatMask("0xzz", 8)
+	require.Error(t, err)
+}
+
+func TestSetMaskRendersGroupedMask(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	const script = "/tune.sh"
+	require.NoError(t, afero.WriteFile(fs, "/sys/devices/system/cpu/possible", []byte("0-47\n"), 0644))
+	require.NoError(t, afero.WriteFile(fs, "/proc/irq/10/smp_affinity", []byte("0\n"), 0644))
+	cpuMasks := NewCpuMasks(fs, nil, executors.NewScriptRenderingExecutor(fs, script))
+	require.NoError(t, cpuMasks.SetMask("/proc/irq/10/smp_affinity", "0xffff00000000"))
+	content, err := afero.ReadFile(fs, script)
+	require.NoError(t, err)
+	require.Contains(t, string(content), "echo 'ffff,00000000' > /proc/irq/10/smp_affinity")
+}
+
+func TestMaskCpus(t *testing.T) {
+	cpus, err := MaskCpus("0x00000001,0x00000003")
+	require.NoError(t, err)
+	require.Equal(t, []int{0, 1, 32}, cpus)
+	require.Equal(t, "0x00000001,0x00000003", CpusMask(cpus))
+	require.Equal(t, "0x00000000", CpusMask(nil))
+}
+
+func TestGetIRQsDistributionMasksSpreadsOverCores(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/sys/devices/system/cpu/possible", []byte("0-3\n"), 0644))
+	// CPUs 0 and 1 are siblings, and so are 2 and 3.
+	for cpu, siblings := range []string{"0-1", "0-1", "2-3", "2-3"} {
+		dir := fmt.Sprintf("/sys/devices/system/cpu/cpu%d/topology/", cpu)
+		require.NoError(t, afero.WriteFile(fs, dir+"physical_package_id", []byte("0\n"), 0644))
+		require.NoError(t, afero.WriteFile(fs, dir+"core_id", []byte(fmt.Sprint(cpu/2)), 0644))
+		require.NoError(t, afero.WriteFile(fs, dir+"thread_siblings_list", []byte(siblings), 0644))
+	}
+	cpuMasks := NewCpuMasks(fs, nil, executors.NewDirectExecutor())
+	dist, err := cpuMasks.GetIRQsDistributionMasks([]int{10, 11, 12}, "0x0000000f")
+	require.NoError(t, err)
+	expected := map[int]string{10: "0x00000001", 11: "0x00000004", 12: "0x00000002"}
+	require.Equal(t, expected, dist)
+
+	cores, err := cpuMasks.GetNumberOfCores("0x00000007")
+	require.NoError(t, err)
+	require.Equal(t, uint(2), cores)
+	pus, err := cpuMasks.GetNumberOfPUs("0x00000007")
+	require.NoError(t, err)
+	require.Equal(t, uint(3), pus)
+
+	masks, err := cpuMasks.GetDistributionMasks(2)
+	require.NoError(t, err)
+	require.Equal(t, []string{"0x00000003", "0x0000000c"}, masks)
+}
@@ -0,0 +1,89 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package irq
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"runtime"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+)
+
+const possibleCpusFile = "/sys/devices/system/cpu/possible"
+
+// Returns the NUMA node the device whose sysfs directory is devicePath (e.g.
+// /sys/class/net/eth0) is attached to, or -1 if it's unknown. The kernel
+// reports -1 itself on machines with a single node.
+func GetNumaNode(fs afero.Fs, devicePath string) (int, error) {
+	file := path.Join(devicePath, "device", "numa_node")
+	content, err := afero.ReadFile(fs, file)
+	if os.IsNotExist(err) {
+		return -1, nil
+	}
+	if err != nil {
+		return -1, err
+	}
+	node, err := strconv.Atoi(strings.TrimSpace(string(content)))
+	if err != nil {
+		return -1, fmt.Errorf("couldn't parse '%s': %w", file, err)
+	}
+	return node, nil
+}
+
+// Returns the number of CPU ids the kernel supports (nr_cpu_ids), which is
+// the number of bits a CPU mask may have. It's read from the list of
+// possible CPUs (e.g. '0-63'), falling back to the number of CPUs usable by
+// the current process if it's not available.
+func GetNrCpus(fs afero.Fs) int {
+	content, err := afero.ReadFile(fs, possibleCpusFile)
+	if err != nil {
+		log.Debugf("Couldn't read '%s': %v", possibleCpusFile, err)
+		return runtime.NumCPU()
+	}
+	ranges := strings.Split(strings.TrimSpace(string(content)), ",")
+	bounds := strings.Split(ranges[len(ranges)-1], "-")
+	last, err := strconv.Atoi(bounds[len(bounds)-1])
+	if err != nil {
+		log.Debugf("Couldn't parse '%s': %v", possibleCpusFile, err)
+		return runtime.NumCPU()
+	}
+	return last + 1
+}
+
+// Checks that mask, made of comma-separated 32-bit groups with the most
+// significant one first (e.g. '0x00000001,0xffffffff'), doesn't have any bits
+// set for CPUs beyond nrCpus, which the kernel would reject.
+func ValidateMask(mask string, nrCpus int) error {
+	groups := strings.Split(mask, ",")
+	for i, group := range groups {
+		value, err := parseMask(group)
+		if err != nil {
+			return fmt.Errorf("invalid CPU mask '%s': %w", mask, err)
+		}
+		offset := (len(groups) - 1 - i) * 32
+		for bit := 0; value != 0; bit++ {
+			if value&1 == 1 && offset+bit >= nrCpus {
+				return fmt.Errorf(
+					"CPU mask '%s' includes CPU %d, but there are only %d CPUs",
+					mask,
+					offset+bit,
+					nrCpus,
+				)
+			}
+			value >>= 1
+		}
+	}
+	return nil
+}
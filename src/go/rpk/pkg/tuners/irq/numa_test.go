@@ -0,0 +1,137 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package irq
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetNumaNode(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		expected    int
+		expectedErr string
+	}{
+		{
+			name:     "it should return the device's node",
+			content:  "1\n",
+			expected: 1,
+		},
+		{
+			name:     "it should return -1 if the kernel doesn't know the node",
+			content:  "-1\n",
+			expected: -1,
+		},
+		{
+			name:     "it should return -1 if the file doesn't exist",
+			expected: -1,
+		},
+		{
+			name:        "it should fail if the file can't be parsed",
+			content:     "one",
+			expected:    -1,
+			expectedErr: "couldn't parse '/sys/class/net/eth0/device/numa_node'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			fs := afero.NewMemMapFs()
+			if tt.content != "" {
+				err := afero.WriteFile(
+					fs,
+					"/sys/class/net/eth0/device/numa_node",
+					[]byte(tt.content),
+					0644,
+				)
+				require.NoError(st, err)
+			}
+			node, err := GetNumaNode(fs, "/sys/class/net/eth0")
+			require.Equal(st, tt.expected, node)
+			if tt.expectedErr != "" {
+				require.Error(st, err)
+				require.Contains(st, err.Error(), tt.expectedErr)
+				return
+			}
+			require.NoError(st, err)
+		})
+	}
+}
+
+func TestGetNrCpus(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.Equal(t, runtime.NumCPU(), GetNrCpus(fs))
+
+	err := afero.WriteFile(fs, possibleCpusFile, []byte("0-63\n"), 0644)
+	require.NoError(t, err)
+	require.Equal(t, 64, GetNrCpus(fs))
+
+	err = afero.WriteFile(fs, possibleCpusFile, []byte("0\n"), 0644)
+	require.NoError(t, err)
+	require.Equal(t, 1, GetNrCpus(fs))
+}
+
+func TestValidateMask(t *testing.T) {
+	tests := []struct {
+		name        string
+		mask        string
+		nrCpus      int
+		expectedErr string
+	}{
+		{
+			name:   "it should accept masks within the CPU count",
+			mask:   "0x000000ff",
+			nrCpus: 8,
+		},
+		{
+			name:   "it should accept multi-group masks",
+			mask:   "0x00000001,0x00000000",
+			nrCpus: 33,
+		},
+		{
+			name:   "it should accept empty groups",
+			mask:   "0x1,,0x1",
+			nrCpus: 65,
+		},
+		{
+			name:        "it should reject masks with bits beyond the CPU count",
+			mask:        "0x000001ff",
+			nrCpus:      8,
+			expectedErr: "CPU mask '0x000001ff' includes CPU 8, but there are only 8 CPUs",
+		},
+		{
+			name:        "it should reject bits beyond the CPU count in higher groups",
+			mask:        "0x00000002,0x00000000",
+			nrCpus:      33,
+			expectedErr: "CPU mask '0x00000002,0x00000000' includes CPU 33, but there are only 33 CPUs",
+		},
+		{
+			name:        "it should reject invalid masks",
+			mask:        "0xzz",
+			nrCpus:      8,
+			expectedErr: "invalid CPU mask '0xzz'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			err := ValidateMask(tt.mask, tt.nrCpus)
+			if tt.expectedErr != "" {
+				require.Error(st, err)
+				require.Contains(st, err.Error(), tt.expectedErr)
+				return
+			}
+			require.NoError(st, err)
+		})
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners
+
+import (
+	log "github.com/sirupsen/logrus"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/irq"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/topology"
+)
+
+const irqBalanceDesc = "irqbalance's banned CPUs"
+
+type irqBalanceTuner struct {
+	cpuSet         string
+	balanceService irq.BalanceService
+	executor       executors.Executor
+}
+
+// Creates a tuner banning the CPUs in cpuSet (in cpuset(7)'s list format),
+// which redpanda is pinned to, in irqbalance's config and restarting it, so
+// that it doesn't move IRQs onto them, undoing what the IRQ tuners set. It's
+// reversible (see commands.Reversible): the undo script restores the config
+// and restarts irqbalance again. If irqbalance isn't running, nothing is
+// changed.
+func NewIRQBalanceTuner(
+	cpuSet string, balanceService irq.BalanceService, executor executors.Executor,
+) Tunable {
+	return &irqBalanceTuner{
+		cpuSet:         cpuSet,
+		balanceService: balanceService,
+		executor:       executor,
+	}
+}
+
+func (t *irqBalanceTuner) CheckIfSupported() (supported bool, reason string) {
+	if t.cpuSet == "" || t.cpuSet == "all" {
+		return false, "The CPU set redpanda is pinned to is required (--cpu-set)" +
+			", as there would be no CPUs to leave for irqbalance"
+	}
+	_, err := t.bannedCpus()
+	if err != nil {
+		return false, err.Error()
+	}
+	return true, ""
+}
+
+func (t *irqBalanceTuner) Tune() TuneResult {
+	tunable, err := t.createTunable()
+	if err != nil {
+		return NewTuneError(err)
+	}
+	return tunable.Tune()
+}
+
+func (t *irqBalanceTuner) Check() ([]CheckResult, error) {
+	tunable, err := t.createTunable()
+	if err != nil {
+		return nil, err
+	}
+	return CheckTunable(tunable)
+}
+
+func (t *irqBalanceTuner) createTunable() (Tunable, error) {
+	banned, err := t.bannedCpus()
+	if err != nil {
+		return nil, err
+	}
+	if !t.balanceService.IsRunning() {
+		return &stoppedIRQBalance{banned: banned}, nil
+	}
+	return NewCheckedTunable(
+		NewEqualityChecker(
+			IRQBalanceBannedCpusChecker,
+			irqBalanceDesc,
+			Warning,
+			formatCpuList(banned),
+			func() (interface{}, error) {
+				mask, err := t.balanceService.GetBannedCPUs()
+				if err != nil || mask == "" {
+					return "none", err
+				}
+				cpus, err := topology.ParseMask(mask)
+				if err != nil {
+					return "", err
+				}
+				return formatCpuList(cpus), nil
+			},
+		),
+		func() TuneResult {
+			err := t.balanceService.BanCPUsAndRestart(topology.FormatMask(banned))
+			if err != nil {
+				return NewTuneError(err)
+			}
+			return NewTuneResult(false)
+		},
+		func() (bool, string) {
+			return true, ""
+		},
+		t.executor.IsLazy(),
+	), nil
+}
+
+func (t *irqBalanceTuner) bannedCpus() ([]int, error) {
+	return topology.ParseList(t.cpuSet)
+}
+
+// Reports that irqbalance isn't running, so there's nothing to configure.
+type stoppedIRQBalance struct {
+	banned []int
+}
+
+func (s *stoppedIRQBalance) CheckIfSupported() (supported bool, reason string) {
+	return true, ""
+}
+
+func (s *stoppedIRQBalance) Tune() TuneResult {
+	log.Info("Skipping the irqbalance config, as it isn't running")
+	return NewUnchangedTuneResult()
+}
+
+func (s *stoppedIRQBalance) Check() ([]CheckResult, error) {
+	return []CheckResult{{
+		CheckerId: IRQBalanceBannedCpusChecker,
+		IsOk:      true,
+		Desc:      irqBalanceDesc,
+		Severity:  Warning,
+		Current:   "irqbalance isn't running",
+		Required:  formatCpuList(s.banned),
+	}}, nil
+}
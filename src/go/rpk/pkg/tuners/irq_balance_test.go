@@ -0,0 +1,111 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/irq"
+)
+
+type bannedCpusBalanceService struct {
+	irq.BalanceService
+	running bool
+	mask    string
+	bans    int
+}
+
+func (s *bannedCpusBalanceService) IsRunning() bool {
+	return s.running
+}
+
+func (s *bannedCpusBalanceService) GetBannedCPUs() (string, error) {
+	return s.mask, nil
+}
+
+func (s *bannedCpusBalanceService) BanCPUsAndRestart(mask string) error {
+	s.mask = mask
+	s.bans++
+	return nil
+}
+
+func TestIRQBalanceTuner(t *testing.T) {
+	tests := []struct {
+		name            string
+		running         bool
+		mask            string
+		expectedOk      bool
+		expectedCurrent string
+		expectedBans    int
+		expectedMask    string
+	}{
+		{
+			name:            "it should ban the CPUs",
+			running:         true,
+			expectedCurrent: "none",
+			expectedBans:    1,
+			expectedMask:    "3c",
+		},
+		{
+			name:            "it should replace other banned CPUs",
+			running:         true,
+			mask:            "1",
+			expectedCurrent: "0",
+			expectedBans:    1,
+			expectedMask:    "3c",
+		},
+		{
+			name:            "it shouldn't restart irqbalance if the CPUs are banned already",
+			running:         true,
+			mask:            "0000003c",
+			expectedOk:      true,
+			expectedCurrent: "2-5",
+			expectedMask:    "0000003c",
+		},
+		{
+			name:            "it shouldn't change anything if irqbalance isn't running",
+			expectedOk:      true,
+			expectedCurrent: "irqbalance isn't running",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &bannedCpusBalanceService{running: tt.running, mask: tt.mask}
+			tuner := tuners.NewIRQBalanceTuner("2-5", service, executors.NewDirectExecutor())
+			supported, reason := tuner.CheckIfSupported()
+			require.True(t, supported, reason)
+
+			results, err := tuners.CheckTunable(tuner)
+			require.NoError(t, err)
+			require.Len(t, results, 1)
+			require.Equal(t, tt.expectedOk, results[0].IsOk)
+			require.Equal(t, tt.expectedCurrent, results[0].Current)
+			require.Equal(t, "2-5", results[0].Required)
+
+			res := tuner.Tune()
+			require.NoError(t, res.Error())
+			require.Equal(t, tt.expectedBans, service.bans)
+			require.Equal(t, tt.expectedMask, service.mask)
+		})
+	}
+}
+
+func TestIRQBalanceTunerRequiresCpuSet(t *testing.T) {
+	tuner := tuners.NewIRQBalanceTuner(
+		"all",
+		&bannedCpusBalanceService{running: true},
+		executors.NewDirectExecutor(),
+	)
+	supported, _ := tuner.CheckIfSupported()
+	require.False(t, supported)
+}
@@ -0,0 +1,267 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors/commands"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/topology"
+)
+
+// The CPUs the unbound workqueues' workers may run on (see the kernel's
+// Documentation/core-api/workqueue.rst).
+const WorkqueueCpuMaskFile = "/sys/devices/virtual/workqueue/cpumask"
+
+var ksoftirqdRegex = regexp.MustCompile(`^ksoftirqd/(\d+)$`)
+
+type kernelThreadIsolationTuner struct {
+	fs       afero.Fs
+	cpuSet   string
+	executor executors.Executor
+}
+
+// Creates a tuner keeping the kernel threads which can be moved off the CPUs
+// in cpuSet (in cpuset(7)'s list format), which redpanda is pinned to, so
+// that they don't steal cycles from it. The unbound workqueues are
+// restricted to the rest of the CPUs, which is reversible (see
+// commands.Reversible). The ksoftirqd threads can't be moved, as there's
+// one bound to each CPU, so they're only reported: they're kept idle by
+// steering the IRQs away from the CPUs instead.
+func NewKernelThreadIsolationTuner(
+	fs afero.Fs, cpuSet string, executor executors.Executor,
+) Tunable {
+	return &kernelThreadIsolationTuner{fs: fs, cpuSet: cpuSet, executor: executor}
+}
+
+func (t *kernelThreadIsolationTuner) CheckIfSupported() (supported bool, reason string) {
+	if t.cpuSet == "" || t.cpuSet == "all" {
+		return false, "The CPU set redpanda is pinned to is required (--cpu-set)" +
+			", as there would be no CPUs to move the kernel threads to"
+	}
+	exists, err := afero.Exists(t.fs, WorkqueueCpuMaskFile)
+	if err != nil {
+		return false, err.Error()
+	}
+	if !exists {
+		return false, fmt.Sprintf(
+			"The kernel doesn't expose the unbound workqueues' CPU mask ('%s')",
+			WorkqueueCpuMaskFile,
+		)
+	}
+	_, _, err = t.splitCpus()
+	if err != nil {
+		return false, err.Error()
+	}
+	return true, ""
+}
+
+func (t *kernelThreadIsolationTuner) Tune() TuneResult {
+	tunables, err := t.createTunables()
+	if err != nil {
+		return NewTuneError(err)
+	}
+	return NewAggregatedTunable(tunables).Tune()
+}
+
+func (t *kernelThreadIsolationTuner) Check() ([]CheckResult, error) {
+	tunables, err := t.createTunables()
+	if err != nil {
+		return nil, err
+	}
+	return CheckTunable(NewAggregatedTunable(tunables))
+}
+
+func (t *kernelThreadIsolationTuner) createTunables() ([]Tunable, error) {
+	online, reserved, err := t.splitCpus()
+	if err != nil {
+		return nil, err
+	}
+	housekeeping := subtractCpus(online, reserved)
+	workqueues := NewCheckedTunable(
+		NewEqualityChecker(
+			WorkqueueCpuMaskChecker,
+			"Unbound workqueues' CPUs",
+			Warning,
+			formatCpuList(housekeeping),
+			func() (interface{}, error) {
+				content, err := afero.ReadFile(t.fs, WorkqueueCpuMaskFile)
+				if err != nil {
+					return "", err
+				}
+				cpus, err := topology.ParseMask(string(content))
+				if err != nil {
+					return "", err
+				}
+				// The mask may include CPUs which aren't online.
+				return formatCpuList(intersectCpus(cpus, online)), nil
+			},
+		),
+		func() TuneResult {
+			log.Infof(
+				"Restricting the unbound workqueues to CPUs %s",
+				formatCpuList(housekeeping),
+			)
+			err := t.executor.Execute(commands.NewWriteFileCmd(
+				t.fs,
+				WorkqueueCpuMaskFile,
+				topology.FormatMask(housekeeping),
+			))
+			if err != nil {
+				return NewTuneError(err)
+			}
+			return NewTuneResult(false)
+		},
+		func() (bool, string) {
+			return true, ""
+		},
+		t.executor.IsLazy(),
+	)
+	return []Tunable{workqueues, &pinnedKsoftirqds{fs: t.fs, reserved: reserved}}, nil
+}
+
+// Returns the online CPUs, and which of them are in the CPU set. Both are
+// sorted.
+func (t *kernelThreadIsolationTuner) splitCpus() (online, reserved []int, err error) {
+	topo, err := topology.Read(t.fs)
+	if err != nil {
+		return nil, nil, err
+	}
+	online = topo.SpreadOrder(nil)
+	sort.Ints(online)
+	cpus, err := topology.ParseList(t.cpuSet)
+	if err != nil {
+		return nil, nil, err
+	}
+	reserved = intersectCpus(online, cpus)
+	if len(reserved) == 0 {
+		return nil, nil, fmt.Errorf("none of the CPUs in '%s' are online", t.cpuSet)
+	}
+	if len(reserved) == len(online) {
+		return nil, nil, fmt.Errorf(
+			"the CPU set '%s' has all the online CPUs, leaving none to move"+
+				" the kernel threads to",
+			t.cpuSet,
+		)
+	}
+	return online, reserved, nil
+}
+
+// Reports the ksoftirqd threads of the reserved CPUs, which can't be moved.
+type pinnedKsoftirqds struct {
+	fs       afero.Fs
+	reserved []int
+}
+
+func (p *pinnedKsoftirqds) CheckIfSupported() (supported bool, reason string) {
+	return true, ""
+}
+
+func (p *pinnedKsoftirqds) Tune() TuneResult {
+	cpus, err := p.cpus()
+	if err != nil {
+		return NewTuneError(err)
+	}
+	if len(cpus) > 0 {
+		log.Warnf(
+			"The ksoftirqd threads of CPUs %s can't be moved, as they're"+
+				" bound to their CPU. Steer the IRQs away from those CPUs"+
+				" (e.g. with the disk_irq and net tuners) so that they"+
+				" have fewer softirqs to process",
+			formatCpuList(cpus),
+		)
+	}
+	return NewUnchangedTuneResult()
+}
+
+func (p *pinnedKsoftirqds) Check() ([]CheckResult, error) {
+	cpus, err := p.cpus()
+	if err != nil {
+		return nil, err
+	}
+	current := "none"
+	if len(cpus) > 0 {
+		current = fmt.Sprintf("bound to CPUs %s", formatCpuList(cpus))
+	}
+	return []CheckResult{{
+		CheckerId: KsoftirqdChecker,
+		IsOk:      true,
+		Desc:      "ksoftirqd threads on redpanda's CPUs",
+		Severity:  Warning,
+		Current:   current,
+		Required:  "kept as is, as they're per-CPU threads",
+	}}, nil
+}
+
+// Returns the reserved CPUs with a ksoftirqd thread, found by their name in
+// /proc.
+func (p *pinnedKsoftirqds) cpus() ([]int, error) {
+	files, err := afero.Glob(p.fs, "/proc/[0-9]*/comm")
+	if err != nil {
+		return nil, err
+	}
+	var cpus []int
+	for _, file := range files {
+		content, err := afero.ReadFile(p.fs, file)
+		if os.IsNotExist(err) {
+			// The process exited.
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		match := ksoftirqdRegex.FindStringSubmatch(strings.TrimSpace(string(content)))
+		if match == nil {
+			continue
+		}
+		cpu, _ := strconv.Atoi(match[1])
+		cpus = append(cpus, cpu)
+	}
+	sort.Ints(cpus)
+	return intersectCpus(cpus, p.reserved), nil
+}
+
+// Returns the CPUs in a which are also in b, in a's order.
+func intersectCpus(a, b []int) []int {
+	inB := map[int]bool{}
+	for _, cpu := range b {
+		inB[cpu] = true
+	}
+	var res []int
+	for _, cpu := range a {
+		if inB[cpu] {
+			res = append(res, cpu)
+		}
+	}
+	return res
+}
+
+// Returns the CPUs in a which aren't in b, in a's order.
+func subtractCpus(a, b []int) []int {
+	inB := map[int]bool{}
+	for _, cpu := range b {
+		inB[cpu] = true
+	}
+	var res []int
+	for _, cpu := range a {
+		if !inB[cpu] {
+			res = append(res, cpu)
+		}
+	}
+	return res
+}
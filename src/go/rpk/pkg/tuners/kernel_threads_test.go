@@ -0,0 +1,114 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners_test
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors"
+)
+
+// Writes the topology of cpus CPUs without siblings, the workqueue mask and
+// a ksoftirqd thread per CPU, along with a process which isn't one.
+func writeKernelThreads(t *testing.T, fs afero.Fs, cpus int, mask string) {
+	for cpu := 0; cpu < cpus; cpu++ {
+		writeCpuIdleStates(t, fs, cpu)
+		comm := fmt.Sprintf("/proc/%d/comm", 10+cpu)
+		require.NoError(t, afero.WriteFile(fs, comm, []byte(fmt.Sprintf("ksoftirqd/%d\n", cpu)), 0644))
+	}
+	require.NoError(t, afero.WriteFile(fs, "/proc/1/comm", []byte("systemd\n"), 0644))
+	require.NoError(t, afero.WriteFile(fs, tuners.WorkqueueCpuMaskFile, []byte(mask+"\n"), 0644))
+}
+
+func TestKernelThreadIsolationTuner(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	writeKernelThreads(t, fs, 4, "ff")
+	tuner := tuners.NewKernelThreadIsolationTuner(fs, "2-3", executors.NewDirectExecutor())
+	supported, reason := tuner.CheckIfSupported()
+	require.True(t, supported, reason)
+
+	results, err := tuners.CheckTunable(tuner)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	// The mask's offline CPUs are ignored.
+	require.False(t, results[0].IsOk)
+	require.Equal(t, "0-3", results[0].Current)
+	require.Equal(t, "0-1", results[0].Required)
+	require.True(t, results[1].IsOk)
+	require.Equal(t, "bound to CPUs 2-3", results[1].Current)
+
+	res := tuner.Tune()
+	require.NoError(t, res.Error())
+	content, err := afero.ReadFile(fs, tuners.WorkqueueCpuMaskFile)
+	require.NoError(t, err)
+	require.Equal(t, "3", string(content))
+
+	results, err = tuners.CheckTunable(tuner)
+	require.NoError(t, err)
+	require.True(t, results[0].IsOk)
+}
+
+func TestKernelThreadIsolationTunerUndo(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	writeKernelThreads(t, fs, 4, "f")
+	recorder := executors.NewRecordingExecutor(executors.NewDirectExecutor())
+	tuner := tuners.NewKernelThreadIsolationTuner(fs, "1-3", recorder)
+	require.NoError(t, tuner.Tune().Error())
+
+	var out bytes.Buffer
+	w := bufio.NewWriter(&out)
+	require.NoError(t, recorder.RenderUndoScript(w))
+	require.Contains(t, out.String(), "echo 'f' > /sys/devices/virtual/workqueue/cpumask")
+}
+
+func TestKernelThreadIsolationTunerUnsupported(t *testing.T) {
+	tests := []struct {
+		name   string
+		cpuSet string
+		noMask bool
+		reason string
+	}{
+		{
+			name:   "it shouldn't be supported without a CPU set",
+			cpuSet: "all",
+			reason: "The CPU set redpanda is pinned to is required (--cpu-set), as there would be no CPUs to move the kernel threads to",
+		},
+		{
+			name:   "it shouldn't be supported if the CPU set has every CPU",
+			cpuSet: "0-7",
+			reason: "the CPU set '0-7' has all the online CPUs, leaving none to move the kernel threads to",
+		},
+		{
+			name:   "it shouldn't be supported if the kernel lacks the mask",
+			cpuSet: "1",
+			noMask: true,
+			reason: "The kernel doesn't expose the unbound workqueues' CPU mask ('/sys/devices/virtual/workqueue/cpumask')",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			writeKernelThreads(t, fs, 2, "3")
+			if tt.noMask {
+				require.NoError(t, fs.Remove(tuners.WorkqueueCpuMaskFile))
+			}
+			tuner := tuners.NewKernelThreadIsolationTuner(fs, tt.cpuSet, executors.NewDirectExecutor())
+			supported, reason := tuner.CheckIfSupported()
+			require.False(t, supported)
+			require.Equal(t, tt.reason, reason)
+		})
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors/commands"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/utils"
+)
+
+// The minimum number of memory map areas a process may have, as redpanda
+// maps a few per log segment, and fails to map more once the limit (65530 by
+// default) is hit.
+const RecommendedMaxMapCount = 262144
+
+const (
+	maxMapCountProperty = "vm.max_map_count"
+	maxMapCountFile     = "/proc/sys/vm/max_map_count"
+)
+
+func NewMaxMapCountChecker(fs afero.Fs) Checker {
+	return NewIntChecker(
+		MaxMapCountChecker,
+		"Max memory map areas",
+		Warning,
+		func(current int) bool {
+			return current >= RecommendedMaxMapCount
+		},
+		func() string {
+			return fmt.Sprintf(">= %d", RecommendedMaxMapCount)
+		},
+		func() (int, error) {
+			return utils.ReadIntFromFile(fs, maxMapCountFile)
+		},
+	)
+}
+
+// Creates a tuner raising vm.max_map_count to RecommendedMaxMapCount, and
+// persisting it in commands.SysctlDropInFile so that it's kept after a
+// reboot. It's never lowered, so a higher value set by the operator is kept.
+func NewMaxMapCountTuner(fs afero.Fs, executor executors.Executor) Tunable {
+	return NewCheckedTunable(
+		NewMaxMapCountChecker(fs),
+		func() TuneResult {
+			log.Debugf("Setting max memory map areas to %d", RecommendedMaxMapCount)
+			err := executor.Execute(
+				commands.NewPersistentSysctlSetCmd(
+					fs,
+					maxMapCountProperty,
+					fmt.Sprint(RecommendedMaxMapCount),
+				),
+			)
+			if err != nil {
+				return NewTuneError(err)
+			}
+			return NewTuneResult(false)
+		},
+		func() (bool, string) {
+			return true, ""
+		},
+		executor.IsLazy(),
+	)
+}
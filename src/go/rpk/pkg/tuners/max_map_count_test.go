@@ -0,0 +1,82 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors"
+)
+
+func TestMaxMapCountTuner(t *testing.T) {
+	const (
+		scriptPath      = "/tune.sh"
+		maxMapCountFile = "/proc/sys/vm/max_map_count"
+	)
+	tests := []struct {
+		name           string
+		current        string
+		expectedOk     bool
+		expectedScript string
+	}{
+		{
+			name:    "it should raise the value if it's lower",
+			current: "65530\n",
+			expectedScript: `if grep -qE '^[[:space:]]*vm\.max_map_count[[:space:]]*=' /etc/sysctl.d/99-redpanda.conf 2>/dev/null; then
+sed -i -E 's/^[[:space:]]*vm\.max_map_count[[:space:]]*=.*/vm.max_map_count = 262144/' /etc/sysctl.d/99-redpanda.conf
+else
+if [ -s /etc/sysctl.d/99-redpanda.conf ] && [ -n "$(tail -c1 /etc/sysctl.d/99-redpanda.conf)" ]; then
+printf '\n%s' 'vm.max_map_count = 262144' >> /etc/sysctl.d/99-redpanda.conf
+else
+printf '%s\n' 'vm.max_map_count = 262144' >> /etc/sysctl.d/99-redpanda.conf
+fi
+fi
+sysctl -p /etc/sysctl.d/99-redpanda.conf
+`,
+		},
+		{
+			name:       "it shouldn't change anything if it's the recommended one",
+			current:    "262144\n",
+			expectedOk: true,
+		},
+		{
+			name:       "it shouldn't lower a higher value",
+			current:    "1048576\n",
+			expectedOk: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			require.NoError(t, afero.WriteFile(fs, maxMapCountFile, []byte(tt.current), 0644))
+			tuner := tuners.NewMaxMapCountTuner(
+				fs,
+				executors.NewScriptRenderingExecutor(fs, scriptPath),
+			)
+
+			results, err := tuners.CheckTunable(tuner)
+			require.NoError(t, err)
+			require.Len(t, results, 1)
+			require.Equal(t, tt.expectedOk, results[0].IsOk)
+			require.Equal(t, ">= 262144", results[0].Required)
+
+			res := tuner.Tune()
+			require.NoError(t, res.Error())
+			require.Equal(t, !tt.expectedOk, res.IsChanged())
+			script, err := afero.ReadFile(fs, scriptPath)
+			require.NoError(t, err)
+			require.True(t, strings.HasSuffix(string(script), "RPK\n\n"+tt.expectedScript))
+		})
+	}
+}
@@ -11,9 +11,10 @@ package tuners
 
 import (
 	"fmt"
+	"path/filepath"
 	"strconv"
+	"strings"
 
-	"github.com/lorenzosaino/go-sysctl"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/afero"
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/ethtool"
@@ -37,6 +38,8 @@ type NetCheckersFactory interface {
 	NewRfsTableSizeChecker() Checker
 	NewListenBacklogChecker() Checker
 	NewSynBacklogChecker() Checker
+	NewNetworkBufferCheckers() []Checker
+	NewNetworkBufferChecker(property string) Checker
 }
 
 type netCheckersFactory struct {
@@ -183,33 +186,110 @@ func (f *netCheckersFactory) NewNicRfsCheckers(interfaces []string) []Checker {
 }
 
 func (f *netCheckersFactory) NewNicRfsChecker(nic network.Nic) Checker {
-	return NewEqualityChecker(
-		NicRfsChecker,
-		fmt.Sprintf("NIC %s RFS set", nic.Name()),
-		Warning,
-		true,
-		func() (interface{}, error) {
-			return isSet(nic, func(currentNic network.Nic) (bool, error) {
-				limits, err := currentNic.GetRpsLimitFiles()
-				queueLimit := network.OneRPSQueueLimit(limits)
-				if err != nil {
-					return false, err
-				}
-				for _, limitFile := range limits {
-					setLimit, err := utils.ReadIntFromFile(f.fs, limitFile)
-					if err != nil {
-						return false, err
-					}
-					if setLimit != queueLimit {
-						return false, nil
-					}
-				}
-				return true, nil
-			})
-		},
+	return &nicRfsChecker{fs: f.fs, nic: nic}
+}
+
+// Checks that the flow entries of the RFS table are split evenly among the
+// NIC's RX queues (or its slaves' if it's a bond), reporting how they're
+// currently distributed.
+type nicRfsChecker struct {
+	fs  afero.Fs
+	nic network.Nic
+}
+
+func (c *nicRfsChecker) Id() CheckerID {
+	return NicRfsChecker
+}
+
+func (c *nicRfsChecker) GetDesc() string {
+	return fmt.Sprintf("NIC %s RFS set", c.nic.Name())
+}
+
+func (c *nicRfsChecker) GetSeverity() Severity {
+	return Warning
+}
+
+func (c *nicRfsChecker) GetRequiredAsString() string {
+	return fmt.Sprintf(
+		"%d flow entries split evenly across the RX queues",
+		rfsTableSize(c.fs),
 	)
 }
 
+func (c *nicRfsChecker) Check() *CheckResult {
+	res := &CheckResult{
+		CheckerId: c.Id(),
+		Desc:      c.GetDesc(),
+		Severity:  c.GetSeverity(),
+		Required:  c.GetRequiredAsString(),
+	}
+	distribution, ok, err := rfsDistribution(c.fs, c.nic, "", rfsTableSize(c.fs))
+	if err != nil {
+		res.Err = err
+		return res
+	}
+	res.Current = strings.Join(distribution, ", ")
+	res.IsOk = ok
+	return res
+}
+
+// Returns the flow count of each of the NIC's RX queues as "<queue>: <count>",
+// and whether they all hold their share of tableSize.
+func rfsDistribution(
+	fs afero.Fs, nic network.Nic, prefix string, tableSize int,
+) ([]string, bool, error) {
+	if nic.IsHwInterface() {
+		limits, err := nic.GetRpsLimitFiles()
+		if err != nil {
+			return nil, false, err
+		}
+		queueLimit := network.RPSQueueLimit(tableSize, len(limits))
+		var distribution []string
+		ok := true
+		for _, limitFile := range limits {
+			setLimit, err := utils.ReadIntFromFile(fs, limitFile)
+			if err != nil {
+				return nil, false, err
+			}
+			distribution = append(distribution, fmt.Sprintf(
+				"%s%s: %d", prefix, network.RxQueueName(limitFile), setLimit,
+			))
+			ok = ok && setLimit == queueLimit
+		}
+		return distribution, ok, nil
+	}
+	if !nic.IsBondIface() {
+		return nil, true, nil
+	}
+	slaves, err := nic.Slaves()
+	if err != nil {
+		return nil, false, err
+	}
+	var distribution []string
+	ok := true
+	for _, slave := range slaves {
+		slaveDistribution, slaveOk, err := rfsDistribution(
+			fs, slave, slave.Name()+"/", tableSize,
+		)
+		if err != nil {
+			return nil, false, err
+		}
+		distribution = append(distribution, slaveDistribution...)
+		ok = ok && slaveOk
+	}
+	return distribution, ok, nil
+}
+
+// Returns the size the RFS table has once tuned: its current one if it's
+// big enough already, network.RfsTableSize otherwise.
+func rfsTableSize(fs afero.Fs) int {
+	values, err := readSysctlInts(fs, network.RfsTableSizeProperty)
+	if err != nil || values[0] < network.RfsTableSize {
+		return network.RfsTableSize
+	}
+	return values[0]
+}
+
 func (f *netCheckersFactory) NewNicNTupleCheckers(
 	interfaces []string,
 ) []Checker {
@@ -291,11 +371,11 @@ func (f *netCheckersFactory) NewRfsTableSizeChecker() Checker {
 			return fmt.Sprintf(">= %d", network.RfsTableSize)
 		},
 		func() (int, error) {
-			value, err := sysctl.Get(network.RfsTableSizeProperty)
+			values, err := readSysctlInts(f.fs, network.RfsTableSizeProperty)
 			if err != nil {
 				return 0, err
 			}
-			return strconv.Atoi(value)
+			return values[0], nil
 		},
 	)
 }
@@ -374,3 +454,55 @@ func (f *netCheckersFactory) forNonVirtualInterfaces(
 	}
 	return chkrs
 }
+
+func (f *netCheckersFactory) NewNetworkBufferCheckers() []Checker {
+	var checkers []Checker
+	for _, property := range network.BufferSizeProperties {
+		checkers = append(checkers, f.NewNetworkBufferChecker(property))
+	}
+	return checkers
+}
+
+func (f *netCheckersFactory) NewNetworkBufferChecker(property string) Checker {
+	return NewIntChecker(
+		NetworkBuffersChecker,
+		fmt.Sprintf("Max socket buffer size (%s)", property),
+		Warning,
+		func(current int) bool {
+			return current >= network.BufferMaxSize
+		},
+		func() string {
+			return fmt.Sprintf(">= %d", network.BufferMaxSize)
+		},
+		func() (int, error) {
+			sizes, err := readSysctlInts(f.fs, property)
+			if err != nil {
+				return 0, err
+			}
+			return sizes[len(sizes)-1], nil
+		},
+	)
+}
+
+// Reads the whitespace-separated integers held by a sysctl property through
+// its file in /proc/sys.
+func readSysctlInts(fs afero.Fs, property string) ([]int, error) {
+	file := filepath.Join("/proc/sys", strings.ReplaceAll(property, ".", "/"))
+	content, err := afero.ReadFile(fs, file)
+	if err != nil {
+		return nil, err
+	}
+	fields := strings.Fields(string(content))
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("'%s' is empty", file)
+	}
+	var values []int
+	for _, field := range fields {
+		value, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't parse '%s': %w", file, err)
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
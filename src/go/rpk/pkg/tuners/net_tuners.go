@@ -11,6 +11,8 @@ package tuners
 
 import (
 	"fmt"
+	"runtime"
+	"strings"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/afero"
@@ -40,12 +42,12 @@ func NewNetTuner(
 			factory.NewNICsBalanceServiceTuner(interfaces),
 			factory.NewNICsIRQsAffinityTuner(interfaces, mode, cpuMask),
 			factory.NewNICsRpsTuner(interfaces, mode, cpuMask),
-			factory.NewNICsRfsTuner(interfaces),
+			factory.NewRfsTuner(interfaces),
 			factory.NewNICsNTupleTuner(interfaces),
 			factory.NewNICsXpsTuner(interfaces),
-			factory.NewRfsTableSizeTuner(),
 			factory.NewListenBacklogTuner(),
 			factory.NewSynBacklogTuner(),
+			factory.NewNetworkBufferTuner(),
 		})
 }
 
@@ -57,8 +59,10 @@ type NetTunersFactory interface {
 	NewNICsNTupleTuner(interfaces []string) Tunable
 	NewNICsXpsTuner(interfaces []string) Tunable
 	NewRfsTableSizeTuner() Tunable
+	NewRfsTuner(interfaces []string) Tunable
 	NewListenBacklogTuner() Tunable
 	NewSynBacklogTuner() Tunable
+	NewNetworkBufferTuner() Tunable
 }
 
 type netTunersFactory struct {
@@ -196,7 +200,19 @@ func (f *netTunersFactory) NewNICsRfsTuner(interfaces []string) Tunable {
 			if err != nil {
 				return NewTuneError(err)
 			}
-			queueLimit := network.OneRPSQueueLimit(limits)
+			if len(limits) == 0 {
+				log.Debugf("'%s' has no RX queues to set the RFS flow count of", nic.Name())
+				return NewUnchangedTuneResult()
+			}
+			tableSize := rfsTableSize(f.fs)
+			queueLimit := network.RPSQueueLimit(tableSize, len(limits))
+			log.Infof(
+				"Splitting %d RFS flow entries across the %d RX queues of '%s' (%d each)",
+				tableSize,
+				len(limits),
+				nic.Name(),
+				queueLimit,
+			)
 			for _, limitFile := range limits {
 				err := f.writeIntToFile(limitFile, queueLimit)
 				if err != nil {
@@ -283,6 +299,17 @@ func (f *netTunersFactory) NewRfsTableSizeTuner() Tunable {
 	)
 }
 
+// Creates a tuner sizing the RFS table (net.core.rps_sock_flow_entries) and
+// splitting its entries evenly among the RX queues of the given interfaces
+// (their rps_flow_cnt). Virtual interfaces are skipped.
+func (f *netTunersFactory) NewRfsTuner(interfaces []string) Tunable {
+	// The table is sized first, as the queues' share is derived from it.
+	return NewAggregatedTunable([]Tunable{
+		f.NewRfsTableSizeTuner(),
+		f.NewNICsRfsTuner(interfaces),
+	})
+}
+
 func (f *netTunersFactory) NewListenBacklogTuner() Tunable {
 	return NewCheckedTunable(
 		f.checkersFactory.NewListenBacklogChecker(),
@@ -319,6 +346,48 @@ func (f *netTunersFactory) NewSynBacklogTuner() Tunable {
 	)
 }
 
+// Raises the max socket buffer sizes to network.BufferMaxSize. Only the
+// properties below it are changed, and the min & default sizes held by the
+// TCP ones are left as they are.
+func (f *netTunersFactory) NewNetworkBufferTuner() Tunable {
+	var tunables []Tunable
+	for _, property := range network.BufferSizeProperties {
+		tunables = append(tunables, f.newNetworkBufferTuner(property))
+	}
+	return NewAggregatedTunable(tunables)
+}
+
+func (f *netTunersFactory) newNetworkBufferTuner(property string) Tunable {
+	return NewCheckedTunable(
+		f.checkersFactory.NewNetworkBufferChecker(property),
+		func() TuneResult {
+			log.Debugf("Tuning max socket buffer size (%s)", property)
+			sizes, err := readSysctlInts(f.fs, property)
+			if err != nil {
+				return NewTuneError(err)
+			}
+			sizes[len(sizes)-1] = network.BufferMaxSize
+			var fields []string
+			for _, size := range sizes {
+				fields = append(fields, fmt.Sprint(size))
+			}
+			err = f.executor.Execute(
+				commands.NewSysctlSetCmd(property, strings.Join(fields, " ")))
+			if err != nil {
+				return NewTuneError(err)
+			}
+			return NewTuneResult(false)
+		},
+		func() (bool, string) {
+			if runtime.GOOS != "linux" {
+				return false, "Socket buffer sizes can only be tuned on Linux"
+			}
+			return true, ""
+		},
+		f.executor.IsLazy(),
+	)
+}
+
 func (f *netTunersFactory) writeIntToFile(file string, value int) error {
 	return f.executor.Execute(
 		commands.NewWriteFileCmd(f.fs, file, fmt.Sprint(value)))
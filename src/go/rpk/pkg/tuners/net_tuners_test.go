@@ -223,3 +223,178 @@ func TestListenBacklogTuner(t *testing.T) {
 		})
 	}
 }
+
+func TestNetworkBufferTuner(t *testing.T) {
+	tests := []struct {
+		name           string
+		values         map[string]string
+		expected       string
+		expectedErrMsg string
+	}{
+		{
+			name: "it shouldn't do anything if current >= reference",
+			values: map[string]string{
+				"/proc/sys/net/core/rmem_max": "16777216",
+				"/proc/sys/net/core/wmem_max": "33554432",
+				"/proc/sys/net/ipv4/tcp_rmem": "4096\t131072\t16777216",
+				"/proc/sys/net/ipv4/tcp_wmem": "4096\t16384\t16777216",
+			},
+		},
+		{
+			name: "it should only raise the values below the reference",
+			values: map[string]string{
+				"/proc/sys/net/core/rmem_max": "212992",
+				"/proc/sys/net/core/wmem_max": "16777216",
+				"/proc/sys/net/ipv4/tcp_rmem": "4096\t131072\t6291456",
+				"/proc/sys/net/ipv4/tcp_wmem": "4096\t16384\t4194304",
+			},
+			expected: `sysctl -w net.core.rmem_max=16777216
+sysctl -w net.ipv4.tcp_rmem='4096 131072 16777216'
+sysctl -w net.ipv4.tcp_wmem='4096 16384 16777216'
+`,
+		},
+		{
+			name: "it should fail if a value isn't a number",
+			values: map[string]string{
+				"/proc/sys/net/core/rmem_max": "lots",
+			},
+			expectedErrMsg: "/proc/sys/net/core/rmem_max",
+		},
+		{
+			name:           "it should fail if the file is missing",
+			expectedErrMsg: "/proc/sys/net/core/rmem_max",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			const scriptPath = "/tune.sh"
+			fs := afero.NewMemMapFs()
+			exec := executors.NewScriptRenderingExecutor(fs, scriptPath)
+			for file, value := range tt.values {
+				_, err := utils.WriteBytes(fs, []byte(value+"\n"), file)
+				require.NoError(st, err)
+			}
+			f, err := mockNetTunersFactory(fs, exec)
+			require.NoError(st, err)
+			tuner := f.NewNetworkBufferTuner()
+			supported, _ := tuner.CheckIfSupported()
+			require.True(st, supported)
+			res := tuner.Tune()
+			if tt.expectedErrMsg != "" {
+				require.Contains(st, res.Error().Error(), tt.expectedErrMsg)
+				return
+			}
+			require.NoError(st, res.Error())
+			contents, err := afero.ReadFile(fs, scriptPath)
+			require.NoError(st, err)
+			expected := `#!/bin/bash
+
+# Redpanda Tuning Script
+# ----------------------------------
+# This file was autogenerated by RPK
+
+` + tt.expected
+			require.Exactly(st, expected, string(contents))
+		})
+	}
+}
+
+func writeRfsFiles(t *testing.T, fs afero.Fs, files map[string]string) {
+	for file, value := range files {
+		_, err := utils.WriteBytes(fs, []byte(value+"\n"), file)
+		require.NoError(t, err)
+	}
+	// Only HW interfaces have a device.
+	require.NoError(t, fs.MkdirAll("/sys/class/net/eth0/device", 0755))
+}
+
+func TestRfsTuner(t *testing.T) {
+	const scriptPath = "/tune.sh"
+	fs := afero.NewMemMapFs()
+	writeRfsFiles(t, fs, map[string]string{
+		"/proc/sys/net/core/rps_sock_flow_entries":      "4096",
+		"/sys/class/net/eth0/queues/rx-0/rps_flow_cnt":  "0",
+		"/sys/class/net/eth0/queues/rx-2/rps_flow_cnt":  "0",
+		"/sys/class/net/eth0/queues/rx-10/rps_flow_cnt": "0",
+		"/sys/class/net/eth0/queues/tx-0/xps_cpus":      "0",
+		"/sys/class/net/lo/queues/rx-0/rps_flow_cnt":    "0",
+	})
+	exec := executors.NewScriptRenderingExecutor(fs, scriptPath)
+	f, err := mockNetTunersFactory(fs, exec)
+	require.NoError(t, err)
+	res := f.NewRfsTuner([]string{"eth0", "lo"}).Tune()
+	require.NoError(t, res.Error())
+	contents, err := afero.ReadFile(fs, scriptPath)
+	require.NoError(t, err)
+	expected := `#!/bin/bash
+
+# Redpanda Tuning Script
+# ----------------------------------
+# This file was autogenerated by RPK
+
+sysctl -w net.core.rps_sock_flow_entries=32768
+echo '10922' > /sys/class/net/eth0/queues/rx-0/rps_flow_cnt
+echo '10922' > /sys/class/net/eth0/queues/rx-2/rps_flow_cnt
+echo '10922' > /sys/class/net/eth0/queues/rx-10/rps_flow_cnt
+`
+	require.Exactly(t, expected, string(contents))
+}
+
+func TestNicRfsChecker(t *testing.T) {
+	tests := []struct {
+		name             string
+		files            map[string]string
+		expectedOk       bool
+		expectedCurrent  string
+		expectedRequired string
+	}{
+		{
+			name: "it should report the current distribution",
+			files: map[string]string{
+				"/proc/sys/net/core/rps_sock_flow_entries":     "4096",
+				"/sys/class/net/eth0/queues/rx-0/rps_flow_cnt": "16384",
+				"/sys/class/net/eth0/queues/rx-3/rps_flow_cnt": "0",
+			},
+			expectedCurrent:  "rx-0: 16384, rx-3: 0",
+			expectedRequired: "32768 flow entries split evenly across the RX queues",
+		},
+		{
+			name: "it should split a bigger existing table",
+			files: map[string]string{
+				"/proc/sys/net/core/rps_sock_flow_entries":     "65536",
+				"/sys/class/net/eth0/queues/rx-0/rps_flow_cnt": "32768",
+				"/sys/class/net/eth0/queues/rx-3/rps_flow_cnt": "32768",
+			},
+			expectedOk:       true,
+			expectedCurrent:  "rx-0: 32768, rx-3: 32768",
+			expectedRequired: "65536 flow entries split evenly across the RX queues",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			fs := afero.NewMemMapFs()
+			writeRfsFiles(st, fs, tt.files)
+			procFile := irq.NewProcFile(fs)
+			proc := os.NewProc()
+			eth, err := ethtool.NewEthtoolWrapper()
+			require.NoError(st, err)
+			deviceInfo := irq.NewDeviceInfo(fs, procFile)
+			exec := executors.NewDirectExecutor()
+			f := tuners.NewNetCheckersFactory(
+				fs,
+				procFile,
+				deviceInfo,
+				eth,
+				irq.NewBalanceService(fs, proc, exec, time.Second),
+				irq.NewCpuMasks(fs, hwloc.NewHwLocCmd(proc, time.Second), exec),
+			)
+			nic := network.NewNic(fs, procFile, deviceInfo, eth, "eth0")
+			res := f.NewNicRfsChecker(nic).Check()
+			require.NoError(st, res.Err)
+			require.Equal(st, tt.expectedOk, res.IsOk)
+			require.Equal(st, tt.expectedCurrent, res.Current)
+			require.Equal(st, tt.expectedRequired, res.Required)
+		})
+	}
+}
@@ -16,5 +16,15 @@ const (
 	RfsTableSize         = 32768
 	SynBacklogSize       = 4096
 	ListenBacklogSize    = 4096
+	BufferMaxSize        = 16777216
 	MaxInt               = int(^uint(0) >> 1)
 )
+
+// The sysctls limiting the socket buffer sizes. The TCP ones hold the min,
+// default and max sizes, of which only the max is tuned.
+var BufferSizeProperties = []string{
+	"net.core.rmem_max",
+	"net.core.wmem_max",
+	"net.ipv4.tcp_rmem",
+	"net.ipv4.tcp_wmem",
+}
@@ -11,6 +11,7 @@ package network
 
 import (
 	"fmt"
+	"path/filepath"
 	"regexp"
 	"sort"
 	"strconv"
@@ -50,6 +51,7 @@ type Nic interface {
 	GetXpsCPUFiles() ([]string, error)
 	GetRpsLimitFiles() ([]string, error)
 	GetNTupleStatus() (NTupleStatus, error)
+	GetNumaNode() (int, error)
 	Name() string
 }
 
@@ -227,7 +229,36 @@ func (n *nic) GetXpsCPUFiles() ([]string, error) {
 }
 
 func (n *nic) GetRpsLimitFiles() ([]string, error) {
-	return afero.Glob(n.fs, fmt.Sprintf("/sys/class/net/%s/queues/*/rps_flow_cnt", n.name))
+	// RX queues aren't necessarily numbered contiguously (e.g. when some
+	// were disabled), so they're globbed rather than counted, then sorted by
+	// their number.
+	files, err := afero.Glob(
+		n.fs,
+		fmt.Sprintf("/sys/class/net/%s/queues/rx-*/rps_flow_cnt", n.name),
+	)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return rxQueueNumber(files[i]) < rxQueueNumber(files[j])
+	})
+	return files, nil
+}
+
+// Returns the number of the RX queue a file under
+// /sys/class/net/<nic>/queues/rx-<n>/ belongs to, or -1 if it can't be parsed.
+func rxQueueNumber(file string) int {
+	number, err := strconv.Atoi(strings.TrimPrefix(RxQueueName(file), "rx-"))
+	if err != nil {
+		return -1
+	}
+	return number
+}
+
+// Returns the name of the RX queue a file under
+// /sys/class/net/<nic>/queues/rx-<n>/ belongs to, e.g. "rx-0".
+func RxQueueName(file string) string {
+	return filepath.Base(filepath.Dir(file))
 }
 
 func (n *nic) GetNTupleStatus() (NTupleStatus, error) {
@@ -243,3 +274,8 @@ func (n *nic) GetNTupleStatus() (NTupleStatus, error) {
 	}
 	return NTupleNotSupported, nil
 }
+
+// Returns the NUMA node the NIC is attached to, or -1 if it's unknown.
+func (n *nic) GetNumaNode() (int, error) {
+	return irq.GetNumaNode(n.fs, fmt.Sprintf("/sys/class/net/%s", n.name))
+}
@@ -117,6 +117,18 @@ func GetHwInterfaceIRQsDistribution(
 	if err != nil {
 		return nil, err
 	}
+	// Handling the IRQs in the socket local to the NIC's PCIe root complex
+	// avoids crossing the interconnect between sockets.
+	numaNode, err := nic.GetNumaNode()
+	if err != nil {
+		return nil, err
+	}
+	if numaNode >= 0 {
+		irqCPUMask, err = cpuMasks.RestrictToNumaNode(irqCPUMask, numaNode)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	if maxRxQueues >= len(allIRQs) {
 		log.Debugf("Calculating distribution '%s' IRQs", nic.Name())
@@ -177,6 +189,11 @@ func CollectIRQs(nic Nic) ([]int, error) {
 	return IRQs, nil
 }
 
-func OneRPSQueueLimit(limits []string) int {
-	return RfsTableSize / len(limits)
+// Returns the number of flow entries each of the queues gets when the RFS
+// table's entries are split evenly among them.
+func RPSQueueLimit(tableSize int, queues int) int {
+	if queues == 0 {
+		return 0
+	}
+	return tableSize / queues
 }
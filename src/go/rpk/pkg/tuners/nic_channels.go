@@ -0,0 +1,142 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners
+
+import (
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/os"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/ethtool"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/network"
+)
+
+// Returns the settings setting the number of combined channels, i.e. of the
+// queues serving both RX and TX, each with its own IRQ, to the number of
+// cores, or to the maximum the NIC supports if it's lower.
+func newChannelSettings(cores int) ethtoolSettings {
+	return ethtoolSettings{
+		checkerID: NicChannelsChecker,
+		name:      "combined channels",
+		getOption: "-l",
+		setOption: "-L",
+		recommend: func(lines []string) (current, required ethtool.Settings) {
+			maximums, counts := ethtool.ParseChannelParameters(lines)
+			current, required = ethtool.Settings{}, ethtool.Settings{}
+			max, maxOk := maximums["combined"]
+			count, countOk := counts["combined"]
+			if !maxOk || !countOk || max == 0 {
+				return current, required
+			}
+			target := cores
+			if max < target {
+				target = max
+			}
+			current["combined"] = fmt.Sprint(count)
+			required["combined"] = fmt.Sprint(target)
+			return current, required
+		},
+	}
+}
+
+// Creates a tuner setting the number of combined channels of the given NICs
+// (see PhysicalNics) to the number of cores, or to the maximum each NIC
+// supports, so that each core gets its own queue. The NICs whose driver
+// doesn't support 'ethtool -l' are skipped. So are the excluded ones (e.g.
+// the one carrying the RPC connection), as changing the channels briefly
+// resets the link. The changes are reversible (see commands.Reversible).
+func NewNicChannelsTuner(
+	nics []network.Nic,
+	cores int,
+	excluded []string,
+	proc os.Proc,
+	timeout time.Duration,
+	executor executors.Executor,
+) Tunable {
+	settings := newChannelSettings(cores)
+	skip := map[string]bool{}
+	for _, nic := range excluded {
+		skip[nic] = true
+	}
+	var tunables []Tunable
+	for _, nic := range nics {
+		if skip[nic.Name()] {
+			tunables = append(tunables, &excludedNicChannels{nic: nic.Name()})
+			continue
+		}
+		checker := &ethtoolChecker{
+			proc:     proc,
+			timeout:  timeout,
+			nic:      nic.Name(),
+			settings: settings,
+		}
+		tunables = append(tunables, &nicChannelsTunable{
+			Tunable: newCheckedEthtoolTunable(checker, executor),
+			checker: checker,
+		})
+	}
+	return NewAggregatedTunable(tunables)
+}
+
+// Reports the NICs whose driver doesn't support 'ethtool -l' when tuning,
+// instead of silently passing their check.
+type nicChannelsTunable struct {
+	Tunable
+	checker *ethtoolChecker
+}
+
+func (t *nicChannelsTunable) Tune() TuneResult {
+	_, _, err := t.checker.read()
+	if isUnsupportedByDriver(err) {
+		log.Infof(
+			"Skipping '%s' %s, as its driver doesn't report them: %v",
+			t.checker.nic,
+			t.checker.settings.name,
+			err,
+		)
+		return NewUnchangedTuneResult()
+	}
+	return t.Tunable.Tune()
+}
+
+func (t *nicChannelsTunable) Check() ([]CheckResult, error) {
+	return []CheckResult{*t.checker.Check()}, nil
+}
+
+// Reports a NIC whose channels are left as they are.
+type excludedNicChannels struct {
+	nic string
+}
+
+func (n *excludedNicChannels) CheckIfSupported() (supported bool, reason string) {
+	return true, ""
+}
+
+func (n *excludedNicChannels) Tune() TuneResult {
+	log.Infof(
+		"Skipping '%s' combined channels, as it carries the RPC connection,"+
+			" which changing them would reset",
+		n.nic,
+	)
+	return NewUnchangedTuneResult()
+}
+
+func (n *excludedNicChannels) Check() ([]CheckResult, error) {
+	return []CheckResult{{
+		CheckerId: NicChannelsChecker,
+		IsOk:      true,
+		Desc:      fmt.Sprintf("NIC %s combined channels", n.nic),
+		Severity:  Warning,
+		Current:   "carries the RPC connection",
+		Required:  "kept as is",
+	}}, nil
+}
@@ -0,0 +1,118 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors"
+)
+
+func channels(max, current string) string {
+	return `Channel parameters:
+Pre-set maximums:
+RX:		n/a
+TX:		n/a
+Other:		1
+Combined:	` + max + `
+Current hardware settings:
+RX:		n/a
+TX:		n/a
+Other:		1
+Combined:	` + current + `
+`
+}
+
+func TestNicChannelsTuner(t *testing.T) {
+	tests := []struct {
+		name             string
+		outputs          map[string]string
+		excluded         []string
+		expectedCommands []string
+		expectedCurrent  []string
+		expectedRequired []string
+	}{
+		{
+			name: "it should set the combined channels to the number of cores",
+			outputs: map[string]string{
+				"-l eth0": channels("63", "2"),
+				"-l eth1": channels("4", "1"),
+			},
+			expectedCommands: []string{
+				"ethtool -L eth0 combined 8",
+				"ethtool -L eth1 combined 4",
+			},
+			expectedCurrent:  []string{"combined 2", "combined 1"},
+			expectedRequired: []string{"combined 8", "combined 4"},
+		},
+		{
+			name: "it should skip the NICs whose driver doesn't support it",
+			outputs: map[string]string{
+				"-l eth1": channels("16", "8"),
+			},
+			expectedCurrent:  []string{"unsupported by the driver", "combined 8"},
+			expectedRequired: []string{"unsupported by the driver", "combined 8"},
+		},
+		{
+			name: "it should skip the excluded NICs",
+			outputs: map[string]string{
+				"-l eth0": channels("63", "2"),
+				"-l eth1": channels("63", "2"),
+			},
+			excluded:         []string{"eth0"},
+			expectedCommands: []string{"ethtool -L eth1 combined 8"},
+			expectedCurrent:  []string{"carries the RPC connection", "combined 2"},
+			expectedRequired: []string{"kept as is", "combined 8"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			const scriptPath = "/tune.sh"
+			fs := afero.NewMemMapFs()
+			require.NoError(st, fs.MkdirAll("/sys/class/net/eth1/device", 0755))
+			nics := physicalNics(st, fs, "eth0", "eth1")
+			proc := &ethtoolProcMock{outputs: tt.outputs}
+			tuner := tuners.NewNicChannelsTuner(
+				nics,
+				8,
+				tt.excluded,
+				proc,
+				time.Second,
+				executors.NewScriptRenderingExecutor(fs, scriptPath),
+			)
+
+			results, err := tuners.CheckTunable(tuner)
+			require.NoError(st, err)
+			require.Len(st, results, 2)
+			for i, res := range results {
+				require.Equal(st, tt.expectedCurrent[i], res.Current)
+				require.Equal(st, tt.expectedRequired[i], res.Required)
+			}
+
+			res := tuner.Tune()
+			require.NoError(st, res.Error())
+			require.Equal(st, len(tt.expectedCommands) > 0, res.IsChanged())
+			script, err := afero.ReadFile(fs, scriptPath)
+			require.NoError(st, err)
+			var commands []string
+			for _, line := range strings.Split(string(script), "\n") {
+				if strings.HasPrefix(line, "ethtool") {
+					commands = append(commands, line)
+				}
+			}
+			require.Equal(st, tt.expectedCommands, commands)
+		})
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors/commands"
+)
+
+// A package providing the binaries a tuner runs.
+type RequiredPackage struct {
+	Name     string
+	Binaries []string
+}
+
+func (p RequiredPackage) missingBinaries() []string {
+	var missing []string
+	for _, bin := range p.Binaries {
+		if _, err := exec.LookPath(bin); err != nil {
+			missing = append(missing, bin)
+		}
+	}
+	return missing
+}
+
+type packageInstallingTuner struct {
+	Tunable
+	pkg      RequiredPackage
+	executor executors.Executor
+}
+
+// Wraps tuner, so that if any of pkg's binaries is missing, pkg is installed
+// (see commands.NewInstallPackagesCmd) before checking whether the tuner is
+// supported, instead of it being reported as unsupported. With a lazy
+// executor nothing is installed, as the tuner needs the binaries on the host
+// it runs on.
+func NewPackageInstallingTuner(
+	tuner Tunable, pkg RequiredPackage, executor executors.Executor,
+) Tunable {
+	return &packageInstallingTuner{Tunable: tuner, pkg: pkg, executor: executor}
+}
+
+func (t *packageInstallingTuner) CheckIfSupported() (supported bool, reason string) {
+	missing := t.pkg.missingBinaries()
+	if len(missing) > 0 && !t.executor.IsLazy() {
+		log.Infof(
+			"Installing '%s', as '%s' can't be found",
+			t.pkg.Name,
+			strings.Join(missing, "', '"),
+		)
+		err := t.executor.Execute(commands.NewInstallPackagesCmd(t.pkg.Name))
+		if err != nil {
+			return false, fmt.Sprintf(
+				"'%s' is missing and couldn't be installed: %v",
+				t.pkg.Name,
+				err,
+			)
+		}
+	}
+	return t.Tunable.CheckIfSupported()
+}
+
+func (t *packageInstallingTuner) Check() ([]CheckResult, error) {
+	return CheckTunable(t.Tunable)
+}
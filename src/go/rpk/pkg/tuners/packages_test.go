@@ -0,0 +1,85 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners_test
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors/commands"
+)
+
+type unsupportedTunable struct{}
+
+func (*unsupportedTunable) CheckIfSupported() (bool, string) {
+	return false, "the binary isn't installed"
+}
+
+func (*unsupportedTunable) Tune() tuners.TuneResult {
+	return tuners.NewUnchangedTuneResult()
+}
+
+// Records the commands it's given without executing them.
+type recordingExecutor struct {
+	cmds []commands.Command
+}
+
+func (e *recordingExecutor) Execute(cmd commands.Command) error {
+	e.cmds = append(e.cmds, cmd)
+	return nil
+}
+
+func (*recordingExecutor) IsLazy() bool {
+	return false
+}
+
+func TestPackageInstallingTuner(t *testing.T) {
+	missing := tuners.RequiredPackage{
+		Name:     "some-package",
+		Binaries: []string{"sh", "no-such-binary-anywhere"},
+	}
+
+	executor := &recordingExecutor{}
+	tuner := tuners.NewPackageInstallingTuner(&unsupportedTunable{}, missing, executor)
+	supported, reason := tuner.CheckIfSupported()
+	// The tuner is checked again once the package is installed.
+	require.False(t, supported)
+	require.Equal(t, "the binary isn't installed", reason)
+	require.Len(t, executor.cmds, 1)
+	require.Equal(t, "Install 'some-package'", executor.cmds[0].Describe().Desc)
+
+	executor = &recordingExecutor{}
+	present := tuners.RequiredPackage{Name: "shell", Binaries: []string{"sh"}}
+	tuner = tuners.NewPackageInstallingTuner(&unsupportedTunable{}, present, executor)
+	tuner.CheckIfSupported()
+	require.Empty(t, executor.cmds)
+}
+
+func TestPackageInstallingTunerLazy(t *testing.T) {
+	const scriptPath = "/tune.sh"
+	fs := afero.NewMemMapFs()
+	pkg := tuners.RequiredPackage{
+		Name:     "some-package",
+		Binaries: []string{"no-such-binary-anywhere"},
+	}
+	tuner := tuners.NewPackageInstallingTuner(
+		&unsupportedTunable{},
+		pkg,
+		executors.NewScriptRenderingExecutor(fs, scriptPath),
+	)
+	supported, _ := tuner.CheckIfSupported()
+	require.False(t, supported)
+	script, err := afero.ReadFile(fs, scriptPath)
+	require.NoError(t, err)
+	require.NotContains(t, string(script), "some-package")
+}
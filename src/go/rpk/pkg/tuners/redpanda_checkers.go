@@ -60,6 +60,30 @@ const (
 	KernelVersion
 	WriteCachePolicyChecker
 	BallastFileChecker
+	NetworkBuffersChecker
+	DirtyRatiosChecker
+	FilesLimitChecker
+	NicRingsChecker
+	NicCoalesceChecker
+	CgroupChecker
+	CoredumpChecker
+	BallastFileSizeChecker
+	CpuGovernorChecker
+	CpuFrequencyBoostChecker
+	CStatesChecker
+	PStatesChecker
+	CpuIdleStatesChecker
+	NicChannelsChecker
+	DataDirFilesystemChecker
+	DataDirMountOptionsChecker
+	BlockQueueChecker
+	WorkqueueCpuMaskChecker
+	KsoftirqdChecker
+	IRQBalanceBannedCpusChecker
+	DefaultIRQAffinityChecker
+	TaskSchedulerChecker
+	MaxMapCountChecker
+	CoreLimitChecker
 )
 
 func NewConfigChecker(conf *config.Config) Checker {
@@ -197,7 +221,7 @@ func RedpandaCheckers(
 	irqDeviceInfo := irq.NewDeviceInfo(fs, irqProcFile)
 	blockDevices := disk.NewBlockDevices(fs, irqDeviceInfo, irqProcFile, proc, timeout)
 	deviceFeatures := disk.NewDeviceFeatures(fs, blockDevices)
-	schedulerChecker := NewDirectorySchedulerChecker(
+	schedulerCheckers := NewDirectorySchedulerCheckers(
 		fs,
 		config.Redpanda.Directory,
 		deviceFeatures,
@@ -231,6 +255,7 @@ func RedpandaCheckers(
 	}
 	netCheckersFactory := NewNetCheckersFactory(
 		fs, irqProcFile, irqDeviceInfo, ethtool, balanceService, cpuMasks)
+	nics := PhysicalNics(fs, irqProcFile, irqDeviceInfo, ethtool, interfaces)
 	checkers := map[CheckerID][]Checker{
 		ConfigFileChecker:             {NewConfigChecker(config)},
 		IoConfigFileChecker:           {NewIOConfigFileExistanceChecker(fs, ioConfigFile)},
@@ -239,9 +264,9 @@ func RedpandaCheckers(
 		DataDirAccessChecker:          {NewDataDirWritableChecker(fs, config.Redpanda.Directory)},
 		DiskSpaceChecker:              {NewFreeDiskSpaceChecker(config.Redpanda.Directory)},
 		FsTypeChecker:                 {NewFilesystemTypeChecker(config.Redpanda.Directory)},
-		TransparentHugePagesChecker:   {NewTransparentHugePagesChecker(fs)},
+		TransparentHugePagesChecker:   NewTransparentHugePagesCheckers(fs, RecommendedTHPMode),
 		NtpChecker:                    {NewNTPSyncChecker(timeout, fs)},
-		SchedulerChecker:              {schedulerChecker},
+		SchedulerChecker:              schedulerCheckers,
 		NomergesChecker:               {nomergesChecker},
 		DiskIRQsAffinityChecker:       {dirIRQAffinityChecker},
 		DiskIRQsAffinityStaticChecker: {dirIRQAffinityStaticChecker},
@@ -249,14 +274,20 @@ func RedpandaCheckers(
 		SynBacklogChecker:             {netCheckersFactory.NewSynBacklogChecker()},
 		ListenBacklogChecker:          {netCheckersFactory.NewListenBacklogChecker()},
 		RfsTableEntriesChecker:        {netCheckersFactory.NewRfsTableSizeChecker()},
+		NetworkBuffersChecker:         netCheckersFactory.NewNetworkBufferCheckers(),
 		NicIRQsAffinitStaticChecker:   {netCheckersFactory.NewNicIRQAffinityStaticChecker(interfaces)},
 		NicIRQsAffinitChecker:         netCheckersFactory.NewNicIRQAffinityCheckers(interfaces, irq.Default, "all"),
 		NicRpsChecker:                 netCheckersFactory.NewNicRpsSetCheckers(interfaces, irq.Default, "all"),
 		NicRfsChecker:                 netCheckersFactory.NewNicRfsCheckers(interfaces),
 		NicXpsChecker:                 netCheckersFactory.NewNicXpsCheckers(interfaces),
+		NicRingsChecker:               NewNicRingsCheckers(nics, proc, timeout),
+		NicCoalesceChecker:            NewNicCoalesceCheckers(nics, proc, timeout),
 		MaxAIOEvents:                  {NewMaxAIOEventsChecker(fs)},
+		MaxMapCountChecker:            {NewMaxMapCountChecker(fs)},
 		ClockSource:                   {NewClockSourceChecker(fs)},
 		Swappiness:                    {NewSwappinessChecker(fs)},
+		DirtyRatiosChecker:            NewDirtyRatioCheckers(fs),
+		FilesLimitChecker:             {NewFilesLimitChecker()},
 		KernelVersion:                 {NewKernelVersionChecker(GetKernelVersion)},
 		BallastFileChecker:            {NewBallastFileChecker(fs, config)},
 	}
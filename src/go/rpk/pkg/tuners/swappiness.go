@@ -25,32 +25,60 @@ const (
 	ExpectedSwappiness int    = 1
 )
 
+// Creates a checker which passes if the swappiness is ExpectedSwappiness or
+// lower, since it's never raised.
 func NewSwappinessChecker(fs afero.Fs) Checker {
-	return NewEqualityChecker(
+	return newSwappinessChecker(fs, ExpectedSwappiness)
+}
+
+func newSwappinessChecker(fs afero.Fs, swappiness int) Checker {
+	return NewIntChecker(
 		Swappiness,
 		"Swappiness",
 		Warning,
-		ExpectedSwappiness,
-		func() (interface{}, error) {
-			content, err := afero.ReadFile(fs, File)
-			if err != nil {
-				return -1, err
-			}
-			return strconv.Atoi(strings.TrimSpace(string(content)))
+		func(current int) bool {
+			return current <= swappiness
+		},
+		func() string {
+			return fmt.Sprintf("<= %d", swappiness)
+		},
+		func() (int, error) {
+			return readSwappiness(fs)
 		},
 	)
 }
 
-func NewSwappinessTuner(fs afero.Fs, executor executors.Executor) Tunable {
+func readSwappiness(fs afero.Fs) (int, error) {
+	content, err := afero.ReadFile(fs, File)
+	if err != nil {
+		return -1, err
+	}
+	return strconv.Atoi(strings.TrimSpace(string(content)))
+}
+
+// Creates a tuner lowering the swappiness to the given one, e.g.
+// ExpectedSwappiness.
+func NewSwappinessTuner(
+	fs afero.Fs, swappiness int, executor executors.Executor,
+) Tunable {
 	return NewCheckedTunable(
-		NewSwappinessChecker(fs),
+		newSwappinessChecker(fs, swappiness),
 		func() TuneResult {
-			log.Debugf("Setting swappiness to %d", ExpectedSwappiness)
-			err := executor.Execute(
+			current, err := readSwappiness(fs)
+			if err != nil {
+				return NewTuneError(err)
+			}
+			log.Infof(
+				"Changing '%s' from %d to %d",
+				File,
+				current,
+				swappiness,
+			)
+			err = executor.Execute(
 				commands.NewWriteFileCmd(
-					fs, File, fmt.Sprint(ExpectedSwappiness)))
+					fs, File, fmt.Sprint(swappiness)))
 			if err != nil {
-				log.Errorf("got an error while writing %d to %s: %v", ExpectedSwappiness, File, err)
+				log.Errorf("got an error while writing %d to %s: %v", swappiness, File, err)
 				return NewTuneError(err)
 			}
 			return NewTuneResult(false)
@@ -116,7 +116,7 @@ func TestTuner(t *testing.T) {
 				err := tt.before(fs)
 				require.NoError(t, err)
 			}
-			tuner := tuners.NewSwappinessTuner(fs, executors.NewDirectExecutor())
+			tuner := tuners.NewSwappinessTuner(fs, tuners.ExpectedSwappiness, executors.NewDirectExecutor())
 			res := tuner.Tune()
 			if tt.expectErr {
 				require.Error(t, res.Error())
@@ -0,0 +1,221 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors/commands"
+)
+
+// Where the scheduler's settings are exposed on kernels >= 5.13, which
+// moved them out of /proc/sys/kernel.
+const SchedDebugDir = "/sys/kernel/debug/sched"
+
+// A task scheduler setting, along with the name of its file in
+// SchedDebugDir on the kernels which don't expose it as a sysctl.
+type schedSetting struct {
+	sysctlSetting
+	debugFile string
+}
+
+// A task is considered cache-hot, and isn't migrated to another CPU, for
+// kernel.sched_migration_cost_ns after it last ran, and
+// kernel.sched_latency_ns is the period every runnable task gets to run
+// within. Raising them reduces the needless migrations of the broker's
+// threads, trading latency for throughput. The ranges keep the values within
+// an order of magnitude of the kernel's defaults (0.5ms and 6ms to 24ms,
+// depending on the number of CPUs). Values above the desired ones are kept,
+// as long as they're within the ranges.
+var TaskSchedulerSettings = []schedSetting{
+	{
+		sysctlSetting: sysctlSetting{
+			property: "kernel.sched_migration_cost_ns",
+			desired:  5000000,
+			min:      100000,
+			max:      50000000,
+		},
+		debugFile: "migration_cost_ns",
+	},
+	{
+		sysctlSetting: sysctlSetting{
+			property: "kernel.sched_latency_ns",
+			desired:  24000000,
+			min:      1000000,
+			max:      100000000,
+		},
+		debugFile: "latency_ns",
+	},
+}
+
+func (s schedSetting) sysctlFile() string {
+	return filepath.Join("/proc/sys", strings.ReplaceAll(s.property, ".", "/"))
+}
+
+func (s schedSetting) debugFsFile() string {
+	return filepath.Join(SchedDebugDir, s.debugFile)
+}
+
+// Describes the values the setting's check accepts.
+func (s schedSetting) required() string {
+	return fmt.Sprintf(">= %d, <= %d", s.target(), s.max)
+}
+
+// Returns the file the setting can be changed through: its sysctl file, or
+// its debugfs one if the kernel doesn't expose the former, or "" if neither
+// is writable. The files are opened for writing, without writing to them, as
+// their mode doesn't tell whether the kernel accepts writes, e.g. when
+// debugfs is locked down.
+func (s schedSetting) findFile(fs afero.Fs) string {
+	for _, file := range []string{s.sysctlFile(), s.debugFsFile()} {
+		f, err := fs.OpenFile(file, os.O_WRONLY, 0)
+		if err != nil {
+			log.Debugf("Can't write to '%s': %v", file, err)
+			continue
+		}
+		f.Close()
+		return file
+	}
+	return ""
+}
+
+// Creates a tuner setting the task scheduler's migration cost and latency
+// (see TaskSchedulerSettings), only changing the ones which differ, through
+// whichever of /proc/sys/kernel and SchedDebugDir the kernel exposes them
+// in. The ones the kernel exposes in neither are reported and skipped.
+func NewTaskSchedulerTuner(fs afero.Fs, executor executors.Executor) Tunable {
+	var tunables []Tunable
+	for _, setting := range TaskSchedulerSettings {
+		tunables = append(tunables, newSchedSettingTunable(fs, setting, executor))
+	}
+	return &taskSchedulerTuner{
+		Tunable:  NewAggregatedTunable(tunables),
+		fs:       fs,
+		settings: TaskSchedulerSettings,
+	}
+}
+
+type taskSchedulerTuner struct {
+	Tunable
+	fs       afero.Fs
+	settings []schedSetting
+}
+
+func (t *taskSchedulerTuner) CheckIfSupported() (supported bool, reason string) {
+	for _, setting := range t.settings {
+		if setting.findFile(t.fs) != "" {
+			return t.Tunable.CheckIfSupported()
+		}
+	}
+	return false, fmt.Sprintf(
+		"The task scheduler settings are writable neither in /proc/sys/kernel"+
+			" nor in '%s' (is debugfs mounted?)",
+		SchedDebugDir,
+	)
+}
+
+func (t *taskSchedulerTuner) Check() ([]CheckResult, error) {
+	return CheckTunable(t.Tunable)
+}
+
+func newSchedSettingTunable(
+	fs afero.Fs, setting schedSetting, executor executors.Executor,
+) Tunable {
+	file := setting.findFile(fs)
+	if file == "" {
+		return &unwritableSchedSetting{setting}
+	}
+	return NewCheckedTunable(
+		NewIntChecker(
+			TaskSchedulerChecker,
+			fmt.Sprintf("Task scheduler %s (%s)", setting.debugFile, file),
+			Warning,
+			func(current int) bool {
+				return current >= setting.target() && current <= setting.max
+			},
+			setting.required,
+			func() (int, error) {
+				return readIntFile(fs, file)
+			},
+		),
+		func() TuneResult {
+			value := fmt.Sprint(setting.target())
+			log.Infof("Setting '%s' to %s through '%s'", setting.property, value, file)
+			var cmd commands.Command
+			if file == setting.sysctlFile() {
+				cmd = commands.NewSysctlSetCmd(setting.property, value)
+			} else {
+				cmd = commands.NewWriteFileCmd(fs, file, value)
+			}
+			err := executor.Execute(cmd)
+			if err != nil {
+				return NewTuneError(err)
+			}
+			return NewTuneResult(false)
+		},
+		func() (bool, string) {
+			return true, ""
+		},
+		executor.IsLazy(),
+	)
+}
+
+func readIntFile(fs afero.Fs, file string) (int, error) {
+	content, err := afero.ReadFile(fs, file)
+	if err != nil {
+		return 0, err
+	}
+	value, err := strconv.Atoi(strings.TrimSpace(string(content)))
+	if err != nil {
+		return 0, fmt.Errorf("couldn't parse '%s': %w", file, err)
+	}
+	return value, nil
+}
+
+// Reports that a task scheduler setting can't be changed, as the kernel
+// exposes it in neither location.
+type unwritableSchedSetting struct {
+	setting schedSetting
+}
+
+func (s *unwritableSchedSetting) CheckIfSupported() (supported bool, reason string) {
+	return true, ""
+}
+
+func (s *unwritableSchedSetting) Tune() TuneResult {
+	log.Warnf("Skipping '%s', as %s", s.setting.property, s.reason())
+	return NewUnchangedTuneResult()
+}
+
+func (s *unwritableSchedSetting) Check() ([]CheckResult, error) {
+	return []CheckResult{{
+		CheckerId: TaskSchedulerChecker,
+		IsOk:      true,
+		Desc:      fmt.Sprintf("Task scheduler %s", s.setting.debugFile),
+		Severity:  Warning,
+		Current:   s.reason(),
+		Required:  s.setting.required(),
+	}}, nil
+}
+
+func (s *unwritableSchedSetting) reason() string {
+	return fmt.Sprintf(
+		"neither '%s' nor '%s' is writable",
+		s.setting.sysctlFile(),
+		s.setting.debugFsFile(),
+	)
+}
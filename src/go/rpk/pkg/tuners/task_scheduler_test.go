@@ -0,0 +1,185 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors"
+)
+
+const (
+	migrationCostSysctlFile = "/proc/sys/kernel/sched_migration_cost_ns"
+	latencySysctlFile       = "/proc/sys/kernel/sched_latency_ns"
+	migrationCostDebugFile  = "/sys/kernel/debug/sched/migration_cost_ns"
+	latencyDebugFile        = "/sys/kernel/debug/sched/latency_ns"
+)
+
+// An afero.Fs which refuses to open the given files for writing, as the
+// kernel does for read-only sysctls or a locked down debugfs, regardless of
+// their mode.
+type readOnlyFilesFs struct {
+	afero.Fs
+	files []string
+}
+
+func (fs *readOnlyFilesFs) OpenFile(
+	name string, flag int, perm os.FileMode,
+) (afero.File, error) {
+	for _, file := range fs.files {
+		if file == name && flag&(os.O_WRONLY|os.O_RDWR) != 0 {
+			return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrPermission}
+		}
+	}
+	return fs.Fs.OpenFile(name, flag, perm)
+}
+
+func TestTaskSchedulerTuner(t *testing.T) {
+	const scriptPath = "/tune.sh"
+	tests := []struct {
+		name            string
+		files           map[string]string
+		readOnly        []string
+		expectedResults []tuners.CheckResult
+		expectedScript  string
+	}{
+		{
+			name: "it should set the sysctls which differ",
+			files: map[string]string{
+				migrationCostSysctlFile: "500000\n",
+				latencySysctlFile:       "24000000\n",
+			},
+			expectedResults: []tuners.CheckResult{
+				{
+					Desc:     "Task scheduler migration_cost_ns (/proc/sys/kernel/sched_migration_cost_ns)",
+					Current:  "500000",
+					Required: ">= 5000000, <= 50000000",
+				},
+				{
+					Desc:     "Task scheduler latency_ns (/proc/sys/kernel/sched_latency_ns)",
+					IsOk:     true,
+					Current:  "24000000",
+					Required: ">= 24000000, <= 100000000",
+				},
+			},
+			expectedScript: "sysctl -w kernel.sched_migration_cost_ns=5000000\n",
+		},
+		{
+			name: "it should write to debugfs on kernels which moved the settings there",
+			files: map[string]string{
+				migrationCostDebugFile: "500000\n",
+				latencyDebugFile:       "6000000\n",
+			},
+			expectedResults: []tuners.CheckResult{
+				{
+					Desc:     "Task scheduler migration_cost_ns (/sys/kernel/debug/sched/migration_cost_ns)",
+					Current:  "500000",
+					Required: ">= 5000000, <= 50000000",
+				},
+				{
+					Desc:     "Task scheduler latency_ns (/sys/kernel/debug/sched/latency_ns)",
+					Current:  "6000000",
+					Required: ">= 24000000, <= 100000000",
+				},
+			},
+			expectedScript: "echo '5000000' > /sys/kernel/debug/sched/migration_cost_ns\n" +
+				"echo '24000000' > /sys/kernel/debug/sched/latency_ns\n",
+		},
+		{
+			name: "it should keep the values above the desired ones, within the bounds",
+			files: map[string]string{
+				migrationCostSysctlFile: "60000000\n",
+				latencySysctlFile:       "50000000\n",
+			},
+			expectedResults: []tuners.CheckResult{
+				{
+					Desc:     "Task scheduler migration_cost_ns (/proc/sys/kernel/sched_migration_cost_ns)",
+					Current:  "60000000",
+					Required: ">= 5000000, <= 50000000",
+				},
+				{
+					Desc:     "Task scheduler latency_ns (/proc/sys/kernel/sched_latency_ns)",
+					IsOk:     true,
+					Current:  "50000000",
+					Required: ">= 24000000, <= 100000000",
+				},
+			},
+			expectedScript: "sysctl -w kernel.sched_migration_cost_ns=5000000\n",
+		},
+		{
+			name: "it should report the settings which aren't writable",
+			files: map[string]string{
+				migrationCostSysctlFile: "500000\n",
+				latencySysctlFile:       "6000000\n",
+			},
+			readOnly: []string{latencySysctlFile},
+			expectedResults: []tuners.CheckResult{
+				{
+					Desc:     "Task scheduler migration_cost_ns (/proc/sys/kernel/sched_migration_cost_ns)",
+					Current:  "500000",
+					Required: ">= 5000000, <= 50000000",
+				},
+				{
+					Desc:     "Task scheduler latency_ns",
+					IsOk:     true,
+					Current:  "neither '/proc/sys/kernel/sched_latency_ns' nor '/sys/kernel/debug/sched/latency_ns' is writable",
+					Required: ">= 24000000, <= 100000000",
+				},
+			},
+			expectedScript: "sysctl -w kernel.sched_migration_cost_ns=5000000\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mem := afero.NewMemMapFs()
+			for file, content := range tt.files {
+				require.NoError(t, afero.WriteFile(mem, file, []byte(content), 0644))
+			}
+			fs := &readOnlyFilesFs{mem, tt.readOnly}
+			tuner := tuners.NewTaskSchedulerTuner(
+				fs,
+				executors.NewScriptRenderingExecutor(fs, scriptPath),
+			)
+			supported, reason := tuner.CheckIfSupported()
+			require.True(t, supported, reason)
+
+			results, err := tuners.CheckTunable(tuner)
+			require.NoError(t, err)
+			require.Len(t, results, len(tt.expectedResults))
+			for i, expected := range tt.expectedResults {
+				require.EqualValues(t, tuners.TaskSchedulerChecker, results[i].CheckerId)
+				require.Equal(t, expected.Desc, results[i].Desc)
+				require.Equal(t, expected.IsOk, results[i].IsOk)
+				require.Equal(t, expected.Current, results[i].Current)
+				require.Equal(t, expected.Required, results[i].Required)
+			}
+
+			res := tuner.Tune()
+			require.NoError(t, res.Error())
+			script, err := afero.ReadFile(fs, scriptPath)
+			require.NoError(t, err)
+			require.Contains(t, string(script), "RPK\n\n"+tt.expectedScript)
+		})
+	}
+}
+
+func TestTaskSchedulerTunerUnsupported(t *testing.T) {
+	tuner := tuners.NewTaskSchedulerTuner(
+		afero.NewMemMapFs(),
+		executors.NewDirectExecutor(),
+	)
+	supported, reason := tuner.CheckIfSupported()
+	require.False(t, supported)
+	require.Contains(t, reason, "is debugfs mounted?")
+}
@@ -21,12 +21,14 @@ import (
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors/commands"
 )
 
-const enabledFile = "enabled"
-
-type thpTuner struct {
-	fs       afero.Fs
-	executor executors.Executor
-}
+const (
+	enabledFile = "enabled"
+	defragFile  = "defrag"
+	// The THP mode recommended for Redpanda. With 'madvise', huge pages are
+	// only used for the memory regions the allocator asks for, avoiding the
+	// latency spikes caused by 'always' compacting memory on some kernels.
+	RecommendedTHPMode = "madvise"
+)
 
 // Returns the known locations where config files for Transparent Huge Pages
 // might be found across distros.
@@ -53,50 +55,94 @@ func getTHPDir(fs afero.Fs) (string, error) {
 	)
 }
 
-/*
-/ Create a new tuner to enable Transparent Huge Pages
-*/
-func NewEnableTHPTuner(fs afero.Fs, executor executors.Executor) Tunable {
-	return &thpTuner{fs: fs, executor: executor}
-}
-
-func (t *thpTuner) CheckIfSupported() (bool, string) {
-	dir, err := getTHPDir(t.fs)
+// Reads the options in a THP file, e.g. 'always [madvise] never', where the
+// selected one is wrapped in brackets.
+func readTHPOptions(fs afero.Fs, file string) (*system.RuntimeOptions, error) {
+	dir, err := getTHPDir(fs)
 	if err != nil {
-		return false, err.Error()
+		return nil, err
 	}
-	return dir != "", ""
+	return system.ReadRuntineOptions(fs, filepath.Join(dir, file))
 }
 
-func (t *thpTuner) Tune() TuneResult {
-	dir, err := getTHPDir(t.fs)
-	if err != nil {
-		return NewTuneError(err)
-	}
-	// Write 'always' to the 'enabled' file in the existing THP dir so that
-	// they're always enabled.
-	// https://www.kernel.org/doc/Documentation/vm/transhuge.txt
+/*
+/ Create a new tuner setting the Transparent Huge Pages 'enabled' and 'defrag'
+/ modes to the given one, e.g. RecommendedTHPMode.
+*/
+func NewTransparentHugePagesTuner(
+	fs afero.Fs, executor executors.Executor, mode string,
+) Tunable {
+	return NewAggregatedTunable([]Tunable{
+		newTHPFileTuner(fs, executor, enabledFile, mode),
+		newTHPFileTuner(fs, executor, defragFile, mode),
+	})
+}
 
-	cmd := commands.NewWriteFileCmd(
-		t.fs,
-		filepath.Join(dir, enabledFile),
-		"always",
+func newTHPFileTuner(
+	fs afero.Fs, executor executors.Executor, file string, mode string,
+) Tunable {
+	return NewCheckedTunable(
+		NewTransparentHugePagesChecker(fs, file, mode),
+		func() TuneResult {
+			dir, err := getTHPDir(fs)
+			if err != nil {
+				return NewTuneError(err)
+			}
+			// https://www.kernel.org/doc/Documentation/vm/transhuge.txt
+			err = executor.Execute(commands.NewWriteFileCmd(
+				fs,
+				filepath.Join(dir, file),
+				mode,
+			))
+			if err != nil {
+				return NewTuneError(err)
+			}
+			return NewTuneResult(false)
+		},
+		func() (bool, string) {
+			options, err := readTHPOptions(fs, file)
+			if err != nil {
+				return false, err.Error()
+			}
+			for _, opt := range options.GetAvailable() {
+				if opt == mode {
+					return true, ""
+				}
+			}
+			return false, fmt.Sprintf(
+				"'%s' isn't a valid THP %s mode in this kernel",
+				mode,
+				file,
+			)
+		},
+		executor.IsLazy(),
 	)
-	err = t.executor.Execute(cmd)
-	if err != nil {
-		return NewTuneError(err)
-	}
-	return NewTuneResult(false)
 }
 
-func NewTransparentHugePagesChecker(fs afero.Fs) Checker {
+// Creates a checker comparing the selected option in the given THP file
+// ('enabled' or 'defrag') to mode.
+func NewTransparentHugePagesChecker(
+	fs afero.Fs, file string, mode string,
+) Checker {
 	return NewEqualityChecker(
 		TransparentHugePagesChecker,
-		"Transparent huge pages active",
+		fmt.Sprintf("Transparent huge pages mode (%s)", file),
 		Warning,
-		true,
+		mode,
 		func() (interface{}, error) {
-			return system.GetTransparentHugePagesActive(fs)
+			options, err := readTHPOptions(fs, file)
+			if err != nil {
+				return "", err
+			}
+			return options.GetActive(), nil
 		},
 	)
 }
+
+// Creates the checkers for the 'enabled' and 'defrag' THP modes.
+func NewTransparentHugePagesCheckers(fs afero.Fs, mode string) []Checker {
+	return []Checker{
+		NewTransparentHugePagesChecker(fs, enabledFile, mode),
+		NewTransparentHugePagesChecker(fs, defragFile, mode),
+	}
+}
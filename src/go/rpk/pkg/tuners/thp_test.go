@@ -11,6 +11,7 @@ package tuners_test
 
 import (
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/spf13/afero"
@@ -19,21 +20,45 @@ import (
 	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors"
 )
 
+func writeTHPFiles(fs afero.Fs, dir string, enabled, defrag string) error {
+	err := fs.MkdirAll(dir, 0755)
+	if err != nil {
+		return err
+	}
+	files := map[string]string{"enabled": enabled, "defrag": defrag}
+	for name, content := range files {
+		if content == "" {
+			continue
+		}
+		err = afero.WriteFile(fs, filepath.Join(dir, name), []byte(content+"\n"), 0644)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func TestTHPTunerSupported(t *testing.T) {
 	tests := []struct {
 		name           string
 		thpDir         string
+		enabled        string
+		defrag         string
 		expected       bool
 		expectedReason string
 	}{
 		{
 			name:     "should return true if the default dir exists",
 			thpDir:   "/sys/kernel/mm/transparent_hugepage",
+			enabled:  "[always] madvise never",
+			defrag:   "always defer defer+madvise [madvise] never",
 			expected: true,
 		},
 		{
 			name:     "should return true if the RHEL-specific dir exists",
 			thpDir:   "/sys/kernel/mm/redhat_transparent_hugepage",
+			enabled:  "[always] madvise never",
+			defrag:   "[always] madvise never",
 			expected: true,
 		},
 		{
@@ -41,6 +66,21 @@ func TestTHPTunerSupported(t *testing.T) {
 			expected:       false,
 			expectedReason: "None of /sys/kernel/mm/transparent_hugepage, /sys/kernel/mm/redhat_transparent_hugepage was found",
 		},
+		{
+			name:           "should return false if a file is missing",
+			thpDir:         "/sys/kernel/mm/transparent_hugepage",
+			enabled:        "[always] madvise never",
+			expected:       false,
+			expectedReason: "/sys/kernel/mm/transparent_hugepage/defrag",
+		},
+		{
+			name:           "should return false if the kernel doesn't support the mode",
+			thpDir:         "/sys/kernel/mm/transparent_hugepage",
+			enabled:        "[always] never",
+			defrag:         "[always] madvise never",
+			expected:       false,
+			expectedReason: "'madvise' isn't a valid THP enabled mode in this kernel",
+		},
 	}
 
 	for _, tt := range tests {
@@ -48,122 +88,147 @@ func TestTHPTunerSupported(t *testing.T) {
 			fs := afero.NewMemMapFs()
 
 			if tt.thpDir != "" {
-				err := fs.MkdirAll(tt.thpDir, 0755)
+				err := writeTHPFiles(fs, tt.thpDir, tt.enabled, tt.defrag)
 				require.NoError(st, err)
 			}
 			exec := executors.NewDirectExecutor()
-			tuner := tuners.NewEnableTHPTuner(fs, exec)
+			tuner := tuners.NewTransparentHugePagesTuner(
+				fs, exec, tuners.RecommendedTHPMode)
 			supported, reason := tuner.CheckIfSupported()
 			require.Equal(st, tt.expected, supported)
-			require.Equal(st, tt.expectedReason, reason)
+			require.Contains(st, reason, tt.expectedReason)
 		})
 	}
 }
 
 func TestTHPTunerScriptExecutor(t *testing.T) {
-	expected := `#!/bin/bash
-
-# Redpanda Tuning Script
-# ----------------------------------
-# This file was autogenerated by RPK
+	tests := []struct {
+		name     string
+		enabled  string
+		defrag   string
+		expected string
+	}{
+		{
+			name:    "should set both modes if they're different",
+			enabled: "[always] madvise never",
+			defrag:  "always defer defer+madvise madvise [never]",
+			expected: `echo 'madvise' > /sys/kernel/mm/transparent_hugepage/enabled
+echo 'madvise' > /sys/kernel/mm/transparent_hugepage/defrag
+`,
+		},
+		{
+			name:    "should only set the modes which are different",
+			enabled: "always [madvise] never",
+			defrag:  "[always] defer defer+madvise madvise never",
+			expected: `echo 'madvise' > /sys/kernel/mm/transparent_hugepage/defrag
+`,
+		},
+		{
+			name:    "should do nothing if both modes are already set",
+			enabled: "always [madvise] never",
+			defrag:  "always defer defer+madvise [madvise] never",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(st *testing.T) {
+			fs := afero.NewMemMapFs()
+			scriptFileName := "script.sh"
+			exec := executors.NewScriptRenderingExecutor(fs, scriptFileName)
+			dir := "/sys/kernel/mm/transparent_hugepage"
+			err := writeTHPFiles(fs, dir, tt.enabled, tt.defrag)
+			require.NoError(st, err)
 
-echo 'always' > /sys/kernel/mm/transparent_hugepage/enabled
-`
-	fs := afero.NewMemMapFs()
-	scriptFileName := "script.sh"
-	exec := executors.NewScriptRenderingExecutor(fs, scriptFileName)
-	dir := "/sys/kernel/mm/transparent_hugepage"
-	err := fs.MkdirAll(dir, 0755)
-	require.NoError(t, err)
+			tuner := tuners.NewTransparentHugePagesTuner(
+				fs, exec, tuners.RecommendedTHPMode)
 
-	_, err = fs.Create(filepath.Join(dir, "enabled"))
-	require.NoError(t, err)
+			res := tuner.Tune()
+			require.False(st, res.IsFailed())
 
-	tuner := tuners.NewEnableTHPTuner(fs, exec)
+			bs, err := afero.ReadFile(fs, scriptFileName)
+			require.NoError(st, err)
 
-	res := tuner.Tune()
-	require.False(t, res.IsFailed())
+			expected := `#!/bin/bash
 
-	bs, err := afero.ReadFile(fs, scriptFileName)
-	require.NoError(t, err)
+# Redpanda Tuning Script
+# ----------------------------------
+# This file was autogenerated by RPK
 
-	require.Equal(t, expected, string(bs))
+` + tt.expected
+			require.Equal(st, expected, string(bs))
+		})
+	}
 }
 
 func TestTHPTunerDirectExecutor(t *testing.T) {
-	// The file is on sysfs, and when printed, its contents are printed
-	// differently, showing the valid options and the chosen option wrapped
-	// in brackets, so in reality it would look like this:
-	//
-	// [always] madvise never
-	//
-	// This expected value is meant only for this test, which uses an
-	// fs.MemMapFs
-	expected := "always"
+	// The files are on sysfs, and when printed, their contents show the
+	// valid options and the chosen option wrapped in brackets. The memory
+	// fs used here doesn't, so after tuning, each holds just the written
+	// mode, which reads as the single (and so selected) option.
 	fs := afero.NewMemMapFs()
 	exec := executors.NewDirectExecutor()
 	dir := "/sys/kernel/mm/transparent_hugepage"
-	filePath := filepath.Join(dir, "enabled")
-	err := fs.MkdirAll(dir, 0755)
-	require.NoError(t, err)
-
-	_, err = fs.Create(filePath)
+	err := writeTHPFiles(fs, dir, "[always] madvise never", "[always] madvise never")
 	require.NoError(t, err)
 
-	tuner := tuners.NewEnableTHPTuner(fs, exec)
+	tuner := tuners.NewTransparentHugePagesTuner(
+		fs, exec, tuners.RecommendedTHPMode)
 
 	res := tuner.Tune()
-	require.False(t, res.IsFailed())
+	require.NoError(t, res.Error())
 	require.False(t, res.IsRebootRequired())
 
-	bs, err := afero.ReadFile(fs, filePath)
-	require.NoError(t, err)
-
-	require.Equal(t, expected, string(bs))
+	for _, file := range []string{"enabled", "defrag"} {
+		bs, err := afero.ReadFile(fs, filepath.Join(dir, file))
+		require.NoError(t, err)
+		require.Equal(t, tuners.RecommendedTHPMode, strings.TrimSpace(string(bs)))
+	}
 }
 
 func TestTHPCheckID(t *testing.T) {
-	c := tuners.NewTransparentHugePagesChecker(afero.NewMemMapFs())
+	c := tuners.NewTransparentHugePagesChecker(
+		afero.NewMemMapFs(), "enabled", tuners.RecommendedTHPMode)
 	require.Equal(t, tuners.CheckerID(tuners.TransparentHugePagesChecker), c.Id())
 }
 
 func TestTHPCheck(t *testing.T) {
 	tests := []struct {
-		name     string
-		contents string
-		expected bool
+		name            string
+		contents        string
+		expected        bool
+		expectedCurrent string
 	}{
 		{
-			name:     "should return true if the active value is 'always'",
-			contents: "[always] madvise never",
-			expected: true,
+			name:            "should return false if the active value is 'always'",
+			contents:        "[always] madvise never",
+			expected:        false,
+			expectedCurrent: "always",
 		},
 		{
-			name:     "should return true if the active value is 'madvise'",
-			contents: "always [madvise] never",
-			expected: true,
+			name:            "should return true if the active value is 'madvise'",
+			contents:        "always [madvise] never",
+			expected:        true,
+			expectedCurrent: "madvise",
 		},
 		{
-			name:     "should return false if the active value is 'never'",
-			contents: "always madvise [never]",
-			expected: false,
+			name:            "should return false if the active value is 'never'",
+			contents:        "always madvise [never]",
+			expected:        false,
+			expectedCurrent: "never",
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(st *testing.T) {
 			fs := afero.NewMemMapFs()
 			dir := "/sys/kernel/mm/transparent_hugepage"
-			err := fs.MkdirAll(dir, 0755)
-			require.NoError(t, err)
-
-			f, err := fs.Create(filepath.Join(dir, "enabled"))
-			require.NoError(t, err)
-			defer f.Close()
-			_, err = f.Write([]byte(tt.contents))
-			require.NoError(t, err)
-			c := tuners.NewTransparentHugePagesChecker(fs)
+			err := writeTHPFiles(fs, dir, tt.contents, "")
+			require.NoError(st, err)
+			c := tuners.NewTransparentHugePagesChecker(
+				fs, "enabled", tuners.RecommendedTHPMode)
 			res := c.Check()
-			require.Equal(t, tt.expected, res.IsOk)
+			require.NoError(st, res.Err)
+			require.Equal(st, tt.expected, res.IsOk)
+			require.Equal(st, tt.expectedCurrent, res.Current)
+			require.Equal(st, tuners.RecommendedTHPMode, res.Required)
 		})
 	}
 }
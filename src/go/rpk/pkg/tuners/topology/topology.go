@@ -0,0 +1,304 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package topology
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+)
+
+const cpusDir = "/sys/devices/system/cpu"
+
+var cpuDirRegex = regexp.MustCompile(`^cpu(\d+)$`)
+
+// A physical core, along with its hardware threads (i.e. the logical CPUs
+// which are hyperthread siblings).
+type Core struct {
+	ID int
+	// The ids of the core's online logical CPUs, in ascending order.
+	Threads []int
+}
+
+type Socket struct {
+	ID int
+	// The socket's cores, in the order of their first thread.
+	Cores []Core
+}
+
+// The online CPUs, grouped in sockets and cores.
+type Topology struct {
+	// The sockets, in ascending order of their ids.
+	Sockets []Socket
+}
+
+// Reads the topology of the online CPUs from sysfs. Offline CPUs, whose
+// topology directory is missing (or whose 'online' file reads 0), are
+// skipped, and gaps in the CPUs numbering (e.g. after hot-unplugging them)
+// are allowed.
+func Read(fs afero.Fs) (*Topology, error) {
+	entries, err := afero.ReadDir(fs, cpusDir)
+	if err != nil {
+		return nil, err
+	}
+	online := map[int]bool{}
+	var cpus []int
+	for _, entry := range entries {
+		match := cpuDirRegex.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+		cpu, _ := strconv.Atoi(match[1])
+		isOnline, err := isCpuOnline(fs, cpu)
+		if err != nil {
+			return nil, err
+		}
+		if !isOnline {
+			log.Debugf("Skipping offline CPU %d", cpu)
+			continue
+		}
+		online[cpu] = true
+		cpus = append(cpus, cpu)
+	}
+	if len(cpus) == 0 {
+		return nil, fmt.Errorf("no online CPUs were found in '%s'", cpusDir)
+	}
+	sort.Ints(cpus)
+
+	sockets := map[int]*Socket{}
+	// The cores are told apart by their threads, as core ids are only
+	// unique within a die, and a socket may have several of them.
+	seen := map[int]bool{}
+	for _, cpu := range cpus {
+		if seen[cpu] {
+			continue
+		}
+		socketID, err := readTopologyInt(fs, cpu, "physical_package_id")
+		if err != nil {
+			return nil, err
+		}
+		coreID, err := readTopologyInt(fs, cpu, "core_id")
+		if err != nil {
+			return nil, err
+		}
+		siblings, err := readSiblings(fs, cpu)
+		if err != nil {
+			return nil, err
+		}
+		core := Core{ID: coreID}
+		for _, sibling := range siblings {
+			if online[sibling] && !seen[sibling] {
+				core.Threads = append(core.Threads, sibling)
+				seen[sibling] = true
+			}
+		}
+		if !seen[cpu] {
+			// The siblings list should always include the CPU
+			// itself.
+			core.Threads = append([]int{cpu}, core.Threads...)
+			seen[cpu] = true
+		}
+		socket, ok := sockets[socketID]
+		if !ok {
+			socket = &Socket{ID: socketID}
+			sockets[socketID] = socket
+		}
+		socket.Cores = append(socket.Cores, core)
+	}
+
+	topology := &Topology{}
+	for _, socket := range sockets {
+		topology.Sockets = append(topology.Sockets, *socket)
+	}
+	sort.Slice(topology.Sockets, func(i, j int) bool {
+		return topology.Sockets[i].ID < topology.Sockets[j].ID
+	})
+	return topology, nil
+}
+
+// Returns the ids of the CPUs in cpus which are online, in the order in which
+// they should be used to spread work over as many physical cores as
+// possible: the first thread of every core, then the second one of every
+// core, and so on. If cpus is nil, all the online CPUs are returned.
+func (t *Topology) SpreadOrder(cpus []int) []int {
+	cores := t.restrict(cpus)
+	var order []int
+	for i := 0; ; i++ {
+		added := false
+		for _, core := range cores {
+			if i < len(core.Threads) {
+				order = append(order, core.Threads[i])
+				added = true
+			}
+		}
+		if !added {
+			return order
+		}
+	}
+}
+
+// Returns the number of physical cores with threads in cpus, or of all of
+// them if cpus is nil.
+func (t *Topology) NumCores(cpus []int) int {
+	return len(t.restrict(cpus))
+}
+
+// Returns the number of online CPUs in cpus, or of all of them if cpus is
+// nil.
+func (t *Topology) NumThreads(cpus []int) int {
+	return len(t.SpreadOrder(cpus))
+}
+
+// Returns the cores with threads in cpus, keeping only those threads.
+func (t *Topology) restrict(cpus []int) []Core {
+	var allowed map[int]bool
+	if cpus != nil {
+		allowed = map[int]bool{}
+		for _, cpu := range cpus {
+			allowed[cpu] = true
+		}
+	}
+	var cores []Core
+	for _, socket := range t.Sockets {
+		for _, core := range socket.Cores {
+			restricted := Core{ID: core.ID}
+			for _, thread := range core.Threads {
+				if allowed == nil || allowed[thread] {
+					restricted.Threads = append(restricted.Threads, thread)
+				}
+			}
+			if len(restricted.Threads) > 0 {
+				cores = append(cores, restricted)
+			}
+		}
+	}
+	return cores
+}
+
+// Parses a list of CPUs in cpuset(7)'s list format, e.g. '0-3,8,10-11'.
+func ParseList(list string) ([]int, error) {
+	var cpus []int
+	list = strings.TrimSpace(list)
+	if list == "" {
+		return cpus, nil
+	}
+	for _, part := range strings.Split(list, ",") {
+		bounds := strings.SplitN(part, "-", 2)
+		first, err := strconv.Atoi(bounds[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid CPU list '%s': %w", list, err)
+		}
+		last := first
+		if len(bounds) == 2 {
+			last, err = strconv.Atoi(bounds[1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid CPU list '%s': %w", list, err)
+			}
+		}
+		if last < first {
+			return nil, fmt.Errorf("invalid CPU list '%s': '%s' is a decreasing range", list, part)
+		}
+		for cpu := first; cpu <= last; cpu++ {
+			cpus = append(cpus, cpu)
+		}
+	}
+	return cpus, nil
+}
+
+// Parses a CPU mask in the kernel's bitmap format, i.e. comma-separated
+// groups of 32 bits in hex, the most significant one first, e.g.
+// '00000001,0000000f' for CPUs 0-3 and 32.
+func ParseMask(mask string) ([]int, error) {
+	mask = strings.TrimSpace(mask)
+	groups := strings.Split(mask, ",")
+	var cpus []int
+	for i := len(groups) - 1; i >= 0; i-- {
+		bits, err := strconv.ParseUint(groups[i], 16, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CPU mask '%s': %w", mask, err)
+		}
+		base := (len(groups) - 1 - i) * 32
+		for bit := 0; bit < 32; bit++ {
+			if bits&(1<<bit) != 0 {
+				cpus = append(cpus, base+bit)
+			}
+		}
+	}
+	return cpus, nil
+}
+
+// Formats cpus as a CPU mask in the kernel's bitmap format (see ParseMask).
+func FormatMask(cpus []int) string {
+	var groups []uint32
+	for _, cpu := range cpus {
+		for len(groups) <= cpu/32 {
+			groups = append(groups, 0)
+		}
+		groups[cpu/32] |= 1 << (cpu % 32)
+	}
+	if len(groups) == 0 {
+		return "0"
+	}
+	parts := make([]string, 0, len(groups))
+	parts = append(parts, fmt.Sprintf("%x", groups[len(groups)-1]))
+	for i := len(groups) - 2; i >= 0; i-- {
+		parts = append(parts, fmt.Sprintf("%08x", groups[i]))
+	}
+	return strings.Join(parts, ",")
+}
+
+func isCpuOnline(fs afero.Fs, cpu int) (bool, error) {
+	cpuDir := filepath.Join(cpusDir, fmt.Sprintf("cpu%d", cpu))
+	hasTopology, err := afero.DirExists(fs, filepath.Join(cpuDir, "topology"))
+	if err != nil || !hasTopology {
+		return false, err
+	}
+	// CPU 0 usually can't be offlined, and lacks the file.
+	content, err := afero.ReadFile(fs, filepath.Join(cpuDir, "online"))
+	if os.IsNotExist(err) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(string(content)) != "0", nil
+}
+
+func readTopologyInt(fs afero.Fs, cpu int, name string) (int, error) {
+	path := topologyFile(cpu, name)
+	content, err := afero.ReadFile(fs, path)
+	if err != nil {
+		return 0, err
+	}
+	value, err := strconv.Atoi(strings.TrimSpace(string(content)))
+	if err != nil {
+		return 0, fmt.Errorf("couldn't parse '%s': %w", path, err)
+	}
+	return value, nil
+}
+
+func readSiblings(fs afero.Fs, cpu int) ([]int, error) {
+	content, err := afero.ReadFile(fs, topologyFile(cpu, "thread_siblings_list"))
+	if err != nil {
+		return nil, err
+	}
+	return ParseList(string(content))
+}
+
+func topologyFile(cpu int, name string) string {
+	return filepath.Join(cpusDir, fmt.Sprintf("cpu%d", cpu), "topology", name)
+}
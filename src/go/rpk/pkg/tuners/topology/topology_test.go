@@ -0,0 +1,177 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package topology_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/topology"
+)
+
+type cpu struct {
+	id       int
+	socket   int
+	core     int
+	siblings string
+	// If set, the 'online' file is written with it.
+	online string
+	// Offline CPUs lack their topology directory.
+	offline bool
+}
+
+func writeSysfs(t *testing.T, fs afero.Fs, cpus []cpu) {
+	require.NoError(t, fs.MkdirAll("/sys/devices/system/cpu/cpufreq", 0755))
+	for _, c := range cpus {
+		dir := fmt.Sprintf("/sys/devices/system/cpu/cpu%d", c.id)
+		require.NoError(t, fs.MkdirAll(dir, 0755))
+		if c.online != "" {
+			require.NoError(t, afero.WriteFile(fs, dir+"/online", []byte(c.online+"\n"), 0644))
+		}
+		if c.offline {
+			continue
+		}
+		files := map[string]string{
+			"physical_package_id":  fmt.Sprint(c.socket),
+			"core_id":              fmt.Sprint(c.core),
+			"thread_siblings_list": c.siblings,
+		}
+		for name, content := range files {
+			path := dir + "/topology/" + name
+			require.NoError(t, afero.WriteFile(fs, path, []byte(content+"\n"), 0644))
+		}
+	}
+}
+
+// 2 sockets with 2 cores each, their hyperthread siblings numbered after all
+// of the cores, as on most x86 machines.
+var twoSockets = []cpu{
+	{id: 0, socket: 0, core: 0, siblings: "0,4"},
+	{id: 1, socket: 0, core: 1, siblings: "1,5"},
+	{id: 2, socket: 1, core: 0, siblings: "2,6"},
+	{id: 3, socket: 1, core: 1, siblings: "3,7"},
+	{id: 4, socket: 0, core: 0, siblings: "0,4"},
+	{id: 5, socket: 0, core: 1, siblings: "1,5"},
+	{id: 6, socket: 1, core: 0, siblings: "2,6"},
+	{id: 7, socket: 1, core: 1, siblings: "3,7"},
+}
+
+func TestRead(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	writeSysfs(t, fs, twoSockets)
+	topo, err := topology.Read(fs)
+	require.NoError(t, err)
+	expected := &topology.Topology{Sockets: []topology.Socket{
+		{ID: 0, Cores: []topology.Core{
+			{ID: 0, Threads: []int{0, 4}},
+			{ID: 1, Threads: []int{1, 5}},
+		}},
+		{ID: 1, Cores: []topology.Core{
+			{ID: 0, Threads: []int{2, 6}},
+			{ID: 1, Threads: []int{3, 7}},
+		}},
+	}}
+	require.Equal(t, expected, topo)
+	require.Equal(t, 4, topo.NumCores(nil))
+	require.Equal(t, 8, topo.NumThreads(nil))
+}
+
+func TestReadOfflineCpus(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	writeSysfs(t, fs, []cpu{
+		{id: 0, socket: 0, core: 0, siblings: "0-1"},
+		{id: 1, socket: 0, core: 0, siblings: "0-1", online: "1"},
+		// Offlined, without its topology directory.
+		{id: 2, offline: true, online: "0"},
+		// Offlined, with a stale topology directory.
+		{id: 3, socket: 0, core: 1, siblings: "3", online: "0"},
+		// There's a gap in the numbering after hot-unplugging CPUs 4-7.
+		{id: 8, socket: 0, core: 4, siblings: "8-9"},
+		{id: 9, socket: 0, core: 4, siblings: "8-9"},
+	})
+	topo, err := topology.Read(fs)
+	require.NoError(t, err)
+	expected := &topology.Topology{Sockets: []topology.Socket{
+		{ID: 0, Cores: []topology.Core{
+			{ID: 0, Threads: []int{0, 1}},
+			{ID: 4, Threads: []int{8, 9}},
+		}},
+	}}
+	require.Equal(t, expected, topo)
+}
+
+func TestReadNoCpus(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	writeSysfs(t, fs, nil)
+	_, err := topology.Read(fs)
+	require.Error(t, err)
+}
+
+func TestSpreadOrder(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	writeSysfs(t, fs, twoSockets)
+	topo, err := topology.Read(fs)
+	require.NoError(t, err)
+
+	require.Equal(t, []int{0, 1, 2, 3, 4, 5, 6, 7}, topo.SpreadOrder(nil))
+	// CPUs 0 and 4 are siblings, so 1 is used before 4.
+	require.Equal(t, []int{0, 1, 4}, topo.SpreadOrder([]int{0, 1, 4}))
+	require.Equal(t, 2, topo.NumCores([]int{0, 1, 4}))
+	require.Equal(t, 3, topo.NumThreads([]int{0, 1, 4}))
+	// CPUs which don't exist are ignored.
+	require.Equal(t, []int{3}, topo.SpreadOrder([]int{3, 12}))
+
+	// Siblings numbered next to each other.
+	fs = afero.NewMemMapFs()
+	writeSysfs(t, fs, []cpu{
+		{id: 0, socket: 0, core: 0, siblings: "0-1"},
+		{id: 1, socket: 0, core: 0, siblings: "0-1"},
+		{id: 2, socket: 0, core: 1, siblings: "2-3"},
+		{id: 3, socket: 0, core: 1, siblings: "2-3"},
+	})
+	topo, err = topology.Read(fs)
+	require.NoError(t, err)
+	require.Equal(t, []int{0, 2, 1, 3}, topo.SpreadOrder(nil))
+}
+
+func TestParseList(t *testing.T) {
+	cpus, err := topology.ParseList("0-2,5,8-9\n")
+	require.NoError(t, err)
+	require.Equal(t, []int{0, 1, 2, 5, 8, 9}, cpus)
+
+	_, err = topology.ParseList("3-1")
+	require.Error(t, err)
+	_, err = topology.ParseList("a")
+	require.Error(t, err)
+}
+
+func TestParseMask(t *testing.T) {
+	cpus, err := topology.ParseMask("f\n")
+	require.NoError(t, err)
+	require.Equal(t, []int{0, 1, 2, 3}, cpus)
+
+	cpus, err = topology.ParseMask("00000001,80000004")
+	require.NoError(t, err)
+	require.Equal(t, []int{2, 31, 32}, cpus)
+
+	_, err = topology.ParseMask("fg")
+	require.Error(t, err)
+	_, err = topology.ParseMask("1ffffffff")
+	require.Error(t, err)
+}
+
+func TestFormatMask(t *testing.T) {
+	require.Equal(t, "0", topology.FormatMask(nil))
+	require.Equal(t, "f0", topology.FormatMask([]int{4, 5, 6, 7}))
+	require.Equal(t, "1,80000004", topology.FormatMask([]int{2, 31, 32}))
+	require.Equal(t, "1,00000000,00000000", topology.FormatMask([]int{64}))
+}
@@ -9,7 +9,27 @@
 
 package tuners
 
+import "errors"
+
 type Tunable interface {
 	CheckIfSupported() (supported bool, reason string)
 	Tune() TuneResult
 }
+
+// Checkable is implemented by the tunables which can tell whether what they
+// tune is already set as required, without changing anything.
+type Checkable interface {
+	// Returns a result for each setting the tunable changes. The errors
+	// reading a setting are reported in its result, while the returned
+	// error means that the settings themselves couldn't be determined.
+	Check() ([]CheckResult, error)
+}
+
+// Checks tunable, if it's Checkable.
+func CheckTunable(tunable Tunable) ([]CheckResult, error) {
+	c, ok := tunable.(Checkable)
+	if !ok {
+		return nil, errors.New("the tuner can't be checked without running it")
+	}
+	return c.Check()
+}
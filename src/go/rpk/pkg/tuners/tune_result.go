@@ -13,11 +13,15 @@ type TuneResult interface {
 	IsFailed() bool
 	Error() error
 	IsRebootRequired() bool
+	// Returns whether the tuner changed the system (or, with a lazy
+	// executor, would have). It's false when it was already tuned.
+	IsChanged() bool
 }
 
 type tuneResult struct {
 	err            error
 	rebootRequired bool
+	changed        bool
 }
 
 func NewTuneError(err error) TuneResult {
@@ -25,7 +29,13 @@ func NewTuneError(err error) TuneResult {
 }
 
 func NewTuneResult(rebootRequired bool) TuneResult {
-	return &tuneResult{rebootRequired: rebootRequired}
+	return &tuneResult{rebootRequired: rebootRequired, changed: true}
+}
+
+// Returns the result of a tuner which found the system already tuned, so it
+// didn't need to change anything.
+func NewUnchangedTuneResult() TuneResult {
+	return &tuneResult{}
 }
 
 func (result *tuneResult) IsFailed() bool {
@@ -39,3 +49,7 @@ func (result *tuneResult) Error() error {
 func (result *tuneResult) IsRebootRequired() bool {
 	return result.rebootRequired
 }
+
+func (result *tuneResult) IsChanged() bool {
+	return result.changed
+}
@@ -24,12 +24,12 @@ func TestNewTuneResult(t *testing.T) {
 		{
 			name:           "Shall indicate that reboot is required when passed true",
 			rebootRequired: true,
-			want:           &tuneResult{rebootRequired: true},
+			want:           &tuneResult{rebootRequired: true, changed: true},
 		},
 		{
 			name:           "Shall indicate that reboot is required when passed true",
 			rebootRequired: false,
-			want:           &tuneResult{rebootRequired: false},
+			want:           &tuneResult{rebootRequired: false, changed: true},
 		},
 	}
 	for _, tt := range tests {
@@ -39,3 +39,10 @@ func TestNewTuneResult(t *testing.T) {
 		})
 	}
 }
+
+func TestNewUnchangedTuneResult(t *testing.T) {
+	got := NewUnchangedTuneResult()
+	require.False(t, got.IsChanged())
+	require.False(t, got.IsFailed())
+	require.False(t, got.IsRebootRequired())
+}
@@ -0,0 +1,200 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners
+
+import (
+	"fmt"
+	"runtime"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors/commands"
+)
+
+// A sysctl property to tune, along with the range its value is kept in.
+type sysctlSetting struct {
+	property string
+	desired  int
+	min      int
+	max      int
+}
+
+// A dirty page ratio, along with the sysctl property setting the same limit
+// in bytes. Setting either one clears the other, and the kernel only uses the
+// one which is set.
+type dirtyRatioSetting struct {
+	sysctlSetting
+	bytesProperty string
+}
+
+// The ratios are percentages of the available memory. Writeback starts in the
+// background once vm.dirty_background_ratio is dirty, and processes writing
+// are throttled at vm.dirty_ratio. Keeping them low bounds the amount of data
+// a single fsync may have to flush. The desired values are upper bounds, so
+// lower ratios are kept.
+var DirtyRatioSettings = []dirtyRatioSetting{
+	{
+		sysctlSetting: sysctlSetting{
+			property: "vm.dirty_background_ratio",
+			desired:  5,
+			min:      1,
+			max:      50,
+		},
+		bytesProperty: "vm.dirty_background_bytes",
+	},
+	{
+		sysctlSetting: sysctlSetting{
+			property: "vm.dirty_ratio",
+			desired:  10,
+			min:      5,
+			max:      80,
+		},
+		bytesProperty: "vm.dirty_bytes",
+	},
+}
+
+// Returns the value the setting should have: the desired one, clamped into
+// its range.
+func (s sysctlSetting) target() int {
+	if s.desired < s.min {
+		return s.min
+	}
+	if s.desired > s.max {
+		return s.max
+	}
+	return s.desired
+}
+
+// Returns the limit set in bytes instead of the ratio, or 0 if there's none.
+func (s dirtyRatioSetting) bytes(fs afero.Fs) int {
+	values, err := readSysctlInts(fs, s.bytesProperty)
+	if err != nil {
+		return 0
+	}
+	return values[0]
+}
+
+func newDirtyRatioChecker(fs afero.Fs, setting dirtyRatioSetting) Checker {
+	desc := fmt.Sprintf("VM dirty ratio (%s)", setting.property)
+	if bytes := setting.bytes(fs); bytes > 0 {
+		return &dirtyBytesSet{desc: desc, setting: setting, bytes: bytes}
+	}
+	return NewIntChecker(
+		DirtyRatiosChecker,
+		desc,
+		Warning,
+		func(current int) bool {
+			return current <= setting.target()
+		},
+		func() string {
+			return fmt.Sprintf("<= %d", setting.target())
+		},
+		func() (int, error) {
+			values, err := readSysctlInts(fs, setting.property)
+			if err != nil {
+				return 0, err
+			}
+			return values[0], nil
+		},
+	)
+}
+
+func NewDirtyRatioCheckers(fs afero.Fs) []Checker {
+	var checkers []Checker
+	for _, setting := range DirtyRatioSettings {
+		checkers = append(checkers, newDirtyRatioChecker(fs, setting))
+	}
+	return checkers
+}
+
+func newDirtyRatioTuner(
+	fs afero.Fs, setting dirtyRatioSetting, executor executors.Executor,
+) Tunable {
+	return NewCheckedTunable(
+		newDirtyRatioChecker(fs, setting),
+		func() TuneResult {
+			values, err := readSysctlInts(fs, setting.property)
+			if err != nil {
+				return NewTuneError(err)
+			}
+			log.Infof(
+				"Changing '%s' from %d to %d",
+				setting.property,
+				values[0],
+				setting.target(),
+			)
+			err = executor.Execute(commands.NewSysctlSetCmd(
+				setting.property,
+				fmt.Sprint(setting.target()),
+			))
+			if err != nil {
+				return NewTuneError(err)
+			}
+			return NewTuneResult(false)
+		},
+		func() (bool, string) {
+			if runtime.GOOS != "linux" {
+				return false, "Setting sysctl properties is only supported on Linux"
+			}
+			return true, ""
+		},
+		executor.IsLazy(),
+	)
+}
+
+// Creates a tuner lowering the dirty page ratios (see DirtyRatioSettings).
+// The ones whose limit is set in bytes instead are reported as unused and
+// left as they are, since setting the ratio would clear it.
+func NewDirtyRatioTuner(fs afero.Fs, executor executors.Executor) Tunable {
+	var tunables []Tunable
+	for _, setting := range DirtyRatioSettings {
+		tunables = append(tunables, newDirtyRatioTuner(fs, setting, executor))
+	}
+	return NewAggregatedTunable(tunables)
+}
+
+// Reports that a dirty page ratio is unused, as its limit is set in bytes.
+type dirtyBytesSet struct {
+	desc    string
+	setting dirtyRatioSetting
+	bytes   int
+}
+
+func (c *dirtyBytesSet) Id() CheckerID {
+	return DirtyRatiosChecker
+}
+
+func (c *dirtyBytesSet) GetDesc() string {
+	return c.desc
+}
+
+func (c *dirtyBytesSet) GetSeverity() Severity {
+	return Warning
+}
+
+func (c *dirtyBytesSet) GetRequiredAsString() string {
+	return fmt.Sprintf("<= %d", c.setting.target())
+}
+
+func (c *dirtyBytesSet) Check() *CheckResult {
+	return &CheckResult{
+		CheckerId: c.Id(),
+		IsOk:      true,
+		Desc:      c.desc,
+		Severity:  c.GetSeverity(),
+		Current: fmt.Sprintf(
+			"unused, as %s is set to %d",
+			c.setting.bytesProperty,
+			c.bytes,
+		),
+		Required: c.GetRequiredAsString(),
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2021 Vectorized, Inc.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.md
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0
+
+package tuners_test
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/tuners/executors"
+	"github.com/vectorizedio/redpanda/src/go/rpk/pkg/utils"
+)
+
+func TestDirtyRatioTuner(t *testing.T) {
+	tests := []struct {
+		name           string
+		values         map[string]string
+		expectedScript string
+	}{
+		{
+			name: "it shouldn't do anything if the values are already set",
+			values: map[string]string{
+				"/proc/sys/vm/dirty_background_ratio": "5",
+				"/proc/sys/vm/dirty_ratio":            "10",
+			},
+		},
+		{
+			name: "it should lower the ratios",
+			values: map[string]string{
+				"/proc/sys/vm/dirty_background_ratio": "10",
+				"/proc/sys/vm/dirty_ratio":            "20",
+			},
+			expectedScript: `sysctl -w vm.dirty_background_ratio=5
+sysctl -w vm.dirty_ratio=10
+`,
+		},
+		{
+			name: "it should never raise the ratios",
+			values: map[string]string{
+				"/proc/sys/vm/dirty_background_ratio": "2",
+				"/proc/sys/vm/dirty_ratio":            "20",
+			},
+			expectedScript: `sysctl -w vm.dirty_ratio=10
+`,
+		},
+		{
+			name: "it should leave the ratios whose limit is set in bytes",
+			values: map[string]string{
+				"/proc/sys/vm/dirty_background_ratio": "0",
+				"/proc/sys/vm/dirty_background_bytes": "1048576",
+				"/proc/sys/vm/dirty_ratio":            "20",
+				"/proc/sys/vm/dirty_bytes":            "0",
+			},
+			expectedScript: `sysctl -w vm.dirty_ratio=10
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			const scriptPath = "/tune.sh"
+			fs := afero.NewMemMapFs()
+			for file, value := range tt.values {
+				_, err := utils.WriteBytes(fs, []byte(value+"\n"), file)
+				require.NoError(t, err)
+			}
+			exec := executors.NewScriptRenderingExecutor(fs, scriptPath)
+			tuner := tuners.NewDirtyRatioTuner(fs, exec)
+			res := tuner.Tune()
+			require.NoError(t, res.Error())
+
+			script, err := afero.ReadFile(fs, scriptPath)
+			require.NoError(t, err)
+			header := "#!/bin/bash\n\n# Redpanda Tuning Script\n# ----------------------------------\n# This file was autogenerated by RPK\n\n"
+			require.Equal(t, header+tt.expectedScript, string(script))
+		})
+	}
+}
+
+func TestDirtyRatioCheckers(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	_, err := utils.WriteBytes(fs, []byte("5\n"), "/proc/sys/vm/dirty_background_ratio")
+	require.NoError(t, err)
+	_, err = utils.WriteBytes(fs, []byte("20\n"), "/proc/sys/vm/dirty_ratio")
+	require.NoError(t, err)
+
+	checkers := tuners.NewDirtyRatioCheckers(fs)
+	require.Len(t, checkers, 2)
+
+	res := checkers[0].Check()
+	require.NoError(t, res.Err)
+	require.True(t, res.IsOk)
+
+	res = checkers[1].Check()
+	require.NoError(t, res.Err)
+	require.False(t, res.IsOk)
+	require.Equal(t, "20", res.Current)
+	require.Equal(t, "<= 10", res.Required)
+
+	_, err = utils.WriteBytes(fs, []byte("1073741824\n"), "/proc/sys/vm/dirty_bytes")
+	require.NoError(t, err)
+	res = tuners.NewDirtyRatioCheckers(fs)[1].Check()
+	require.NoError(t, res.Err)
+	require.True(t, res.IsOk)
+	require.Equal(t, "unused, as vm.dirty_bytes is set to 1073741824", res.Current)
+}
@@ -15,6 +15,8 @@ import (
 	"encoding/hex"
 	"fmt"
 	"io"
+	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 
@@ -117,3 +119,57 @@ func ReadIntFromFile(fs afero.Fs, file string) (int, error) {
 	}
 	return strconv.Atoi(strings.TrimSpace(string(content)))
 }
+
+// The maximum number of links EvalSymlinks follows, like Linux's MAXSYMLINKS.
+const maxSymlinks = 40
+
+// Returns the absolute path path refers to, with its symlinks resolved
+// through fs, like filepath.EvalSymlinks. If fs doesn't support symlinks
+// (see afero.Lstater and afero.LinkReader), the path is only made absolute.
+func EvalSymlinks(fs afero.Fs, path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	lstater, ok := fs.(afero.Lstater)
+	reader, rok := fs.(afero.LinkReader)
+	if !ok || !rok {
+		return abs, nil
+	}
+	resolved := "/"
+	todo := strings.Split(abs, "/")
+	links := 0
+	for len(todo) > 0 {
+		part := todo[0]
+		todo = todo[1:]
+		switch part {
+		case "", ".":
+			continue
+		case "..":
+			resolved = filepath.Dir(resolved)
+			continue
+		}
+		next := filepath.Join(resolved, part)
+		info, lstatCalled, err := lstater.LstatIfPossible(next)
+		if err != nil {
+			return "", err
+		}
+		if !lstatCalled || info.Mode()&os.ModeSymlink == 0 {
+			resolved = next
+			continue
+		}
+		links++
+		if links > maxSymlinks {
+			return "", fmt.Errorf("too many links resolving '%s'", path)
+		}
+		target, err := reader.ReadlinkIfPossible(next)
+		if err != nil {
+			return "", err
+		}
+		if filepath.IsAbs(target) {
+			resolved = "/"
+		}
+		todo = append(strings.Split(target, "/"), todo...)
+	}
+	return resolved, nil
+}
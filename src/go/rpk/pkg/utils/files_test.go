@@ -10,6 +10,8 @@
 package utils_test
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/spf13/afero"
@@ -33,3 +35,25 @@ func TestWriteBytes(t *testing.T) {
 	require.NoError(t, err)
 	require.Exactly(t, bs, buf)
 }
+
+func TestEvalSymlinks(t *testing.T) {
+	dir := t.TempDir()
+	fs := afero.NewOsFs()
+	require.NoError(t, fs.MkdirAll(filepath.Join(dir, "volume", "data"), 0755))
+	require.NoError(t, os.Symlink(filepath.Join(dir, "volume"), filepath.Join(dir, "mnt")))
+	require.NoError(t, os.Symlink("mnt/data", filepath.Join(dir, "data")))
+
+	resolved, err := utils.EvalSymlinks(fs, filepath.Join(dir, "data"))
+	require.NoError(t, err)
+	expected, err := filepath.EvalSymlinks(filepath.Join(dir, "volume", "data"))
+	require.NoError(t, err)
+	require.Equal(t, expected, resolved)
+
+	_, err = utils.EvalSymlinks(fs, filepath.Join(dir, "missing"))
+	require.True(t, os.IsNotExist(err))
+
+	// Without symlinks, the path is only made absolute.
+	resolved, err = utils.EvalSymlinks(afero.NewMemMapFs(), "/var/lib/../lib/redpanda")
+	require.NoError(t, err)
+	require.Equal(t, "/var/lib/redpanda", resolved)
+}